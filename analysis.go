@@ -0,0 +1,188 @@
+package gomme
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// ============================================================================
+// Static Grammar Analysis
+//
+
+// ByteSet is a set of bytes.
+// It is used for the set of bytes a parser can start with (FIRST set).
+type ByteSet [4]uint64
+
+// AllBytes returns a set containing all 256 bytes.
+func AllBytes() ByteSet {
+	return ByteSet{^uint64(0), ^uint64(0), ^uint64(0), ^uint64(0)}
+}
+
+// Add adds the byte `b` to the set.
+func (bs *ByteSet) Add(b byte) {
+	bs[b>>6] |= 1 << (b & 63)
+}
+
+// AddRange adds all bytes from `from` to `to` (inclusive) to the set.
+func (bs *ByteSet) AddRange(from, to byte) {
+	for b := int(from); b <= int(to); b++ {
+		bs.Add(byte(b))
+	}
+}
+
+// Has reports whether the byte `b` is in the set.
+func (bs ByteSet) Has(b byte) bool {
+	return bs[b>>6]&(1<<(b&63)) != 0
+}
+
+// Union returns a new set with all bytes of both sets.
+func (bs ByteSet) Union(other ByteSet) ByteSet {
+	for i := range bs {
+		bs[i] |= other[i]
+	}
+	return bs
+}
+
+// Intersection returns a new set with the bytes that are in both sets.
+func (bs ByteSet) Intersection(other ByteSet) ByteSet {
+	for i := range bs {
+		bs[i] &= other[i]
+	}
+	return bs
+}
+
+// Intersects reports whether both sets have at least one byte in common.
+func (bs ByteSet) Intersects(other ByteSet) bool {
+	for i := range bs {
+		if bs[i]&other[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of bytes in the set.
+func (bs ByteSet) Count() int {
+	n := 0
+	for _, word := range bs {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// String returns the bytes of the set in a compact form like `[0-9A-Fa-f]`.
+func (bs ByteSet) String() string {
+	sb := strings.Builder{}
+	sb.WriteByte('[')
+	for b := 0; b < 256; b++ {
+		if !bs.Has(byte(b)) {
+			continue
+		}
+		end := b
+		for end < 255 && bs.Has(byte(end+1)) {
+			end++
+		}
+		writeSetByte(&sb, byte(b))
+		if end > b {
+			if end > b+1 {
+				sb.WriteByte('-')
+			}
+			writeSetByte(&sb, byte(end))
+		}
+		b = end
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+func writeSetByte(sb *strings.Builder, b byte) {
+	if b > ' ' && b < 0x7f && b != '-' && b != '[' && b != ']' && b != '\\' {
+		sb.WriteByte(b)
+		return
+	}
+	sb.WriteString(`\x`)
+	sb.WriteByte("0123456789abcdef"[b>>4])
+	sb.WriteByte("0123456789abcdef"[b&15])
+}
+
+// Analysis is the static information about a parser.
+// It is computed when the parser is constructed and never runs the parser.
+//
+// The information is conservative: if a parser doesn't know better
+// (e.g. because it is lazy or a custom parser) it is reported to be
+// nullable, to start with any byte and to have a minimum length of 0.
+type Analysis struct {
+	// Nullable is true if the parser might succeed without consuming any input.
+	Nullable bool
+	// First is the set of bytes the parser can start with if it consumes input.
+	First ByteSet
+	// MinLen is the minimum number of bytes the parser consumes on success.
+	MinLen int
+}
+
+// UnknownAnalysis returns the conservative analysis for parsers
+// that don't know anything about themselves.
+func UnknownAnalysis() Analysis {
+	return Analysis{Nullable: true, First: AllBytes(), MinLen: 0}
+}
+
+// analyzer is implemented by parsers that carry static analysis information.
+type analyzer interface {
+	analysis() (Analysis, bool)
+}
+
+// Analyze returns the static analysis of the parser.
+// Lazy parsers and parsers without analysis information
+// get the conservative UnknownAnalysis.
+//
+// Analyze is cheap because combinators compute their analysis
+// once at construction time from the analyses of their sub-parsers.
+func Analyze[Output any](parse Parser[Output]) Analysis {
+	if a, ok := parse.(analyzer); ok {
+		if analysis, known := a.analysis(); known {
+			return analysis
+		}
+	}
+	return UnknownAnalysis()
+}
+
+// WithAnalysis returns a copy of the parser that carries the given analysis.
+// It is meant for parser implementations that know more about themselves
+// than the conservative default.
+// Parsers not created by NewParser (e.g. lazy parsers) are returned unchanged.
+func WithAnalysis[Output any](parse Parser[Output], analysis Analysis) Parser[Output] {
+	p, ok := parse.(prsr[Output])
+	if !ok {
+		return parse
+	}
+	p.analyzed = &analysis
+	return p
+}
+
+// ChoiceAnalysis combines the analyses of alternatives (like FirstSuccessful).
+func ChoiceAnalysis(alternatives ...Analysis) Analysis {
+	if len(alternatives) == 0 {
+		return UnknownAnalysis()
+	}
+	result := alternatives[0]
+	for _, a := range alternatives[1:] {
+		result.Nullable = result.Nullable || a.Nullable
+		result.First = result.First.Union(a.First)
+		result.MinLen = min(result.MinLen, a.MinLen)
+	}
+	return result
+}
+
+// SequenceAnalysis combines the analyses of parsers that are applied
+// one after the other (like MapN).
+func SequenceAnalysis(sequence ...Analysis) Analysis {
+	result := Analysis{Nullable: true}
+	for _, a := range sequence {
+		if result.Nullable {
+			result.First = result.First.Union(a.First)
+		}
+		result.Nullable = result.Nullable && a.Nullable
+		result.MinLen += a.MinLen
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package gomme_test
+
+import (
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		analysis     gomme.Analysis
+		wantNullable bool
+		wantFirst    string
+		wantMinLen   int
+	}{
+		{
+			name:         "string",
+			analysis:     gomme.Analyze(pcb.String("while")),
+			wantNullable: false,
+			wantFirst:    "[w]",
+			wantMinLen:   5,
+		}, {
+			name:         "digits",
+			analysis:     gomme.Analyze(pcb.Digit1()),
+			wantNullable: false,
+			wantFirst:    `[0-9\xc2-\xf4]`,
+			wantMinLen:   1,
+		}, {
+			name:         "optional",
+			analysis:     gomme.Analyze(pcb.Optional(pcb.Char('+'))),
+			wantNullable: true,
+			wantFirst:    "[+]",
+			wantMinLen:   0,
+		}, {
+			name:         "alternatives",
+			analysis:     gomme.Analyze(pcb.FirstSuccessful(pcb.String("if"), pcb.String("else"), pcb.String("for"))),
+			wantNullable: false,
+			wantFirst:    "[efi]",
+			wantMinLen:   2,
+		}, {
+			name: "sequence with optional prefix",
+			analysis: gomme.Analyze(pcb.Delimited(
+				pcb.Optional(pcb.OneOf("+", "#")),
+				pcb.String("42"),
+				pcb.Optional(pcb.Char(';')),
+			)),
+			wantNullable: false,
+			wantFirst:    "[#+4]",
+			wantMinLen:   2,
+		}, {
+			name:         "lazy is unknown",
+			analysis:     gomme.Analyze(gomme.LazyParser(func() gomme.Parser[string] { return pcb.String("x") })),
+			wantNullable: true,
+			wantFirst:    `[\x00-\xff]`,
+			wantMinLen:   0,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tc.analysis.Nullable != tc.wantNullable {
+				t.Errorf("got nullable %t, want nullable %t", tc.analysis.Nullable, tc.wantNullable)
+			}
+			if got := tc.analysis.First.String(); got != tc.wantFirst {
+				t.Errorf("got first %s, want first %s", got, tc.wantFirst)
+			}
+			if tc.analysis.MinLen != tc.wantMinLen {
+				t.Errorf("got minimum length %d, want minimum length %d", tc.analysis.MinLen, tc.wantMinLen)
+			}
+		})
+	}
+}
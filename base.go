@@ -7,13 +7,17 @@ package gomme
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"iter"
 	"log"
 	"log/slog"
+	"strings"
 	"sync"
 )
 
 // Use the stringer package from the Go team for printing of names of enums:
-//go:generate go run golang.org/x/tools/cmd/stringer@latest -linecomment -type ParsingMode,Ternary
+//go:generate go run golang.org/x/tools/cmd/stringer@latest -linecomment -type ParsingMode,Ternary,RecoveryKind
 
 // DefaultMaxDel of 3 is a compromise between speed and optimal fault tolerance
 // (ANTLR is using 1)
@@ -61,6 +65,13 @@ type Separator interface {
 	~rune | ~byte | ~string | ~[]byte
 }
 
+// Integral is a generic type for all integer types.
+// It is useful for counts and lengths parsed from the input.
+type Integral interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
 // Recoverer is a simplified parser that only returns the number of bytes
 // to reach a SaveSpot.
 // If it can't recover it should return -1.
@@ -95,6 +106,7 @@ type Parser[Output any] interface {
 	setSaveSpot()
 	Recover(State) int
 	SwapRecoverer(Recoverer) Parser[Output]
+	WithExpected(string) Parser[Output]
 }
 
 type prsr[Output any] struct {
@@ -103,6 +115,9 @@ type prsr[Output any] struct {
 	recoverer   func(State) int
 	saveSpot    bool
 	stepRecover bool
+	analyzed    *Analysis
+	optimizer   func() Parser[Output]
+	lit         *string
 }
 
 // NewParser is THE way to create parsers.
@@ -124,6 +139,26 @@ func (p prsr[Output]) Expected() string {
 }
 
 func (p prsr[Output]) It(state State) (State, Output, *ParserError) {
+	if state.budget != nil && !state.budget.step() {
+		errState := state.NewFailure(fmt.Sprintf("step budget exceeded in %s", p.expected))
+		return errState, ZeroOf[Output](), errState.CurrentError()
+	}
+	if state.stats != nil {
+		state.stats.invoke(state.input.pos)
+		newState, output, err := p.run(state)
+		if err == nil {
+			state.stats.consumed(newState.input.pos)
+		}
+		return newState, output, err
+	}
+	return p.run(state)
+}
+
+// run runs the parse function with the middleware of the state.
+func (p prsr[Output]) run(state State) (State, Output, *ParserError) {
+	if len(state.middleware) > 0 {
+		return runMiddleware(state.middleware, p.expected, p.parser, state)
+	}
 	return p.parser(state)
 }
 
@@ -140,14 +175,59 @@ func (p prsr[Output]) Recover(state State) int {
 }
 
 func (p prsr[Output]) SwapRecoverer(newRecoverer Recoverer) Parser[Output] {
+	var optimizer func() Parser[Output]
+	if p.optimizer != nil { // the optimized parser has to keep the new recoverer
+		optimizer = func() Parser[Output] {
+			return p.optimizer().SwapRecoverer(newRecoverer)
+		}
+	}
 	return prsr[Output]{ // make it concurrency safe without locking
 		expected:  p.expected,
 		parser:    p.parser,
 		saveSpot:  p.saveSpot,
 		recoverer: newRecoverer,
+		analyzed:  p.analyzed,
+		optimizer: optimizer,
+		lit:       p.lit,
 	}
 }
 
+// WithExpected returns a copy of the parser with the expectation replaced.
+// If the copy fails without matching anything, its error is replaced by
+// `expected <expected>`. So library parsers can be labeled for domain
+// specific error messages once instead of at every use site.
+func (p prsr[Output]) WithExpected(expected string) Parser[Output] {
+	var optimizer func() Parser[Output]
+	if p.optimizer != nil { // the optimized parser has to keep the new expectation
+		optimizer = func() Parser[Output] {
+			return p.optimizer().WithExpected(expected)
+		}
+	}
+	parse := p.parser
+	return prsr[Output]{
+		expected: expected,
+		parser: func(state State) (State, Output, *ParserError) {
+			newState, output, err := parse(state)
+			if err != nil && err.Pos() == state.CurrentPos() && !err.IsSemantic() {
+				err = err.WithText("expected " + expected)
+				return newState.SwapError(err), output, err
+			}
+			return newState, output, err
+		},
+		saveSpot:  p.saveSpot,
+		recoverer: p.recoverer,
+		analyzed:  p.analyzed,
+		optimizer: optimizer,
+	}
+}
+
+func (p prsr[Output]) analysis() (Analysis, bool) {
+	if p.analyzed == nil {
+		return Analysis{}, false
+	}
+	return *p.analyzed, true
+}
+
 type lazyprsr[Output any] struct {
 	once         sync.Once
 	makePrsr     func() Parser[Output]
@@ -203,6 +283,15 @@ func (lp *lazyprsr[Output]) SwapRecoverer(newRecoverer Recoverer) Parser[Output]
 	return lp.cachedPrsr.SwapRecoverer(newRecoverer)
 }
 
+func (lp *lazyprsr[Output]) WithExpected(expected string) Parser[Output] {
+	return &lazyprsr[Output]{ // the parser might not be constructable yet
+		makePrsr: func() Parser[Output] {
+			lp.once.Do(lp.ensurePrsr)
+			return lp.cachedPrsr.WithExpected(expected)
+		},
+	}
+}
+
 // ============================================================================
 // Running a parser
 //
@@ -232,10 +321,190 @@ func RunOnBytes[Output any](input []byte, parse Parser[Output]) (Output, error)
 	return output, nil
 }
 
+// Parse runs a parser on text input and returns the output and error(s).
+// Error recovery is turned on.
+// Input that isn't consumed by the parser is silently ignored.
+// Use ParseAll to require that the whole input is consumed.
+// The options WithoutRecovery and WithMaxParserSteps are supported.
+func Parse[Output any](parse Parser[Output], input string, opts ...ReaderOption) (Output, error) {
+	newState, output := RunOnState(newReaderConfig(opts).textState(input), parse)
+	if err := newState.Errors(); err != nil {
+		return ZeroOf[Output](), err
+	}
+	return output, nil
+}
+
+// ParseAll runs a parser on text input just like Parse.
+// But it additionally requires that the whole input is consumed.
+func ParseAll[Output any](parse Parser[Output], input string, opts ...ReaderOption) (Output, error) {
+	newState, output := RunOnState(newReaderConfig(opts).textState(input), parse)
+	if !newState.HasError() && !newState.AtEnd() {
+		newState = newState.NewError("end of the input")
+	}
+	if err := newState.Errors(); err != nil {
+		return ZeroOf[Output](), err
+	}
+	return output, nil
+}
+
+// Items parses the text input as a sequence of items separated by
+// the separator parser.
+// The items are produced lazily. So the input can be processed without
+// building a slice of all items first.
+// A separator after the last item is allowed.
+// The sequence stops after the first error.
+func Items[Output any, S Separator](parse Parser[Output], separator Parser[S], input string) iter.Seq2[Output, error] {
+	return func(yield func(Output, error) bool) {
+		var zero Output
+
+		state := NewFromString(input, true)
+		for i := 0; !state.AtEnd(); i++ {
+			start := state
+			if i > 0 {
+				sepState, _, err := separator.It(state)
+				if err != nil {
+					yield(zero, err)
+					return
+				}
+				state = sepState
+				if state.AtEnd() {
+					return
+				}
+			}
+
+			newState, output, err := parse.It(state)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if !newState.Moved(start) {
+				yield(zero, newState.NewError(fmt.Sprintf(
+					"many %s (empty element incl. separator => endless loop)", parse.Expected())).CurrentError())
+				return
+			}
+			if !yield(output, nil) {
+				return
+			}
+			state = newState
+		}
+	}
+}
+
+// ReaderOption configures how ParseReader reads and parses its input.
+type ReaderOption func(*readerConfig)
+
+type readerConfig struct {
+	binary   bool  // parse binary input instead of text
+	recover  bool  // recover from errors
+	maxSize  int64 // maximum number of bytes to read (0: unlimited)
+	maxSteps int   // maximum number of parser invocations (0: unlimited)
+}
+
+func newReaderConfig(opts []ReaderOption) readerConfig {
+	cfg := readerConfig{recover: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// textState returns a State for the text input with the step budget set.
+func (cfg readerConfig) textState(input string) State {
+	return cfg.withBudget(NewFromString(input, cfg.recover))
+}
+
+func (cfg readerConfig) withBudget(state State) State {
+	if cfg.maxSteps > 0 {
+		return state.WithMaxSteps(cfg.maxSteps)
+	}
+	return state
+}
+
+// WithBinaryInput lets ParseReader treat the input as binary data.
+func WithBinaryInput() ReaderOption {
+	return func(cfg *readerConfig) {
+		cfg.binary = true
+	}
+}
+
+// WithoutRecovery turns off error recovery for ParseReader.
+// So parsing stops at the first error.
+func WithoutRecovery() ReaderOption {
+	return func(cfg *readerConfig) {
+		cfg.recover = false
+	}
+}
+
+// WithMaxSize limits the number of bytes ParseReader reads.
+// Input beyond the limit results in an error.
+func WithMaxSize(maxSize int64) ReaderOption {
+	return func(cfg *readerConfig) {
+		cfg.maxSize = maxSize
+	}
+}
+
+// WithMaxParserSteps limits the work of ParseReader, Parse and ParseAll to
+// `maxSteps` parser invocations (see State.WithMaxSteps).
+func WithMaxParserSteps(maxSteps int) ReaderOption {
+	return func(cfg *readerConfig) {
+		cfg.maxSteps = maxSteps
+	}
+}
+
+// ParseReader reads the input from the reader and runs the parser on it.
+// It returns the output and all errors (read and parse errors) joined.
+// By default, the input is parsed as text with error recovery turned on.
+//
+// NOTE:
+//   - The input is read completely before parsing starts. There is no
+//     incremental buffering because backtracking and error recovery need
+//     random access to all of the input.
+//     So the whole input has to fit into memory (see WithMaxSize).
+func ParseReader[Output any](parse Parser[Output], r io.Reader, opts ...ReaderOption) (Output, error) {
+	cfg := newReaderConfig(opts)
+	if cfg.maxSize > 0 {
+		r = io.LimitReader(r, cfg.maxSize+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ZeroOf[Output](), fmt.Errorf("unable to read input: %w", err)
+	}
+	if cfg.maxSize > 0 && int64(len(data)) > cfg.maxSize {
+		return ZeroOf[Output](), fmt.Errorf("input is larger than %d bytes", cfg.maxSize)
+	}
+
+	var state State
+	if cfg.binary {
+		state = cfg.withBudget(NewFromBytes(data, cfg.recover))
+	} else {
+		state = cfg.textState(string(data))
+	}
+	newState, output := RunOnState(state, parse)
+	if pErrs := newState.Errors(); pErrs != nil {
+		return ZeroOf[Output](), pErrs
+	}
+	return output, nil
+}
+
+// RunOnState runs the parser on the state including error recovery and
+// returns the final state and the output.
+// Errors of the returned state stay valid. But the caches of the state
+// are cleared and its error arena is reused by the next run.
+// Errors that parsers return outside of a run (e.g. by calling Parser.It
+// directly) never come from the error arena. So they stay valid, too.
 func RunOnState[Output any](state State, parse Parser[Output]) (State, Output) {
+	state.errArena.beginRun()
+	newState, output := runOnState(state, parse)
+	if newState.mode == ParsingModeHappy && newState.errHand.witnessID > 0 { // no SaveSpot after the handled error
+		newState = newState.commitRecovery()
+	}
+	return newState.endRun(), output
+}
+
+func runOnState[Output any](state State, parse Parser[Output]) (State, Output) {
 	var output Output
 
-	id := NewBranchParserID()
+	id := state.idAllocator().newID()
 	newState := state
 
 	for {
@@ -268,6 +537,9 @@ func RunOnState[Output any](state State, parse Parser[Output]) (State, Output) {
 		if newState.mode == ParsingModeEscape && newState.AtEnd() { // stop riding a dead horse
 			return newState, output
 		}
+		if newState.maxStepsExceeded() { // recovering would fail, too
+			return newState, output
+		}
 		Debugf("RunOnState - %s: curDel=%d, ignoreErrParser=%t", state.mode, state.errHand.curDel, state.errHand.ignoreErrParser)
 	}
 }
@@ -288,6 +560,7 @@ type Input struct {
 	pos    int    // current position in the input a.k.a. the *byte* index
 	prevNl int    // position of newline preceding 'pos' (-1 for line==1)
 	line   int    // current line number
+	file   string // name of the file the input has been read from (empty if unknown)
 }
 
 func newInput(binary bool, bytes []byte, text string) Input {
@@ -311,15 +584,43 @@ func NewFromBytes(input []byte, recover bool) State {
 	return newState(true, input, "", recover)
 }
 
+// NewFromSeq creates a new parser state from the bytes produced by the
+// sequence (e.g. by a generator). The input is binary.
+// The parsers need random access to the input for backtracking and
+// error recovery. So the whole sequence is collected before parsing
+// starts and it has to be finite.
+func NewFromSeq(seq iter.Seq[byte], recover bool) State {
+	var data []byte
+	for b := range seq {
+		data = append(data, b)
+	}
+	return NewFromBytes(data, recover)
+}
+
+// NewFromRuneSeq creates a new parser state from the runes produced by
+// the sequence. The input is text (UTF-8 encoded).
+// Invalid runes are replaced by utf8.RuneError.
+// Just like for NewFromSeq the whole sequence is collected before parsing
+// starts and it has to be finite.
+func NewFromRuneSeq(seq iter.Seq[rune], recover bool) State {
+	text := strings.Builder{}
+	for r := range seq {
+		text.WriteRune(r)
+	}
+	return NewFromString(text.String(), recover)
+}
+
 // newState creates a new parser state from the input data.
 func newState(binary bool, bytes []byte, text string, recover bool) State {
 	return State{
-		input:                  newInput(binary, bytes, text),
-		saveSpot:               -1,
-		recover:                recover,
-		recovererWasteCache:    make(map[uint64][]cachedWaste),
-		recovererWasteIdxCache: make(map[uint64][]cachedWasteIdx),
-		parserCache:            make(map[uint64][]ParserResult),
+		input:       newInput(binary, bytes, text),
+		saveSpot:    -1,
+		recover:     recover,
+		cache:       NewMapCache(),
+		limitCaches: make(map[int]CacheBackend),
+		sharedIDs:   make(map[*ParserID]uint64),
+		errArena:    &errorArena{},
+		recovery:    &recoveryLog{},
 	}
 }
 
@@ -1,8 +1,15 @@
 package gomme_test
 
 import (
+	"errors"
 	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"io"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 )
 
 func TestErrorReporting(t *testing.T) {
@@ -21,62 +28,62 @@ func TestErrorReporting(t *testing.T) {
 			name:          "at start of line in the middle",
 			givenState:    txtState,
 			givenPosition: 14,
-			expectedError: "error [3:1] ▶line3",
+			expectedError: "error [3:1] (offset 14) ▶line3",
 		}, {
 			name:          "at end of input with last NL",
 			givenState:    txtState,
 			givenPosition: len(input),
-			expectedError: "error [5:1] ▶",
+			expectedError: "error [5:1] (offset 26) ▶",
 		}, {
 			name:          "at NL in the middle",
 			givenState:    txtState,
 			givenPosition: 13,
-			expectedError: "error [2:6] line2▶",
+			expectedError: "error [2:6] (offset 13) line2▶",
 		}, {
 			name:          "at start of input",
 			givenState:    txtState,
 			givenPosition: 0,
-			expectedError: "error [1:1] ▶content",
+			expectedError: "error [1:1] (offset 0) ▶content",
 		}, {
 			name:          "empty input",
 			givenState:    gomme.NewFromString(-1, nil, -1, ""),
 			givenPosition: 0,
-			expectedError: "error [1:1] ▶",
+			expectedError: "error [1:1] (offset 0) ▶",
 		}, {
 			name:          "at end of input without last NL",
 			givenState:    gomme.NewFromString(-1, nil, -1, input2),
 			givenPosition: len(input2),
-			expectedError: "error [2:6] line2▶",
+			expectedError: "error [2:6] (offset 11) line2▶",
 		}, {
 			name:          "binary: at start of input",
 			givenState:    binState,
 			givenPosition: 0,
-			expectedError: "error:\n 00000000  ▶63 6f 6e 74 65 6e 74 0a  6c 69 6e 65 32 0a 6c 69  |▶content.line2.li|",
+			expectedError: "error (offset 0):\n 00000000  ▶63 6f 6e 74 65 6e 74 0a  6c 69 6e 65 32 0a 6c 69  |▶content.line2.li|",
 		}, {
 			name:          "binary: in middle of input",
 			givenState:    binState,
 			givenPosition: 10,
-			expectedError: "error:\n 00000002  6e 74 65 6e 74 0a 6c 69  ▶6e 65 32 0a 6c 69 6e 65  |ntent.li▶ne2.line|",
+			expectedError: "error (offset 10):\n 00000002  6e 74 65 6e 74 0a 6c 69  ▶6e 65 32 0a 6c 69 6e 65  |ntent.li▶ne2.line|",
 		}, {
 			name:          "binary: at end of input",
 			givenState:    binState,
 			givenPosition: len(input),
-			expectedError: "error:\n 00000009  69 6e 65 32 0a 6c 69 6e  65 33 0a 61 6e 64 34 0a ▶ |ine2.line3.and4.▶|",
+			expectedError: "error (offset 26):\n 00000009  69 6e 65 32 0a 6c 69 6e  65 33 0a 61 6e 64 34 0a ▶ |ine2.line3.and4.▶|",
 		}, {
 			name:          "binary: at start of short input",
 			givenState:    gomme.NewFromBytes(-1, nil, -1, []byte(input2)),
 			givenPosition: 0,
-			expectedError: "error:\n 00000000  ▶6c 69 6e 65 31 0a 6c 69  6e 65 32                 |▶line1.line2|",
+			expectedError: "error (offset 0):\n 00000000  ▶6c 69 6e 65 31 0a 6c 69  6e 65 32                 |▶line1.line2|",
 		}, {
 			name:          "binary: in middle of short input",
 			givenState:    gomme.NewFromBytes(-1, nil, -1, []byte(input2)),
 			givenPosition: 8,
-			expectedError: "error:\n 00000000  6c 69 6e 65 31 0a 6c 69  ▶6e 65 32                 |line1.li▶ne2|",
+			expectedError: "error (offset 8):\n 00000000  6c 69 6e 65 31 0a 6c 69  ▶6e 65 32                 |line1.li▶ne2|",
 		}, {
 			name:          "binary: at end of short input",
 			givenState:    gomme.NewFromBytes(-1, nil, -1, []byte(input2)),
 			givenPosition: len(input2) - 1,
-			expectedError: "error:\n 00000000  6c 69 6e 65 31 0a 6c 69  6e 65 ▶32                 |line1.line▶2|",
+			expectedError: "error (offset 10):\n 00000000  6c 69 6e 65 31 0a 6c 69  6e 65 ▶32                 |line1.line▶2|",
 		},
 	}
 
@@ -91,3 +98,281 @@ func TestErrorReporting(t *testing.T) {
 		})
 	}
 }
+
+func TestFailingParsersDontAllocate(t *testing.T) {
+	state := gomme.NewFromString(-1, nil, -1, "content\nline2\nline3\nand4\n").MoveBy(17)
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		_ = state.NewError("digit")
+	})
+	if allocs >= 1 {
+		t.Errorf("got %.2f allocations per error, want less than 1", allocs)
+	}
+
+	want := "expected digit [3:4] (offset 17) lin▶e3"
+	if got := state.NewError("digit").Errors().Error(); got != want {
+		t.Errorf("got error %q, want error %q", got, want)
+	}
+}
+
+func TestErrorsOutliveRun(t *testing.T) {
+	t.Parallel()
+
+	state := gomme.NewFromString("x", false)
+	firstState, _ := gomme.RunOnState(state, pcb.Char('a'))
+	want := firstState.CurrentError().Error()
+
+	for i := 0; i < 100; i++ { // reuses the errors of the first run
+		_, _ = gomme.RunOnState(state, pcb.Digit1())
+	}
+	if got := firstState.CurrentError().Error(); got != want {
+		t.Errorf("got error %q after more runs, want error %q", got, want)
+	}
+}
+
+func TestBinaryExcerpt(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("content\nline2\nline3\nand4\n")
+	specs := []struct {
+		name          string
+		givenExcerpt  gomme.BinaryExcerpt
+		expectedError string
+	}{
+		{
+			name:          "default",
+			givenExcerpt:  gomme.BinaryExcerpt{},
+			expectedError: "error (offset 20):\n 00000009  69 6e 65 32 0a 6c 69 6e  65 33 0a ▶61 6e 64 34 0a  |ine2.line3.▶and4.|",
+		}, {
+			name:         "wide",
+			givenExcerpt: gomme.BinaryExcerpt{Width: 32},
+			expectedError: "error (offset 20):\n 00000000  63 6f 6e 74 65 6e 74 0a  6c 69 6e 65 32 0a 6c 69  |content.line2.li|" +
+				"\n 00000010  6e 65 33 0a ▶61 6e 64 34  0a                       |ne3.▶and4.|",
+		}, {
+			name:          "narrow without offset",
+			givenExcerpt:  gomme.BinaryExcerpt{Width: 4, HideOffset: true},
+			expectedError: "error (offset 20):\n 33 0a ▶61 6e                                       |3.▶an|",
+		}, {
+			name:          "without ASCII",
+			givenExcerpt:  gomme.BinaryExcerpt{Width: 8, HideASCII: true},
+			expectedError: "error (offset 20):\n 00000010  6e 65 33 0a ▶61 6e 64 34",
+		},
+	}
+
+	for _, spec := range specs {
+		spec := spec
+
+		t.Run(spec.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := gomme.NewFromBytes(-1, nil, -1, input).WithBinaryExcerpt(spec.givenExcerpt)
+			gotError := state.MoveBy(20).NewSemanticError("error").Errors().Error()
+
+			if gotError != spec.expectedError {
+				t.Errorf("Expected error %q, got: %q", spec.expectedError, gotError)
+			}
+		})
+	}
+}
+
+func TestErrorPosition(t *testing.T) {
+	t.Parallel()
+
+	txtErr := gomme.NewFromString(-1, nil, -1, "line1\nlüne2\n").MoveBy(9).NewError("x").CurrentError()
+	if txtErr.Line() != 2 || txtErr.Column() != 3 || txtErr.Pos() != 9 {
+		t.Errorf("got position [%d:%d] (offset %d), want [2:3] (offset 9)", txtErr.Line(), txtErr.Column(), txtErr.Pos())
+	}
+
+	binErr := gomme.NewFromBytes(-1, nil, -1, []byte("line1\nline2\n")).MoveBy(9).NewError("x").CurrentError()
+	if binErr.Line() != 0 || binErr.Column() != 0 || binErr.Pos() != 9 {
+		t.Errorf("got position [%d:%d] (offset %d), want [0:0] (offset 9)", binErr.Line(), binErr.Column(), binErr.Pos())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	state := gomme.NewFromString(-1, nil, -1, "abc def")
+	if state.Errors() != nil || state.Err() != nil {
+		t.Fatalf("got errors %v for a fresh state, want none", state.Errors())
+	}
+
+	state = state.NewSemanticError("bad word").MoveBy(4).NewError("digit")
+	pErrs := state.Errors()
+	if pErrs.Len() != 2 {
+		t.Fatalf("got %d errors, want 2: %v", pErrs.Len(), pErrs)
+	}
+	if semantic := pErrs.Semantic(); len(semantic) != 1 || semantic[0].Text() != "bad word" || !semantic[0].IsSemantic() {
+		t.Errorf("got semantic errors %v, want only `bad word`", semantic)
+	}
+	if syntax := pErrs.Syntax(); len(syntax) != 1 || syntax[0].Text() != "expected digit" || syntax[0].Pos() != 4 {
+		t.Errorf("got syntax errors %v, want only `expected digit` at 4", syntax)
+	}
+	if at := pErrs.At(4); len(at) != 1 || at[0].Text() != "expected digit" {
+		t.Errorf("got errors %v at 4, want only `expected digit`", at)
+	}
+	if at := pErrs.At(2); len(at) != 0 {
+		t.Errorf("got errors %v at 2, want none", at)
+	}
+
+	var pErr *gomme.ParserError
+	if err := state.Err(); !errors.As(err, &pErr) || pErr.Text() != "bad word" {
+		t.Errorf("got %v from errors.As, want the first error", pErr)
+	}
+	want := "bad word [1:1] (offset 0) ▶abc def\nexpected digit [1:5] (offset 4) abc ▶def"
+	if got := pErrs.Error(); got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+}
+
+func TestParserID(t *testing.T) {
+	t.Parallel()
+
+	ids1, ids2 := gomme.NewIDAllocator(), gomme.NewIDAllocator()
+	state1 := gomme.NewFromString("", false).WithIDs(ids1)
+	state2 := gomme.NewFromString("", false).WithIDs(ids2)
+	a, b := gomme.NewParserID(), gomme.NewParserID()
+
+	if got := []uint64{b.In(state1), a.In(state1), b.In(state1)}; got[0] != 1 || got[1] != 2 || got[2] != 1 {
+		t.Errorf("got IDs %v, want [1 2 1]", got)
+	}
+	if got := []uint64{a.In(state2), b.In(state2), a.In(state2)}; got[0] != 1 || got[1] != 2 || got[2] != 1 {
+		t.Errorf("got IDs %v from the second allocator, want [1 2 1]", got)
+	}
+	if id := gomme.NewBranchParserID(); id <= 1<<63 {
+		t.Errorf("got unscoped ID %d, want it outside of the range of the allocators", id)
+	}
+}
+
+func TestGrammar(t *testing.T) {
+	t.Parallel()
+
+	g := gomme.NewGrammar()
+	pid := gomme.NewParserID()
+	state := g.NewFromString("abc", false)
+	if id := pid.In(state); id != 1 {
+		t.Errorf("got first ID %d, want 1", id)
+	}
+	if id := pid.In(g.NewFromString("xyz", false)); id != 1 {
+		t.Errorf("got ID %d in another state of the grammar, want 1", id)
+	}
+
+	g.Release(state)
+	if state = g.NewFromString("def", false); state.CurrentString() != "def" {
+		t.Errorf("got input %q from reused state, want %q", state.CurrentString(), "def")
+	}
+}
+
+func TestWithExpected(t *testing.T) {
+	t.Parallel()
+
+	port := pcb.Digit1().WithExpected("port number")
+	if got := port.Expected(); got != "port number" {
+		t.Errorf("got expectation %q, want %q", got, "port number")
+	}
+
+	output, err := gomme.RunOnString("80", port)
+	if err != nil || output != "80" {
+		t.Errorf("got (%q, %v), want (%q, nil)", output, err, "80")
+	}
+
+	_, err = gomme.RunOnString("http", port)
+	if err == nil || !strings.HasPrefix(err.Error(), "expected port number [1:1]") {
+		t.Errorf("got error %v, want it to start with %q", err, "expected port number [1:1]")
+	}
+
+	lazy := gomme.LazyParser(func() gomme.Parser[string] { return pcb.Digit1() }).WithExpected("port number")
+	if got := lazy.Expected(); got != "port number" {
+		t.Errorf("got expectation %q of lazy parser, want %q", got, "port number")
+	}
+
+	// relabeling a lazy parser while it is used concurrently must not race
+	shared := gomme.LazyParser(func() gomme.Parser[string] { return pcb.Digit1() })
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if output, err := gomme.RunOnString("80", shared.WithExpected("port number")); err != nil || output != "80" {
+				t.Errorf("got (%q, %v), want (%q, nil)", output, err, "80")
+			}
+			_, _ = gomme.RunOnString("80", shared)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestParseReader(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		reader     io.Reader
+		opts       []gomme.ReaderOption
+		wantOutput string
+		wantErr    string
+		wantIs     error
+	}{
+		{name: "text input", reader: strings.NewReader("123"), wantOutput: "123"},
+		{name: "binary input", reader: strings.NewReader("123"), opts: []gomme.ReaderOption{gomme.WithBinaryInput()}, wantOutput: "123"},
+		{name: "input at max size", reader: strings.NewReader("123"), opts: []gomme.ReaderOption{gomme.WithMaxSize(3)}, wantOutput: "123"},
+		{name: "too large input", reader: strings.NewReader("1234"), opts: []gomme.ReaderOption{gomme.WithMaxSize(3)}, wantErr: "larger than 3 bytes"},
+		{name: "read error", reader: iotest.ErrReader(io.ErrUnexpectedEOF), wantErr: "unable to read input", wantIs: io.ErrUnexpectedEOF},
+		{name: "parse error", reader: strings.NewReader("abc"), opts: []gomme.ReaderOption{gomme.WithoutRecovery()}, wantErr: "digit"},
+		{name: "too many steps", reader: strings.NewReader("123"), opts: []gomme.ReaderOption{gomme.WithMaxParserSteps(1)}, wantIs: gomme.ErrBudgetExceeded},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := pcb.Map2(pcb.Digit1(), pcb.EOF(), func(digits string, _ interface{}) (string, error) {
+				return digits, nil
+			})
+			output, err := gomme.ParseReader(parser, tc.reader, tc.opts...)
+			if tc.wantErr == "" && tc.wantIs == nil {
+				if err != nil || output != tc.wantOutput {
+					t.Errorf("got (%q, %v), want (%q, nil)", output, err, tc.wantOutput)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("got output %q, want error", output)
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("got error %q, want error containing %q", err, tc.wantErr)
+			}
+			if tc.wantIs != nil && !errors.Is(err, tc.wantIs) {
+				t.Errorf("got error %v, want error wrapping %v", err, tc.wantIs)
+			}
+		})
+	}
+}
+
+func TestNewFromSeq(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Repeat("héllo ", 1000)
+	state := gomme.NewFromSeq(slices.Values([]byte(input)), false)
+	if got := string(state.CurrentBytes()); got != input {
+		t.Errorf("got %d bytes of input, want %d", len(got), len(input))
+	}
+
+	runes := func(yield func(rune) bool) {
+		for _, r := range input {
+			if !yield(r) {
+				return
+			}
+		}
+	}
+	state = gomme.NewFromRuneSeq(runes, false)
+	newState, output := gomme.RunOnState(state, pcb.String("héllo"))
+	if newState.HasError() || output != "héllo" {
+		t.Errorf("got (%q, %v), want (%q, nil)", output, newState.Errors(), "héllo")
+	}
+	if got := state.CurrentString(); got != input {
+		t.Errorf("got %d bytes of text input, want %d", len(got), len(input))
+	}
+}
@@ -0,0 +1,136 @@
+package gomme
+
+import "fmt"
+
+// ============================================================================
+// Writing Custom Branch Parsers
+//
+
+// Branch is the identity of a branch parser created with NewBranch.
+// It is passed to all callbacks of the branch parser.
+type Branch struct {
+	ID       uint64 // for IWitnessed, HandleWitness and the parser result cache (see ParserID.In)
+	Expected string // expectation of the branch parser
+}
+
+// Witnessed reports an error of the sub-parser with index `idx` that this
+// branch parser witnessed (see IWitnessed).
+func (b Branch) Witnessed(state State, idx int, errState State) State {
+	return IWitnessed(state, b.ID, idx, errState)
+}
+
+// CacheResult caches the result of the sub-parser with index `idx` at the
+// position of `state` (see State.CacheParserResult).
+// If the sub-parser moved the SaveSpot mark, it is remembered, too.
+func (b Branch) CacheResult(state State, idx int, newState State, output any) {
+	if state.SaveSpotMoved(newState) {
+		state.CacheParserResult(b.ID, idx, idx, 0, newState, output)
+		return
+	}
+	state.CacheParserResult(b.ID, idx, -1, -1, newState, output)
+}
+
+// CachedResult returns the result cached with CacheResult at the position
+// of `state`.
+func (b Branch) CachedResult(state State) (ParserResult, bool) {
+	return state.CachedParserResult(b.ID)
+}
+
+// BranchModes contains the callbacks of a branch parser for the parsing
+// modes (see ERROR_HANDLING.md).
+// Happy is required. The callbacks for the error modes (Error, Handle,
+// Rewind and Escape) have to be given all together or not at all.
+// Without them the branch parser is treated like a leaf parser:
+// it is the witness parser (1) of its own errors and handles them by
+// deleting input in front of itself and calling Happy again.
+// Happy must not use SaveSpot parsers in this case.
+type BranchModes[Output any] struct {
+	Happy  func(b Branch, state State) (State, Output, *ParserError) // normal parsing (forward)
+	Error  func(b Branch, state State) (State, Output, *ParserError) // find previous SaveSpot (backward)
+	Handle func(b Branch, state State) (State, Output, *ParserError) // find error again (forward)
+	Rewind func(b Branch, state State) (State, Output, *ParserError) // go back to the witness parser (backward)
+	Escape func(b Branch, state State) (State, Output, *ParserError) // use the recoverers (forward)
+}
+
+// NewBranch creates a branch parser from the callbacks for the parsing
+// modes. It takes care of the ID of the parser and the dispatching to the
+// callbacks. So third parties can add branch parsers without mimicking
+// the internals of MapN or FirstSuccessful.
+// This function panics during the construction phase if modes.Happy is nil
+// or if only some of the callbacks for the error modes are given.
+func NewBranch[Output any](expected string, modes BranchModes[Output], recover Recoverer) Parser[Output] {
+	if modes.Happy == nil {
+		panic(fmt.Sprintf("NewBranch(%q) needs a callback for parsing mode `happy`", expected))
+	}
+	errorModes := 0
+	for _, handler := range []func(Branch, State) (State, Output, *ParserError){
+		modes.Error, modes.Handle, modes.Rewind, modes.Escape,
+	} {
+		if handler != nil {
+			errorModes++
+		}
+	}
+	switch errorModes {
+	case 0:
+		modes = leafModes(modes.Happy)
+	case 4:
+	default:
+		panic(fmt.Sprintf(
+			"NewBranch(%q) needs callbacks for all parsing modes `error`, `handle`, `rewind` and `escape` or none of them",
+			expected,
+		))
+	}
+	pid := NewParserID()
+
+	branchParse := func(state State) (State, Output, *ParserError) {
+		var handler func(Branch, State) (State, Output, *ParserError)
+		switch state.ParsingMode() {
+		case ParsingModeHappy:
+			handler = modes.Happy
+		case ParsingModeError:
+			handler = modes.Error
+		case ParsingModeHandle:
+			handler = modes.Handle
+		case ParsingModeRewind:
+			handler = modes.Rewind
+		case ParsingModeEscape:
+			handler = modes.Escape
+		default:
+			errState := state.NewSemanticError(fmt.Sprintf(
+				"parsing mode `%s` hasn't been handled in `%s`", state.ParsingMode(), expected,
+			))
+			return errState, ZeroOf[Output](), errState.CurrentError()
+		}
+		Debugf("%s - mode=%s, pos=%d", expected, state.ParsingMode(), state.CurrentPos())
+		return handler(Branch{ID: pid.In(state), Expected: expected}, state)
+	}
+	return NewParser[Output](expected, branchParse, recover)
+}
+
+// leafModes returns the callbacks of a branch parser that is treated like
+// a leaf parser.
+func leafModes[Output any](happy func(Branch, State) (State, Output, *ParserError)) BranchModes[Output] {
+	handle := func(b Branch, state State) (State, Output, *ParserError) {
+		again := NewParser[Output](b.Expected, func(state State) (State, Output, *ParserError) {
+			return happy(b, state)
+		}, nil)
+		newState, output := HandleWitness(state, b.ID, 0, again)
+		return newState, output, newState.CurrentError()
+	}
+	return BranchModes[Output]{
+		Happy: func(b Branch, state State) (State, Output, *ParserError) {
+			newState, output, err := happy(b, state)
+			if err != nil {
+				newState.errHand.witnessID = 0 // ensure we are the witness!
+				return b.Witnessed(state, 0, newState), output, err
+			}
+			return newState, output, nil
+		},
+		Error: func(_ Branch, state State) (State, Output, *ParserError) {
+			return state, ZeroOf[Output](), nil // there is no SaveSpot in a leaf parser
+		},
+		Handle: handle,
+		Rewind: handle,
+		Escape: happy, // the recoverer of the parent has moved us to a SaveSpot
+	}
+}
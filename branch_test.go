@@ -0,0 +1,143 @@
+package gomme_test
+
+import (
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"testing"
+)
+
+func TestNewBranch(t *testing.T) {
+	t.Parallel()
+
+	digits := pcb.Digit1()
+	letters := pcb.Alpha1()
+	var id uint64
+	longest := gomme.NewBranch[string]("longest of digits or letters", gomme.BranchModes[string]{
+		Happy: func(b gomme.Branch, state gomme.State) (gomme.State, string, *gomme.ParserError) {
+			id = b.ID
+			dState, dOut, dErr := digits.It(state)
+			lState, lOut, lErr := letters.It(state)
+			switch {
+			case dErr != nil && lErr != nil:
+				return state.Preserve(gomme.BetterOf(dState, lState)), "", dErr
+			case lErr != nil || (dErr == nil && dState.CurrentPos() >= lState.CurrentPos()):
+				b.CacheResult(state, 0, dState, dOut)
+				return dState, dOut, nil
+			}
+			b.CacheResult(state, 1, lState, lOut)
+			return lState, lOut, nil
+		},
+	}, nil)
+
+	for _, input := range []string{"123", "abc"} {
+		output, err := gomme.RunOnString(input, longest)
+		if err != nil || output != input {
+			t.Errorf("got (%q, %v), want (%q, nil)", output, err, input)
+		}
+	}
+	if id == 0 {
+		t.Errorf("got no ID for the branch parser")
+	}
+	if _, err := gomme.RunOnString("+", longest); err == nil {
+		t.Errorf("got no error for invalid input")
+	}
+}
+
+func TestNewBranchLeafRecovery(t *testing.T) {
+	t.Parallel()
+
+	digits := pcb.Digit1()
+	var modes []gomme.ParsingMode
+	leaf := gomme.NewBranch[string]("digits", gomme.BranchModes[string]{
+		Happy: func(b gomme.Branch, state gomme.State) (gomme.State, string, *gomme.ParserError) {
+			modes = append(modes, state.ParsingMode())
+			return digits.It(state)
+		},
+	}, nil)
+
+	output, err := gomme.RunOnString("+123", leaf)
+	if output != "123" {
+		t.Errorf("got output %q, want %q", output, "123")
+	}
+	if err == nil {
+		t.Errorf("got no error for the deleted input")
+	}
+	for _, mode := range modes {
+		if mode != gomme.ParsingModeHappy {
+			t.Errorf("Happy has been called in mode %s", mode)
+		}
+	}
+}
+
+func TestNewBranchModes(t *testing.T) {
+	t.Parallel()
+
+	digits := pcb.Digit1()
+	seen := make(map[gomme.ParsingMode]bool)
+	record := func(b gomme.Branch, state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		seen[state.ParsingMode()] = true
+		switch state.ParsingMode() {
+		case gomme.ParsingModeHappy:
+			newState, output, err := digits.It(state)
+			if err != nil {
+				return b.Witnessed(state, 0, newState), output, err
+			}
+			return newState, output, nil
+		case gomme.ParsingModeError:
+			return state, "", nil
+		case gomme.ParsingModeHandle, gomme.ParsingModeRewind:
+			newState, output := gomme.HandleWitness(state, b.ID, 0, digits)
+			return newState, output, newState.CurrentError()
+		}
+		return digits.It(state)
+	}
+	branch := gomme.NewBranch[string]("digits", gomme.BranchModes[string]{
+		Happy:  record,
+		Error:  record,
+		Handle: record,
+		Rewind: record,
+		Escape: record,
+	}, nil)
+
+	output, err := gomme.RunOnString("+#123", branch)
+	if output != "123" || err == nil {
+		t.Errorf("got (%q, %v), want (%q, error)", output, err, "123")
+	}
+	for _, mode := range []gomme.ParsingMode{
+		gomme.ParsingModeHappy, gomme.ParsingModeHandle, gomme.ParsingModeRewind,
+	} {
+		if !seen[mode] {
+			t.Errorf("callback for parsing mode %s hasn't been called", mode)
+		}
+	}
+}
+
+func TestNewBranchPanics(t *testing.T) {
+	t.Parallel()
+
+	happy := func(_ gomme.Branch, state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		return state, "", nil
+	}
+	specs := []struct {
+		name  string
+		modes gomme.BranchModes[string]
+	}{
+		{name: "no happy", modes: gomme.BranchModes[string]{Error: happy}},
+		{name: "only handle", modes: gomme.BranchModes[string]{Happy: happy, Handle: happy}},
+		{name: "no escape", modes: gomme.BranchModes[string]{
+			Happy: happy, Error: happy, Handle: happy, Rewind: happy,
+		}},
+	}
+	for _, spec := range specs {
+		spec := spec
+		t.Run(spec.name, func(t *testing.T) {
+			t.Parallel()
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewBranch didn't panic")
+				}
+			}()
+			gomme.NewBranch[string](spec.name, spec.modes, nil)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package gomme
+
+import "errors"
+
+// ============================================================================
+// Limiting The Work Of Parsers
+//
+
+// ErrBudgetExceeded is found (with errors.Is) in the errors of a parse
+// that exceeded the budget set with State.WithMaxSteps.
+var ErrBudgetExceeded = errors.New("parser step budget exceeded")
+
+// stepBudget limits the number of parser invocations.
+// Budgets can be nested. Every step counts for all enclosing budgets, too.
+type stepBudget struct {
+	steps    int
+	limit    int
+	exceeded bool
+	global   bool // set by WithMaxSteps for the whole parse
+	parent   *stepBudget
+}
+
+// step counts one parser invocation and returns false if this or an
+// enclosing budget is exceeded.
+func (sb *stepBudget) step() bool {
+	ok := true
+	for b := sb; b != nil; b = b.parent {
+		b.steps++
+		if b.steps > b.limit {
+			b.exceeded = true
+			ok = false
+		}
+	}
+	return ok
+}
+
+// WithMaxSteps returns the State with a budget for the whole parse of
+// `maxSteps` parser invocations (including backtracked ones and the ones
+// during error recovery).
+// If the budget is exceeded, the parse fails and its errors contain
+// ErrBudgetExceeded. This protects servers from adversarial inputs that
+// trigger exponential behavior.
+// It should be called before parsing starts.
+func (st State) WithMaxSteps(maxSteps int) State {
+	st.budget = &stepBudget{limit: maxSteps, global: true}
+	return st
+}
+
+// maxStepsExceeded returns true if the budget set with WithMaxSteps has
+// been exceeded.
+func (st State) maxStepsExceeded() bool {
+	for b := st.budget; b != nil; b = b.parent {
+		if b.global {
+			return b.exceeded
+		}
+	}
+	return false
+}
+
+// WithinStepBudget returns the State with a new budget of `maxSteps`
+// parser invocations. It is nested in the current budget (if any).
+// Every parser invocation counts, including the ones that are backtracked.
+// If the budget is exceeded, all parsers fail immediately.
+// Use LeaveStepBudget to return to the enclosing budget.
+func (st State) WithinStepBudget(maxSteps int) State {
+	st.budget = &stepBudget{limit: maxSteps, parent: st.budget}
+	return st
+}
+
+// LeaveStepBudget returns the State with the budget of `outer` restored.
+func (st State) LeaveStepBudget(outer State) State {
+	st.budget = outer.budget
+	return st
+}
+
+// StepBudgetExceeded returns true if the innermost budget of the state
+// has been exceeded.
+func (st State) StepBudgetExceeded() bool {
+	return st.budget != nil && st.budget.exceeded
+}
+
+// StepsUsed returns the number of parser invocations counted by the
+// innermost budget of the state (0 without budget).
+func (st State) StepsUsed() int {
+	if st.budget == nil {
+		return 0
+	}
+	return st.budget.steps
+}
@@ -0,0 +1,56 @@
+package gomme_test
+
+import (
+	"errors"
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"strings"
+	"testing"
+)
+
+func TestWithMaxSteps(t *testing.T) {
+	t.Parallel()
+
+	parser := pcb.Many0(pcb.Char('a'))
+
+	state := gomme.NewFromString("aaa", false).WithMaxSteps(100)
+	newState, output := gomme.RunOnState(state, parser)
+	if err := newState.Errors(); err != nil || len(output) != 3 {
+		t.Errorf("got (%q, %v), want 3 runes and no error", output, err)
+	}
+
+	state = gomme.NewFromString(strings.Repeat("a", 100), true).WithMaxSteps(10)
+	newState, _ = gomme.RunOnState(state, parser)
+	err := newState.Errors()
+	if err == nil || !errors.Is(err, gomme.ErrBudgetExceeded) {
+		t.Errorf("got error %v, want ErrBudgetExceeded", err)
+	}
+}
+
+func TestParseWithMaxParserSteps(t *testing.T) {
+	t.Parallel()
+
+	parser := pcb.Many0(pcb.Char('a'))
+	input := strings.Repeat("a", 100)
+
+	if _, err := gomme.Parse(parser, input, gomme.WithMaxParserSteps(10)); !errors.Is(err, gomme.ErrBudgetExceeded) {
+		t.Errorf("Parse: got error %v, want ErrBudgetExceeded", err)
+	}
+	if _, err := gomme.ParseAll(parser, input, gomme.WithMaxParserSteps(10)); !errors.Is(err, gomme.ErrBudgetExceeded) {
+		t.Errorf("ParseAll: got error %v, want ErrBudgetExceeded", err)
+	}
+	if output, err := gomme.ParseAll(parser, input, gomme.WithMaxParserSteps(1000)); err != nil || len(output) != 100 {
+		t.Errorf("ParseAll: got (%d runes, %v), want 100 runes and no error", len(output), err)
+	}
+}
+
+func TestMaxStepsInEmbeddedInput(t *testing.T) {
+	t.Parallel()
+
+	parser := pcb.ParseWith(pcb.GoString(), pcb.Many0(pcb.Char('a')))
+	input := `"` + strings.Repeat("a", 100) + `"`
+
+	if _, err := gomme.Parse(parser, input, gomme.WithMaxParserSteps(10)); !errors.Is(err, gomme.ErrBudgetExceeded) {
+		t.Errorf("got error %v, want ErrBudgetExceeded", err)
+	}
+}
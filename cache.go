@@ -0,0 +1,319 @@
+package gomme
+
+// ============================================================================
+// Cache Backends
+//
+
+// CacheKind identifies one of the caches used for error recovery.
+type CacheKind uint8
+
+const (
+	CacheRecovererWaste    CacheKind = iota // waste of CachingRecoverers
+	CacheRecovererWasteIdx                  // waste and best index of CombiningRecoverers
+	CacheParserResult                       // results of branch parsers
+)
+
+// CacheBackend stores the values of the caches of a State.
+// A value is identified by the ID of the parser or recoverer and the
+// position in the input. Every kind of cache has its own typed methods.
+// So storing values doesn't box them.
+// A backend may forget values at any time.
+// But parsers use the cached results to find their way back to an error.
+// So forgetting results makes error recovery slower or impossible.
+// A backend is used by a single parse at a time and doesn't have to be
+// safe for concurrent use.
+type CacheBackend interface {
+	// StoreWaste saves the waste of a CachingRecoverer (CacheRecovererWaste).
+	StoreWaste(id uint64, pos int, waste int)
+	// LoadWaste returns the waste stored for the key or (-1, false).
+	LoadWaste(id uint64, pos int) (waste int, ok bool)
+	// StoreWasteIdx saves the waste and best index of a CombiningRecoverer
+	// (CacheRecovererWasteIdx).
+	StoreWasteIdx(id uint64, pos int, waste, idx int)
+	// LoadWasteIdx returns the waste and index stored for the key or
+	// (-1, -1, false).
+	LoadWasteIdx(id uint64, pos int) (waste, idx int, ok bool)
+	// StoreResult saves the result of a branch parser (CacheParserResult).
+	StoreResult(id uint64, pos int, result ParserResult)
+	// LoadResult returns the result stored for the key or
+	// (ParserResult{}, false).
+	LoadResult(id uint64, pos int) (result ParserResult, ok bool)
+	// Clear removes all values.
+	Clear()
+}
+
+// DefaultMapCacheSlots is the number of positions cached per ID by the
+// backend returned by NewMapCache.
+const DefaultMapCacheSlots = 8
+
+type wasteIdx struct {
+	waste int
+	idx   int
+}
+
+type slotEntry[V any] struct {
+	pos   int
+	value V
+}
+
+// slotCache keeps the values of the last few positions for every ID.
+type slotCache[V any] struct {
+	entries map[uint64][]slotEntry[V] // created lazily
+	count   int                       // number of entries of all IDs
+}
+
+func (sc *slotCache[V]) store(slots int, id uint64, pos int, value V) {
+	if sc.entries == nil {
+		sc.entries = make(map[uint64][]slotEntry[V])
+	}
+	entries := sc.entries[id]
+	oldest := -1
+	for i := range entries {
+		if entries[i].pos == pos {
+			entries[i].value = value
+			return
+		}
+		if oldest < 0 || entries[i].pos < entries[oldest].pos {
+			oldest = i
+		}
+	}
+	if len(entries) < slots {
+		if entries == nil {
+			entries = make([]slotEntry[V], 0, slots)
+		}
+		sc.entries[id] = append(entries, slotEntry[V]{pos: pos, value: value})
+		sc.count++
+		return
+	}
+	entries[oldest] = slotEntry[V]{pos: pos, value: value} // the smallest position is the least likely to be needed again
+}
+
+func (sc *slotCache[V]) clear() {
+	clear(sc.entries)
+	sc.count = 0
+}
+
+func (sc *slotCache[V]) load(id uint64, pos int) (value V, ok bool) {
+	for _, entry := range sc.entries[id] {
+		if entry.pos == pos {
+			return entry.value, true
+		}
+	}
+	return value, false
+}
+
+// mapCache is the default backend.
+// It keeps the values of the last few positions for every ID.
+type mapCache struct {
+	slots     int
+	wastes    slotCache[int]
+	wasteIdxs slotCache[wasteIdx]
+	results   slotCache[ParserResult]
+}
+
+// NewMapCache returns the default CacheBackend.
+// It stores DefaultMapCacheSlots positions for every ID in maps that grow
+// with the number of IDs used. The maps are created on first use.
+func NewMapCache() CacheBackend {
+	return NewMapCacheWithSlots(DefaultMapCacheSlots)
+}
+
+// NewMapCacheWithSlots returns the default CacheBackend storing `slots`
+// positions for every ID.
+// More slots help grammars that backtrack a lot while fewer slots save
+// memory.
+// This function panics during the construction phase if `slots` isn't
+// positive.
+func NewMapCacheWithSlots(slots int) CacheBackend {
+	if slots <= 0 {
+		panic("NewMapCacheWithSlots needs a positive number of slots")
+	}
+	return &mapCache{slots: slots}
+}
+
+func (mc *mapCache) StoreWaste(id uint64, pos int, waste int) {
+	mc.wastes.store(mc.slots, id, pos, waste)
+}
+
+func (mc *mapCache) LoadWaste(id uint64, pos int) (waste int, ok bool) {
+	if waste, ok = mc.wastes.load(id, pos); !ok {
+		return -1, false
+	}
+	return waste, true
+}
+
+func (mc *mapCache) StoreWasteIdx(id uint64, pos int, waste, idx int) {
+	mc.wasteIdxs.store(mc.slots, id, pos, wasteIdx{waste: waste, idx: idx})
+}
+
+func (mc *mapCache) LoadWasteIdx(id uint64, pos int) (waste, idx int, ok bool) {
+	value, ok := mc.wasteIdxs.load(id, pos)
+	if !ok {
+		return -1, -1, false
+	}
+	return value.waste, value.idx, true
+}
+
+func (mc *mapCache) StoreResult(id uint64, pos int, result ParserResult) {
+	mc.results.store(mc.slots, id, pos, result)
+}
+
+func (mc *mapCache) LoadResult(id uint64, pos int) (result ParserResult, ok bool) {
+	return mc.results.load(id, pos)
+}
+
+func (mc *mapCache) Clear() {
+	mc.wastes.clear()
+	mc.wasteIdxs.clear()
+	mc.results.clear()
+}
+
+func (mc *mapCache) len() int {
+	return mc.wastes.count + mc.wasteIdxs.count + mc.results.count
+}
+
+// nopCache doesn't cache anything.
+type nopCache struct{}
+
+// NewNopCache returns a CacheBackend that doesn't cache anything and
+// doesn't allocate.
+// It should only be used for parsing without error recovery
+// (e.g. tiny one-shot parses).
+func NewNopCache() CacheBackend {
+	return nopCache{}
+}
+
+func (nopCache) StoreWaste(uint64, int, int) {}
+
+func (nopCache) LoadWaste(uint64, int) (int, bool) {
+	return -1, false
+}
+
+func (nopCache) StoreWasteIdx(uint64, int, int, int) {}
+
+func (nopCache) LoadWasteIdx(uint64, int) (int, int, bool) {
+	return -1, -1, false
+}
+
+func (nopCache) StoreResult(uint64, int, ParserResult) {}
+
+func (nopCache) LoadResult(uint64, int) (ParserResult, bool) {
+	return ParserResult{}, false
+}
+
+func (nopCache) Clear() {}
+
+func (nopCache) len() int {
+	return 0
+}
+
+type boundedEntry struct {
+	kind   CacheKind
+	id     uint64
+	pos    int
+	used   bool
+	waste  wasteIdx     // for CacheRecovererWaste and CacheRecovererWasteIdx
+	result ParserResult // for CacheParserResult
+}
+
+// boundedCache is a direct mapped cache with a fixed number of entries.
+type boundedCache struct {
+	entries []boundedEntry
+	used    int // number of used entries
+}
+
+// NewBoundedCache returns a CacheBackend that never holds more than
+// `maxEntries` values.
+// All memory is allocated upfront. A new value replaces an older one
+// with a colliding key.
+// This function panics during the construction phase if `maxEntries`
+// isn't positive.
+func NewBoundedCache(maxEntries int) CacheBackend {
+	if maxEntries <= 0 {
+		panic("NewBoundedCache needs a positive number of entries")
+	}
+	return &boundedCache{entries: make([]boundedEntry, maxEntries)}
+}
+
+func (bc *boundedCache) slot(kind CacheKind, id uint64, pos int) *boundedEntry {
+	h := (id*0x9e3779b97f4a7c15 ^ uint64(pos)) * 0xbf58476d1ce4e5b9
+	h ^= uint64(kind) + h>>31
+	return &bc.entries[h%uint64(len(bc.entries))]
+}
+
+// store puts the entry into its slot and replaces the old one.
+func (bc *boundedCache) store(entry boundedEntry) {
+	slot := bc.slot(entry.kind, entry.id, entry.pos)
+	if !slot.used {
+		bc.used++
+	}
+	*slot = entry
+}
+
+// lookup returns the entry for the key or nil.
+func (bc *boundedCache) lookup(kind CacheKind, id uint64, pos int) *boundedEntry {
+	entry := bc.slot(kind, id, pos)
+	if !entry.used || entry.kind != kind || entry.id != id || entry.pos != pos {
+		return nil
+	}
+	return entry
+}
+
+func (bc *boundedCache) StoreWaste(id uint64, pos int, waste int) {
+	bc.store(boundedEntry{
+		kind: CacheRecovererWaste, id: id, pos: pos, used: true, waste: wasteIdx{waste: waste},
+	})
+}
+
+func (bc *boundedCache) LoadWaste(id uint64, pos int) (waste int, ok bool) {
+	entry := bc.lookup(CacheRecovererWaste, id, pos)
+	if entry == nil {
+		return -1, false
+	}
+	return entry.waste.waste, true
+}
+
+func (bc *boundedCache) StoreWasteIdx(id uint64, pos int, waste, idx int) {
+	bc.store(boundedEntry{
+		kind: CacheRecovererWasteIdx, id: id, pos: pos, used: true, waste: wasteIdx{waste: waste, idx: idx},
+	})
+}
+
+func (bc *boundedCache) LoadWasteIdx(id uint64, pos int) (waste, idx int, ok bool) {
+	entry := bc.lookup(CacheRecovererWasteIdx, id, pos)
+	if entry == nil {
+		return -1, -1, false
+	}
+	return entry.waste.waste, entry.waste.idx, true
+}
+
+func (bc *boundedCache) StoreResult(id uint64, pos int, result ParserResult) {
+	bc.store(boundedEntry{
+		kind: CacheParserResult, id: id, pos: pos, used: true, result: result,
+	})
+}
+
+func (bc *boundedCache) LoadResult(id uint64, pos int) (result ParserResult, ok bool) {
+	entry := bc.lookup(CacheParserResult, id, pos)
+	if entry == nil {
+		return ParserResult{}, false
+	}
+	return entry.result, true
+}
+
+func (bc *boundedCache) Clear() {
+	clear(bc.entries)
+	bc.used = 0
+}
+
+func (bc *boundedCache) len() int {
+	return bc.used
+}
+
+// WithCacheBackend returns the State with the backend used for all of
+// its caches (see CacheBackend).
+// It should be called before parsing starts.
+func (st State) WithCacheBackend(backend CacheBackend) State {
+	st.cache = backend
+	return st
+}
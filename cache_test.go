@@ -0,0 +1,110 @@
+package gomme_test
+
+import (
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"strings"
+	"testing"
+)
+
+func TestCacheBackends(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		backend    gomme.CacheBackend
+		wantCached bool
+	}{
+		{name: "map", backend: gomme.NewMapCache(), wantCached: true},
+		{name: "map with one slot", backend: gomme.NewMapCacheWithSlots(1), wantCached: true},
+		{name: "nop", backend: gomme.NewNopCache(), wantCached: false},
+		{name: "bounded", backend: gomme.NewBoundedCache(16), wantCached: true},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.backend.StoreResult(1, 3, gomme.ParserResult{Output: "value"})
+			result, ok := tc.backend.LoadResult(1, 3)
+			if ok != tc.wantCached || (ok && result.Output != "value") {
+				t.Errorf("got (%v, %t), want cached: %t", result.Output, ok, tc.wantCached)
+			}
+			if _, ok = tc.backend.LoadResult(1, 4); ok {
+				t.Errorf("got value for other position")
+			}
+			if _, ok = tc.backend.LoadWaste(1, 3); ok {
+				t.Errorf("got value for other kind")
+			}
+			tc.backend.StoreWasteIdx(1, 3, 5, 2)
+			waste, idx, ok := tc.backend.LoadWasteIdx(1, 3)
+			if ok != tc.wantCached || (ok && (waste != 5 || idx != 2)) {
+				t.Errorf("got (%d, %d, %t), want (5, 2, cached: %t)", waste, idx, ok, tc.wantCached)
+			}
+			tc.backend.Clear()
+			if _, ok = tc.backend.LoadResult(1, 3); ok {
+				t.Errorf("got value after Clear")
+			}
+
+			tc.backend.StoreWaste(1, 3, 7)
+			tc.backend.StoreWaste(1, 4, 8) // might replace the older position
+			if waste, ok := tc.backend.LoadWaste(1, 4); ok != tc.wantCached || (ok && waste != 8) {
+				t.Errorf("got (%d, %t) for the newest position, want (8, cached: %t)", waste, ok, tc.wantCached)
+			}
+
+			state := gomme.NewFromString("abc", false).WithCacheBackend(tc.backend)
+			newState, output := gomme.RunOnState(state, pcb.FirstSuccessful(pcb.String("ab"), pcb.String("abc")))
+			if err := newState.Errors(); err != nil || output != "ab" {
+				t.Errorf("got (%q, %v), want (%q, nil)", output, err, "ab")
+			}
+		})
+	}
+}
+
+// clearCounter counts the calls of Clear.
+type clearCounter struct {
+	gomme.CacheBackend
+	clears int
+}
+
+func (cc *clearCounter) Clear() {
+	cc.clears++
+	cc.CacheBackend.Clear()
+}
+
+func TestAutoClearCaches(t *testing.T) {
+	t.Parallel()
+
+	policy := gomme.CacheClearing{AfterEachItem: true}
+	parser := pcb.Many0(pcb.Char('a'))
+
+	noRecovery := &clearCounter{CacheBackend: gomme.NewMapCache()}
+	state := gomme.NewFromString("aaaa", false).WithCacheBackend(noRecovery).WithCacheClearing(policy)
+	newState, output := gomme.RunOnState(state, parser)
+	if err := newState.Errors(); err != nil || len(output) != 4 {
+		t.Errorf("got (%q, %v), want 4 runes and no error", output, err)
+	}
+	if noRecovery.clears < 4 {
+		t.Errorf("got %d clearings without error recovery, want at least 4 (one per item)", noRecovery.clears)
+	}
+
+	// with error recovery only the end of the run is a safe point
+	withRecovery := &clearCounter{CacheBackend: gomme.NewMapCache()}
+	state = gomme.NewFromString("aaaa", true).WithCacheBackend(withRecovery).WithCacheClearing(policy)
+	newState, output = gomme.RunOnState(state, parser)
+	if err := newState.Errors(); err != nil || len(output) != 4 {
+		t.Errorf("got (%q, %v), want 4 runes and no error", output, err)
+	}
+	if withRecovery.clears > 1 {
+		t.Errorf("got %d clearings with error recovery, want at most 1", withRecovery.clears)
+	}
+
+	// enclosing parsers still find their cached results when recovering
+	seq := pcb.Sequence(pcb.Skip(parser), pcb.Skip(gomme.SaveSpot(pcb.Char(';'))))
+	state = gomme.NewFromString("aaa#;", true).WithCacheClearing(policy)
+	newState, _ = gomme.RunOnState(state, seq)
+	err := newState.Errors()
+	if err == nil || strings.Contains(err.Error(), "cache was empty") {
+		t.Errorf("got error %v, want a recovered syntax error", err)
+	}
+}
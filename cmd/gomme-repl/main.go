@@ -0,0 +1,73 @@
+// Command gomme-repl exercises grammars interactively.
+// Every line of input is parsed with the current rule and the output value,
+// the remaining input and the diagnostics are printed.
+//
+// Usage:
+//
+//	gomme-repl [-rule name] [-trace]
+//
+// Go can't load parsers at runtime. So this command registers a few example
+// rules. Copy it and register the rules of your own grammar with
+// repl.Register to get a REPL for it.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"github.com/oleiade/gomme/repl"
+)
+
+func main() {
+	rule := flag.String("rule", "expr", "rule to start with")
+	trace := flag.Bool("trace", false, "show the trace of all parsers")
+	flag.Parse()
+
+	r := repl.New()
+	registerRules(r)
+	if err := r.Use(*rule); err != nil {
+		fmt.Fprintf(os.Stderr, "gomme-repl: %v\n", err)
+		os.Exit(2)
+	}
+	r.SetTrace(*trace)
+	if err := r.Run(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "gomme-repl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func registerRules(r *repl.REPL) {
+	repl.Register(r, "int", func() gomme.Parser[int64] {
+		return pcb.Int64(true, 10)
+	})
+	repl.Register(r, "word", pcb.Alpha1)
+	repl.Register(r, "expr", func() gomme.Parser[int64] {
+		binary := func(op string, operands []int64) (int64, error) {
+			a, b := operands[0], operands[1]
+			switch op {
+			case "+":
+				return a + b, nil
+			case "-":
+				return a - b, nil
+			case "*":
+				return a * b, nil
+			case "/":
+				if b == 0 {
+					return 0, errors.New("division by zero")
+				}
+				return a / b, nil
+			}
+			return 0, errors.New("unknown operator " + op)
+		}
+		ot := &pcb.OperatorTable[int64]{SkipSpace: true}
+		ot.Add(pcb.Operator[int64]{Kind: pcb.Infix, Token: "+", Precedence: 1, Combine: binary}).
+			Add(pcb.Operator[int64]{Kind: pcb.Infix, Token: "-", Precedence: 1, Combine: binary}).
+			Add(pcb.Operator[int64]{Kind: pcb.Infix, Token: "*", Precedence: 2, Combine: binary}).
+			Add(pcb.Operator[int64]{Kind: pcb.Infix, Token: "/", Precedence: 2, Combine: binary})
+		return ot.Parser(pcb.Int64(false, 10))
+	})
+}
@@ -0,0 +1,87 @@
+// Command gomme runs grammars in PEG notation without writing Go code.
+// So grammar changes can be tested by everybody.
+//
+// Usage:
+//
+//	gomme run --grammar foo.peg --input data.txt [--start Rule] [--format text|json]
+//
+// The parse tree (a node for every matched rule) is printed to standard
+// output. Diagnostics are printed to standard error and the exit code is 1.
+// Without --input the input is read from standard input.
+// See the codegen package for the grammar notation.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/oleiade/gomme/codegen"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "run" {
+		fmt.Fprintf(os.Stderr, "usage: gomme run --grammar file [--input file] [--start rule] [--format text|json]\n")
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("gomme run", flag.ExitOnError)
+	grammarFile := flags.String("grammar", "", "grammar file in PEG notation (required)")
+	inputFile := flags.String("input", "", "input file (default: standard input)")
+	start := flags.String("start", "", "start rule (default: the first rule)")
+	format := flags.String("format", "text", "output format: text or json")
+	_ = flags.Parse(os.Args[2:])
+	if *grammarFile == "" || flags.NArg() != 0 || (*format != "text" && *format != "json") {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*grammarFile, *inputFile, *start, *format, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "gomme: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(grammarFile, inputFile, start, format string, out io.Writer) error {
+	src, err := os.ReadFile(grammarFile)
+	if err != nil {
+		return err
+	}
+	g, err := codegen.ParseGrammar(string(src))
+	if err != nil {
+		return fmt.Errorf("%s: %w", grammarFile, err)
+	}
+
+	var input []byte
+	if inputFile == "" {
+		input, err = io.ReadAll(os.Stdin)
+		inputFile = "<stdin>"
+	} else {
+		input, err = os.ReadFile(inputFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	tree, err := g.Parse(string(input), start)
+	if err != nil {
+		return fmt.Errorf("%s: %w", inputFile, err)
+	}
+	if format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tree)
+	}
+	printTree(out, tree, 0)
+	return nil
+}
+
+func printTree(out io.Writer, node *codegen.Node, depth int) {
+	fmt.Fprintf(out, "%s%s [%d:%d] %q\n", strings.Repeat("  ", depth), node.Rule, node.Start, node.End, node.Text)
+	for _, child := range node.Children {
+		printTree(out, child, depth+1)
+	}
+}
@@ -0,0 +1,59 @@
+// Command gommegen generates specialized Go recognizers for a grammar in
+// PEG notation.
+//
+// Usage:
+//
+//	gommegen -package lists -o lists_gen.go lists.peg
+//
+// See the codegen package for the grammar notation and the generated code.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/oleiade/gomme/codegen"
+)
+
+func main() {
+	pkg := flag.String("package", "", "package name of the generated file (required)")
+	prefix := flag.String("prefix", "Match", "prefix of the generated function names")
+	out := flag.String("o", "", "output file (default: standard output)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: gommegen -package name [-prefix Match] [-o file] grammar.peg\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *pkg == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *out, codegen.Config{Package: *pkg, Prefix: *prefix}); err != nil {
+		fmt.Fprintf(os.Stderr, "gommegen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(grammarFile, outFile string, cfg codegen.Config) error {
+	src, err := os.ReadFile(grammarFile)
+	if err != nil {
+		return err
+	}
+	g, err := codegen.ParseGrammar(string(src))
+	if err != nil {
+		return fmt.Errorf("%s: %w", grammarFile, err)
+	}
+
+	buf := bytes.Buffer{}
+	if err = codegen.Generate(&buf, g, cfg); err != nil {
+		return err
+	}
+	if outFile == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(outFile, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,203 @@
+// Package codegen generates specialized Go code for grammars.
+// The generated recognizers work directly on a string without any
+// interface dispatch, closures or allocations.
+// They are meant for users who need maximum throughput in production
+// and have a grammar that is stable.
+//
+// The grammar is given separately in PEG notation (see ParseGrammar).
+// It isn't extracted from a tree of gomme parsers because their analysis
+// (see gomme.Analyze) only summarizes them (nullability, FIRST set and
+// minimum length). So a grammar that is used with both has to be kept in
+// sync by hand.
+// The command `cmd/gommegen` is a thin wrapper around this package.
+//
+// A grammar can be interpreted directly with Grammar.Parse, too.
+// The command `cmd/gomme run` uses that to test grammars without Go code.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Config configures the generated code.
+type Config struct {
+	// Package is the name of the package of the generated file.
+	Package string
+	// Prefix is prepended to the names of all generated functions.
+	// It is `Match` by default.
+	Prefix string
+}
+
+// Generate writes a formatted Go source file with one recognizer function
+// per rule of the grammar to `w`:
+//
+//	func MatchNumber(input string, pos int) (end int, ok bool)
+//
+// A recognizer matches its rule at position `pos` of the input and returns
+// the position after the match.
+// If the rule doesn't match, ok is false and end is equal to pos.
+func Generate(w io.Writer, g *Grammar, cfg Config) error {
+	if cfg.Package == "" {
+		return fmt.Errorf("missing package name")
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "Match"
+	}
+
+	cg := &generator{cfg: cfg, grammar: g}
+	cg.printf("// Code generated by gommegen. DO NOT EDIT.\n\n")
+	cg.printf("package %s\n\n", cfg.Package)
+	cg.printf("import \"strings\"\n\n")
+	cg.printf("var _ = strings.HasPrefix\n")
+	for _, r := range g.Rules {
+		cg.rule(r)
+	}
+	for len(cg.pending) > 0 {
+		node := cg.pending[0]
+		cg.pending = cg.pending[1:]
+		cg.node(node)
+	}
+
+	src, err := format.Source(cg.buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated code is invalid: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type pendingNode struct {
+	name string
+	expr *Expr
+}
+
+type generator struct {
+	cfg     Config
+	grammar *Grammar
+	buf     bytes.Buffer
+	count   int
+	pending []pendingNode
+}
+
+func (cg *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&cg.buf, format, args...)
+}
+
+func (cg *generator) ruleFunc(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return cg.cfg.Prefix + string(unicode.ToUpper(r)) + name[size:]
+}
+
+func (cg *generator) rule(r *Rule) {
+	fn := cg.ruleFunc(r.Name)
+	cg.printf("\n// %s matches the rule `%s` at position pos of the input.\n", fn, r.Name)
+	cg.printf("func %s(input string, pos int) (int, bool) {\n", fn)
+	cg.printf("\treturn %s(input, pos)\n}\n", cg.call(r.Expr))
+}
+
+// call returns the name of the function that matches the expression.
+// Functions for sub-expressions are generated later.
+func (cg *generator) call(e *Expr) string {
+	if e.Kind == ExprRef {
+		return cg.ruleFunc(e.Text)
+	}
+	cg.count++
+	name := fmt.Sprintf("%s%d", strings.ToLower(cg.cfg.Prefix[:1])+cg.cfg.Prefix[1:], cg.count)
+	cg.pending = append(cg.pending, pendingNode{name: name, expr: e})
+	return name
+}
+
+func (cg *generator) node(n pendingNode) {
+	e := n.expr
+	cg.printf("\nfunc %s(input string, pos int) (int, bool) {\n", n.name)
+	switch e.Kind {
+	case ExprLiteral:
+		cg.printf("\tif strings.HasPrefix(input[pos:], %s) {\n", strconv.Quote(e.Text))
+		cg.printf("\t\treturn pos + %d, true\n\t}\n", len(e.Text))
+		cg.printf("\treturn pos, false\n")
+	case ExprClass:
+		cg.printf("\tif pos < len(input) {\n")
+		cg.printf("\t\tif c := input[pos]; %s {\n", classCondition(e.Class))
+		cg.printf("\t\t\treturn pos + 1, true\n\t\t}\n\t}\n")
+		cg.printf("\treturn pos, false\n")
+	case ExprAny:
+		cg.printf("\tif pos < len(input) {\n\t\treturn pos + 1, true\n\t}\n")
+		cg.printf("\treturn pos, false\n")
+	case ExprSequence:
+		cg.printf("\tstart := pos\n\tok := false\n")
+		for _, c := range e.Children {
+			cg.printf("\tif pos, ok = %s(input, pos); !ok {\n\t\treturn start, false\n\t}\n", cg.call(c))
+		}
+		cg.printf("\treturn pos, true\n")
+	case ExprChoice:
+		for _, c := range e.Children {
+			cg.printf("\tif end, ok := %s(input, pos); ok {\n\t\treturn end, true\n\t}\n", cg.call(c))
+		}
+		cg.printf("\treturn pos, false\n")
+	case ExprZeroOrMore, ExprOneOrMore:
+		sub := cg.call(e.Children[0])
+		if e.Kind == ExprOneOrMore {
+			cg.printf("\tstart := pos\n")
+			cg.printf("\tend, ok := %s(input, pos)\n\tif !ok {\n\t\treturn start, false\n\t}\n", sub)
+			cg.printf("\tpos = end\n")
+		}
+		cg.printf("\tfor {\n")
+		cg.printf("\t\tend, ok := %s(input, pos)\n", sub)
+		cg.printf("\t\tif !ok || end == pos { // an empty match would loop endlessly\n")
+		cg.printf("\t\t\treturn pos, true\n\t\t}\n")
+		cg.printf("\t\tpos = end\n\t}\n")
+	case ExprOptional:
+		cg.printf("\tif end, ok := %s(input, pos); ok {\n\t\treturn end, true\n\t}\n", cg.call(e.Children[0]))
+		cg.printf("\treturn pos, true\n")
+	case ExprAnd:
+		cg.printf("\t_, ok := %s(input, pos)\n\treturn pos, ok\n", cg.call(e.Children[0]))
+	case ExprNot:
+		cg.printf("\t_, ok := %s(input, pos)\n\treturn pos, !ok\n", cg.call(e.Children[0]))
+	}
+	cg.printf("}\n")
+}
+
+// classCondition returns a Go condition testing the byte `c` for
+// membership in the class.
+// Consecutive bytes are merged into ranges.
+func classCondition(class [256]bool) string {
+	var parts []string
+	for b := 0; b < 256; b++ {
+		if !class[b] {
+			continue
+		}
+		end := b
+		for end < 255 && class[end+1] {
+			end++
+		}
+		switch {
+		case b == end:
+			parts = append(parts, fmt.Sprintf("c == %s", byteLiteral(byte(b))))
+		case b == 0:
+			parts = append(parts, fmt.Sprintf("c <= %s", byteLiteral(byte(end))))
+		case end == 255:
+			parts = append(parts, fmt.Sprintf("c >= %s", byteLiteral(byte(b))))
+		default:
+			parts = append(parts, fmt.Sprintf("(c >= %s && c <= %s)", byteLiteral(byte(b)), byteLiteral(byte(end))))
+		}
+		b = end
+	}
+	if len(parts) == 0 {
+		return "false"
+	}
+	return strings.Join(parts, " || ")
+}
+
+func byteLiteral(b byte) string {
+	if b >= ' ' && b < 0x7f {
+		return strconv.QuoteRune(rune(b))
+	}
+	return fmt.Sprintf("0x%02x", b)
+}
@@ -0,0 +1,106 @@
+package codegen
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const listGrammar = `
+# a list of numbers
+List   <- "[" _ (Number _ ("," _ Number _)*)? "]"
+Number <- "-"? [0-9]+ ("." [0-9]+)? !Letter
+Letter <- [a-zA-Z_]
+_      <- [ \t\n]*
+`
+
+func TestParseGrammar(t *testing.T) {
+	t.Parallel()
+
+	g, err := ParseGrammar(listGrammar)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	if len(g.Rules) != 4 {
+		t.Fatalf("got %d rules, want 4", len(g.Rules))
+	}
+	if got := g.Rules[1].Expr.Kind; got != ExprSequence {
+		t.Errorf("got kind %d for rule Number, want %d", got, ExprSequence)
+	}
+	if r := g.Rule("Letter"); r == nil || !r.Expr.Class['Q'] || r.Expr.Class['1'] {
+		t.Errorf("got wrong class for rule Letter: %+v", r)
+	}
+}
+
+func TestParseGrammarErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		grammar string
+		wantErr string
+	}{
+		{name: "empty grammar", grammar: "# nothing\n", wantErr: "no rules"},
+		{name: "missing arrow", grammar: "A \"a\"", wantErr: "expected `<-`"},
+		{name: "undefined rule", grammar: "A <- B", wantErr: `undefined rule "B"`},
+		{name: "rule defined twice", grammar: "A <- \"a\"\nA <- \"b\"", wantErr: "line 2: rule \"A\" is defined twice"},
+		{name: "unterminated class", grammar: "A <- [a-z", wantErr: "unterminated class"},
+		{name: "missing paren", grammar: "A <- (\"a\"", wantErr: "expected `)`"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseGrammar(tc.grammar)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("got error %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	g, err := ParseGrammar(listGrammar)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	sb := strings.Builder{}
+	if err = Generate(&sb, g, Config{Package: "lists"}); err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	src := sb.String()
+
+	f, err := parser.ParseFile(token.NewFileSet(), "lists.go", src, 0)
+	if err != nil {
+		t.Fatalf("generated code doesn't parse: %v\n%s", err, src)
+	}
+	if f.Name.Name != "lists" {
+		t.Errorf("got package %q, want package %q", f.Name.Name, "lists")
+	}
+	for _, fn := range []string{"MatchList", "MatchNumber", "MatchLetter", "Match_"} {
+		if !strings.Contains(src, "func "+fn+"(input string, pos int) (int, bool)") {
+			t.Errorf("generated code is missing function %s:\n%s", fn, src)
+		}
+	}
+	if !strings.Contains(src, "c >= '0' && c <= '9'") {
+		t.Errorf("generated code is missing the digit class:\n%s", src)
+	}
+}
+
+func TestGenerateMissingPackage(t *testing.T) {
+	t.Parallel()
+
+	g, err := ParseGrammar(`A <- "a"`)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	if err = Generate(&strings.Builder{}, g, Config{}); err == nil {
+		t.Errorf("expected error for missing package name")
+	}
+}
@@ -0,0 +1,131 @@
+package codegen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ChangeKind is the kind of a change between two versions of a grammar.
+type ChangeKind int
+
+const (
+	StartRuleChanged      ChangeKind = iota // the first rule is a different one
+	RuleAdded                               // rule added
+	RuleRemoved                             // rule removed
+	RuleChanged                             // rule changed
+	AlternativeAdded                        // alternative added to a rule
+	AlternativeRemoved                      // alternative removed from a rule
+	AlternativesReordered                   // same alternatives in a different order
+)
+
+// Change is one difference between two versions of a grammar found by
+// Diff. Expressions are given in PEG notation.
+type Change struct {
+	Kind ChangeKind
+	Rule string // name of the rule (the new start rule for StartRuleChanged)
+	Old  string // old rule, alternative or start rule (empty if added)
+	New  string // new rule, alternative or start rule (empty if removed)
+	Line int    // line of the rule in the new grammar (old grammar if removed)
+}
+
+// String returns the change in a short, diff like form.
+func (c Change) String() string {
+	switch c.Kind {
+	case StartRuleChanged:
+		return fmt.Sprintf("start rule changed from %s to %s", c.Old, c.New)
+	case RuleAdded:
+		return fmt.Sprintf("line %d: + %s <- %s", c.Line, c.Rule, c.New)
+	case RuleRemoved:
+		return fmt.Sprintf("line %d: - %s <- %s", c.Line, c.Rule, c.Old)
+	case RuleChanged:
+		return fmt.Sprintf("line %d: ~ %s <- %s (was: %s)", c.Line, c.Rule, c.New, c.Old)
+	case AlternativeAdded:
+		return fmt.Sprintf("line %d:   %s: + / %s", c.Line, c.Rule, c.New)
+	case AlternativeRemoved:
+		return fmt.Sprintf("line %d:   %s: - / %s", c.Line, c.Rule, c.Old)
+	}
+	return fmt.Sprintf("line %d:   %s: alternatives reordered to %s (was: %s)", c.Line, c.Rule, c.New, c.Old)
+}
+
+// Diff compares two versions of a grammar and returns the changed start
+// rule, the removed and changed rules in the order of the old grammar and
+// the added rules in the order of the new grammar.
+// Rules are compared by their expressions (see Expr.String). So layout
+// and comments don't matter.
+// A changed rule is followed by the changes of its top level alternatives.
+// Because alternatives are ordered, a new order is reported, too.
+// So grammar changes can be reviewed the way API changes are.
+func Diff(oldGrammar, newGrammar *Grammar) []Change {
+	var changes []Change
+	if oldStart, newStart := oldGrammar.Rules[0].Name, newGrammar.Rules[0].Name; oldStart != newStart {
+		changes = append(changes, Change{Kind: StartRuleChanged, Rule: newStart, Old: oldStart, New: newStart, Line: newGrammar.Rules[0].Line})
+	}
+
+	for _, oldRule := range oldGrammar.Rules {
+		oldExpr := oldRule.Expr.String()
+		newRule := newGrammar.Rule(oldRule.Name)
+		if newRule == nil {
+			changes = append(changes, Change{Kind: RuleRemoved, Rule: oldRule.Name, Old: oldExpr, Line: oldRule.Line})
+			continue
+		}
+		newExpr := newRule.Expr.String()
+		if oldExpr == newExpr {
+			continue
+		}
+		changes = append(changes, Change{Kind: RuleChanged, Rule: oldRule.Name, Old: oldExpr, New: newExpr, Line: newRule.Line})
+		changes = append(changes, diffAlternatives(newRule, alternatives(oldRule.Expr), alternatives(newRule.Expr))...)
+	}
+
+	for _, newRule := range newGrammar.Rules {
+		if oldGrammar.Rule(newRule.Name) == nil {
+			changes = append(changes, Change{Kind: RuleAdded, Rule: newRule.Name, New: newRule.Expr.String(), Line: newRule.Line})
+		}
+	}
+	return changes
+}
+
+// alternatives returns the top level alternatives of the expression in
+// PEG notation.
+func alternatives(e *Expr) []string {
+	if e.Kind != ExprChoice {
+		return []string{e.String()}
+	}
+	alts := make([]string, len(e.Children))
+	for i, c := range e.Children {
+		alts[i] = c.String()
+	}
+	return alts
+}
+
+// diffAlternatives returns the changes of the alternatives of a rule.
+// Nothing is reported if neither version has more than one alternative
+// because the RuleChanged says it all.
+func diffAlternatives(r *Rule, oldAlts, newAlts []string) []Change {
+	if len(oldAlts) == 1 && len(newAlts) == 1 {
+		return nil
+	}
+	var changes []Change
+	var oldKept, newKept []string // alternatives in both versions
+	for _, alt := range oldAlts {
+		if slices.Contains(newAlts, alt) {
+			oldKept = append(oldKept, alt)
+		} else {
+			changes = append(changes, Change{Kind: AlternativeRemoved, Rule: r.Name, Old: alt, Line: r.Line})
+		}
+	}
+	for _, alt := range newAlts {
+		if slices.Contains(oldAlts, alt) {
+			newKept = append(newKept, alt)
+		} else {
+			changes = append(changes, Change{Kind: AlternativeAdded, Rule: r.Name, New: alt, Line: r.Line})
+		}
+	}
+	if !slices.Equal(oldKept, newKept) {
+		changes = append(changes, Change{
+			Kind: AlternativesReordered, Rule: r.Name, Line: r.Line,
+			Old: strings.Join(oldKept, " / "), New: strings.Join(newKept, " / "),
+		})
+	}
+	return changes
+}
@@ -0,0 +1,80 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExprString(t *testing.T) {
+	t.Parallel()
+
+	g, err := ParseGrammar(listGrammar + "Misc <- (&\"a\" / !.) (\"b\" \"c\")+ / `x`\n")
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"List":   `"[" _ (Number _ ("," _ Number _)*)? "]"`,
+		"Number": `"-"? [0-9]+ ("." [0-9]+)? !Letter`,
+		"Letter": `[A-Z_a-z]`,
+		"_":      `[\x09\x0a ]*`,
+		"Misc":   `(&"a" / !.) ("b" "c")+ / "x"`,
+	}
+	src := strings.Builder{}
+	for _, r := range g.Rules {
+		if got := r.Expr.String(); got != want[r.Name] {
+			t.Errorf("got rule %s <- %s, want %s", r.Name, got, want[r.Name])
+		}
+		src.WriteString(r.Name + " <- " + r.Expr.String() + "\n")
+	}
+
+	g2, err := ParseGrammar(src.String())
+	if err != nil {
+		t.Fatalf("got unexpected error for printed grammar: %v", err)
+	}
+	if changes := Diff(g, g2); len(changes) != 0 {
+		t.Errorf("got changes %v for printed grammar, want none", changes)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	oldGrammar, err := ParseGrammar(`
+Value  <- Number / String / "null"
+Number <- [0-9]+
+String <- "\"" [^"]* "\""
+Old    <- "x"
+`)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	newGrammar, err := ParseGrammar(`
+# comments and layout don't matter
+Doc    <- Value
+Value  <- String / Number / Bool
+Number <- [0-9]+   # unchanged
+String <- "\"" [^"]* "\""
+Bool   <- "true" / "false"
+`)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, c := range Diff(oldGrammar, newGrammar) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"start rule changed from Value to Doc",
+		`line 4: ~ Value <- String / Number / Bool (was: Number / String / "null")`,
+		`line 4:   Value: - / "null"`,
+		`line 4:   Value: + / Bool`,
+		`line 4:   Value: alternatives reordered to String / Number (was: Number / String)`,
+		`line 5: - Old <- "x"`,
+		`line 3: + Doc <- Value`,
+		`line 7: + Bool <- "true" / "false"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got changes:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,450 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ExprKind is the kind of a grammar expression.
+type ExprKind int
+
+const (
+	ExprLiteral    ExprKind = iota // literal
+	ExprClass                      // class
+	ExprAny                        // any
+	ExprRef                        // reference
+	ExprSequence                   // sequence
+	ExprChoice                     // choice
+	ExprZeroOrMore                 // zero or more
+	ExprOneOrMore                  // one or more
+	ExprOptional                   // optional
+	ExprAnd                        // and predicate
+	ExprNot                        // not predicate
+)
+
+// Expr is a node of a parsed grammar.
+// Only the fields for its Kind are used.
+type Expr struct {
+	Kind ExprKind
+	// Text is the literal text (ExprLiteral) or the rule name (ExprRef).
+	Text string
+	// Class is the byte class of ExprClass.
+	Class [256]bool
+	// Children are the sub-expressions of all other kinds.
+	Children []*Expr
+}
+
+// Rule is a named grammar rule.
+type Rule struct {
+	Name string
+	Expr *Expr
+	Line int
+}
+
+// Grammar is a parsed grammar file.
+// The first rule is the start rule.
+type Grammar struct {
+	Rules []*Rule
+}
+
+// Rule returns the rule with the given name or nil.
+func (g *Grammar) Rule(name string) *Rule {
+	for _, r := range g.Rules {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// ParseGrammar parses a grammar in PEG notation:
+//
+//	# comments start with a hash sign
+//	Number <- [0-9]+ ("." [0-9]+)?
+//	List   <- "[" Number ("," Number)* "]" / "[]"
+//
+// Literals are Go strings ("..." or `...`), classes match single bytes
+// ([a-z_], [^"\\]) and `.` matches any byte.
+// Alternatives are ordered (`/`) and repetitions are greedy just like
+// FirstSuccessful and Many0 of the pcb package.
+// Left recursive rules aren't supported.
+func ParseGrammar(src string) (*Grammar, error) {
+	gp := &grammarParser{src: src, line: 1}
+	g := &Grammar{}
+	gp.skipSpace()
+	for !gp.atEnd() {
+		line := gp.line
+		name := gp.ident()
+		if name == "" {
+			return nil, gp.errorf("expected rule name")
+		}
+		gp.skipSpace()
+		if !gp.consume("<-") && !gp.consume("=") {
+			return nil, gp.errorf("expected `<-` after rule name %q", name)
+		}
+		expr, err := gp.choice()
+		if err != nil {
+			return nil, err
+		}
+		if g.Rule(name) != nil {
+			return nil, fmt.Errorf("line %d: rule %q is defined twice", line, name)
+		}
+		g.Rules = append(g.Rules, &Rule{Name: name, Expr: expr, Line: line})
+	}
+	if len(g.Rules) == 0 {
+		return nil, fmt.Errorf("grammar has no rules")
+	}
+	for _, r := range g.Rules {
+		if err := g.checkRefs(r, r.Expr); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+func (g *Grammar) checkRefs(r *Rule, e *Expr) error {
+	if e.Kind == ExprRef && g.Rule(e.Text) == nil {
+		return fmt.Errorf("line %d: rule %q uses undefined rule %q", r.Line, r.Name, e.Text)
+	}
+	for _, c := range e.Children {
+		if err := g.checkRefs(r, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type grammarParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (gp *grammarParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", gp.line, fmt.Sprintf(format, args...))
+}
+
+func (gp *grammarParser) atEnd() bool {
+	return gp.pos >= len(gp.src)
+}
+
+func (gp *grammarParser) skipSpace() {
+	for !gp.atEnd() {
+		switch c := gp.src[gp.pos]; {
+		case c == '\n':
+			gp.line++
+			gp.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			gp.pos++
+		case c == '#':
+			for !gp.atEnd() && gp.src[gp.pos] != '\n' {
+				gp.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (gp *grammarParser) consume(token string) bool {
+	if !strings.HasPrefix(gp.src[gp.pos:], token) {
+		return false
+	}
+	gp.pos += len(token)
+	gp.skipSpace()
+	return true
+}
+
+func (gp *grammarParser) ident() string {
+	start := gp.pos
+	for !gp.atEnd() {
+		r := rune(gp.src[gp.pos])
+		if !(r == '_' || unicode.IsLetter(r) || (gp.pos > start && unicode.IsDigit(r))) {
+			break
+		}
+		gp.pos++
+	}
+	return gp.src[start:gp.pos]
+}
+
+// isRuleStart reports whether a new rule starts at the current position.
+func (gp *grammarParser) isRuleStart() bool {
+	save, saveLine := gp.pos, gp.line
+	defer func() { gp.pos, gp.line = save, saveLine }()
+
+	if gp.ident() == "" {
+		return false
+	}
+	gp.skipSpace()
+	return strings.HasPrefix(gp.src[gp.pos:], "<-") || strings.HasPrefix(gp.src[gp.pos:], "=")
+}
+
+func (gp *grammarParser) choice() (*Expr, error) {
+	first, err := gp.sequence()
+	if err != nil {
+		return nil, err
+	}
+	alternatives := []*Expr{first}
+	for gp.consume("/") {
+		alt, sErr := gp.sequence()
+		if sErr != nil {
+			return nil, sErr
+		}
+		alternatives = append(alternatives, alt)
+	}
+	if len(alternatives) == 1 {
+		return first, nil
+	}
+	return &Expr{Kind: ExprChoice, Children: alternatives}, nil
+}
+
+func (gp *grammarParser) sequence() (*Expr, error) {
+	var items []*Expr
+	for !gp.atEnd() && !gp.isRuleStart() {
+		c := gp.src[gp.pos]
+		if c == '/' || c == ')' {
+			break
+		}
+		item, err := gp.prefixed()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	switch len(items) {
+	case 0:
+		return nil, gp.errorf("expected expression")
+	case 1:
+		return items[0], nil
+	}
+	return &Expr{Kind: ExprSequence, Children: items}, nil
+}
+
+func (gp *grammarParser) prefixed() (*Expr, error) {
+	kind := ExprKind(-1)
+	if gp.consume("&") {
+		kind = ExprAnd
+	} else if gp.consume("!") {
+		kind = ExprNot
+	}
+	e, err := gp.suffixed()
+	if err != nil || kind < 0 {
+		return e, err
+	}
+	return &Expr{Kind: kind, Children: []*Expr{e}}, nil
+}
+
+func (gp *grammarParser) suffixed() (*Expr, error) {
+	e, err := gp.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case gp.consume("*"):
+			e = &Expr{Kind: ExprZeroOrMore, Children: []*Expr{e}}
+		case gp.consume("+"):
+			e = &Expr{Kind: ExprOneOrMore, Children: []*Expr{e}}
+		case gp.consume("?"):
+			e = &Expr{Kind: ExprOptional, Children: []*Expr{e}}
+		default:
+			return e, nil
+		}
+	}
+}
+
+func (gp *grammarParser) primary() (*Expr, error) {
+	if gp.atEnd() {
+		return nil, gp.errorf("unexpected end of grammar")
+	}
+	switch c := gp.src[gp.pos]; c {
+	case '(':
+		gp.consume("(")
+		e, err := gp.choice()
+		if err != nil {
+			return nil, err
+		}
+		if !gp.consume(")") {
+			return nil, gp.errorf("expected `)`")
+		}
+		return e, nil
+	case '.':
+		gp.consume(".")
+		return &Expr{Kind: ExprAny}, nil
+	case '"', '`':
+		return gp.literal(c)
+	case '[':
+		return gp.class()
+	}
+	name := gp.ident()
+	if name == "" {
+		return nil, gp.errorf("unexpected character %q", gp.src[gp.pos])
+	}
+	gp.skipSpace()
+	return &Expr{Kind: ExprRef, Text: name}, nil
+}
+
+func (gp *grammarParser) literal(quote byte) (*Expr, error) {
+	end := gp.pos + 1
+	for end < len(gp.src) && gp.src[end] != quote {
+		if gp.src[end] == '\\' && quote == '"' {
+			end++
+		}
+		end++
+	}
+	if end >= len(gp.src) {
+		return nil, gp.errorf("unterminated literal")
+	}
+	text, err := strconv.Unquote(gp.src[gp.pos : end+1])
+	if err != nil {
+		return nil, gp.errorf("bad literal %s: %v", gp.src[gp.pos:end+1], err)
+	}
+	gp.line += strings.Count(gp.src[gp.pos:end+1], "\n")
+	gp.pos = end + 1
+	gp.skipSpace()
+	return &Expr{Kind: ExprLiteral, Text: text}, nil
+}
+
+func (gp *grammarParser) class() (*Expr, error) {
+	e := &Expr{Kind: ExprClass}
+	gp.pos++ // '['
+	negate := false
+	if !gp.atEnd() && gp.src[gp.pos] == '^' {
+		negate = true
+		gp.pos++
+	}
+	for {
+		if gp.atEnd() {
+			return nil, gp.errorf("unterminated class")
+		}
+		if gp.src[gp.pos] == ']' {
+			gp.pos++
+			break
+		}
+		from, err := gp.classByte()
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if gp.pos+1 < len(gp.src) && gp.src[gp.pos] == '-' && gp.src[gp.pos+1] != ']' {
+			gp.pos++
+			if to, err = gp.classByte(); err != nil {
+				return nil, err
+			}
+			if to < from {
+				return nil, gp.errorf("bad range %q-%q in class", from, to)
+			}
+		}
+		for b := int(from); b <= int(to); b++ {
+			e.Class[b] = true
+		}
+	}
+	if negate {
+		for b := range e.Class {
+			e.Class[b] = !e.Class[b]
+		}
+	}
+	gp.skipSpace()
+	return e, nil
+}
+
+func (gp *grammarParser) classByte() (byte, error) {
+	c := gp.src[gp.pos]
+	if c != '\\' {
+		if c >= 0x80 {
+			return 0, gp.errorf("classes only support ASCII characters, use a literal instead")
+		}
+		gp.pos++
+		return c, nil
+	}
+	if gp.pos+1 >= len(gp.src) {
+		return 0, gp.errorf("unterminated class")
+	}
+	c = gp.src[gp.pos+1]
+	gp.pos += 2
+	switch c {
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'x':
+		if gp.pos+2 > len(gp.src) {
+			return 0, gp.errorf("bad hex escape in class")
+		}
+		v, err := strconv.ParseUint(gp.src[gp.pos:gp.pos+2], 16, 8)
+		if err != nil {
+			return 0, gp.errorf("bad hex escape in class: %v", err)
+		}
+		gp.pos += 2
+		return byte(v), nil
+	}
+	return c, nil
+}
+
+// String returns the expression in PEG notation (see ParseGrammar).
+// Equal expressions result in equal strings. So it can be used for
+// comparing expressions, too.
+func (e *Expr) String() string {
+	sb := strings.Builder{}
+	e.write(&sb, 0)
+	return sb.String()
+}
+
+// write writes the expression with parentheses if its precedence is
+// lower than `minPrec` (0: choice, 1: sequence, 2: predicate,
+// 3: repetition, 4: primary).
+func (e *Expr) write(sb *strings.Builder, minPrec int) {
+	prec, sep, childPrec := 4, "", 0
+	switch e.Kind {
+	case ExprChoice:
+		prec, sep, childPrec = 0, " / ", 1
+	case ExprSequence:
+		prec, sep, childPrec = 1, " ", 2
+	case ExprAnd, ExprNot:
+		prec, childPrec = 2, 3
+	case ExprZeroOrMore, ExprOneOrMore, ExprOptional:
+		prec, childPrec = 3, 4
+	}
+	if prec < minPrec {
+		sb.WriteByte('(')
+		defer sb.WriteByte(')')
+	}
+
+	switch e.Kind {
+	case ExprLiteral:
+		sb.WriteString(strconv.Quote(e.Text))
+	case ExprClass:
+		sb.WriteString(classString(e.Class))
+	case ExprAny:
+		sb.WriteByte('.')
+	case ExprRef:
+		sb.WriteString(e.Text)
+	case ExprChoice, ExprSequence:
+		for i, c := range e.Children {
+			if i > 0 {
+				sb.WriteString(sep)
+			}
+			c.write(sb, childPrec)
+		}
+	case ExprAnd, ExprNot:
+		if e.Kind == ExprAnd {
+			sb.WriteByte('&')
+		} else {
+			sb.WriteByte('!')
+		}
+		e.Children[0].write(sb, childPrec)
+	case ExprZeroOrMore:
+		e.Children[0].write(sb, childPrec)
+		sb.WriteByte('*')
+	case ExprOneOrMore:
+		e.Children[0].write(sb, childPrec)
+		sb.WriteByte('+')
+	case ExprOptional:
+		e.Children[0].write(sb, childPrec)
+		sb.WriteByte('?')
+	}
+}
@@ -0,0 +1,252 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxDepth limits the nesting of rules while interpreting a grammar.
+// Only left recursive rules or pathological inputs get that deep.
+const maxDepth = 10_000
+
+// Node is a node of the parse tree created by Grammar.Parse.
+// Every node is the match of a rule.
+type Node struct {
+	Rule     string  `json:"rule"`
+	Start    int     `json:"start"` // byte position of the start of the match
+	End      int     `json:"end"`   // byte position after the match
+	Text     string  `json:"text"`  // the matched input
+	Children []*Node `json:"children,omitempty"`
+}
+
+// ParseError is the error of Grammar.Parse.
+// It is reported at the farthest position any expression failed at.
+type ParseError struct {
+	Pos      int      // byte position in the input
+	Line     int      // line number (starting at 1)
+	Column   int      // byte column in the line (starting at 1)
+	Expected []string // descriptions of the expressions that failed
+}
+
+func (e *ParseError) Error() string {
+	expected := strings.Join(e.Expected, ", ")
+	if len(e.Expected) > 1 {
+		expected = "one of: " + expected
+	}
+	return fmt.Sprintf("line %d, column %d: expected %s", e.Line, e.Column, expected)
+}
+
+// Parse interprets the grammar without generating code.
+// The rule `start` (the first rule if empty) has to match the whole input.
+// The returned tree contains a node for every rule that matched.
+// Results of rules are memoized. So the time is linear in the input size.
+func (g *Grammar) Parse(input, start string) (*Node, error) {
+	if start == "" {
+		start = g.Rules[0].Name
+	}
+	if g.Rule(start) == nil {
+		return nil, fmt.Errorf("start rule %q is undefined", start)
+	}
+	in := &interp{input: input, rules: make(map[string]*Rule, len(g.Rules)), memo: make(map[memoKey]memoResult)}
+	for _, r := range g.Rules {
+		in.rules[r.Name] = r
+	}
+
+	end, nodes, ok := in.match(&Expr{Kind: ExprRef, Text: start}, 0)
+	if in.err != nil {
+		return nil, in.err
+	}
+	if ok && end == len(input) {
+		return nodes[0], nil
+	}
+	if ok {
+		in.fail(end, "end of input")
+	}
+	return nil, in.parseError()
+}
+
+type memoKey struct {
+	rule string
+	pos  int
+}
+
+type memoResult struct {
+	end  int
+	node *Node
+	ok   bool
+}
+
+type interp struct {
+	input    string
+	rules    map[string]*Rule
+	memo     map[memoKey]memoResult
+	depth    int
+	quiet    int // expectations aren't recorded inside of predicates
+	failPos  int
+	expected []string
+	err      error
+}
+
+// match matches the expression at the position and returns the position
+// after the match and the nodes of all rules matched directly inside.
+func (in *interp) match(e *Expr, pos int) (int, []*Node, bool) {
+	if in.err != nil {
+		return pos, nil, false
+	}
+	switch e.Kind {
+	case ExprLiteral:
+		if strings.HasPrefix(in.input[pos:], e.Text) {
+			return pos + len(e.Text), nil, true
+		}
+		in.fail(pos, strconv.Quote(e.Text))
+	case ExprClass:
+		if pos < len(in.input) && e.Class[in.input[pos]] {
+			return pos + 1, nil, true
+		}
+		in.fail(pos, classString(e.Class))
+	case ExprAny:
+		if pos < len(in.input) {
+			return pos + 1, nil, true
+		}
+		in.fail(pos, "any character")
+	case ExprRef:
+		return in.matchRule(e.Text, pos)
+	case ExprSequence:
+		var nodes []*Node
+		end := pos
+		for _, c := range e.Children {
+			cEnd, cNodes, ok := in.match(c, end)
+			if !ok {
+				return pos, nil, false
+			}
+			end, nodes = cEnd, append(nodes, cNodes...)
+		}
+		return end, nodes, true
+	case ExprChoice:
+		for _, c := range e.Children {
+			if end, nodes, ok := in.match(c, pos); ok {
+				return end, nodes, true
+			}
+		}
+	case ExprZeroOrMore, ExprOneOrMore:
+		var nodes []*Node
+		end := pos
+		for n := 0; ; n++ {
+			cEnd, cNodes, ok := in.match(e.Children[0], end)
+			if !ok || cEnd == end { // an empty match would loop endlessly
+				if n == 0 && e.Kind == ExprOneOrMore && !ok {
+					return pos, nil, false
+				}
+				return end, nodes, true
+			}
+			end, nodes = cEnd, append(nodes, cNodes...)
+		}
+	case ExprOptional:
+		if end, nodes, ok := in.match(e.Children[0], pos); ok {
+			return end, nodes, true
+		}
+		return pos, nil, true
+	case ExprAnd, ExprNot:
+		in.quiet++
+		_, _, ok := in.match(e.Children[0], pos)
+		in.quiet--
+		return pos, nil, ok == (e.Kind == ExprAnd)
+	}
+	return pos, nil, false
+}
+
+func (in *interp) matchRule(name string, pos int) (int, []*Node, bool) {
+	key := memoKey{rule: name, pos: pos}
+	useMemo := in.quiet == 0 // so expectations are recorded outside of predicates
+	if result, ok := in.memo[key]; ok && useMemo {
+		if !result.ok {
+			return pos, nil, false
+		}
+		return result.end, []*Node{result.node}, true
+	}
+
+	in.depth++
+	defer func() { in.depth-- }()
+	if in.depth > maxDepth {
+		in.err = fmt.Errorf("rule %q is nested too deeply at position %d (is it left recursive?)", name, pos)
+		return pos, nil, false
+	}
+	end, children, ok := in.match(in.rules[name].Expr, pos)
+	if !ok {
+		if useMemo {
+			in.memo[key] = memoResult{}
+		}
+		return pos, nil, false
+	}
+	node := &Node{Rule: name, Start: pos, End: end, Text: in.input[pos:end], Children: children}
+	if useMemo {
+		in.memo[key] = memoResult{end: end, node: node, ok: true}
+	}
+	return end, []*Node{node}, true
+}
+
+// fail records the expectation at the position if it is the farthest
+// one so far.
+func (in *interp) fail(pos int, expected string) {
+	switch {
+	case in.quiet > 0 || pos < in.failPos:
+	case pos > in.failPos:
+		in.failPos, in.expected = pos, []string{expected}
+	default:
+		for _, e := range in.expected {
+			if e == expected {
+				return
+			}
+		}
+		in.expected = append(in.expected, expected)
+	}
+}
+
+func (in *interp) parseError() *ParseError {
+	before := in.input[:in.failPos]
+	expected := append([]string(nil), in.expected...)
+	sort.Strings(expected)
+	return &ParseError{
+		Pos:      in.failPos,
+		Line:     strings.Count(before, "\n") + 1,
+		Column:   in.failPos - strings.LastIndexByte(before, '\n'),
+		Expected: expected,
+	}
+}
+
+// classString returns the class in PEG notation.
+func classString(class [256]bool) string {
+	sb := strings.Builder{}
+	sb.WriteByte('[')
+	for b := 0; b < 256; b++ {
+		if !class[b] {
+			continue
+		}
+		end := b
+		for end < 255 && class[end+1] {
+			end++
+		}
+		sb.WriteString(classByteString(byte(b)))
+		if end > b {
+			if end > b+1 {
+				sb.WriteByte('-')
+			}
+			sb.WriteString(classByteString(byte(end)))
+		}
+		b = end
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+func classByteString(b byte) string {
+	switch {
+	case b == ']' || b == '\\' || b == '-' || b == '^':
+		return `\` + string(rune(b))
+	case b >= ' ' && b < 0x7f:
+		return string(rune(b))
+	}
+	return fmt.Sprintf(`\x%02x`, b)
+}
@@ -0,0 +1,71 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGrammarParse(t *testing.T) {
+	t.Parallel()
+
+	g, err := ParseGrammar(listGrammar)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+
+	tree, err := g.Parse("[1, -2.5]", "")
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	var numbers []string
+	for _, child := range tree.Children {
+		if child.Rule == "Number" {
+			numbers = append(numbers, child.Text)
+		}
+	}
+	if tree.Rule != "List" || tree.End != 9 || strings.Join(numbers, " ") != "1 -2.5" {
+		t.Errorf("got tree %+v with numbers %q, want List with numbers 1 and -2.5", tree, numbers)
+	}
+
+	if tree, err = g.Parse("42", "Number"); err != nil || tree.Text != "42" {
+		t.Errorf("got (%+v, %v) for start rule Number, want text 42", tree, err)
+	}
+
+	testCases := []struct {
+		name    string
+		input   string
+		start   string
+		wantErr string
+	}{
+		{name: "missing bracket", input: "[1, 2", wantErr: `line 1, column 6: expected one of: ",", ".", "]"`},
+		{name: "letter after number", input: "[1a]", wantErr: `line 1, column 3: expected one of:`},
+		{name: "trailing input", input: "[]]", wantErr: "line 1, column 3: expected end of input"},
+		{name: "error in later line", input: "[\n1,\n]", wantErr: "line 3, column 1: expected"},
+		{name: "class", input: "1", start: "Letter", wantErr: "expected [A-Z_a-z]"},
+		{name: "undefined start rule", input: "", start: "Nothing", wantErr: `start rule "Nothing" is undefined`},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := g.Parse(tc.input, tc.start)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("got error %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGrammarParseLeftRecursion(t *testing.T) {
+	t.Parallel()
+
+	g, err := ParseGrammar(`Expr <- Expr "+" "1" / "1"`)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	if _, err = g.Parse("1+1", ""); err == nil || !strings.Contains(err.Error(), "left recursive") {
+		t.Errorf("got error %v, want error about left recursion", err)
+	}
+}
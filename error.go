@@ -3,6 +3,7 @@ package gomme
 import (
 	"encoding/hex"
 	"fmt"
+	"slices"
 	"strings"
 	"unicode/utf8"
 )
@@ -11,19 +12,142 @@ const errorMarker = 0x25B6 // easy to spot marker (▶) for exact error position
 
 // ParserError is an error message from the parser.
 // It consists of the text itself and the position in the input where it happened.
+//
+// Creating an error has to be cheap because backtracking parsers create lots
+// of errors that are thrown away again.
+// So the source line is only extracted when the error is rendered.
 type ParserError struct {
-	text      string // the error message from the parser
-	pos       int    // pos is the byte index in the input (state.input.pos)
-	line, col int    // col is the 0-based byte index within srcLine; convert to 1-based rune index for user
-	srcLine   string // line of the source code containing the error or bytes around the error in binary case
-	binary    bool   // are we in binary or text mode?
-	parserID  int32  // ID of the parser reporting the error (only set for syntax errors)
+	text     string // the error message from the parser
+	expected bool   // `expected ` has to be prepended to text
+	pos      int    // pos is the byte index in the input (state.input.pos)
+	input    Input  // the input at the time of the error for finding the source line later
+	excerpt  BinaryExcerpt
+	semantic bool  // the error was reported with State.NewSemanticError
+	parserID int32 // ID of the parser reporting the error (only set for syntax errors)
 }
 
 func (e *ParserError) Error() string {
 	return singleErrorMsg(*e)
 }
 
+// Text returns the error message without the position and source line.
+func (e *ParserError) Text() string {
+	if e.expected {
+		return "expected " + e.text
+	}
+	return e.text
+}
+
+// Pos returns the byte index in the input where the error happened.
+func (e *ParserError) Pos() int {
+	return e.pos
+}
+
+// Line returns the 1-based line number of the error.
+// It is 0 for binary input.
+func (e *ParserError) Line() int {
+	if e.input.binary {
+		return 0
+	}
+	line, _, _ := e.location()
+	return line
+}
+
+// Column returns the 1-based column (in runes) of the error within its line.
+// It is 0 for binary input.
+func (e *ParserError) Column() int {
+	if e.input.binary {
+		return 0
+	}
+	_, col, srcLine := e.location()
+	return utf8.RuneCountInString(srcLine[:col]) + 1
+}
+
+// File returns the name of the file containing the error.
+// It is empty if the input wasn't read by a Session.
+func (e *ParserError) File() string {
+	return e.input.file
+}
+
+// IsSemantic returns true for semantic errors (see State.NewSemanticError)
+// and false for syntax errors.
+func (e *ParserError) IsSemantic() bool {
+	return e.semantic
+}
+
+// WithText returns a copy of the error with the message replaced.
+// Position and source line are kept.
+func (e *ParserError) WithText(text string) *ParserError {
+	newErr := *e
+	newErr.text = text
+	newErr.expected = false
+	return &newErr
+}
+
+// ParseErrors contains all errors of a parse run in the order they were found.
+// It is returned by State.Errors.
+type ParseErrors struct {
+	errs           []ParserError
+	budgetExceeded bool // the budget of State.WithMaxSteps has been exceeded
+}
+
+// Error returns the messages of all errors separated by newlines.
+func (pe *ParseErrors) Error() string {
+	msgs := make([]string, len(pe.errs))
+	for i, e := range pe.errs {
+		msgs[i] = singleErrorMsg(e)
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns all errors. So errors.As and errors.Is look at all of them.
+// ErrBudgetExceeded is added if the parse exceeded its budget.
+func (pe *ParseErrors) Unwrap() []error {
+	errs := make([]error, len(pe.errs), len(pe.errs)+1)
+	for i := range pe.errs {
+		errs[i] = &pe.errs[i]
+	}
+	if pe.budgetExceeded {
+		errs = append(errs, ErrBudgetExceeded)
+	}
+	return errs
+}
+
+// Len returns the number of errors.
+func (pe *ParseErrors) Len() int {
+	return len(pe.errs)
+}
+
+// All returns all errors.
+func (pe *ParseErrors) All() []*ParserError {
+	return pe.filter(func(*ParserError) bool { return true })
+}
+
+// Syntax returns all syntax errors.
+func (pe *ParseErrors) Syntax() []*ParserError {
+	return pe.filter(func(e *ParserError) bool { return !e.semantic })
+}
+
+// Semantic returns all semantic errors.
+func (pe *ParseErrors) Semantic() []*ParserError {
+	return pe.filter(func(e *ParserError) bool { return e.semantic })
+}
+
+// At returns all errors at the byte position `pos` in the input.
+func (pe *ParseErrors) At(pos int) []*ParserError {
+	return pe.filter(func(e *ParserError) bool { return e.pos == pos })
+}
+
+func (pe *ParseErrors) filter(keep func(*ParserError) bool) []*ParserError {
+	var result []*ParserError
+	for i := range pe.errs {
+		if keep(&pe.errs[i]) {
+			result = append(result, &pe.errs[i])
+		}
+	}
+	return result
+}
+
 // errHand contains all data needed for handling one error.
 type errHand struct {
 	err             *ParserError // error that is currently handled
@@ -35,6 +159,7 @@ type errHand struct {
 	orgPos          int          // state.input.pos before starting to use deleter
 	orgLine         int          // state.input.line before starting to use deleter
 	orgPrevNl       int          // state.input.prevNl before starting to use deleter
+	deleted         int          // bytes deleted by the last successful attempt (committed by the next SaveSpot)
 }
 
 // IWitnessed lets a branch parser report an error that it witnessed in
@@ -93,6 +218,7 @@ func HandleWitness[Output any](state State, id uint64, idx int, parsers ...Parse
 		state.errHand.curDel = state.maxDel
 		state.errHand.ignoreErrParser = true
 		state.mode = ParsingModeEscape
+		state.recordRecovery(RecoveryEscape, state.input.pos, 0)
 		Debugf("HandleWitness - EOF -> escape: curDel=%d, ignoreErrParser=%t", state.errHand.curDel, state.errHand.ignoreErrParser)
 		return state, zero
 	}
@@ -124,6 +250,7 @@ func HandleWitness[Output any](state State, id uint64, idx int, parsers ...Parse
 					state.input.line = state.errHand.orgLine
 					state.input.prevNl = state.errHand.orgPrevNl
 					state.mode = ParsingModeEscape // give up and go the hard way
+					state.recordRecovery(RecoveryEscape, state.input.pos, 0)
 					Debugf("HandleWitness - rewind -> escape: curDel=%d, ignoreErrParser=%t", state.errHand.curDel, state.errHand.ignoreErrParser)
 					return state, zero
 				}
@@ -138,6 +265,8 @@ func HandleWitness[Output any](state State, id uint64, idx int, parsers ...Parse
 		oldRemaining := state.BytesRemaining()
 		state = state.deleter(state, min(state.errHand.curDel, 1))
 		if oldRemaining > state.BytesRemaining() || state.errHand.curDel == 0 {
+			// the deletion is tentative until the next SaveSpot succeeds (see commitRecovery)
+			state.errHand.deleted = state.input.pos - state.errHand.orgPos
 			if state.errHand.ignoreErrParser {
 				Debugf("HandleWitness - return -> %s: curDel=%d, ignoreErrParser=%t", state.mode, state.errHand.curDel, state.errHand.ignoreErrParser)
 				return state, zero
@@ -155,6 +284,116 @@ func HandleWitness[Output any](state State, id uint64, idx int, parsers ...Parse
 	}
 }
 
+// ============================================================================
+// Recovery Telemetry
+//
+
+// RecoveryKind is the kind of action taken for recovering from an error.
+type RecoveryKind int
+
+const (
+	// RecoveryDelete - input has been deleted by the deleter
+	// (only recorded once the error is handled successfully)
+	RecoveryDelete RecoveryKind = iota // delete
+	// RecoveryJump - a recoverer skipped input to the next SaveSpot
+	RecoveryJump // jump
+	// RecoveryEscape - deleting didn't help so recoverers have to be used
+	RecoveryEscape // escape
+)
+
+// RecoveryEvent is a single action taken for recovering from an error.
+type RecoveryEvent struct {
+	Kind  RecoveryKind
+	Pos   int // byte position in the input where the action started
+	Bytes int // number of bytes skipped (0 for RecoveryEscape)
+}
+
+// RecoveryStats summarizes all actions taken for recovering from errors.
+// Services can use it to monitor how "dirty" their inputs are.
+type RecoveryStats struct {
+	Deletes      int // number of RecoveryDelete events
+	Jumps        int // number of RecoveryJump events
+	Escapes      int // number of RecoveryEscape events
+	BytesSkipped int // total number of bytes deleted or jumped over
+	// Events are the first events in the order they happened.
+	// At most 1024 events are kept. So a huge broken input can't exhaust
+	// the memory.
+	Events        []RecoveryEvent
+	DroppedEvents int // number of events that haven't been kept in Events
+}
+
+// maxRecoveryEvents is the maximum number of events kept in
+// RecoveryStats.Events. The counters keep counting beyond it.
+const maxRecoveryEvents = 1024
+
+// recoveryLog is shared by all states of one parse run (like the caches).
+type recoveryLog struct {
+	stats RecoveryStats
+}
+
+// commitRecovery records the deletion of the error that has just been
+// handled successfully and resets the error handling.
+// Deletions are only recorded here because attempts that fail later are
+// rewound.
+func (st State) commitRecovery() State {
+	if st.errHand.deleted > 0 {
+		st.recordRecovery(RecoveryDelete, st.errHand.orgPos, st.errHand.deleted)
+	}
+	st.errHand = errHand{}
+	return st
+}
+
+// recordRecovery records a recovery action.
+func (st State) recordRecovery(kind RecoveryKind, pos, bytes int) {
+	if st.recoveryObserver != nil {
+		st.recoveryObserver(RecoveryEvent{Kind: kind, Pos: pos, Bytes: bytes})
+	}
+	for _, trace := range st.traces {
+		trace.recovery(st.mode, RecoveryEvent{Kind: kind, Pos: pos, Bytes: bytes})
+	}
+	if st.recovery == nil {
+		return
+	}
+	stats := &st.recovery.stats
+	switch kind {
+	case RecoveryDelete:
+		stats.Deletes++
+	case RecoveryJump:
+		stats.Jumps++
+	case RecoveryEscape:
+		stats.Escapes++
+	}
+	stats.BytesSkipped += bytes
+	if len(stats.Events) >= maxRecoveryEvents {
+		stats.DroppedEvents++
+		return
+	}
+	stats.Events = append(stats.Events, RecoveryEvent{Kind: kind, Pos: pos, Bytes: bytes})
+}
+
+// WithRecoveryObserver returns the State with an observer that is called
+// for every action taken for recovering from an error.
+// It sees exactly the events recorded for Recoveries (including the ones
+// beyond the limit of RecoveryStats.Events).
+// So debuggers and IDE integrations can visualize what the recovery
+// machinery did.
+// The observer is called synchronously; it should return quickly.
+func (st State) WithRecoveryObserver(observer func(RecoveryEvent)) State {
+	st.recoveryObserver = observer
+	return st
+}
+
+// Recoveries returns the statistics of all actions taken so far
+// for recovering from errors.
+func (st State) Recoveries() RecoveryStats {
+	if st.recovery == nil {
+		return RecoveryStats{}
+	}
+	stats := st.recovery.stats
+	stats.Events = slices.Clone(stats.Events)
+	return stats
+}
+
 // ============================================================================
 // Recoverers
 //
@@ -186,9 +425,10 @@ func DefaultRecovererFunc[Output any](parse func(State) (State, Output, *ParserE
 // will be used multiple times with the exact same input position.
 // The SaveSpot parser is such a case.
 func CachingRecoverer(recoverer Recoverer) Recoverer {
-	id := cachingRecovererIDs.Add(1)
+	pid := NewParserID()
 
 	return func(state State) int {
+		id := pid.In(state)
 		waste, ok := state.cachedRecovererWaste(id)
 		if !ok {
 			waste = recoverer(state)
@@ -201,7 +441,7 @@ func CachingRecoverer(recoverer Recoverer) Recoverer {
 type CombiningRecoverer struct {
 	recoverers []Recoverer
 	lastIdx    int
-	id         uint64
+	id         *ParserID // nil: no caching
 }
 
 // NewCombiningRecoverer recovers by calling all sub-recoverers and returning
@@ -209,9 +449,9 @@ type CombiningRecoverer struct {
 // The index of the best Recoverer is stored in the cache.
 // If `doCache` is false then no caching is performed.
 func NewCombiningRecoverer(doCache bool, recoverers ...Recoverer) CombiningRecoverer {
-	id := uint64(0)
+	var id *ParserID
 	if doCache {
-		id = combiningRecovererIDs.Add(1)
+		id = NewParserID()
 	}
 	return CombiningRecoverer{
 		recoverers: recoverers,
@@ -221,8 +461,8 @@ func NewCombiningRecoverer(doCache bool, recoverers ...Recoverer) CombiningRecov
 }
 
 func (crc CombiningRecoverer) Recover(state State) int {
-	if crc.id > 0 {
-		waste, idx, ok := state.cachedRecovererWasteIdx(crc.id)
+	if crc.id != nil {
+		waste, idx, ok := state.cachedRecovererWasteIdx(crc.id.In(state))
 		if ok {
 			crc.lastIdx = idx
 			return waste
@@ -248,8 +488,8 @@ func (crc CombiningRecoverer) Recover(state State) int {
 		}
 	}
 	crc.lastIdx = idx
-	if crc.id > 0 {
-		state.cacheRecovererWasteIdx(crc.id, waste, idx)
+	if crc.id != nil {
+		state.cacheRecovererWasteIdx(crc.id.In(state), waste, idx)
 	}
 	return waste
 }
@@ -259,7 +499,10 @@ func (crc CombiningRecoverer) LastIndex() int {
 }
 
 func (crc CombiningRecoverer) CachedIndex(state State) (waste, idx int, ok bool) {
-	waste, idx, ok = state.cachedRecovererWasteIdx(crc.id)
+	if crc.id == nil {
+		return 0, -1, false
+	}
+	waste, idx, ok = state.cachedRecovererWasteIdx(crc.id.In(state))
 	if !ok {
 		return 0, -1, false
 	}
@@ -272,41 +515,87 @@ func (crc CombiningRecoverer) CachedIndex(state State) (waste, idx int, ok bool)
 
 func singleErrorMsg(pcbErr ParserError) string {
 	fullMsg := strings.Builder{}
-	fullMsg.WriteString(pcbErr.text)
-	if pcbErr.binary {
-		fullMsg.WriteString(formatBinaryLine(pcbErr.line, pcbErr.col, pcbErr.srcLine))
+	fullMsg.WriteString(pcbErr.Text())
+	line, col, srcLine := pcbErr.location()
+	if pcbErr.input.binary {
+		fullMsg.WriteString(formatBinaryLine(line, col, srcLine, pcbErr.input.file, pcbErr.excerpt))
 	} else {
-		fullMsg.WriteString(formatSrcLine(pcbErr.line, pcbErr.col, pcbErr.srcLine))
+		fullMsg.WriteString(formatSrcLine(line, col, srcLine, pcbErr.input.file, pcbErr.pos))
 	}
 
 	return fullMsg.String()
 }
 
-func formatBinaryLine(line, col int, srcLine string) string {
-	start := line
-	text := hex.Dump([]byte(srcLine))
-	text = text[10:] // remove wrong offset and spaces
+// location finds the line, column and source line of the error.
+// col is the 0-based byte index within srcLine; convert to 1-based rune index for user.
+// In the binary case line is the offset of srcLine and srcLine contains the bytes around the error.
+func (e ParserError) location() (line, col int, srcLine string) {
+	st := State{input: e.input}
+	if e.input.binary { // the rare binary case is misusing the text case data a bit...
+		return st.bytesAround(e.pos, e.excerpt.width())
+	}
+	return st.textAround(e.pos)
+}
+
+// formatBinaryLine renders the bytes in srcLine as hex dump with 16 bytes per row.
+// `start` is the offset of srcLine in the input and `col` the index of the error in srcLine.
+// The name of the file is only shown if it isn't empty.
+func formatBinaryLine(start, col int, srcLine, file string, excerpt BinaryExcerpt) string {
+	const hexWidth = 8*3 + 1 + 8*3 // first hex + space + second hex
 
-	m1 := col * 3
-	if col >= 8 {
-		m1++
+	data := []byte(srcLine)
+	rows := max(1, (len(data)+15)/16)
+	errRow := min(col/16, rows-1)
+	result := strings.Builder{}
+	if file != "" {
+		result.WriteString(fmt.Sprintf(" (%s, offset %d):", file, start+col))
+	} else {
+		result.WriteString(fmt.Sprintf(" (offset %d):", start+col))
+	}
+	for r := 0; r < rows; r++ {
+		row := data[r*16 : min(len(data), r*16+16)]
+		text := strings.Repeat(" ", hexWidth) + " ||"
+		if len(row) > 0 {
+			text = hex.Dump(row)
+			text = text[10 : len(text)-1] // remove wrong offset, spaces and newline
+		}
+		if excerpt.HideASCII {
+			text = text[:hexWidth]
+		}
+		if r == errRow {
+			rowCol := col - r*16
+			m1 := rowCol * 3
+			if rowCol >= 8 {
+				m1++
+			}
+			if excerpt.HideASCII {
+				text = text[:m1] + string(errorMarker) + text[m1:]
+			} else {
+				m2 := hexWidth + 2 + rowCol // hex + space + bar + col
+				text = text[:m1] + string(errorMarker) + text[m1:m2] + string(errorMarker) + text[m2:]
+			}
+		}
+		result.WriteString("\n ")
+		if !excerpt.HideOffset {
+			result.WriteString(fmt.Sprintf("%08x  ", start+r*16))
+		}
+		result.WriteString(strings.TrimRight(text, " "))
 	}
-	// first hex + space + second hex + space + bar + col
-	m2 := 8*3 + 1 + 8*3 + 1 + 1 + col
-	return fmt.Sprintf(":\n %08x  %s%c%s%c%s",
-		// offset, first hex, marker, last hex + ASCII, marker, last ASCII
-		start, text[:m1], errorMarker, text[m1:m2], errorMarker, text[m2:len(text)-1])
+	return result.String()
 }
 
-func formatSrcLine(line, col int, srcLine string) string {
+func formatSrcLine(line, col int, srcLine, file string, offset int) string {
 	result := strings.Builder{}
 	lineStart := srcLine[:col]
 	srcLine = srcLine[col:]
 	result.WriteString(lastNRunes(lineStart, 10))
 	result.WriteRune(errorMarker)
 	result.WriteString(firstNRunes(srcLine, 20))
-	return fmt.Sprintf(` [%d:%d] %s`,
-		line, utf8.RuneCountInString(lineStart)+1, result.String()) // columns for the user start at 1
+	if file != "" {
+		file += ":"
+	}
+	return fmt.Sprintf(` [%s%d:%d] (offset %d) %s`,
+		file, line, utf8.RuneCountInString(lineStart)+1, offset, result.String()) // columns for the user start at 1
 }
 func firstNRunes(s string, n int) string {
 	l := len(s)
@@ -0,0 +1,71 @@
+package gomme_test
+
+import (
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"slices"
+	"testing"
+)
+
+func TestRecoveries(t *testing.T) {
+	t.Parallel()
+
+	parser := pcb.Sequence(gomme.SaveSpot(pcb.String("a")), pcb.String("b"), gomme.SaveSpot(pcb.String("c")))
+
+	newState, output := gomme.RunOnState(gomme.NewFromString("a##bc", true), parser)
+	if !newState.HasError() {
+		t.Errorf("got no error, want the error at '#'")
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(output, want) {
+		t.Errorf("got output %q, want %q", output, want)
+	}
+
+	// deleting only the first '#' fails and is rewound; so it isn't recorded
+	stats := newState.Recoveries()
+	want := []gomme.RecoveryEvent{{Kind: gomme.RecoveryDelete, Pos: 1, Bytes: 2}}
+	if !slices.Equal(stats.Events, want) {
+		t.Errorf("got events %+v, want %+v", stats.Events, want)
+	}
+	if stats.Deletes != 1 || stats.BytesSkipped != 2 || stats.DroppedEvents != 0 {
+		t.Errorf("got stats %+v, want 1 delete of 2 bytes", stats)
+	}
+}
+
+func TestWithRecoveryObserver(t *testing.T) {
+	t.Parallel()
+
+	parser := pcb.Sequence(gomme.SaveSpot(pcb.String("a")), pcb.String("b"), gomme.SaveSpot(pcb.String("c")))
+	var observed []gomme.RecoveryEvent
+	state := gomme.NewFromString("a##bca#c", true).WithRecoveryObserver(func(event gomme.RecoveryEvent) {
+		observed = append(observed, event)
+	})
+
+	newState, _ := gomme.RunOnState(state, pcb.Many1(parser))
+	recorded := newState.Recoveries().Events
+	if len(recorded) == 0 {
+		t.Fatalf("got no recovery events")
+	}
+	if !slices.Equal(observed, recorded) {
+		t.Errorf("got observed events %+v, want recorded events %+v", observed, recorded)
+	}
+}
+
+func TestErrorsSurviveTheNextRun(t *testing.T) {
+	t.Parallel()
+
+	state := gomme.NewFromString("x", false)
+	_, _, itErr := pcb.String("a").It(state)
+	newState, _ := gomme.RunOnState(state, pcb.String("b"))
+	runErr := newState.Errors().All()[0]
+	wantIt, wantRun := itErr.Error(), runErr.Error()
+
+	for i := 0; i < 2; i++ {
+		_, _ = gomme.RunOnState(state, pcb.String("c"))
+	}
+	if got := itErr.Error(); got != wantIt {
+		t.Errorf("got error of Parser.It %q after the next parses, want %q", got, wantIt)
+	}
+	if got := runErr.Error(); got != wantRun {
+		t.Errorf("got error of the first run %q after the next parses, want %q", got, wantRun)
+	}
+}
@@ -1,31 +1,138 @@
 // Package csv implements a parser for CSV files.
 //
-// It is a simple, incomplete, example of how to use the gomme
-// parser combinator library to build a parser targeting the
-// format described in [RFC4180].
+// It is an example of how to use the gomme parser combinator library
+// to build a streaming parser targeting the format described in [RFC4180].
+// The delimiter and quote characters can be configured, so TSV files are
+// supported, too. Quoted fields may contain embedded newlines.
+//
+// Errors are recovered per record. So a single bad row doesn't abort
+// the import of all following rows.
 //
 // [RFC4180]: https://tools.ietf.org/html/rfc4180
 package csv
 
 import (
+	"math"
+	"strings"
+
 	"github.com/oleiade/gomme"
 	. "github.com/oleiade/gomme/cute"
 	"github.com/oleiade/gomme/pcb"
 )
 
+// Config configures the dialect of the CSV input.
+type Config struct {
+	// Delimiter separates the fields of a record.
+	Delimiter rune
+	// Quote starts and ends a quoted field.
+	// A quote inside a quoted field has to be doubled.
+	Quote rune
+	// AllowRaggedRows allows records with a different number of fields
+	// than the first record.
+	AllowRaggedRows bool
+}
+
+var (
+	// CSV is the configuration for comma separated values as described in RFC4180.
+	CSV = Config{Delimiter: ',', Quote: '"'}
+	// TSV is the configuration for tab separated values.
+	TSV = Config{Delimiter: '\t', Quote: '"'}
+)
+
+// ParseCSV parses comma separated values and returns all records.
 func ParseCSV(input string) ([][]string, error) {
-	parser := pcb.Separated1(
-		pcb.Separated1(
-			FirstSuccessful(
-				pcb.Alphanumeric1(),
-				pcb.Delimited(pcb.Char('"'), pcb.Alphanumeric1(), pcb.Char('"')),
-			),
-			pcb.Char(','),
-			false,
+	return Parse(input, CSV)
+}
+
+// ParseTSV parses tab separated values and returns all records.
+func ParseTSV(input string) ([][]string, error) {
+	return Parse(input, TSV)
+}
+
+// Parse parses the input according to `cfg` and returns all records.
+// The records that could be parsed are returned even if an error occurred.
+func Parse(input string, cfg Config) ([][]string, error) {
+	records := make([][]string, 0, 64)
+	_, err := ParseEach(input, cfg, func(record []string) error {
+		records = append(records, record)
+		return nil
+	})
+	return records, err
+}
+
+// ParseEach parses the input according to `cfg` and calls `fn` for every
+// record without collecting them.
+// It returns the number of records that have been handed to `fn`.
+//
+// An error returned by `fn` is reported like a syntax error in the record.
+func ParseEach(input string, cfg Config, fn func(record []string) error) (int, error) {
+	return gomme.RunOnString(input, newParser(cfg, fn))
+}
+
+func newParser(cfg Config, fn func(record []string) error) gomme.Parser[int] {
+	field := FirstSuccessful(
+		quotedField(cfg.Quote),
+		pcb.SatisfyMN("field character", 0, math.MaxInt, func(r rune) bool {
+			return r != cfg.Delimiter && r != cfg.Quote && r != '\r' && r != '\n'
+		}),
+	)
+
+	record := pcb.Separated1(field, pcb.Char(cfg.Delimiter), false)
+	if !cfg.AllowRaggedRows {
+		width := -1
+		record = pcb.Verify(record, func(fields []string) bool {
+			if width < 0 {
+				width = len(fields)
+			}
+			return len(fields) == width
+		}, "record with the same number of fields as the first record")
+	}
+
+	// the line end is the spot to recover at, so every record gets a chance
+	endOfRecord := FirstSuccessful(
+		pcb.Skip(SaveSpot(FirstSuccessful(pcb.CRLF(), S("\n")))),
+		pcb.Skip(pcb.EOF()),
+	)
+
+	return pcb.Suffixed(
+		pcb.Many0Each(
+			pcb.Terminated(pcb.Preceded(record, pcb.Skip(pcb.Not(pcb.EOF()))), endOfRecord),
+			fn,
 		),
-		pcb.CRLF(),
-		false,
+		pcb.EOF(),
 	)
+}
+
+// quotedField parses a field enclosed in quotes.
+// Quotes inside the field are escaped by doubling them.
+func quotedField(quote rune) gomme.Parser[string] {
+	expected := "quoted field"
+	q := string(quote)
+
+	parse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		input := state.CurrentString()
+		if !strings.HasPrefix(input, q) {
+			errState := state.NewError(expected)
+			return errState, "", errState.CurrentError()
+		}
+
+		sb := strings.Builder{}
+		i := len(q)
+		for {
+			j := strings.Index(input[i:], q)
+			if j < 0 {
+				errState := state.MoveBy(len(input)).NewError("closing quote " + q)
+				return state.Preserve(errState), "", errState.CurrentError()
+			}
+			sb.WriteString(input[i : i+j])
+			i += j + len(q)
+			if !strings.HasPrefix(input[i:], q) {
+				return state.MoveBy(i), sb.String(), nil
+			}
+			sb.WriteString(q)
+			i += len(q)
+		}
+	}
 
-	return gomme.RunOnString(input, parser)
+	return gomme.NewParser[string](expected, parse, pcb.BasicRecovererFunc(parse))
 }
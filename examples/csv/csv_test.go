@@ -1,6 +1,8 @@
 package csv
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,6 +38,30 @@ func TestParseRGBColor(t *testing.T) {
 			wantErr:    false,
 			wantOutput: [][]string{{"abc", "def", "ghi"}},
 		},
+		{
+			name:       "parsing quoted fields with embedded quotes and newlines should succeed",
+			input:      "\"a,b\",\"c\"\"d\",\"e\r\nf\"\r\n",
+			wantErr:    false,
+			wantOutput: [][]string{{"a,b", "c\"d", "e\r\nf"}},
+		},
+		{
+			name:       "parsing empty fields and a missing final line end should succeed",
+			input:      "a,,c\nd,e,",
+			wantErr:    false,
+			wantOutput: [][]string{{"a", "", "c"}, {"d", "e", ""}},
+		},
+		{
+			name:       "parsing ragged rows should fail but keep the good rows",
+			input:      "a,b\nc\nd,e\n",
+			wantErr:    true,
+			wantOutput: [][]string{{"a", "b"}, {"d", "e"}},
+		},
+		{
+			name:       "parsing an unclosed quote should fail",
+			input:      "a,\"b\n",
+			wantErr:    true,
+			wantOutput: [][]string{},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -56,3 +82,52 @@ func TestParseRGBColor(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTSV(t *testing.T) {
+	t.Parallel()
+
+	gotOutput, gotErr := ParseTSV("a\tb c\n1\t2\n")
+
+	assert.NoError(t, gotErr)
+	assert.Equal(t, [][]string{{"a", "b c"}, {"1", "2"}}, gotOutput)
+}
+
+func TestParseRaggedRows(t *testing.T) {
+	t.Parallel()
+
+	cfg := CSV
+	cfg.AllowRaggedRows = true
+	gotOutput, gotErr := Parse("a,b\nc\n", cfg)
+
+	assert.NoError(t, gotErr)
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, gotOutput)
+}
+
+func TestParseEach(t *testing.T) {
+	t.Parallel()
+
+	var gotFirst []string
+	count, gotErr := ParseEach("a,b\nc,d\n", CSV, func(record []string) error {
+		if gotFirst == nil {
+			gotFirst = record
+		}
+		return nil
+	})
+	assert.NoError(t, gotErr)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"a", "b"}, gotFirst)
+
+	_, gotErr = ParseEach("a,b\nc,d\n", CSV, func(record []string) error {
+		return errors.New("no thanks")
+	})
+	assert.ErrorContains(t, gotErr, "no thanks")
+}
+
+func BenchmarkParseCSV(b *testing.B) {
+	input := strings.Repeat("abc,\"d,e\",123\r\n", 1000)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = ParseCSV(input)
+	}
+}
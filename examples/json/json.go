@@ -1,3 +1,12 @@
+// Json parses the JSON document test.json and prints its value.
+//
+// ParseJSON is a reference implementation of how to use the gomme
+// parser combinator library to build a parser with full error recovery
+// for the format described in [RFC8259].
+// Objects and arrays recover at their closing delimiter, so a single
+// invalid value doesn't hide all later errors in the document.
+//
+// [RFC8259]: https://tools.ietf.org/html/rfc8259
 package main
 
 import (
@@ -22,10 +31,11 @@ func init() {
 	objectp = gomme.LazyParser(parseObject)
 	arrayp = parseArray()
 	valuep = parseValue()
+	documentp = parseDocument()
 }
 
 func main() {
-	output, err := gomme.RunOnString(testJSON, valuep)
+	output, err := ParseJSON(testJSON)
 	if err != nil {
 		log.Fatal(err.Error())
 		return
@@ -34,6 +44,12 @@ func main() {
 	fmt.Println(output)
 }
 
+// ParseJSON parses a complete JSON document.
+// All errors found in the document are returned joined.
+func ParseJSON(input string) (JSONValue, error) {
+	return gomme.RunOnString(input, documentp)
+}
+
 type (
 	// JSONValue represents any value that can be encountered in
 	// JSON, including complex types like objects and arrays.
@@ -74,12 +90,21 @@ func parseValue() gomme.Parser[JSONValue] {
 
 var valuep gomme.Parser[JSONValue]
 
+// parseDocument parses a whole JSON document: a single element
+// followed by the end of the input.
+func parseDocument() gomme.Parser[JSONValue] {
+	return pcb.Suffixed(element, pcb.EOF())
+}
+
+var documentp gomme.Parser[JSONValue]
+
 // parseObject parses a JSON object, which starts and ends with
 // curly braces and contains key-value pairs.
+// It recovers from errors at the closing curly brace.
 func parseObject() gomme.Parser[JSONValue] {
 	return pcb.Map(
-		pcb.Delimited[rune, map[string]JSONValue, rune](
-			pcb.Char('{'),
+		pcb.Between[rune, map[string]JSONValue, rune](
+			SaveSpot(C('{')),
 			pcb.Optional[map[string]JSONValue](
 				pcb.Prefixed(
 					ws,
@@ -101,10 +126,11 @@ var objectp gomme.Parser[JSONValue]
 
 // parseArray parses a JSON array, which starts and ends with
 // square brackets and contains a list of values.
+// It recovers from errors at the closing square bracket.
 func parseArray() gomme.Parser[JSONValue] {
 	return pcb.Map(
-		pcb.Delimited[rune, []JSONValue, rune](
-			pcb.Char('['),
+		pcb.Between[rune, []JSONValue, rune](
+			SaveSpot(C('[')),
 			FirstSuccessful(
 				elements,
 				pcb.Map(ws, func(s string) ([]JSONValue, error) { return []JSONValue{}, nil }),
@@ -252,7 +278,7 @@ func memberParser() gomme.Parser[kv] {
 
 	return pcb.Map3(
 		pcb.Delimited(ws, pstring, ws),
-		pcb.Char(':'),
+		SaveSpot(C(':')),
 		element,
 		mapFunc,
 	)
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSON(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput JSONValue
+	}{
+		{
+			name:       "parsing a number should succeed",
+			input:      "123",
+			wantErr:    false,
+			wantOutput: JSONNumber(123),
+		},
+		{
+			name:       "parsing a string should succeed",
+			input:      `"abc\n"`,
+			wantErr:    false,
+			wantOutput: JSONString("abc\n"),
+		},
+		{
+			name:       "parsing an array should succeed",
+			input:      `[1, "a", true]`,
+			wantErr:    false,
+			wantOutput: JSONArray{JSONNumber(1), JSONString("a"), JSONBool(true)},
+		},
+		{
+			name:       "parsing an object should succeed",
+			input:      `{"a": 1, "b": [false]}`,
+			wantErr:    false,
+			wantOutput: JSONObject{"a": JSONNumber(1), "b": JSONArray{JSONBool(false)}},
+		},
+		{
+			name:       "parsing an array with an invalid value should fail",
+			input:      `[1, x, 3]`,
+			wantErr:    true,
+			wantOutput: nil,
+		},
+		{
+			name:       "parsing an object with a missing colon should fail",
+			input:      `{"a" 1}`,
+			wantErr:    true,
+			wantOutput: nil,
+		},
+		{
+			name:       "parsing trailing garbage should fail",
+			input:      `{"a": 1} x`,
+			wantErr:    true,
+			wantOutput: nil,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotResult, gotErr := ParseJSON(tc.input)
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", gotErr, tc.wantErr)
+			}
+
+			assert.Equal(t,
+				tc.wantOutput,
+				gotResult,
+				"got output %v, want output %v", gotResult, tc.wantOutput,
+			)
+		})
+	}
+}
+
+func TestParseJSONReportsAllErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseJSON(`{"a": [1, x], "b": [y, 2]}`)
+
+	assert.ErrorContains(t, err, "[1:11]")
+	assert.ErrorContains(t, err, "[1:21]")
+}
+
+func BenchmarkParseJSON(b *testing.B) {
+	b.SetBytes(int64(len(testJSON)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = ParseJSON(testJSON)
+	}
+}
+
+func BenchmarkEncodingJSON(b *testing.B) {
+	data := []byte(testJSON)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var v interface{}
+		_ = json.Unmarshal(data, &v)
+	}
+}
@@ -0,0 +1,117 @@
+// Package sexpr implements a reader for s-expressions.
+//
+// It reads atoms (symbols and numbers), strings and arbitrarily nested
+// lists into a simple tree of nodes. Comments start with ';' and end at
+// the end of the line.
+//
+// Besides being a useful utility it demonstrates how to build
+// recursive grammars with `gomme.LazyParser`.
+package sexpr
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/oleiade/gomme"
+	. "github.com/oleiade/gomme/cute"
+	"github.com/oleiade/gomme/pcb"
+)
+
+// Kind is the kind of a node.
+type Kind int
+
+const (
+	Symbol Kind = iota
+	Number
+	String
+	List
+)
+
+// Node is a node in the tree of s-expressions.
+// Text holds the symbol or the decoded string,
+// Number holds the value of a number and
+// Children holds the elements of a list.
+type Node struct {
+	Kind     Kind
+	Text     string
+	Number   float64
+	Children []Node
+}
+
+// ReadOne reads exactly one s-expression from the input.
+func ReadOne(input string) (Node, error) {
+	return gomme.RunOnString(input, pcb.Suffixed(exprp, pcb.EOF()))
+}
+
+// ReadAll reads all s-expressions from the input.
+func ReadAll(input string) ([]Node, error) {
+	return gomme.RunOnString(input, pcb.Suffixed(pcb.Many0(exprp), pcb.EOF()))
+}
+
+// break initialization cycle:
+func init() {
+	listp = gomme.LazyParser(parseList)
+	exprp = parseExpr()
+}
+
+var (
+	exprp gomme.Parser[Node]
+	listp gomme.Parser[Node]
+)
+
+// ws skips whitespace and comments.
+var ws = pcb.SkipMany0(FirstSuccessful(
+	pcb.Skip(pcb.Whitespace1()),
+	pcb.Skip(pcb.Prefixed(C(';'), pcb.SatisfyMN("comment", 0, math.MaxInt, func(r rune) bool {
+		return r != '\n'
+	}))),
+))
+
+// parseExpr parses a single s-expression surrounded by optional whitespace.
+func parseExpr() gomme.Parser[Node] {
+	return pcb.Delimited(ws, FirstSuccessful(listp, parseString(), parseAtom()), ws)
+}
+
+// parseList parses a list of s-expressions in parentheses.
+// It recovers from errors at the closing parenthesis.
+func parseList() gomme.Parser[Node] {
+	return pcb.Map(
+		pcb.Between(SaveSpot(C('(')), pcb.Many0(exprp), C(')')),
+		func(children []Node) (Node, error) {
+			return Node{Kind: List, Children: children}, nil
+		},
+	)
+}
+
+// parseString parses a string in double quotes with Go escape sequences.
+func parseString() gomme.Parser[Node] {
+	return pcb.Map(pcb.GoString(), func(text string) (Node, error) {
+		return Node{Kind: String, Text: text}, nil
+	})
+}
+
+// parseAtom parses a number or a symbol.
+// Every atom that starts like a number and can be parsed as a float is
+// a number. So `inf` and `nan` are symbols.
+func parseAtom() gomme.Parser[Node] {
+	atom := pcb.SatisfyMN("atom", 1, math.MaxInt, func(r rune) bool {
+		return !strings.ContainsRune(" \t\r\n();\"`", r)
+	})
+	return pcb.Map(atom, func(text string) (Node, error) {
+		if !startsLikeNumber(text) {
+			return Node{Kind: Symbol, Text: text}, nil
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return Node{Kind: Number, Number: f}, nil
+		}
+		return Node{Kind: Symbol, Text: text}, nil
+	})
+}
+
+// startsLikeNumber reports whether the atom starts with a digit or a dot
+// after an optional sign.
+func startsLikeNumber(text string) bool {
+	text = strings.TrimLeft(text, "+-")
+	return text != "" && (text[0] >= '0' && text[0] <= '9' || text[0] == '.')
+}
@@ -0,0 +1,136 @@
+package sexpr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOne(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput Node
+	}{
+		{
+			name:       "reading a symbol should succeed",
+			input:      " foo-bar? ",
+			wantErr:    false,
+			wantOutput: Node{Kind: Symbol, Text: "foo-bar?"},
+		},
+		{
+			name:       "reading a number should succeed",
+			input:      "-1.5e3",
+			wantErr:    false,
+			wantOutput: Node{Kind: Number, Number: -1500},
+		},
+		{
+			name:       "reading inf should give a symbol",
+			input:      "-inf",
+			wantErr:    false,
+			wantOutput: Node{Kind: Symbol, Text: "-inf"},
+		},
+		{
+			name:       "reading nan should give a symbol",
+			input:      "NaN",
+			wantErr:    false,
+			wantOutput: Node{Kind: Symbol, Text: "NaN"},
+		},
+		{
+			name:       "reading a string should succeed",
+			input:      `"a\"b"`,
+			wantErr:    false,
+			wantOutput: Node{Kind: String, Text: `a"b`},
+		},
+		{
+			name:    "reading nested lists should succeed",
+			input:   "(define (sq x) ; square\n  (* x x))",
+			wantErr: false,
+			wantOutput: Node{Kind: List, Children: []Node{
+				{Kind: Symbol, Text: "define"},
+				{Kind: List, Children: []Node{{Kind: Symbol, Text: "sq"}, {Kind: Symbol, Text: "x"}}},
+				{Kind: List, Children: []Node{
+					{Kind: Symbol, Text: "*"}, {Kind: Symbol, Text: "x"}, {Kind: Symbol, Text: "x"},
+				}},
+			}},
+		},
+		{
+			name:       "reading an empty list should succeed",
+			input:      "()",
+			wantErr:    false,
+			wantOutput: Node{Kind: List, Children: []Node{}},
+		},
+		{
+			name:       "reading an unclosed list should fail",
+			input:      "(a (b c)",
+			wantErr:    true,
+			wantOutput: Node{},
+		},
+		{
+			name:       "reading two expressions should fail",
+			input:      "a b",
+			wantErr:    true,
+			wantOutput: Node{},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, gotErr := ReadOne(tc.input)
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", gotErr, tc.wantErr)
+			}
+
+			assert.Equal(t,
+				tc.wantOutput,
+				gotOutput,
+				"got output %v, want output %v", gotOutput, tc.wantOutput,
+			)
+		})
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	t.Parallel()
+
+	gotOutput, gotErr := ReadAll("a (b) \"c\" 1")
+
+	assert.NoError(t, gotErr)
+	assert.Len(t, gotOutput, 4)
+	assert.Equal(t, []Kind{Symbol, List, String, Number},
+		[]Kind{gotOutput[0].Kind, gotOutput[1].Kind, gotOutput[2].Kind, gotOutput[3].Kind})
+}
+
+func TestReadDeeplyNested(t *testing.T) {
+	t.Parallel()
+
+	const depth = 1000
+	gotOutput, gotErr := ReadOne(strings.Repeat("(", depth) + "x" + strings.Repeat(")", depth))
+	assert.NoError(t, gotErr)
+
+	n := 0
+	for gotOutput.Kind == List {
+		assert.Len(t, gotOutput.Children, 1)
+		gotOutput = gotOutput.Children[0]
+		n++
+	}
+	assert.Equal(t, depth, n)
+	assert.Equal(t, Node{Kind: Symbol, Text: "x"}, gotOutput)
+}
+
+func BenchmarkReadOne(b *testing.B) {
+	input := strings.Repeat("(let ((x 1) (y \"two\")) (+ x y)) ", 100)
+	input = "(" + input + ")"
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = ReadOne(input)
+	}
+}
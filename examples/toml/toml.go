@@ -0,0 +1,351 @@
+// Package toml implements a parser for a subset of TOML documents.
+//
+// It is a mid-size example of how to use the gomme parser combinator
+// library for a realistic configuration format described in [TOML].
+// Supported are:
+//   - comments, bare, quoted and dotted keys
+//   - table headers including nested tables (`[a.b.c]`)
+//   - basic and literal strings, integers, floats and booleans
+//   - offset date-times, local date-times and local dates
+//   - arrays of values (possibly spanning multiple lines)
+//
+// Not supported are multi-line strings, inline tables and arrays of tables.
+// All errors have line and column information and are recovered at the
+// end of the line.
+//
+// [TOML]: https://toml.io/en/v1.0.0
+package toml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oleiade/gomme"
+	. "github.com/oleiade/gomme/cute"
+	"github.com/oleiade/gomme/pcb"
+)
+
+// Table is a TOML table.
+// The values are of type string, int64, float64, bool, time.Time,
+// []interface{} or Table.
+type Table map[string]interface{}
+
+// ParseTOML parses a TOML document and returns its root table.
+// The parsers are free of side effects because they might be applied
+// several times (backtracking and error recovery). So the table is built
+// from the entries of all lines after parsing.
+func ParseTOML(input string) (Table, error) {
+	entries, err := gomme.RunOnString(input, pcb.Suffixed(pcb.Many0(linep), pcb.EOF()))
+	if err != nil {
+		return nil, err
+	}
+
+	doc := newDocument()
+	for _, e := range entries {
+		if err := doc.add(e); err != nil {
+			line := strings.Count(input[:e.pos], "\n") + 1
+			return nil, fmt.Errorf("%w [%d:1]", err, line)
+		}
+	}
+	return doc.root, nil
+}
+
+// entry is the result of parsing a single line.
+// An empty line results in an entry without key.
+type entry struct {
+	pos    int // start of the line in the input
+	header bool
+	key    []string
+	value  interface{}
+}
+
+// break initialization cycle:
+func init() {
+	valuep = gomme.LazyParser(parseValue)
+	linep = parseLine()
+}
+
+var (
+	valuep gomme.Parser[interface{}]
+	linep  gomme.Parser[entry]
+)
+
+var (
+	ws      = pcb.SatisfyMN("whitespace", 0, math.MaxInt, isBlank)
+	comment = pcb.Optional(pcb.Prefixed(C('#'), pcb.SatisfyMN("comment", 0, math.MaxInt, isNotNewline)))
+	newline = FirstSuccessful(pcb.CRLF(), S("\n"))
+)
+
+// wsnl parses whitespace including newlines and comments as they are
+// allowed inside of arrays.
+var wsnl = pcb.SkipMany0(FirstSuccessful(
+	pcb.Skip(pcb.SatisfyMN("whitespace", 1, math.MaxInt, isBlank)),
+	pcb.Skip(newline),
+	pcb.Skip(pcb.Prefixed(C('#'), pcb.SatisfyMN("comment", 0, math.MaxInt, isNotNewline))),
+))
+
+// parseLine parses a single line of the document: a table header,
+// a key/value pair or nothing. The line end is used for recovering.
+func parseLine() gomme.Parser[entry] {
+	statement := pcb.Optional(FirstSuccessful(parseHeader(), parseKeyValue()))
+	endOfLine := FirstSuccessful(
+		pcb.Skip(SaveSpot(newline)),
+		pcb.Skip(pcb.EOF()),
+	)
+
+	line := pcb.Terminated(
+		pcb.Preceded(statement, pcb.Skip(pcb.Not(pcb.EOF())), pcb.Skip(ws)),
+		pcb.Skip(ws), pcb.Skip(comment), endOfLine,
+	)
+	return pcb.Map2(position, line, func(pos int, e entry) (entry, error) {
+		e.pos = pos
+		return e, nil
+	})
+}
+
+// position returns the current position in the input without consuming
+// anything.
+var position = gomme.NewParser[int]("position", func(state gomme.State) (gomme.State, int, *gomme.ParserError) {
+	return state, state.CurrentPos(), nil
+}, pcb.Forbidden("position"))
+
+// parseHeader parses a table header like `[server.http]`.
+func parseHeader() gomme.Parser[entry] {
+	return pcb.Map(
+		pcb.Between(SaveSpot(C('[')), parseKey(), C(']')),
+		func(key []string) (entry, error) {
+			return entry{header: true, key: key}, nil
+		},
+	)
+}
+
+// parseKeyValue parses a key/value pair like `port = 8080`.
+func parseKeyValue() gomme.Parser[entry] {
+	return pcb.Map3(
+		parseKey(),
+		SaveSpot(C('=')),
+		pcb.Prefixed(ws, valuep),
+		func(key []string, _ rune, value interface{}) (entry, error) {
+			return entry{key: key, value: value}, nil
+		},
+	)
+}
+
+// parseKey parses a possibly dotted key like `a."b c".d`.
+func parseKey() gomme.Parser[[]string] {
+	bareKey := pcb.SatisfyMN("bare key", 1, math.MaxInt, func(r rune) bool {
+		return pcb.IsAlphanumeric(r) || r == '_' || r == '-'
+	})
+
+	return pcb.Separated1(
+		pcb.Delimited(ws, FirstSuccessful(bareKey, basicString(), literalString()), ws),
+		C('.'),
+		false,
+	)
+}
+
+// parseValue parses any supported TOML value.
+func parseValue() gomme.Parser[interface{}] {
+	return FirstSuccessful(
+		toValue(basicString()),
+		toValue(literalString()),
+		toValue(parseArray()),
+		parseScalar(),
+	)
+}
+
+// basicString parses a string in double quotes.
+// Escape sequences are interpreted by Go rules, which are a superset
+// of those of TOML.
+func basicString() gomme.Parser[string] {
+	return pcb.GoString()
+}
+
+// literalString parses a string in single quotes without escaping.
+func literalString() gomme.Parser[string] {
+	return pcb.Delimited(
+		C('\''),
+		pcb.SatisfyMN("literal string", 0, math.MaxInt, func(r rune) bool {
+			return r != '\'' && isNotNewline(r)
+		}),
+		C('\''),
+	)
+}
+
+// parseArray parses an array of values that may span multiple lines.
+// It recovers from errors at the closing square bracket.
+func parseArray() gomme.Parser[[]interface{}] {
+	return pcb.Between(
+		SaveSpot(C('[')),
+		pcb.Suffixed(pcb.Separated0(pcb.Delimited(wsnl, valuep, wsnl), C(','), true), wsnl),
+		C(']'),
+	)
+}
+
+// parseScalar parses booleans, numbers and dates.
+// It first recognizes the whole token and then finds out what it is.
+func parseScalar() gomme.Parser[interface{}] {
+	token := pcb.SatisfyMN("value", 1, math.MaxInt, func(r rune) bool {
+		return pcb.IsAlphanumeric(r) || strings.ContainsRune("+-.:_", r)
+	})
+	return pcb.Map(token, scalarValue)
+}
+
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02"}
+
+func scalarValue(token string) (interface{}, error) {
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+
+	if len(token) >= 10 && token[4] == '-' {
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, token); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid date %q", token)
+	}
+
+	if i, ok := parseInteger(token); ok {
+		return i, nil
+	}
+	if !strings.ContainsAny(token, "xob") && !hasLeadingZero(token) { // hex, octal and binary are integers only
+		if f, err := strconv.ParseFloat(token, 64); err == nil {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid value %q", token)
+}
+
+// parseInteger parses a TOML integer. Only the prefixes `0x`, `0o` and
+// `0b` change the base. So `010` isn't octal but invalid because leading
+// zeros aren't allowed.
+func parseInteger(token string) (int64, bool) {
+	sign, digits := "", token
+	if strings.HasPrefix(digits, "+") || strings.HasPrefix(digits, "-") {
+		sign, digits = digits[:1], digits[1:]
+	}
+
+	base := 10
+	switch {
+	case strings.HasPrefix(digits, "0x"):
+		base = 16
+	case strings.HasPrefix(digits, "0o"):
+		base = 8
+	case strings.HasPrefix(digits, "0b"):
+		base = 2
+	}
+	if base != 10 {
+		if sign != "" { // only decimal integers can have a sign
+			return 0, false
+		}
+		digits = digits[2:]
+	} else if len(digits) > 1 && digits[0] == '0' {
+		return 0, false
+	}
+
+	// underscores are allowed between digits only
+	if digits == "" || digits[0] == '_' || digits[len(digits)-1] == '_' || strings.Contains(digits, "__") {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(sign+strings.ReplaceAll(digits, "_", ""), base, 64)
+	return i, err == nil
+}
+
+// hasLeadingZero returns true if the integer part of the number has
+// leading zeros (e.g. `01.5`).
+func hasLeadingZero(token string) bool {
+	digits := strings.TrimLeft(token, "+-")
+	return len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' && digits[1] <= '9'
+}
+
+func toValue[Output any](parse gomme.Parser[Output]) gomme.Parser[interface{}] {
+	return pcb.Map(parse, func(output Output) (interface{}, error) {
+		return output, nil
+	})
+}
+
+// document collects the entries of all lines in the root table.
+type document struct {
+	root    Table
+	current Table
+	defined map[string]bool
+}
+
+func newDocument() *document {
+	root := Table{}
+	return &document{root: root, current: root, defined: map[string]bool{}}
+}
+
+func (doc *document) add(e entry) error {
+	if e.key == nil { // empty line
+		return nil
+	}
+
+	if e.header {
+		name := strings.Join(e.key, ".")
+		if doc.defined[name] {
+			return fmt.Errorf("table %q is defined twice", name)
+		}
+		doc.defined[name] = true
+
+		table, err := doc.root.subTable(e.key)
+		if err != nil {
+			return err
+		}
+		doc.current = table
+		return nil
+	}
+
+	last := len(e.key) - 1
+	table, err := doc.current.subTable(e.key[:last])
+	if err != nil {
+		return err
+	}
+	if _, ok := table[e.key[last]]; ok {
+		return fmt.Errorf("key %q is defined twice", strings.Join(e.key, "."))
+	}
+	table[e.key[last]] = e.value
+	return nil
+}
+
+// subTable returns the table at the end of `path` and creates
+// all missing tables on the way.
+func (t Table) subTable(path []string) (Table, error) {
+	for _, key := range path {
+		value, ok := t[key]
+		if !ok {
+			sub := Table{}
+			t[key] = sub
+			t = sub
+			continue
+		}
+		sub, ok := value.(Table)
+		if !ok {
+			return nil, fmt.Errorf("key %q is not a table", key)
+		}
+		t = sub
+	}
+	return t, nil
+}
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+func isNotNewline(r rune) bool {
+	return r != '\n' && r != '\r'
+}
@@ -0,0 +1,140 @@
+package toml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTOML(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput Table
+	}{
+		{
+			name:       "parsing an empty document should succeed",
+			input:      "",
+			wantErr:    false,
+			wantOutput: Table{},
+		},
+		{
+			name:    "parsing key value pairs should succeed",
+			input:   "# comment\na = 1\nb = -2.5 # comment\nc = true\n\"d e\" = \"x\\ty\"\nf = 'C:\\dir'\n",
+			wantErr: false,
+			wantOutput: Table{
+				"a":   int64(1),
+				"b":   -2.5,
+				"c":   true,
+				"d e": "x\ty",
+				"f":   `C:\dir`,
+			},
+		},
+		{
+			name:    "parsing nested tables and dotted keys should succeed",
+			input:   "[server]\nhost = 'localhost'\n\n[server.http]\nport = 8080\nlimits.max = 0x10",
+			wantErr: false,
+			wantOutput: Table{
+				"server": Table{
+					"host": "localhost",
+					"http": Table{
+						"port":   int64(8080),
+						"limits": Table{"max": int64(16)},
+					},
+				},
+			},
+		},
+		{
+			name:    "parsing dates should succeed",
+			input:   "d = 1979-05-27\ndt = 1979-05-27T07:32:00Z\n",
+			wantErr: false,
+			wantOutput: Table{
+				"d":  time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC),
+				"dt": time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "parsing multi-line arrays should succeed",
+			input:   "a = [\n  1, # one\n  'two',\n  [3.0],\n]\n",
+			wantErr: false,
+			wantOutput: Table{
+				"a": []interface{}{int64(1), "two", []interface{}{3.0}},
+			},
+		},
+		{
+			name:    "parsing integers with prefixes and underscores should succeed",
+			input:   "a = 0o10\nb = 0b101\nc = 1_000\nd = +0\n",
+			wantErr: false,
+			wantOutput: Table{
+				"a": int64(8),
+				"b": int64(5),
+				"c": int64(1000),
+				"d": int64(0),
+			},
+		},
+		{
+			name:       "parsing an integer with leading zero should fail",
+			input:      "a = 010\n",
+			wantErr:    true,
+			wantOutput: nil,
+		},
+		{
+			name:       "parsing an invalid value should fail",
+			input:      "a = 1x\nb = 2\n",
+			wantErr:    true,
+			wantOutput: nil,
+		},
+		{
+			name:       "parsing a duplicate key should fail",
+			input:      "a = 1\na = 2\n",
+			wantErr:    true,
+			wantOutput: nil,
+		},
+		{
+			name:       "parsing a duplicate table should fail",
+			input:      "[a]\n[a]\n",
+			wantErr:    true,
+			wantOutput: nil,
+		},
+		{
+			name:       "parsing a key that is no table should fail",
+			input:      "a = 1\na.b = 2\n",
+			wantErr:    true,
+			wantOutput: nil,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, gotErr := ParseTOML(tc.input)
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", gotErr, tc.wantErr)
+			}
+
+			assert.Equal(t,
+				tc.wantOutput,
+				gotOutput,
+				"got output %v, want output %v", gotOutput, tc.wantOutput,
+			)
+		})
+	}
+}
+
+func TestParseTOMLReportsPositions(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseTOML("a = 1\nb = oops\nc = [1, 2\nd = 4\n")
+
+	assert.ErrorContains(t, err, "[2:5]")
+	assert.ErrorContains(t, err, "[3:")
+
+	_, err = ParseTOML("a = 1\n\na = 2\n")
+	assert.ErrorContains(t, err, "[3:1]")
+}
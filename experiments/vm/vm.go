@@ -0,0 +1,310 @@
+// Package vm is an experimental execution engine for grammars.
+// A grammar is compiled into a compact bytecode that is executed by a
+// single loop. Calls and backtracking use an explicit stack on the heap,
+// so the recursion depth of the grammar is independent of the Go call stack.
+//
+// The bytecode is compiled from a grammar in PEG notation
+// (see codegen.ParseGrammar), not from gomme parsers.
+// The instruction set is modeled after the parsing machine of LPeg.
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oleiade/gomme/codegen"
+)
+
+// DefaultMaxStack is the default maximum number of entries on the stack
+// of calls and backtrack points.
+const DefaultMaxStack = 1 << 20
+
+type opcode uint8
+
+const (
+	opLiteral       opcode = iota // literal
+	opClass                       // class
+	opAny                         // any
+	opChoice                      // choice
+	opCommit                      // commit
+	opPartialCommit               // partialcommit
+	opBackCommit                  // backcommit
+	opFailTwice                   // failtwice
+	opFail                        // fail
+	opCall                        // call
+	opReturn                      // return
+	opJump                        // jump
+	opEnd                         // end
+)
+
+var opNames = [...]string{
+	"literal", "class", "any", "choice", "commit", "partialcommit", "backcommit",
+	"failtwice", "fail", "call", "return", "jump", "end",
+}
+
+type instr struct {
+	op  opcode
+	arg int32 // jump target or index of a literal or class
+}
+
+type byteClass [4]uint64
+
+func (bc *byteClass) has(b byte) bool {
+	return bc[b>>6]&(1<<(b&63)) != 0
+}
+
+// Program is a compiled grammar.
+// It is safe for concurrent use.
+type Program struct {
+	code     []instr
+	literals []string
+	classes  []byteClass
+	// MaxStack is the maximum number of entries on the stack.
+	// Matching fails with an error if it is exceeded.
+	MaxStack int
+}
+
+// Compile compiles the grammar starting with its first rule.
+func Compile(g *codegen.Grammar) (*Program, error) {
+	return CompileRule(g, g.Rules[0].Name)
+}
+
+// CompileRule compiles the grammar starting with the rule `start`.
+func CompileRule(g *codegen.Grammar, start string) (*Program, error) {
+	if g.Rule(start) == nil {
+		return nil, fmt.Errorf("unknown start rule %q", start)
+	}
+	c := &compiler{
+		prog:      &Program{MaxStack: DefaultMaxStack},
+		ruleAddrs: make(map[string]int32, len(g.Rules)),
+		literals:  make(map[string]int32),
+	}
+	c.emit(opCall, 0)
+	c.fixups = append(c.fixups, fixup{at: 0, rule: start})
+	c.emit(opEnd, 0)
+	for _, r := range g.Rules {
+		c.ruleAddrs[r.Name] = c.here()
+		c.expr(r.Expr)
+		c.emit(opReturn, 0)
+	}
+	for _, f := range c.fixups {
+		c.prog.code[f.at].arg = c.ruleAddrs[f.rule]
+	}
+	return c.prog, nil
+}
+
+// Match matches the program at the start of the input and returns the
+// number of bytes matched.
+// ok is false if the input doesn't match.
+// An error is returned if the stack grows beyond MaxStack.
+func (p *Program) Match(input string) (end int, ok bool, err error) {
+	type entry struct {
+		pc  int32 // return address or alternative; -1 for calls
+		ret int32 // return address of calls
+		pos int
+	}
+	stack := make([]entry, 0, 64)
+	pc, pos := int32(0), 0
+
+	for {
+		in := p.code[pc]
+		switch in.op {
+		case opLiteral:
+			lit := p.literals[in.arg]
+			if strings.HasPrefix(input[pos:], lit) {
+				pos += len(lit)
+				pc++
+				continue
+			}
+		case opClass:
+			if pos < len(input) && p.classes[in.arg].has(input[pos]) {
+				pos++
+				pc++
+				continue
+			}
+		case opAny:
+			if pos < len(input) {
+				pos++
+				pc++
+				continue
+			}
+		case opChoice:
+			if len(stack) >= p.MaxStack {
+				return pos, false, fmt.Errorf("stack overflow (more than %d entries)", p.MaxStack)
+			}
+			stack = append(stack, entry{pc: in.arg, pos: pos})
+			pc++
+			continue
+		case opCommit:
+			stack = stack[:len(stack)-1]
+			pc = in.arg
+			continue
+		case opPartialCommit:
+			top := &stack[len(stack)-1]
+			if top.pos == pos { // no progress: leave the loop instead of looping endlessly
+				stack = stack[:len(stack)-1]
+				pc++
+				continue
+			}
+			top.pos = pos
+			pc = in.arg
+			continue
+		case opBackCommit:
+			pos = stack[len(stack)-1].pos
+			stack = stack[:len(stack)-1]
+			pc = in.arg
+			continue
+		case opFailTwice:
+			stack = stack[:len(stack)-1]
+		case opFail:
+		case opCall:
+			if len(stack) >= p.MaxStack {
+				return pos, false, fmt.Errorf("stack overflow (more than %d entries)", p.MaxStack)
+			}
+			stack = append(stack, entry{pc: -1, ret: pc + 1})
+			pc = in.arg
+			continue
+		case opReturn:
+			pc = stack[len(stack)-1].ret
+			stack = stack[:len(stack)-1]
+			continue
+		case opJump:
+			pc = in.arg
+			continue
+		case opEnd:
+			return pos, true, nil
+		}
+
+		// fail: backtrack to the last choice and drop pending calls
+		for len(stack) > 0 && stack[len(stack)-1].pc < 0 {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			return 0, false, nil
+		}
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		pc, pos = top.pc, top.pos
+	}
+}
+
+// String returns a disassembly of the program.
+func (p *Program) String() string {
+	sb := strings.Builder{}
+	for i, in := range p.code {
+		fmt.Fprintf(&sb, "%4d %-13s", i, opNames[in.op])
+		switch in.op {
+		case opLiteral:
+			fmt.Fprintf(&sb, " %q", p.literals[in.arg])
+		case opClass:
+			fmt.Fprintf(&sb, " #%d", in.arg)
+		case opChoice, opCommit, opPartialCommit, opBackCommit, opCall, opJump:
+			fmt.Fprintf(&sb, " %d", in.arg)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+type fixup struct {
+	at   int32
+	rule string
+}
+
+type compiler struct {
+	prog      *Program
+	ruleAddrs map[string]int32
+	literals  map[string]int32
+	fixups    []fixup
+}
+
+func (c *compiler) here() int32 {
+	return int32(len(c.prog.code))
+}
+
+func (c *compiler) emit(op opcode, arg int32) int32 {
+	c.prog.code = append(c.prog.code, instr{op: op, arg: arg})
+	return c.here() - 1
+}
+
+// patch sets the jump target of the instruction at `at` to the current address.
+func (c *compiler) patch(at int32) {
+	c.prog.code[at].arg = c.here()
+}
+
+func (c *compiler) expr(e *codegen.Expr) {
+	switch e.Kind {
+	case codegen.ExprLiteral:
+		idx, ok := c.literals[e.Text]
+		if !ok {
+			idx = int32(len(c.prog.literals))
+			c.prog.literals = append(c.prog.literals, e.Text)
+			c.literals[e.Text] = idx
+		}
+		c.emit(opLiteral, idx)
+	case codegen.ExprClass:
+		bc := byteClass{}
+		for b, in := range e.Class {
+			if in {
+				bc[b>>6] |= 1 << (b & 63)
+			}
+		}
+		c.prog.classes = append(c.prog.classes, bc)
+		c.emit(opClass, int32(len(c.prog.classes)-1))
+	case codegen.ExprAny:
+		c.emit(opAny, 0)
+	case codegen.ExprRef:
+		at := c.emit(opCall, 0)
+		c.fixups = append(c.fixups, fixup{at: at, rule: e.Text})
+	case codegen.ExprSequence:
+		for _, child := range e.Children {
+			c.expr(child)
+		}
+	case codegen.ExprChoice:
+		var commits []int32
+		for i, child := range e.Children {
+			if i == len(e.Children)-1 {
+				c.expr(child)
+				break
+			}
+			choice := c.emit(opChoice, 0)
+			c.expr(child)
+			commits = append(commits, c.emit(opCommit, 0))
+			c.patch(choice)
+		}
+		for _, commit := range commits {
+			c.patch(commit)
+		}
+	case codegen.ExprZeroOrMore:
+		c.star(e.Children[0])
+	case codegen.ExprOneOrMore:
+		c.expr(e.Children[0])
+		c.star(e.Children[0])
+	case codegen.ExprOptional:
+		choice := c.emit(opChoice, 0)
+		c.expr(e.Children[0])
+		commit := c.emit(opCommit, 0)
+		c.patch(choice)
+		c.patch(commit)
+	case codegen.ExprAnd:
+		choice := c.emit(opChoice, 0)
+		c.expr(e.Children[0])
+		backCommit := c.emit(opBackCommit, 0)
+		c.patch(choice)
+		c.emit(opFail, 0)
+		c.patch(backCommit)
+	case codegen.ExprNot:
+		choice := c.emit(opChoice, 0)
+		c.expr(e.Children[0])
+		c.emit(opFailTwice, 0)
+		c.patch(choice)
+	}
+}
+
+func (c *compiler) star(e *codegen.Expr) {
+	choice := c.emit(opChoice, 0)
+	loop := c.here()
+	c.expr(e)
+	c.emit(opPartialCommit, loop)
+	c.patch(choice)
+}
@@ -0,0 +1,103 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oleiade/gomme/codegen"
+)
+
+const listGrammar = `
+List   <- "[" _ (Number _ ("," _ Number _)*)? "]"
+Number <- "-"? [0-9]+ ("." [0-9]+)? !Letter
+Letter <- [a-zA-Z_]
+_      <- [ \t\n]*
+`
+
+func mustCompile(t testing.TB, grammar string) *Program {
+	t.Helper()
+
+	g, err := codegen.ParseGrammar(grammar)
+	if err != nil {
+		t.Fatalf("got unexpected grammar error: %v", err)
+	}
+	prog, err := Compile(g)
+	if err != nil {
+		t.Fatalf("got unexpected compile error: %v", err)
+	}
+	return prog
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	prog := mustCompile(t, listGrammar)
+	testCases := []struct {
+		name    string
+		input   string
+		wantOK  bool
+		wantEnd int
+	}{
+		{name: "empty list", input: "[]", wantOK: true, wantEnd: 2},
+		{name: "numbers", input: "[1, 2.5 ,-3]", wantOK: true, wantEnd: 12},
+		{name: "trailing input", input: "[ 7 ]x", wantOK: true, wantEnd: 5},
+		{name: "not predicate", input: "[1a]", wantOK: false},
+		{name: "missing number", input: "[1,]", wantOK: false},
+		{name: "empty input", input: "", wantOK: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			end, ok, err := prog.Match(tc.input)
+			if err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			if ok != tc.wantOK || (ok && end != tc.wantEnd) {
+				t.Errorf("got (%d, %t), want (%d, %t)", end, ok, tc.wantEnd, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestMatchDeepNesting(t *testing.T) {
+	t.Parallel()
+
+	prog := mustCompile(t, `Nest <- "(" Nest? ")"`)
+	depth := 200_000
+	input := strings.Repeat("(", depth) + strings.Repeat(")", depth)
+
+	end, ok, err := prog.Match(input)
+	if err != nil || !ok || end != len(input) {
+		t.Errorf("got (%d, %t, %v), want (%d, true, nil)", end, ok, err, len(input))
+	}
+
+	prog.MaxStack = 1000
+	if _, _, err = prog.Match(input); err == nil {
+		t.Errorf("expected stack overflow error")
+	}
+}
+
+func TestMatchAndPredicate(t *testing.T) {
+	t.Parallel()
+
+	prog := mustCompile(t, `Key <- &"k" [a-z]+ ("=" / !.)`)
+	for input, want := range map[string]bool{"key=": true, "key": true, "val=": false, "key!": false} {
+		if _, ok, err := prog.Match(input); ok != want || err != nil {
+			t.Errorf("input %q: got (%t, %v), want %t", input, ok, err, want)
+		}
+	}
+}
+
+func BenchmarkMatch(b *testing.B) {
+	prog := mustCompile(b, listGrammar)
+	input := "[1, 2.5, -3, 42, 7.125, 100000, 3]"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = prog.Match(input)
+	}
+}
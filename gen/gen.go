@@ -0,0 +1,168 @@
+// Package gen generates random inputs for grammars.
+// The generated inputs can be used for property based testing of grammars
+// and for seeding fuzz corpora.
+//
+// Generators take a separate description of the grammar: they are combined
+// in parallel to the parsers and mirror the combinators of the pcb package.
+// The static analysis of parsers (see gomme.Analyze) only tells how their
+// input can start, not which inputs they accept as a whole.
+// Near-valid inputs are created by mutating valid ones with Mutate.
+package gen
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// Generator writes a random valid input for a (sub-)grammar to `sb`.
+type Generator func(r *rand.Rand, sb *strings.Builder)
+
+// Samples returns `n` inputs generated by `g`.
+// The same `seed` always produces the same inputs.
+func Samples(g Generator, seed int64, n int) []string {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([]string, n)
+	for i := range samples {
+		sb := strings.Builder{}
+		g(r, &sb)
+		samples[i] = sb.String()
+	}
+	return samples
+}
+
+// String generates the token.
+func String(token string) Generator {
+	return func(_ *rand.Rand, sb *strings.Builder) {
+		sb.WriteString(token)
+	}
+}
+
+// OneOf generates one of the tokens.
+func OneOf(tokens ...string) Generator {
+	if len(tokens) == 0 {
+		panic("OneOf has no tokens to generate")
+	}
+	return func(r *rand.Rand, sb *strings.Builder) {
+		sb.WriteString(tokens[r.Intn(len(tokens))])
+	}
+}
+
+// RunesMN generates between `atLeast` and `atMost` runes of `alphabet`.
+// It mirrors `pcb.SatisfyMN` and friends.
+func RunesMN(alphabet string, atLeast, atMost int) Generator {
+	runes := []rune(alphabet)
+	if len(runes) == 0 {
+		panic("RunesMN has an empty alphabet")
+	}
+	return func(r *rand.Rand, sb *strings.Builder) {
+		for n := between(r, atLeast, atMost); n > 0; n-- {
+			sb.WriteRune(runes[r.Intn(len(runes))])
+		}
+	}
+}
+
+// Sequence generates the inputs of all generators one after the other.
+// It mirrors `pcb.Sequence` and the MapX parsers.
+func Sequence(gens ...Generator) Generator {
+	return func(r *rand.Rand, sb *strings.Builder) {
+		for _, g := range gens {
+			g(r, sb)
+		}
+	}
+}
+
+// FirstSuccessful generates the input of one randomly chosen generator.
+// It mirrors `pcb.FirstSuccessful`.
+func FirstSuccessful(gens ...Generator) Generator {
+	if len(gens) == 0 {
+		panic("FirstSuccessful has no generators")
+	}
+	return func(r *rand.Rand, sb *strings.Builder) {
+		gens[r.Intn(len(gens))](r, sb)
+	}
+}
+
+// Optional generates the input of the generator or nothing.
+// It mirrors `pcb.Optional`.
+func Optional(g Generator) Generator {
+	return func(r *rand.Rand, sb *strings.Builder) {
+		if r.Intn(2) == 0 {
+			g(r, sb)
+		}
+	}
+}
+
+// SeparatedMN generates between `atLeast` and `atMost` inputs of `g`
+// separated by inputs of `separator`.
+// It mirrors `pcb.SeparatedMN`. Use a nil separator for ManyMN.
+func SeparatedMN(g, separator Generator, atLeast, atMost int, separatorAtEnd bool) Generator {
+	return func(r *rand.Rand, sb *strings.Builder) {
+		n := between(r, atLeast, atMost)
+		for i := 0; i < n; i++ {
+			if i > 0 && separator != nil {
+				separator(r, sb)
+			}
+			g(r, sb)
+		}
+		if n > 0 && separatorAtEnd && separator != nil && r.Intn(2) == 0 {
+			separator(r, sb)
+		}
+	}
+}
+
+// Lazy breaks cycles in recursive grammars just like `gomme.LazyParser`.
+// The recursion depth is limited to `maxDepth`; deeper down the
+// `leaf` generator is used instead.
+// The generator isn't safe for concurrent use.
+func Lazy(makeGenerator func() Generator, leaf Generator, maxDepth int) Generator {
+	var g Generator
+	depth := 0
+	return func(r *rand.Rand, sb *strings.Builder) {
+		if depth >= maxDepth {
+			leaf(r, sb)
+			return
+		}
+		if g == nil {
+			g = makeGenerator()
+		}
+		depth++
+		g(r, sb)
+		depth--
+	}
+}
+
+// Mutate returns a near-valid variant of the input with one random rune
+// deleted, duplicated or replaced by a rune of `alphabet`.
+// This is useful for testing error messages and recovery.
+func Mutate(r *rand.Rand, input, alphabet string) string {
+	runes := []rune(input)
+	extra := []rune(alphabet)
+	if len(runes) == 0 {
+		if len(extra) == 0 {
+			return input
+		}
+		return string(extra[r.Intn(len(extra))])
+	}
+
+	i := r.Intn(len(runes))
+	op := r.Intn(3)
+	if len(extra) == 0 {
+		op = r.Intn(2)
+	}
+	switch op {
+	case 0: // delete
+		runes = append(runes[:i], runes[i+1:]...)
+	case 1: // duplicate
+		runes = append(runes[:i+1], runes[i:]...)
+	default: // replace
+		runes[i] = extra[r.Intn(len(extra))]
+	}
+	return string(runes)
+}
+
+func between(r *rand.Rand, atLeast, atMost int) int {
+	if atMost <= atLeast {
+		return atLeast
+	}
+	return atLeast + r.Intn(atMost-atLeast+1)
+}
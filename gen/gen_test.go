@@ -0,0 +1,84 @@
+package gen
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+)
+
+func TestSamplesAreParsable(t *testing.T) {
+	t.Parallel()
+
+	// numbers separated by commas: `1,22,333`
+	parser := pcb.Suffixed(pcb.Separated1(pcb.Digit1(), pcb.Char(','), false), pcb.EOF())
+	generator := SeparatedMN(RunesMN("0123456789", 1, 5), String(","), 1, 10, false)
+
+	for _, sample := range Samples(generator, 42, 100) {
+		if _, err := gomme.RunOnString(sample, parser); err != nil {
+			t.Errorf("sample %q should be parsable but got error: %v", sample, err)
+		}
+	}
+}
+
+func TestSamplesAreReproducible(t *testing.T) {
+	t.Parallel()
+
+	generator := Sequence(OneOf("a", "b"), Optional(String("c")), RunesMN("xyz", 0, 3))
+
+	got, want := Samples(generator, 7, 20), Samples(generator, 7, 20)
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("sample %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLazyLimitsDepth(t *testing.T) {
+	t.Parallel()
+
+	var list Generator
+	list = Lazy(func() Generator {
+		return Sequence(String("("), SeparatedMN(list, String(" "), 1, 3, false), String(")"))
+	}, String("x"), 5)
+
+	for _, sample := range Samples(list, 1, 50) {
+		if depth := strings.Count(sample, "(") - strings.Count(sample, ")"); depth != 0 {
+			t.Errorf("sample %q has unbalanced parentheses", sample)
+		}
+		if maxDepth(sample) > 5 {
+			t.Errorf("sample %q is nested too deeply", sample)
+		}
+	}
+}
+
+func TestMutate(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 50; i++ {
+		got := Mutate(r, "abc", "x")
+		if got == "abc" {
+			t.Errorf("got %q, want a mutated input", got)
+		}
+		if n := len([]rune(got)); n < 2 || n > 4 {
+			t.Errorf("got %q, want a single rune to be changed", got)
+		}
+	}
+}
+
+func maxDepth(s string) int {
+	depth, result := 0, 0
+	for _, r := range s {
+		switch r {
+		case '(':
+			depth++
+			result = max(result, depth)
+		case ')':
+			depth--
+		}
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+// Package gommetest contains helpers for testing grammars built with gomme.
+package gommetest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oleiade/gomme"
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update-golden", false, "update the golden files of gommetest.Golden")
+
+// RenderDiagnostics parses all inputs with the parser and renders the full
+// diagnostics (messages, source excerpts and markers) for each of them.
+// The inputs are rendered in the given order.
+func RenderDiagnostics[Output any](parse gomme.Parser[Output], inputs ...string) string {
+	sb := strings.Builder{}
+	for _, input := range inputs {
+		sb.WriteString("=== input\n")
+		sb.WriteString(input)
+		sb.WriteString("\n--- diagnostics\n")
+		if _, err := gomme.ParseAll(parse, input); err != nil {
+			sb.WriteString(err.Error())
+		} else {
+			sb.WriteString("no errors")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Golden renders the diagnostics for all inputs and compares them with the
+// golden file `testdata/<name>.golden`.
+// So changes to error messages and recovery are visible in reviews.
+//
+// Run the tests with `-update-golden` to create or update the golden files.
+func Golden[Output any](t testing.TB, name string, parse gomme.Parser[Output], inputs ...string) {
+	t.Helper()
+	checkGolden(t, filepath.Join("testdata", name+".golden"), RenderDiagnostics(parse, inputs...), *updateGolden)
+}
+
+func checkGolden(t testing.TB, path, got string, update bool) bool {
+	t.Helper()
+
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("unable to create directory for golden file: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("unable to write golden file: %v", err)
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read golden file (run with -update-golden to create it): %v", err)
+	}
+	return assert.Equal(t, string(want), got, "diagnostics differ from golden file %s", path)
+}
@@ -0,0 +1,61 @@
+package gommetest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oleiade/gomme/pcb"
+)
+
+func TestRenderDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	got := RenderDiagnostics(pcb.Digit1(), "123", "abc")
+
+	if !strings.HasPrefix(got, "=== input\n123\n--- diagnostics\nno errors\n=== input\nabc\n--- diagnostics\n") {
+		t.Errorf("got unexpected rendering:\n%s", got)
+	}
+	if !strings.Contains(got, "[1:1]") {
+		t.Errorf("got rendering without error position:\n%s", got)
+	}
+}
+
+func TestCheckGolden(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "testdata", "digits.golden")
+	got := RenderDiagnostics(pcb.Digit1(), "123", "abc")
+
+	if !checkGolden(t, path, got, true) {
+		t.Fatalf("updating golden file failed")
+	}
+	if !checkGolden(t, path, got, false) {
+		t.Errorf("unchanged diagnostics should match the golden file")
+	}
+
+	if err := os.WriteFile(path, []byte("something else"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTB{}
+	if checkGolden(fake, path, got, false) || !fake.failed {
+		t.Errorf("changed diagnostics should not match the golden file")
+	}
+}
+
+// fakeTB records failures instead of failing the real test.
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(string, ...interface{}) {
+	f.failed = true
+}
+
+func (f *fakeTB) Fatalf(string, ...interface{}) {
+	f.failed = true
+}
@@ -0,0 +1,95 @@
+package gommetest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/oleiade/gomme"
+)
+
+// Overlap describes two alternatives of a FirstSuccessful parser that can
+// start with the same input. So the earlier one might shadow the later one.
+type Overlap struct {
+	Earlier int // index of the alternative that wins
+	Later   int // index of the possibly shadowed alternative
+	// First is the set of bytes both alternatives can start with.
+	First gomme.ByteSet
+	// Empty is true if the earlier alternative accepts the empty input.
+	// So it might succeed for every input of the later one.
+	Empty bool
+	// Input is set if both alternatives are literals (see gomme.LiteralOf).
+	// Then the overlap is certain and Input is the literal of the later
+	// alternative that the earlier one matches, too.
+	Input string
+	// Prefix is true if the earlier literal is a real prefix of the later
+	// one. This is almost always a bug (e.g. "<" before "<=").
+	// If both literals are equal, the grammar is ambiguous.
+	Prefix bool
+}
+
+func (o Overlap) String() string {
+	switch {
+	case o.Input != "":
+		kind := "ambiguous with"
+		if o.Prefix {
+			kind = "a prefix of"
+		}
+		return fmt.Sprintf("alternative %d is %s alternative %d for input %q", o.Earlier, kind, o.Later, o.Input)
+	case o.Empty:
+		return fmt.Sprintf("alternative %d accepts empty input and might shadow alternative %d", o.Earlier, o.Later)
+	default:
+		return fmt.Sprintf("alternatives %d and %d can both start with %s", o.Earlier, o.Later, o.First)
+	}
+}
+
+// FindOverlaps compares the alternatives of a FirstSuccessful parser
+// pairwise with their static analysis (see gomme.Analyze) and reports the
+// pairs that can start with the same input.
+// Later alternatives that are only a prefix of an earlier literal are fine;
+// they are just less specific.
+//
+// The check never runs the parsers. It is exact for literal alternatives
+// (e.g. String("<=")). For other parsers it reports all possible overlaps.
+// Parsers without analysis (e.g. lazy parsers) can start with any byte.
+func FindOverlaps[Output any](alternatives ...gomme.Parser[Output]) []Overlap {
+	analyses := make([]gomme.Analysis, len(alternatives))
+	for i, alt := range alternatives {
+		analyses[i] = gomme.Analyze(alt)
+	}
+
+	var overlaps []Overlap
+	for i, earlier := range alternatives {
+		for j := i + 1; j < len(alternatives); j++ {
+			first := analyses[i].First.Intersection(analyses[j].First)
+			lit1, ok1 := gomme.LiteralOf(earlier)
+			lit2, ok2 := gomme.LiteralOf(alternatives[j])
+			switch {
+			case ok1 && ok2:
+				if strings.HasPrefix(lit2, lit1) {
+					overlaps = append(overlaps, Overlap{
+						Earlier: i, Later: j, First: first, Empty: lit1 == "", Input: lit2, Prefix: len(lit2) > len(lit1),
+					})
+				}
+			case analyses[i].Nullable:
+				overlaps = append(overlaps, Overlap{Earlier: i, Later: j, First: analyses[j].First, Empty: true})
+			case first.Count() > 0:
+				overlaps = append(overlaps, Overlap{Earlier: i, Later: j, First: first})
+			}
+		}
+	}
+	return overlaps
+}
+
+// CheckOverlaps reports all overlaps found by FindOverlaps as test errors.
+// Set `allowAmbiguity` to report only the shadowing by literal prefixes and
+// by alternatives accepting empty input.
+func CheckOverlaps[Output any](t testing.TB, allowAmbiguity bool, alternatives ...gomme.Parser[Output]) {
+	t.Helper()
+
+	for _, overlap := range FindOverlaps(alternatives...) {
+		if overlap.Prefix || overlap.Empty || !allowAmbiguity {
+			t.Errorf("%s", overlap)
+		}
+	}
+}
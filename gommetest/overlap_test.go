@@ -0,0 +1,39 @@
+package gommetest
+
+import (
+	"testing"
+
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindOverlaps(t *testing.T) {
+	t.Parallel()
+
+	var lt, eq gomme.ByteSet
+	lt.Add('<')
+	eq.Add('=')
+
+	got := FindOverlaps(pcb.String("<"), pcb.String("<="), pcb.String("="))
+	assert.Equal(t, []Overlap{{Earlier: 0, Later: 1, First: lt, Input: "<=", Prefix: true}}, got)
+
+	got = FindOverlaps(pcb.String("<="), pcb.String("<"), pcb.String("="))
+	assert.Empty(t, got)
+
+	got = FindOverlaps(pcb.String("="), pcb.OneOf("=", "x"))
+	assert.Equal(t, []Overlap{{Earlier: 0, Later: 1, First: eq}}, got)
+
+	got = FindOverlaps(pcb.Optional(pcb.String("=")), pcb.String("<"))
+	assert.Equal(t, []Overlap{{Earlier: 0, Later: 1, First: lt, Empty: true}}, got)
+}
+
+func TestCheckOverlaps(t *testing.T) {
+	t.Parallel()
+
+	CheckOverlaps(t, true, pcb.String("<="), pcb.String("="), pcb.OneOf("=", "x"))
+
+	fake := &fakeTB{}
+	CheckOverlaps(fake, true, pcb.String("<"), pcb.String("<="))
+	assert.True(t, fake.failed)
+}
@@ -0,0 +1,78 @@
+package gommetest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oleiade/gomme"
+	"github.com/stretchr/testify/assert"
+)
+
+// SaveSpotCheck tells RunTable how to check the SaveSpot mark.
+type SaveSpotCheck int
+
+const (
+	SaveSpotIgnored  SaveSpotCheck = iota // don't check the SaveSpot mark
+	SaveSpotMoved                         // the parser has to move the SaveSpot mark
+	SaveSpotNotMoved                      // the parser must not move the SaveSpot mark
+)
+
+// Case is a single test case for RunTable.
+// If WantErrSubstr is empty, the parser has to succeed.
+// Otherwise, it has to fail with an error containing WantErrSubstr
+// and WantOutput isn't checked.
+type Case[Output any] struct {
+	Name          string // defaults to the input
+	Input         string
+	WantOutput    Output
+	WantRemaining string
+	WantErrSubstr string
+	SaveSpot      SaveSpotCheck
+}
+
+// RunTable runs the parser for all cases as parallel sub-tests and checks
+// the output, the remaining input, the error and the SaveSpot mark.
+// The parser is applied directly without error recovery.
+// So for failing parsers the remaining input is usually the whole input.
+func RunTable[Output any](t *testing.T, parse gomme.Parser[Output], cases []Case[Output]) {
+	t.Helper()
+
+	for _, tc := range cases {
+		tc := tc
+		name := tc.Name
+		if name == "" {
+			name = tc.Input
+		}
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			state := gomme.NewFromString(tc.Input, false)
+			newState, gotOutput, err := parse.It(state)
+
+			switch {
+			case tc.WantErrSubstr == "" && err != nil:
+				t.Errorf("got error %v, want no error", err)
+			case tc.WantErrSubstr != "" && err == nil:
+				t.Errorf("got no error, want error containing %q", tc.WantErrSubstr)
+			case tc.WantErrSubstr != "" && !strings.Contains(err.Error(), tc.WantErrSubstr):
+				t.Errorf("got error %v, want error containing %q", err, tc.WantErrSubstr)
+			case tc.WantErrSubstr == "":
+				assert.Equal(t, tc.WantOutput, gotOutput,
+					"got output %v, want output %v", gotOutput, tc.WantOutput)
+			}
+
+			if remaining := newState.CurrentString(); remaining != tc.WantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remaining, tc.WantRemaining)
+			}
+
+			moved := state.SaveSpotMoved(newState)
+			switch {
+			case tc.SaveSpot == SaveSpotMoved && !moved:
+				t.Errorf("got SaveSpot mark unchanged, want it moved")
+			case tc.SaveSpot == SaveSpotNotMoved && moved:
+				t.Errorf("got SaveSpot mark moved, want it unchanged")
+			}
+		})
+	}
+}
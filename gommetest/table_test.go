@@ -0,0 +1,21 @@
+package gommetest
+
+import (
+	"testing"
+
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+)
+
+func TestRunTable(t *testing.T) {
+	t.Parallel()
+
+	RunTable(t, pcb.Digit1(), []Case[string]{
+		{Input: "123abc", WantOutput: "123", WantRemaining: "abc", SaveSpot: SaveSpotNotMoved},
+		{Name: "no digits", Input: "abc", WantRemaining: "abc", WantErrSubstr: "digit"},
+	})
+
+	RunTable(t, gomme.SaveSpot(pcb.Char('a')), []Case[rune]{
+		{Input: "ab", WantOutput: 'a', WantRemaining: "b", SaveSpot: SaveSpotMoved},
+	})
+}
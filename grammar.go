@@ -0,0 +1,87 @@
+package gomme
+
+import (
+	"iter"
+	"sync"
+)
+
+// ============================================================================
+// Grammar: Owner Of IDs And Caches
+//
+
+// Grammar owns the IDs of the parsers used with its states and the caches
+// used while parsing with them.
+// Nothing global refers to a Grammar. So constructing grammars per request
+// (e.g. in a server) doesn't leak cache key space or recoverer IDs:
+// a Grammar and everything inside it is dropped as a unit once it isn't
+// referenced anymore.
+// A Grammar is safe for concurrent use.
+type Grammar struct {
+	ids        *IDAllocator
+	middleware []Middleware // applied to all parsers running with states of the grammar
+	caches     sync.Pool    // of CacheBackend
+}
+
+// NewGrammar returns an empty Grammar.
+func NewGrammar(opts ...GrammarOption) *Grammar {
+	g := &Grammar{ids: NewIDAllocator()}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// IDs returns the IDAllocator of the grammar.
+func (g *Grammar) IDs() *IDAllocator {
+	return g.ids
+}
+
+// NewFromString creates a new parser state from the input data.
+// The state uses the IDs and global middleware of the grammar and caches
+// reused from earlier states given to Release.
+func (g *Grammar) NewFromString(input string, recover bool) State {
+	return g.prepare(NewFromString(input, recover))
+}
+
+// NewFromBytes creates a new parser state from the input data.
+// The state uses the IDs and global middleware of the grammar and caches
+// reused from earlier states given to Release.
+func (g *Grammar) NewFromBytes(input []byte, recover bool) State {
+	return g.prepare(NewFromBytes(input, recover))
+}
+
+// NewFromSeq creates a new parser state from the bytes of the sequence
+// (see NewFromSeq).
+// The state uses the IDs and global middleware of the grammar and caches
+// reused from earlier states given to Release.
+func (g *Grammar) NewFromSeq(seq iter.Seq[byte], recover bool) State {
+	return g.prepare(NewFromSeq(seq, recover))
+}
+
+// NewFromRuneSeq creates a new parser state from the runes of the sequence
+// (see NewFromRuneSeq).
+// The state uses the IDs and global middleware of the grammar and caches
+// reused from earlier states given to Release.
+func (g *Grammar) NewFromRuneSeq(seq iter.Seq[rune], recover bool) State {
+	return g.prepare(NewFromRuneSeq(seq, recover))
+}
+
+// Release hands the caches of the state back to the grammar.
+// The state and all states derived from it must not be used for parsing
+// anymore.
+func (g *Grammar) Release(state State) {
+	if state.cache == nil {
+		return
+	}
+	state.cache.Clear()
+	g.caches.Put(state.cache)
+}
+
+func (g *Grammar) prepare(state State) State {
+	state.ids = g.ids
+	state.middleware = g.middleware
+	if cache, ok := g.caches.Get().(CacheBackend); ok {
+		state.cache = cache
+	}
+	return state
+}
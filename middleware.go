@@ -0,0 +1,115 @@
+package gomme
+
+import "slices"
+
+// ============================================================================
+// Middleware For Parsers
+//
+
+// Middleware decorates the parse function of a parser with a cross-cutting
+// concern like tracing, metrics, depth counting or skipping whitespace.
+// `expected` is the expectation of the decorated parser.
+// `next` runs the rest of the middleware chain and finally the parser itself.
+// The output of the parser is passed on untouched.
+type Middleware func(expected string, state State, next func(State) (State, *ParserError)) (State, *ParserError)
+
+// Wrap returns a parser that runs the middleware around the parser.
+// The first middleware is the outermost one.
+// Expectation, SaveSpot flag, recoverer, literal and optimizer of the
+// parser are kept. They are looked up when needed. So unresolved
+// references (see Ref) and lazy parsers can be wrapped, too.
+// The analysis isn't kept because a middleware might change the accepted
+// input.
+// Optimize keeps the middleware around the optimized parser. But a wrapped
+// literal that is fused with other literals isn't run on its own anymore.
+func Wrap[Output any](parse Parser[Output], mws ...Middleware) Parser[Output] {
+	if len(mws) == 0 {
+		return parse
+	}
+	return &wrapprsr[Output]{inner: parse, mws: slices.Clone(mws)}
+}
+
+type wrapprsr[Output any] struct {
+	inner Parser[Output]
+	mws   []Middleware
+}
+
+func (wp *wrapprsr[Output]) Expected() string {
+	return wp.inner.Expected()
+}
+
+func (wp *wrapprsr[Output]) It(state State) (State, Output, *ParserError) {
+	return runMiddleware(wp.mws, wp.inner.Expected(), wp.inner.It, state)
+}
+
+func (wp *wrapprsr[Output]) IsSaveSpot() bool {
+	return wp.inner.IsSaveSpot()
+}
+
+func (wp *wrapprsr[Output]) setSaveSpot() {
+	wp.inner.setSaveSpot()
+}
+
+func (wp *wrapprsr[Output]) Recover(state State) int {
+	return wp.inner.Recover(state)
+}
+
+func (wp *wrapprsr[Output]) SwapRecoverer(newRecoverer Recoverer) Parser[Output] {
+	return &wrapprsr[Output]{inner: wp.inner.SwapRecoverer(newRecoverer), mws: wp.mws}
+}
+
+func (wp *wrapprsr[Output]) WithExpected(expected string) Parser[Output] {
+	return &wrapprsr[Output]{inner: wp.inner.WithExpected(expected), mws: wp.mws}
+}
+
+func (wp *wrapprsr[Output]) optimized() Parser[Output] {
+	return &wrapprsr[Output]{inner: Optimize(wp.inner), mws: wp.mws}
+}
+
+func (wp *wrapprsr[Output]) literal() (string, bool) {
+	return LiteralOf(wp.inner)
+}
+
+// runMiddleware runs the middleware around the parse function.
+// Nothing is built in advance. Every middleware just gets a small function
+// that passes the output of the rest of the chain through.
+func runMiddleware[Output any](
+	mws []Middleware, expected string, parse func(State) (State, Output, *ParserError), state State,
+) (State, Output, *ParserError) {
+	if len(mws) == 0 {
+		return parse(state)
+	}
+	var output Output
+	newState, err := mws[0](expected, state, func(st State) (State, *ParserError) {
+		newSt, out, pErr := runMiddleware(mws[1:], expected, parse, st)
+		output = out
+		return newSt, pErr
+	})
+	return newState, output, err
+}
+
+// GrammarOption configures a Grammar.
+type GrammarOption func(*Grammar)
+
+// WithGlobalMiddleware applies the middleware to every parser that runs
+// with a state of the grammar (see Grammar.NewFromString).
+// Parsers running with other states aren't affected. So the same parsers
+// can be used with and without the middleware.
+func WithGlobalMiddleware(mws ...Middleware) GrammarOption {
+	return func(g *Grammar) {
+		g.middleware = append(g.middleware, mws...)
+	}
+}
+
+// DebugTrace is a Middleware that logs the start and result of every
+// parser on the debug level (see SetDebug).
+func DebugTrace(expected string, state State, next func(State) (State, *ParserError)) (State, *ParserError) {
+	Debugf("%s - start: pos=%d", expected, state.CurrentPos())
+	newState, err := next(state)
+	if err != nil {
+		Debugf("%s - failure: pos=%d, error=%q", expected, err.Pos(), err.Text())
+	} else {
+		Debugf("%s - success: pos=%d", expected, newState.CurrentPos())
+	}
+	return newState, err
+}
@@ -0,0 +1,84 @@
+package gomme_test
+
+import (
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	record := func(name string) gomme.Middleware {
+		return func(expected string, state gomme.State, next func(gomme.State) (gomme.State, *gomme.ParserError)) (gomme.State, *gomme.ParserError) {
+			calls = append(calls, name+" "+expected)
+			return next(state)
+		}
+	}
+	skipSpace := func(_ string, state gomme.State, next func(gomme.State) (gomme.State, *gomme.ParserError)) (gomme.State, *gomme.ParserError) {
+		input := state.CurrentString()
+		return next(state.MoveBy(len(input) - len(strings.TrimLeft(input, " "))))
+	}
+
+	parser := gomme.Wrap(pcb.String("abc"), record("outer"), skipSpace, record("inner"))
+	output, err := gomme.RunOnString("  abc", parser)
+	if err != nil || output != "abc" {
+		t.Errorf("got (%q, %v), want (%q, nil)", output, err, "abc")
+	}
+	if want := []string{`outer "abc"`, `inner "abc"`}; strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("got calls %q, want %q", calls, want)
+	}
+}
+
+func TestWithGlobalMiddleware(t *testing.T) {
+	t.Parallel()
+
+	count := 0
+	counter := func(_ string, state gomme.State, next func(gomme.State) (gomme.State, *gomme.ParserError)) (gomme.State, *gomme.ParserError) {
+		count++
+		return next(state)
+	}
+
+	g := gomme.NewGrammar(gomme.WithGlobalMiddleware(counter))
+	parser := pcb.Sequence(pcb.String("a"), pcb.String("b"))
+	newState, _ := gomme.RunOnState(g.NewFromString("ab", false), parser)
+	if newState.HasError() {
+		t.Fatalf("got unexpected error: %v", newState.Errors())
+	}
+	if count < 3 {
+		t.Errorf("got %d middleware calls, want one per parser", count)
+	}
+
+	count = 0
+	if _, err := gomme.RunOnString("ab", parser); err != nil || count != 0 {
+		t.Errorf("got (%d calls, %v) outside of the grammar, want (0, nil)", count, err)
+	}
+}
+
+func TestWrapLazy(t *testing.T) {
+	t.Parallel()
+
+	r := gomme.NewRegistry()
+	calls := 0
+	counter := func(_ string, state gomme.State, next func(gomme.State) (gomme.State, *gomme.ParserError)) (gomme.State, *gomme.ParserError) {
+		calls++
+		return next(state)
+	}
+	parser := gomme.Wrap(gomme.Ref[string](r, "word"), counter) // must not resolve the reference
+	gomme.Register(r, "word", pcb.String("abc"))
+	if err := r.Build(); err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+
+	if parser.Expected() != `"abc"` {
+		t.Errorf("got expectation %q, want %q", parser.Expected(), `"abc"`)
+	}
+	if output, err := gomme.RunOnString("abc", parser); err != nil || output != "abc" || calls != 1 {
+		t.Errorf("got (%q, %v, %d calls), want (%q, nil, 1 call)", output, err, calls, "abc")
+	}
+	if lit, ok := gomme.LiteralOf(gomme.Wrap(pcb.String("abc"), counter)); !ok || lit != "abc" {
+		t.Errorf("got literal (%q, %t), want (%q, true)", lit, ok, "abc")
+	}
+}
@@ -0,0 +1,79 @@
+package gomme
+
+// ============================================================================
+// Optimization Of Parser Trees
+//
+
+// optimizable is implemented by parsers that know how to optimize themselves.
+type optimizable[Output any] interface {
+	optimized() Parser[Output]
+	literal() (string, bool)
+}
+
+// Optimize returns an optimized but equivalent version of the parser.
+// Currently runs of adjacent literal parsers (e.g. Char and String) inside
+// of a Sequence are matched at once. A Sequence of literal parsers only is
+// fused into a single multi-byte match.
+// Adjacent SatisfyMN parsers aren't merged because their outputs have to
+// stay separate.
+//
+// Optimize should be called once after the whole grammar has been
+// constructed and before parsing starts.
+// Parsers that don't know how to optimize themselves (e.g. lazy parsers)
+// are returned unchanged. So recursive grammars are optimized
+// only up to their first LazyParser.
+func Optimize[Output any](parse Parser[Output]) Parser[Output] {
+	if o, ok := parse.(optimizable[Output]); ok {
+		return o.optimized()
+	}
+	return parse
+}
+
+// WithOptimizer returns a copy of the parser that uses `optimize` to
+// create its optimized version in Optimize.
+// Combinators should optimize their sub-parsers and construct themselves anew.
+// Parsers not created by NewParser (e.g. lazy parsers) are returned unchanged.
+func WithOptimizer[Output any](parse Parser[Output], optimize func() Parser[Output]) Parser[Output] {
+	p, ok := parse.(prsr[Output])
+	if !ok {
+		return parse
+	}
+	p.optimizer = optimize
+	return p
+}
+
+// WithLiteral returns a copy of the parser that is marked to match exactly
+// the literal text `lit` and to always produce the same output for it.
+// Optimize uses this information for fusing literal parsers.
+// Parsers not created by NewParser (e.g. lazy parsers) are returned unchanged.
+func WithLiteral[Output any](parse Parser[Output], lit string) Parser[Output] {
+	p, ok := parse.(prsr[Output])
+	if !ok {
+		return parse
+	}
+	p.lit = &lit
+	return p
+}
+
+// LiteralOf returns the literal text the parser matches exactly.
+// The second return value is false if the parser isn't a literal parser.
+func LiteralOf[Output any](parse Parser[Output]) (string, bool) {
+	if o, ok := parse.(optimizable[Output]); ok {
+		return o.literal()
+	}
+	return "", false
+}
+
+func (p prsr[Output]) optimized() Parser[Output] {
+	if p.optimizer == nil {
+		return p
+	}
+	return p.optimizer()
+}
+
+func (p prsr[Output]) literal() (string, bool) {
+	if p.lit == nil {
+		return "", false
+	}
+	return *p.lit, true
+}
@@ -121,12 +121,12 @@ func (o *orchestrator[Output]) parseAll(state State) (State, Output, error) {
 		if result.Error != nil {
 			nState = result.State.SaveError(result.Error)
 			if nState.AtEnd() { // give up
-				return nState, zero, nState.Errors()
+				return nState, zero, nState.Err()
 			}
 			result.State = nState
 			nState, nextID = o.handleError(result)
 			if nextID < 0 { // give up
-				return nState, zero, nState.Errors()
+				return nState, zero, nState.Err()
 			}
 			p = o.parsers[nextID]
 			result = p.parser.Parse(nState, o)
@@ -140,7 +140,7 @@ func (o *orchestrator[Output]) parseAll(state State) (State, Output, error) {
 		}
 	}
 	output, _ := o.GetOutput(id, state.CurrentPos())
-	return result.State, output.(Output), result.State.Errors()
+	return result.State, output.(Output), result.State.Err()
 }
 func (o *orchestrator[Output]) handleError(r ParseResult) (state State, nextID int32) {
 	pos := r.State.CurrentPos()
@@ -160,6 +160,7 @@ func (o *orchestrator[Output]) handleError(r ParseResult) (state State, nextID i
 		return state, -1
 	}
 	Debugf("handleError - best recoverer: ID=%d, waste=%d", minRec.ID(), minWaste)
+	r.State.recordRecovery(RecoveryJump, pos, minWaste)
 	return r.State.MoveBy(minWaste), minRec.ID()
 }
 func (o *orchestrator[Output]) findMinWaste(state State, id int32) (minWaste int, minRec AnyParser) {
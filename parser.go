@@ -54,7 +54,7 @@ func saveSpotHappy[Output any](id uint64, parse Parser[Output], state State) (St
 	newState, output, err := parse.It(state)
 	if err == nil {
 		if newState.errHand.witnessID > 0 { // we just successfully handled an error :)
-			newState.errHand = errHand{}
+			newState = newState.commitRecovery()
 		}
 		newState.saveSpot = newState.input.pos // move the mark!
 		return newState.ClearAllCaches(), output
@@ -79,6 +79,7 @@ func saveSpotEscape[Output any](id uint64, parse Parser[Output], state State) (S
 	if waste < 0 {
 		return state.MoveBy(state.BytesRemaining()), ZeroOf[Output]() // give up
 	}
+	state.recordRecovery(RecoveryJump, state.input.pos, waste)
 	newState := state.MoveBy(waste)
 	newState.errHand = errHand{}
 	newState.mode = ParsingModeHappy
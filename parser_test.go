@@ -140,3 +140,63 @@ func BenchmarkSaveSpot(b *testing.B) {
 		_, _ = p.It(input)
 	}
 }
+
+func TestParseAll(t *testing.T) {
+	t.Parallel()
+
+	p := pcb.Digit1()
+
+	output, err := gomme.Parse(p, "123abc")
+	if err != nil {
+		t.Errorf("Parse: got unexpected error: %v", err)
+	}
+	if output != "123" {
+		t.Errorf("Parse: got output %q, want %q", output, "123")
+	}
+
+	output, err = gomme.ParseAll(p, "123")
+	if err != nil {
+		t.Errorf("ParseAll: got unexpected error: %v", err)
+	}
+	if output != "123" {
+		t.Errorf("ParseAll: got output %q, want %q", output, "123")
+	}
+
+	_, err = gomme.ParseAll(p, "123abc")
+	if err == nil {
+		t.Errorf("ParseAll: got no error for remaining input")
+	} else if !strings.Contains(err.Error(), "expected end of the input") {
+		t.Errorf("ParseAll: got error %q, want it to contain %q", err, "expected end of the input")
+	}
+}
+
+func TestItems(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	for item, err := range gomme.Items(pcb.Alpha1(), pcb.Char(','), "a,bc,def,") {
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		got = append(got, item)
+	}
+	if strings.Join(got, " ") != "a bc def" {
+		t.Errorf("got items %q, want %q", got, []string{"a", "bc", "def"})
+	}
+
+	got = got[:0]
+	var gotErr error
+	for item, err := range gomme.Items(pcb.Alpha1(), pcb.Char(','), "a,1") {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		got = append(got, item)
+	}
+	if gotErr == nil {
+		t.Errorf("got no error for invalid item")
+	}
+	if len(got) != 1 {
+		t.Errorf("got %d items before the error, want 1", len(got))
+	}
+}
@@ -0,0 +1,270 @@
+package pcb
+
+import "github.com/oleiade/gomme"
+
+// ANSIKind is the kind of an ANSI/VT control sequence.
+type ANSIKind uint8
+
+const (
+	ANSIEscape        ANSIKind = iota // ESC, optional intermediate bytes and final byte (e.g. ESC c)
+	ANSICSI                           // control sequence (e.g. ESC [ 1 ; 31 m)
+	ANSIOSC                           // operating system command (e.g. ESC ] 0 ; title BEL)
+	ANSIControlString                 // DCS, SOS, PM or APC string (e.g. ESC P ... ESC \)
+)
+
+// ANSISequence is an ANSI/VT control sequence (ECMA-48).
+type ANSISequence struct {
+	Kind         ANSIKind
+	Private      byte   // private marker of a CSI ('<', '=', '>' or '?'; 0 if none)
+	Params       []int  // numeric parameters of a CSI (-1 for omitted ones)
+	Intermediate string // intermediate bytes of an escape sequence or CSI
+	Final        byte   // final byte; introducer ('P', 'X', '^' or '_') of a control string
+	Data         string // payload of an OSC or control string
+}
+
+// IsSGR reports whether the sequence sets graphic rendition
+// (colors, bold, ...).
+func (seq ANSISequence) IsSGR() bool {
+	return seq.Kind == ANSICSI && seq.Final == 'm' && seq.Private == 0 && seq.Intermediate == ""
+}
+
+// Param returns the parameter with index `i` or `def` if it is omitted.
+func (seq ANSISequence) Param(i, def int) int {
+	if i >= len(seq.Params) || seq.Params[i] < 0 {
+		return def
+	}
+	return seq.Params[i]
+}
+
+// SGRAttributes returns the attributes of an SGR sequence.
+// Omitted parameters are 0 (reset) and extended colors are kept together
+// (e.g. [38 5 196] or [48 2 255 0 0]).
+func (seq ANSISequence) SGRAttributes() [][]int {
+	if len(seq.Params) == 0 {
+		return [][]int{{0}}
+	}
+	attrs := make([][]int, 0, len(seq.Params))
+	for i := 0; i < len(seq.Params); i++ {
+		p := seq.Param(i, 0)
+		n := 1
+		if (p == 38 || p == 48 || p == 58) && i+1 < len(seq.Params) {
+			switch seq.Param(i+1, 0) {
+			case 5:
+				n = 3
+			case 2:
+				n = 5
+			}
+		}
+		n = min(n, len(seq.Params)-i)
+		attr := make([]int, n)
+		for j := range attr {
+			attr[j] = seq.Param(i+j, 0)
+		}
+		attrs = append(attrs, attr)
+		i += n - 1
+	}
+	return attrs
+}
+
+// ANSISegment is a piece of terminal output: either text or a control
+// sequence.
+type ANSISegment struct {
+	Text string        // the text (empty for a sequence)
+	Seq  *ANSISequence // the sequence (nil for text)
+}
+
+// ANSI parses one ANSI/VT control sequence starting with ESC.
+// If `c1` is true, the 8-bit C1 introducers (e.g. 0x9b for CSI) and
+// terminator (0x9c) are recognized, too. This only makes sense for binary
+// input because these bytes are part of UTF-8 characters.
+func ANSI(c1 bool) gomme.Parser[ANSISequence] {
+	expected := "ANSI escape sequence"
+
+	ansiParse := func(state gomme.State) (gomme.State, ANSISequence, *gomme.ParserError) {
+		var seq ANSISequence
+		var n, errPos int
+		var errMsg string
+		if state.IsBinary() { // don't convert the whole input for every sequence
+			seq, n, errMsg, errPos = scanANSI(state.CurrentBytes(), c1)
+		} else {
+			seq, n, errMsg, errPos = scanANSI(state.CurrentString(), c1)
+		}
+		if errMsg != "" {
+			errState := state.MoveBy(errPos).NewError(errMsg)
+			return state.Preserve(errState), ANSISequence{}, errState.CurrentError()
+		}
+		return state.MoveBy(n), seq, nil
+	}
+	return gomme.NewParser[ANSISequence](expected, ansiParse, func(state gomme.State) int {
+		if state.IsBinary() {
+			return ansiIndex(state.CurrentBytes(), c1)
+		}
+		return ansiIndex(state.CurrentString(), c1)
+	})
+}
+
+// ANSISegments splits the whole input into text and control sequences
+// (see ANSI). So terminal output can be processed without regular
+// expressions.
+// A malformed sequence is reported as semantic error and kept as text.
+func ANSISegments(c1 bool) gomme.Parser[[]ANSISegment] {
+	expected := "terminal output"
+
+	segmentsParse := func(state gomme.State) (gomme.State, []ANSISegment, *gomme.ParserError) {
+		var segments []ANSISegment
+		text := []byte(nil)
+		input := state.CurrentBytes() // fetch once; it's a copy for text input
+		for len(input) > 0 {
+			i := ansiIndex(input, c1)
+			if i != 0 {
+				if i < 0 {
+					i = len(input)
+				}
+				text = append(text, input[:i]...)
+				state, input = state.MoveBy(i), input[i:]
+				continue
+			}
+
+			seq, n, errMsg, errPos := scanANSI(input, c1)
+			if errMsg != "" {
+				n = max(errPos, 1)
+				text = append(text, input[:n]...)
+				state = state.MoveBy(errPos).NewSemanticError("expected " + errMsg).MoveBy(n - errPos)
+				input = input[n:]
+				continue
+			}
+			if len(text) > 0 {
+				segments = append(segments, ANSISegment{Text: string(text)})
+				text = text[:0]
+			}
+			segments = append(segments, ANSISegment{Seq: &seq})
+			state, input = state.MoveBy(n), input[n:]
+		}
+		if len(text) > 0 {
+			segments = append(segments, ANSISegment{Text: string(text)})
+		}
+		return state, segments, nil
+	}
+	return gomme.NewParser[[]ANSISegment](expected, segmentsParse, Forbidden("ANSISegments"))
+}
+
+const (
+	asciiESC = 0x1b
+	asciiBEL = 0x07
+)
+
+// ansiIndex returns the index of the first introducer of a sequence or -1.
+func ansiIndex[Input string | []byte](input Input, c1 bool) int {
+	for i := 0; i < len(input); i++ {
+		if b := input[i]; b == asciiESC || (c1 && isC1Introducer(b)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isC1Introducer reports whether the byte is the 8-bit form of
+// CSI, OSC, DCS, SOS, PM or APC.
+func isC1Introducer(b byte) bool {
+	return b == 0x9b || b == 0x9d || b == 0x90 || b == 0x98 || b == 0x9e || b == 0x9f
+}
+
+// scanANSI scans a control sequence at the start of the input.
+// It returns the sequence and its length or the expectation and the
+// position of an error.
+func scanANSI[Input string | []byte](input Input, c1 bool) (seq ANSISequence, n int, errMsg string, errPos int) {
+	if len(input) == 0 {
+		return seq, 0, "ANSI escape sequence", 0
+	}
+	intro, n := byte(0), 1
+	switch {
+	case input[0] == asciiESC:
+		if len(input) < 2 {
+			return seq, 0, "ANSI escape sequence", 1
+		}
+		if b := input[1]; b == '[' || b == ']' || b == 'P' || b == 'X' || b == '^' || b == '_' {
+			intro, n = b, 2
+		}
+	case c1 && isC1Introducer(input[0]):
+		intro = input[0] - 0x40 // 0x9b -> '[' and so on
+	default:
+		return seq, 0, "ANSI escape sequence", 0
+	}
+
+	switch intro {
+	case 0: // ESC, intermediate bytes, final byte
+		seq.Kind = ANSIEscape
+		start := n
+		for n < len(input) && input[n] >= 0x20 && input[n] <= 0x2f {
+			n++
+		}
+		if n >= len(input) || input[n] < 0x30 || input[n] > 0x7e {
+			return ANSISequence{}, 0, "final byte of escape sequence", n
+		}
+		seq.Intermediate, seq.Final = string(input[start:n]), input[n]
+		return seq, n + 1, "", 0
+	case '[':
+		return scanCSI(input, n)
+	case ']', 'P', 'X', '^', '_':
+		seq.Kind = ANSIControlString
+		if intro == ']' {
+			seq.Kind = ANSIOSC
+		} else {
+			seq.Final = intro
+		}
+		start := n
+		for ; n < len(input); n++ {
+			switch {
+			case input[n] == asciiBEL && intro == ']':
+				seq.Data = string(input[start:n])
+				return seq, n + 1, "", 0
+			case input[n] == asciiESC && n+1 < len(input) && input[n+1] == '\\':
+				seq.Data = string(input[start:n])
+				return seq, n + 2, "", 0
+			case input[n] == 0x9c && c1:
+				seq.Data = string(input[start:n])
+				return seq, n + 1, "", 0
+			}
+		}
+		return ANSISequence{}, 0, "string terminator", n
+	}
+	return ANSISequence{}, 0, "ANSI escape sequence", 0 // can never happen
+}
+
+// scanCSI scans the parameters, intermediate bytes and the final byte of
+// a control sequence starting at `n`.
+func scanCSI[Input string | []byte](input Input, n int) (seq ANSISequence, _ int, errMsg string, errPos int) {
+	seq.Kind = ANSICSI
+	if n < len(input) && input[n] >= '<' && input[n] <= '?' {
+		seq.Private = input[n]
+		n++
+	}
+	param, hasParams := -1, false
+	for ; n < len(input) && input[n] >= 0x30 && input[n] <= 0x3f; n++ {
+		hasParams = true
+		switch b := input[n]; {
+		case b >= '0' && b <= '9':
+			if param < 0 {
+				param = 0
+			}
+			if param < 1<<20 { // avoid overflow
+				param = param*10 + int(b-'0')
+			}
+		case b == ';' || b == ':':
+			seq.Params = append(seq.Params, param)
+			param = -1
+		}
+	}
+	if hasParams {
+		seq.Params = append(seq.Params, param)
+	}
+	start := n
+	for n < len(input) && input[n] >= 0x20 && input[n] <= 0x2f {
+		n++
+	}
+	if n >= len(input) || input[n] < 0x40 || input[n] > 0x7e {
+		return ANSISequence{}, 0, "final byte of control sequence", n
+	}
+	seq.Intermediate, seq.Final = string(input[start:n]), input[n]
+	return seq, n + 1, "", 0
+}
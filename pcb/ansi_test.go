@@ -0,0 +1,115 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestANSI(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		c1            bool
+		input         string
+		wantErr       string
+		wantOutput    ANSISequence
+		wantRemaining string
+	}{
+		{
+			name:          "parsing SGR sequence should succeed",
+			input:         "\x1b[1;31mred",
+			wantOutput:    ANSISequence{Kind: ANSICSI, Params: []int{1, 31}, Final: 'm'},
+			wantRemaining: "red",
+		}, {
+			name:       "parsing private CSI should succeed",
+			input:      "\x1b[?25h",
+			wantOutput: ANSISequence{Kind: ANSICSI, Private: '?', Params: []int{25}, Final: 'h'},
+		}, {
+			name:       "omitted parameters should be -1",
+			input:      "\x1b[;5H",
+			wantOutput: ANSISequence{Kind: ANSICSI, Params: []int{-1, 5}, Final: 'H'},
+		}, {
+			name:          "parsing OSC terminated by BEL should succeed",
+			input:         "\x1b]0;title\x07x",
+			wantOutput:    ANSISequence{Kind: ANSIOSC, Data: "0;title"},
+			wantRemaining: "x",
+		}, {
+			name:       "parsing DCS terminated by ST should succeed",
+			input:      "\x1bP1$r\x1b\\",
+			wantOutput: ANSISequence{Kind: ANSIControlString, Final: 'P', Data: "1$r"},
+		}, {
+			name:       "parsing escape sequence should succeed",
+			input:      "\x1b(B",
+			wantOutput: ANSISequence{Kind: ANSIEscape, Intermediate: "(", Final: 'B'},
+		}, {
+			name:       "parsing 8-bit CSI should succeed",
+			c1:         true,
+			input:      "\x9b1m",
+			wantOutput: ANSISequence{Kind: ANSICSI, Params: []int{1}, Final: 'm'},
+		}, {
+			name:          "8-bit CSI should fail without c1",
+			input:         "\x9b1m",
+			wantErr:       "expected ANSI escape sequence [1:1]",
+			wantRemaining: "\x9b1m",
+		}, {
+			name:          "unterminated CSI should fail",
+			input:         "\x1b[1",
+			wantErr:       "expected final byte of control sequence [1:4]",
+			wantRemaining: "\x1b[1",
+		}, {
+			name:          "unterminated OSC should fail",
+			input:         "\x1b]0;title",
+			wantErr:       "expected string terminator [1:10]",
+			wantRemaining: "\x1b]0;title",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), ANSI(tc.c1))
+			if newState.HasError() != (tc.wantErr != "") {
+				t.Errorf("got error %v, want error %q", newState.Errors(), tc.wantErr)
+			}
+			if tc.wantErr != "" {
+				if msg := newState.Errors().Error(); !strings.Contains(msg, tc.wantErr) {
+					t.Errorf("got error %q, want error %q", msg, tc.wantErr)
+				}
+			}
+			if !reflect.DeepEqual(gotResult, tc.wantOutput) {
+				t.Errorf("got output %+v, want output %+v", gotResult, tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestANSISegments(t *testing.T) {
+	t.Parallel()
+
+	input := "a\x1b[38;5;196mred\x1b[0m b\x1b[1"
+	newState, output := gomme.RunOnState(gomme.NewFromString(input, false), ANSISegments(false))
+	if msg := newState.Errors().Error(); !strings.Contains(msg, "expected final byte of control sequence [1:25]") {
+		t.Errorf("got error %q, want error for the unterminated CSI", msg)
+	}
+	if len(output) != 5 || output[0].Text != "a" || output[2].Text != "red" || output[4].Text != " b\x1b[1" {
+		t.Fatalf("got segments %+v, want 5 segments", output)
+	}
+	if got := output[1].Seq.SGRAttributes(); !reflect.DeepEqual(got, [][]int{{38, 5, 196}}) {
+		t.Errorf("got attributes %v, want [[38 5 196]]", got)
+	}
+	if !output[3].Seq.IsSGR() || output[3].Seq.Param(0, 0) != 0 {
+		t.Errorf("got sequence %+v, want SGR reset", output[3].Seq)
+	}
+	if !newState.AtEnd() {
+		t.Errorf("got remaining %q, want all input consumed", newState.CurrentString())
+	}
+}
@@ -0,0 +1,346 @@
+package pcb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/oleiade/gomme"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// SkipBytes skips exactly `n` bytes of the input whatever they are.
+// It fails if there are less than `n` bytes left.
+func SkipBytes(n int) gomme.Parser[struct{}] {
+	if n < 0 {
+		panic("SkipBytes is unable to handle negative `n`")
+	}
+	expected := fmt.Sprintf("%d bytes", n)
+
+	parse := func(state gomme.State) (gomme.State, struct{}, *gomme.ParserError) {
+		if remaining := state.BytesRemaining(); remaining < n {
+			errState := state.NewError(fmt.Sprintf("%s (only %d bytes left)", expected, remaining))
+			return errState, struct{}{}, errState.CurrentError()
+		}
+		return state.MoveBy(n), struct{}{}, nil
+	}
+
+	return gomme.NewParser[struct{}](expected, parse, Forbidden("SkipBytes"))
+}
+
+// PadTo skips the padding bytes up to the next position in the input
+// that is a multiple of `alignment`. The content of the padding isn't checked.
+// It doesn't consume anything if the position is aligned already.
+// It fails if the input ends before the aligned position.
+//
+// Positions are counted from the start of the input.
+// So for typical binary records with 4 or 8 byte alignment
+// the input should start with the file or message.
+func PadTo(alignment int) gomme.Parser[struct{}] {
+	if alignment < 1 {
+		panic("PadTo is unable to handle `alignment` less than 1")
+	}
+	expected := fmt.Sprintf("padding to %d byte alignment", alignment)
+
+	parse := func(state gomme.State) (gomme.State, struct{}, *gomme.ParserError) {
+		n := (alignment - state.CurrentPos()%alignment) % alignment
+		if remaining := state.BytesRemaining(); remaining < n {
+			errState := state.NewError(fmt.Sprintf("%s (need %d bytes, only %d left)", expected, n, remaining))
+			return errState, struct{}{}, errState.CurrentError()
+		}
+		return state.MoveBy(n), struct{}{}, nil
+	}
+
+	return gomme.NewParser[struct{}](expected, parse, Forbidden("PadTo"))
+}
+
+// AtOffset temporarily jumps to the absolute `offset` in the input,
+// applies the provided parser there and returns its output.
+// Afterwards the position is restored. So AtOffset doesn't consume any input.
+// This is needed for formats with pointers to other parts of the file
+// (e.g. ZIP, ELF or TIFF).
+// Use Switch to jump to an offset that has been parsed before.
+//
+// It fails if `offset` is beyond the end of the input or the parser fails.
+func AtOffset[Output any](offset int, parse gomme.Parser[Output]) gomme.Parser[Output] {
+	if offset < 0 {
+		panic("AtOffset is unable to handle negative `offset`")
+	}
+	expected := fmt.Sprintf("%s at offset %d", parse.Expected(), offset)
+
+	atParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		if size := state.CurrentPos() + state.BytesRemaining(); offset > size {
+			errState := state.NewError(fmt.Sprintf("%s (input has only %d bytes)", expected, size))
+			return errState, gomme.ZeroOf[Output](), errState.CurrentError()
+		}
+
+		cp := state.Checkpoint()
+		newState, output, err := parse.It(state.MoveTo(offset))
+		if err != nil {
+			return state.Preserve(newState), gomme.ZeroOf[Output](), err
+		}
+		return newState.Restore(cp), output, nil
+	}
+
+	return gomme.NewParser[Output](expected, atParse, Forbidden("AtOffset"))
+}
+
+// BinaryInt parses a binary integer of the size of `N` (1, 2, 4 or 8 bytes).
+// The byte order is the default endianness of the state
+// (big endian if not set otherwise with WithEndianness).
+// This function panics if `N` doesn't have a fixed size (int or uint).
+func BinaryInt[N gomme.Integral]() gomme.Parser[N] {
+	size := binary.Size(N(0))
+	if size < 0 {
+		panic("BinaryInt is unable to handle integer types without fixed size (int, uint)")
+	}
+	expected := fmt.Sprintf("%d byte binary integer", size)
+
+	parse := func(state gomme.State) (gomme.State, N, *gomme.ParserError) {
+		buf := state.CurrentBytes()
+		if len(buf) < size {
+			errState := state.NewError(fmt.Sprintf("%s (only %d bytes left)", expected, len(buf)))
+			return errState, 0, errState.CurrentError()
+		}
+
+		var u uint64
+		order := state.Endianness().ByteOrder()
+		switch size {
+		case 1:
+			u = uint64(buf[0])
+		case 2:
+			u = uint64(order.Uint16(buf))
+		case 4:
+			u = uint64(order.Uint32(buf))
+		default:
+			u = order.Uint64(buf)
+		}
+		return state.MoveBy(size), N(u), nil
+	}
+
+	return gomme.NewParser[N](expected, parse, BasicRecovererFunc(parse))
+}
+
+// WithEndianness applies the provided parser with `endianness` as the
+// default byte order for binary numbers.
+// The byte order of the outer parsers is restored afterwards.
+// So formats that are uniformly little or big endian can set it once
+// at the top and mixed formats can override it in a sub-grammar.
+func WithEndianness[Output any](endianness gomme.Endianness, parse gomme.Parser[Output]) gomme.Parser[Output] {
+	endParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		newState, output, err := parse.It(state.WithEndianness(endianness))
+		return newState.WithEndianness(state.Endianness()), output, err
+	}
+
+	return gomme.NewParser[Output](parse.Expected(), endParse, parse.Recover)
+}
+
+// TextDecoder decodes bytes of a textual field into a Go string.
+// If the bytes are invalid, it returns the index of the first invalid
+// byte (or -1 if all bytes are valid).
+type TextDecoder func(data []byte) (text string, badIdx int)
+
+// DecodeASCII is a TextDecoder for 7 bit ASCII.
+func DecodeASCII(data []byte) (string, int) {
+	for i, b := range data {
+		if b >= utf8.RuneSelf {
+			return "", i
+		}
+	}
+	return string(data), -1
+}
+
+// DecodeLatin1 is a TextDecoder for ISO 8859-1.
+// All bytes are valid.
+func DecodeLatin1(data []byte) (string, int) {
+	sb := strings.Builder{}
+	sb.Grow(len(data))
+	for _, b := range data {
+		sb.WriteRune(rune(b))
+	}
+	return sb.String(), -1
+}
+
+// DecodeUTF8 is a TextDecoder for UTF-8.
+func DecodeUTF8(data []byte) (string, int) {
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size <= 1 {
+			return "", i
+		}
+		i += size
+	}
+	return string(data), -1
+}
+
+// CString parses a NUL terminated string of at most `maxLen` bytes
+// (not counting the NUL byte) and returns it without the NUL byte.
+// The bytes are returned unchanged; use Map for decoding if necessary.
+// If `maxLen` is 0, the length isn't limited.
+// It fails if the NUL byte can't be found.
+func CString(maxLen int) gomme.Parser[string] {
+	if maxLen < 0 {
+		panic("CString is unable to handle negative `maxLen`")
+	}
+	expected := "NUL terminated string"
+
+	parse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		buf := state.CurrentBytes()
+		limit := len(buf)
+		if maxLen > 0 {
+			limit = min(limit, maxLen+1)
+		}
+		i := bytes.IndexByte(buf[:limit], 0)
+		if i < 0 {
+			msg := fmt.Sprintf("%s (no NUL byte within %d bytes)", expected, maxLen)
+			if limit == len(buf) {
+				msg = expected + " (no NUL byte before EOF)"
+			}
+			errState := state.MoveBy(limit).NewError(msg)
+			return state.Preserve(errState), "", errState.CurrentError()
+		}
+		return state.MoveBy(i + 1), string(buf[:i]), nil
+	}
+
+	return gomme.NewParser[string](expected, parse, BasicRecovererFunc(parse))
+}
+
+// FixedString parses a textual field of exactly `n` bytes and decodes it
+// with `decode`. Trailing NUL bytes and spaces are trimmed from the result.
+// Errors point to the first byte that can't be decoded.
+func FixedString(n int, decode TextDecoder) gomme.Parser[string] {
+	if n < 0 {
+		panic("FixedString is unable to handle negative `n`")
+	}
+	expected := fmt.Sprintf("text field of %d bytes", n)
+
+	parse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		buf := state.CurrentBytes()
+		if len(buf) < n {
+			errState := state.NewError(fmt.Sprintf("%s (only %d bytes left)", expected, len(buf)))
+			return errState, "", errState.CurrentError()
+		}
+		field := bytes.TrimRight(buf[:n], "\x00 ")
+		text, badIdx := decode(field)
+		if badIdx >= 0 {
+			errState := state.MoveBy(badIdx).NewError(fmt.Sprintf("%s (invalid byte 0x%02x)", expected, field[badIdx]))
+			return state.Preserve(errState), "", errState.CurrentError()
+		}
+		return state.MoveBy(n), text, nil
+	}
+
+	return gomme.NewParser[string](expected, parse, BasicRecovererFunc(parse))
+}
+
+// Limited applies the provided parser to a view of the input that is
+// restricted to the next `n` bytes. So the parser sees the boundary as
+// the end of the input.
+// Afterwards parsing continues after the `n` bytes regardless of how much
+// input the parser consumed.
+// If `strict` is true, the parser has to consume all `n` bytes.
+// This is the core building block for nested containers.
+// Use Switch for a limit that has been parsed before.
+//
+// It fails if less than `n` bytes are left or the parser fails.
+func Limited[Output any](n int, parse gomme.Parser[Output], strict bool) gomme.Parser[Output] {
+	if n < 0 {
+		panic("Limited is unable to handle negative `n`")
+	}
+	expected := fmt.Sprintf("%s within %d bytes", parse.Expected(), n)
+
+	limParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		if remaining := state.BytesRemaining(); remaining < n {
+			errState := state.NewError(fmt.Sprintf("%s (only %d bytes left)", expected, remaining))
+			return errState, gomme.ZeroOf[Output](), errState.CurrentError()
+		}
+
+		newState, output, err := parse.It(state.Limit(n))
+		newState = newState.Unlimit(state)
+		if err != nil {
+			return state.Preserve(newState), gomme.ZeroOf[Output](), err
+		}
+		if left := n - state.ByteCount(newState); strict && left > 0 {
+			errState := newState.NewError(fmt.Sprintf("end of %s (still %d bytes left)", expected, left))
+			return state.Preserve(errState), gomme.ZeroOf[Output](), errState.CurrentError()
+		}
+		return newState.MoveTo(state.CurrentPos() + n), output, nil
+	}
+
+	return gomme.NewParser[Output](expected, limParse, BasicRecovererFunc(limParse))
+}
+
+// Decompressor wraps a reader of compressed data (e.g. with `zlib.NewReader`,
+// `gzip.NewReader` or `flate.NewReader`) so it returns decompressed data.
+type Decompressor func(compressed io.Reader) (io.Reader, error)
+
+// Decompressed decompresses the next `n` bytes of the input with `decompress`
+// and parses the decompressed bytes with the provided parser.
+// At most `maxSize` bytes are decompressed. Larger data fails the parser.
+// So a small compressed block can't exhaust the memory (decompression bomb).
+// The parser has to consume all decompressed bytes.
+// Parsing continues after the `n` compressed bytes.
+// Use Switch for a length that has been parsed before.
+//
+// Errors of the parser are reported at the start of the compressed block
+// with the offset inside of the decompressed data.
+// The parser doesn't recover from errors. It shares the configuration,
+// user values and step budget of the state (see gomme.State.Embed).
+func Decompressed[Output any](n, maxSize int, decompress Decompressor, parse gomme.Parser[Output]) gomme.Parser[Output] {
+	if n < 0 {
+		panic("Decompressed is unable to handle negative `n`")
+	}
+	if maxSize < 0 {
+		panic("Decompressed is unable to handle negative `maxSize`")
+	}
+	expected := fmt.Sprintf("%s compressed in %d bytes", parse.Expected(), n)
+
+	decParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		buf := state.CurrentBytes()
+		if len(buf) < n {
+			errState := state.NewError(fmt.Sprintf("%s (only %d bytes left)", expected, len(buf)))
+			return errState, gomme.ZeroOf[Output](), errState.CurrentError()
+		}
+
+		data, dErr := decompressAll(buf[:n], maxSize, decompress)
+		if dErr != nil {
+			errState := state.NewFailure(fmt.Sprintf("invalid compressed block: %v", dErr))
+			return state.Preserve(errState), gomme.ZeroOf[Output](), errState.CurrentError()
+		}
+
+		innerState, output, err := parse.It(state.Embed(gomme.NewFromBytes(data, false)))
+		if err == nil && !innerState.AtEnd() {
+			innerState = innerState.NewError("end of compressed block")
+			err = innerState.CurrentError()
+		}
+		atBlock := func(int) int { return 0 } // decompressed positions don't exist in the input
+		if err != nil {
+			errState := state.NewFailure(fmt.Sprintf(
+				"inside compressed block at offset %d: %s", err.Pos(), err.Text()))
+			return state.Unembed(innerState, atBlock).Preserve(errState), gomme.ZeroOf[Output](), errState.CurrentError()
+		}
+		return state.Unembed(innerState, atBlock).MoveBy(n), output, nil
+	}
+
+	// recovering would decompress at every position of the input
+	noRecovery := func(gomme.State) int { return -1 }
+	return gomme.NewParser[Output](expected, decParse, noRecovery)
+}
+
+func decompressAll(compressed []byte, maxSize int, decompress Decompressor) ([]byte, error) {
+	r, err := decompress(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	data, err := io.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSize {
+		return nil, fmt.Errorf("decompressed data is larger than %d bytes", maxSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,814 @@
+package pcb
+
+import (
+	"bytes"
+	"compress/zlib"
+	"github.com/oleiade/gomme"
+	"io"
+	"testing"
+)
+
+func TestSkipBytes(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[struct{}]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    struct{}
+		wantRemaining string
+	}{
+		{
+			name:  "skipping bytes should succeed",
+			input: "abcdef",
+			args: args{
+				parser: SkipBytes(4),
+			},
+			wantErr:       false,
+			wantOutput:    struct{}{},
+			wantRemaining: "ef",
+		},
+		{
+			name:  "skipping zero bytes should succeed",
+			input: "abc",
+			args: args{
+				parser: SkipBytes(0),
+			},
+			wantErr:       false,
+			wantOutput:    struct{}{},
+			wantRemaining: "abc",
+		},
+		{
+			name:  "skipping too many bytes should fail",
+			input: "abc",
+			args: args{
+				parser: SkipBytes(4),
+			},
+			wantErr:       true,
+			wantOutput:    struct{}{},
+			wantRemaining: "abc",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %v, want output %v", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkSkipBytes(b *testing.B) {
+	parser := SkipBytes(4)
+	input := gomme.NewFromString(1, nil, -1, "abcdef")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestPadTo(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[struct{}]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    struct{}
+		wantRemaining string
+	}{
+		{
+			name:  "padding after unaligned data should succeed",
+			input: "abc\x00xyz",
+			args: args{
+				parser: Prefixed(SkipBytes(3), PadTo(4)),
+			},
+			wantErr:       false,
+			wantOutput:    struct{}{},
+			wantRemaining: "xyz",
+		},
+		{
+			name:  "padding aligned position should consume nothing",
+			input: "abcdxyz",
+			args: args{
+				parser: Prefixed(SkipBytes(4), PadTo(4)),
+			},
+			wantErr:       false,
+			wantOutput:    struct{}{},
+			wantRemaining: "xyz",
+		},
+		{
+			name:  "padding at start should consume nothing",
+			input: "abc",
+			args: args{
+				parser: PadTo(8),
+			},
+			wantErr:       false,
+			wantOutput:    struct{}{},
+			wantRemaining: "abc",
+		},
+		{
+			name:  "padding beyond the end should fail",
+			input: "abcde",
+			args: args{
+				parser: Prefixed(SkipBytes(5), PadTo(8)),
+			},
+			wantErr:       true,
+			wantOutput:    struct{}{},
+			wantRemaining: "abcde",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %v, want output %v", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkPadTo(b *testing.B) {
+	parser := Prefixed(SkipBytes(3), PadTo(4))
+	input := gomme.NewFromString(1, nil, -1, "abc\x00")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestAtOffset(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "jumping forward should succeed",
+			input: "abc123",
+			args: args{
+				parser: AtOffset(3, Digit1()),
+			},
+			wantErr:       false,
+			wantOutput:    "123",
+			wantRemaining: "abc123",
+		},
+		{
+			name:  "jumping backward should succeed",
+			input: "abc123",
+			args: args{
+				parser: Prefixed(SkipBytes(4), AtOffset(0, Alpha1())),
+			},
+			wantErr:       false,
+			wantOutput:    "abc",
+			wantRemaining: "23",
+		},
+		{
+			name:  "jumping to the end should succeed",
+			input: "abc",
+			args: args{
+				parser: AtOffset(3, Alpha0()),
+			},
+			wantErr:       false,
+			wantOutput:    "",
+			wantRemaining: "abc",
+		},
+		{
+			name:  "jumping beyond the end should fail",
+			input: "abc",
+			args: args{
+				parser: AtOffset(4, Alpha0()),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "abc",
+		},
+		{
+			name:  "failing parser should fail",
+			input: "abc123",
+			args: args{
+				parser: AtOffset(1, Digit1()),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "abc123",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkAtOffset(b *testing.B) {
+	parser := AtOffset(3, Digit1())
+	input := gomme.NewFromString(1, nil, -1, "abc123")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestBinaryInt(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[uint32]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    uint32
+		wantRemaining string
+	}{
+		{
+			name:  "parsing big endian should succeed",
+			input: "\x01\x02\x03\x04x",
+			args: args{
+				parser: BinaryInt[uint32](),
+			},
+			wantErr:       false,
+			wantOutput:    0x01020304,
+			wantRemaining: "x",
+		},
+		{
+			name:  "parsing little endian should succeed",
+			input: "\x01\x02\x03\x04x",
+			args: args{
+				parser: WithEndianness(gomme.LittleEndian, BinaryInt[uint32]()),
+			},
+			wantErr:       false,
+			wantOutput:    0x04030201,
+			wantRemaining: "x",
+		},
+		{
+			name:  "restoring outer endianness should succeed",
+			input: "\x01\x02\x03\x04\x01\x02\x03\x04",
+			args: args{
+				parser: Map2(WithEndianness(gomme.LittleEndian, BinaryInt[uint32]()), BinaryInt[uint32](), func(le, be uint32) (uint32, error) { return be ^ le, nil }),
+			},
+			wantErr:       false,
+			wantOutput:    0x01020304 ^ 0x04030201,
+			wantRemaining: "",
+		},
+		{
+			name:  "parsing too few bytes should fail",
+			input: "\x01\x02\x03",
+			args: args{
+				parser: BinaryInt[uint32](),
+			},
+			wantErr:       true,
+			wantOutput:    0,
+			wantRemaining: "\x01\x02\x03",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %x, want output %x", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkBinaryInt(b *testing.B) {
+	parser := BinaryInt[uint32]()
+	input := gomme.NewFromString(1, nil, -1, "\x01\x02\x03\x04")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestBinaryIntSigned(t *testing.T) {
+	t.Parallel()
+
+	newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, "\xff\xfe"), BinaryInt[int16]())
+	if newState.HasError() {
+		t.Errorf("got error %v, want no error", newState.Errors())
+	}
+	if gotResult != -2 {
+		t.Errorf("got output %d, want output %d", gotResult, -2)
+	}
+}
+
+func TestBinarySaveSpot(t *testing.T) {
+	t.Parallel()
+
+	// SaveSpot calls the recoverers during construction and a Forbidden one panics
+	_ = gomme.SaveSpot(BinaryInt[uint16]())
+	_ = gomme.SaveSpot(FixedString(4, DecodeASCII))
+	_ = gomme.SaveSpot(Limited(2, BinaryInt[uint16](), true))
+	_ = gomme.SaveSpot(Decompressed(4, 16, nil, BinaryInt[uint16]()))
+	_ = gomme.SaveSpot(Include(GoString(), Digit1()))
+}
+
+func TestCString(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "parsing terminated string should succeed",
+			input: "abc\x00def",
+			args: args{
+				parser: CString(0),
+			},
+			wantErr:       false,
+			wantOutput:    "abc",
+			wantRemaining: "def",
+		},
+		{
+			name:  "parsing string of max length should succeed",
+			input: "abc\x00def",
+			args: args{
+				parser: CString(3),
+			},
+			wantErr:       false,
+			wantOutput:    "abc",
+			wantRemaining: "def",
+		},
+		{
+			name:  "parsing empty string should succeed",
+			input: "\x00def",
+			args: args{
+				parser: CString(3),
+			},
+			wantErr:       false,
+			wantOutput:    "",
+			wantRemaining: "def",
+		},
+		{
+			name:  "parsing too long string should fail",
+			input: "abcd\x00",
+			args: args{
+				parser: CString(3),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "abcd\x00",
+		},
+		{
+			name:  "parsing unterminated string should fail",
+			input: "abc",
+			args: args{
+				parser: CString(0),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "abc",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkCString(b *testing.B) {
+	parser := CString(16)
+	input := gomme.NewFromString(1, nil, -1, "hello world\x00")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestFixedString(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "parsing padded ASCII field should succeed",
+			input: "ab  \x00\x00rest",
+			args: args{
+				parser: FixedString(6, DecodeASCII),
+			},
+			wantErr:       false,
+			wantOutput:    "ab",
+			wantRemaining: "rest",
+		},
+		{
+			name:  "parsing Latin-1 field should succeed",
+			input: "\xe4bc",
+			args: args{
+				parser: FixedString(3, DecodeLatin1),
+			},
+			wantErr:       false,
+			wantOutput:    "äbc",
+			wantRemaining: "",
+		},
+		{
+			name:  "parsing UTF-8 field should succeed",
+			input: "ä \x00x",
+			args: args{
+				parser: FixedString(4, DecodeUTF8),
+			},
+			wantErr:       false,
+			wantOutput:    "ä",
+			wantRemaining: "x",
+		},
+		{
+			name:  "parsing invalid ASCII should fail",
+			input: "ab\xe4d",
+			args: args{
+				parser: FixedString(4, DecodeASCII),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "ab\xe4d",
+		},
+		{
+			name:  "parsing invalid UTF-8 should fail",
+			input: "a\xffb",
+			args: args{
+				parser: FixedString(3, DecodeUTF8),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "a\xffb",
+		},
+		{
+			name:  "parsing too short field should fail",
+			input: "abc",
+			args: args{
+				parser: FixedString(4, DecodeASCII),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "abc",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkFixedString(b *testing.B) {
+	parser := FixedString(8, DecodeASCII)
+	input := gomme.NewFromString(1, nil, -1, "hello\x00\x00\x00")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestLimited(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "parsing within the limit should succeed",
+			input: "abcdef",
+			args: args{
+				parser: Limited(3, Alpha1(), false),
+			},
+			wantErr:       false,
+			wantOutput:    "abc",
+			wantRemaining: "def",
+		},
+		{
+			name:  "skipping unconsumed bytes should succeed",
+			input: "ab12ef",
+			args: args{
+				parser: Limited(4, Alpha1(), false),
+			},
+			wantErr:       false,
+			wantOutput:    "ab",
+			wantRemaining: "ef",
+		},
+		{
+			name:  "seeing the limit as end of input should succeed",
+			input: "abcdef",
+			args: args{
+				parser: Limited(3, Suffixed(Alpha1(), EOF()), true),
+			},
+			wantErr:       false,
+			wantOutput:    "abc",
+			wantRemaining: "def",
+		},
+		{
+			name:  "not consuming everything in strict mode should fail",
+			input: "ab12ef",
+			args: args{
+				parser: Limited(4, Alpha1(), true),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "ab12ef",
+		},
+		{
+			name:  "failing parser should fail",
+			input: "12abc",
+			args: args{
+				parser: Limited(3, Alpha1(), false),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "12abc",
+		},
+		{
+			name:  "too short input should fail",
+			input: "ab",
+			args: args{
+				parser: Limited(3, Alpha1(), false),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "ab",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestLimitedView(t *testing.T) {
+	t.Parallel()
+
+	for _, state := range []gomme.State{
+		gomme.NewFromString("abcdef", false),
+		gomme.NewFromBytes([]byte("abcdef"), false),
+	} {
+		limited := state.Limit(3)
+		end := state.MoveBy(5) // behind the limit
+		if got, gotBytes := limited.StringTo(end), limited.BytesTo(end); got != "abc" || string(gotBytes) != "abc" {
+			t.Errorf("got (%q, %q) up to the limit, want (%q, %q)", got, gotBytes, "abc", "abc")
+		}
+	}
+
+	// a failure at the limit mustn't be found in the cache without the limit
+	seq := Sequence(String("ab"), String("cd"))
+	parser := FirstSuccessful(Limited(3, seq, false), seq)
+	output, err := gomme.RunOnString("abcd", parser)
+	if err != nil || len(output) != 2 || output[1] != "cd" {
+		t.Errorf("got (%q, %v), want ([ab cd], nil)", output, err)
+	}
+}
+
+func BenchmarkLimited(b *testing.B) {
+	parser := Limited(3, Alpha1(), true)
+	input := gomme.NewFromString(1, nil, -1, "abcdef")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestDecompressed(t *testing.T) {
+	t.Parallel()
+
+	compress := func(data string) string {
+		buf := bytes.Buffer{}
+		w := zlib.NewWriter(&buf)
+		_, _ = w.Write([]byte(data))
+		_ = w.Close()
+		return buf.String()
+	}
+	decompress := func(r io.Reader) (io.Reader, error) {
+		return zlib.NewReader(r)
+	}
+	good := compress("123abc")
+	bad := compress("123a4c")
+
+	testCases := []struct {
+		name          string
+		parser        gomme.Parser[string]
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:          "parsing compressed block should succeed",
+			parser:        Decompressed(len(good), 6, decompress, Map2(Digit1(), Alpha1(), concatMapFunc)),
+			input:         good + "rest",
+			wantErr:       false,
+			wantOutput:    "123abc",
+			wantRemaining: "rest",
+		},
+		{
+			name:          "parsing invalid content should fail",
+			parser:        Decompressed(len(bad), 6, decompress, Map2(Digit1(), Alpha1(), concatMapFunc)),
+			input:         bad + "rest",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: bad + "rest",
+		},
+		{
+			name:          "parsing corrupt compressed data should fail",
+			parser:        Decompressed(4, 6, decompress, Map2(Digit1(), Alpha1(), concatMapFunc)),
+			input:         "abcdrest",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "abcdrest",
+		},
+		{
+			name:          "parsing too large decompressed data should fail",
+			parser:        Decompressed(len(good), 5, decompress, Map2(Digit1(), Alpha1(), concatMapFunc)),
+			input:         good + "rest",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: good + "rest",
+		},
+		{
+			name:          "parsing too short input should fail",
+			parser:        Decompressed(len(good)+1, 6, decompress, Map2(Digit1(), Alpha1(), concatMapFunc)),
+			input:         good,
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: good,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func concatMapFunc(a, b string) (string, error) {
+	return a + b, nil
+}
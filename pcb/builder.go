@@ -0,0 +1,93 @@
+package pcb
+
+import "github.com/oleiade/gomme"
+
+// SeqBuilder builds a parser for a sequence of kept and skipped parsers.
+// It is created with Seq.
+type SeqBuilder[Kept, Output any] struct {
+	steps []seqStep[Kept]
+}
+
+type seqStep[Kept any] struct {
+	keep gomme.Parser[Kept]
+	skip gomme.Parser[struct{}]
+}
+
+// Seq starts building a sequence of parsers.
+// The outputs of the parsers added with Keep are passed to the function
+// given to Build. The parsers added with Skip only consume input.
+// So no Map function has to ignore half of its arguments:
+//
+//	Seq[string, Assignment]().
+//		Skip(Skip(String("let"))).Skip(Skip(Whitespace1())).
+//		Keep(Alpha1()).
+//		Skip(Skip(Char('='))).
+//		Keep(Digit1()).
+//		Build(func(kept []string) (Assignment, error) {
+//			return Assignment{Name: kept[0], Value: kept[1]}, nil
+//		})
+//
+// Parsers with different output types can be kept with `Kept = any`
+// (see ToAny).
+func Seq[Kept, Output any]() *SeqBuilder[Kept, Output] {
+	return &SeqBuilder[Kept, Output]{}
+}
+
+// Keep adds a parser whose output is kept.
+func (sb *SeqBuilder[Kept, Output]) Keep(parse gomme.Parser[Kept]) *SeqBuilder[Kept, Output] {
+	sb.steps = append(sb.steps, seqStep[Kept]{keep: parse})
+	return sb
+}
+
+// Skip adds a parser that only consumes input.
+// Parsers of any output type can be converted with the Skip function.
+func (sb *SeqBuilder[Kept, Output]) Skip(parse gomme.Parser[struct{}]) *SeqBuilder[Kept, Output] {
+	sb.steps = append(sb.steps, seqStep[Kept]{skip: parse})
+	return sb
+}
+
+// Build returns the parser for the sequence.
+// `fn` gets the outputs of all kept parsers in order.
+// An error returned by `fn` is reported as a semantic error.
+// The parser is built with Sequence and Map, so it reports the errors of
+// the failing sub-parser and takes part in error recovery.
+// This method panics during the construction phase if no parser has been
+// added.
+func (sb *SeqBuilder[Kept, Output]) Build(fn func(kept []Kept) (Output, error)) gomme.Parser[Output] {
+	if len(sb.steps) == 0 {
+		panic("Seq needs at least one parser")
+	}
+	parsers := make([]gomme.Parser[Kept], len(sb.steps))
+	keptIdx := make([]int, 0, len(sb.steps))
+	for i, step := range sb.steps {
+		if step.keep != nil {
+			parsers[i] = step.keep
+			keptIdx = append(keptIdx, i)
+			continue
+		}
+		parsers[i] = Map(step.skip, func(struct{}) (Kept, error) {
+			return gomme.ZeroOf[Kept](), nil
+		})
+	}
+
+	return Map(Sequence(parsers...), func(outputs []Kept) (Output, error) {
+		kept := make([]Kept, len(keptIdx))
+		for i, idx := range keptIdx {
+			kept[i] = outputs[idx]
+		}
+		return fn(kept)
+	})
+}
+
+// ToAny converts the output of a parser to `any`.
+// This is useful for keeping parsers with different output types in Seq.
+func ToAny[Output any](parse gomme.Parser[Output]) gomme.Parser[any] {
+	anyParse := func(state gomme.State) (gomme.State, any, *gomme.ParserError) {
+		newState, output, err := parse.It(state)
+		if err != nil {
+			return newState, nil, err
+		}
+		return newState, output, nil
+	}
+	return gomme.NewParser[any](parse.Expected(), anyParse, parse.Recover)
+}
@@ -0,0 +1,117 @@
+package pcb
+
+import (
+	"errors"
+	"github.com/oleiade/gomme"
+	"strings"
+	"testing"
+)
+
+func TestSeq(t *testing.T) {
+	t.Parallel()
+
+	type assignment struct {
+		name, value string
+	}
+	parser := Seq[string, assignment]().
+		Skip(Skip(String("let"))).Skip(Skip(Whitespace1())).
+		Keep(Alpha1()).
+		Skip(Skip(Char('='))).
+		Keep(Digit1()).
+		Build(func(kept []string) (assignment, error) {
+			if kept[1] == "0" {
+				return assignment{}, errors.New("zero isn't allowed")
+			}
+			return assignment{name: kept[0], value: kept[1]}, nil
+		})
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       bool
+		wantOutput    assignment
+		wantRemaining string
+	}{
+		{
+			name:          "matching parsers should succeed",
+			input:         "let abc=123;",
+			wantOutput:    assignment{name: "abc", value: "123"},
+			wantRemaining: ";",
+		}, {
+			name:          "failing skipped parser should fail",
+			input:         "let abc:123;",
+			wantErr:       true,
+			wantRemaining: "let abc:123;",
+		}, {
+			name:          "failing kept parser should fail",
+			input:         "let abc=x;",
+			wantErr:       true,
+			wantRemaining: "let abc=x;",
+		}, {
+			name:          "error of the build function should be reported",
+			input:         "let abc=0;",
+			wantErr:       true,
+			wantRemaining: ";",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %v, want output %v", gotResult, tc.wantOutput)
+			}
+			if remaining := newState.CurrentString(); remaining != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remaining, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestSeqToAny(t *testing.T) {
+	t.Parallel()
+
+	parser := Seq[any, string]().
+		Keep(ToAny(Char('x'))).
+		Skip(Skip(Char(':'))).
+		Keep(ToAny(Int64(false, 10))).
+		Build(func(kept []any) (string, error) {
+			return string(kept[0].(rune)) + "=" + string(rune('0'+kept[1].(int64))), nil
+		})
+
+	output, err := gomme.RunOnString("x:7", parser)
+	if err != nil || output != "x=7" {
+		t.Errorf("got (%q, %v), want (%q, nil)", output, err, "x=7")
+	}
+}
+
+func TestSeqRecovery(t *testing.T) {
+	t.Parallel()
+
+	parser := Seq[string, string]().
+		Keep(Alpha1()).
+		Skip(Skip(Char('='))).
+		Keep(gomme.SaveSpot(Digit1())).
+		Build(func(kept []string) (string, error) {
+			return kept[0] + "=" + kept[1], nil
+		})
+
+	_, err := gomme.RunOnString("abc:123", parser)
+	if err == nil {
+		t.Fatalf("got no error for the invalid separator")
+	}
+	if got := err.Error(); !strings.Contains(got, "'='") {
+		t.Errorf("got error %q, want the expectation of the failing sub-parser", got)
+	}
+
+	output, err := gomme.RunOnString("abc=#123", parser)
+	if err == nil || output != "abc=123" {
+		t.Errorf("got (%q, %v), want (%q, error)", output, err, "abc=123")
+	}
+}
@@ -35,7 +35,8 @@ func Char(char rune) gomme.Parser[rune] {
 		return state.MoveBy(size), r
 	}
 
-	return gomme.NewParser[rune](expected, parse, false, IndexOf(char), nil)
+	p := gomme.NewParser[rune](expected, parse, false, IndexOf(char), nil)
+	return literalParser(p, string(char))
 }
 
 // Byte parses a single byte and matches it with
@@ -59,7 +60,8 @@ func Byte(byt byte) gomme.Parser[byte] {
 		return state.MoveBy(1), b
 	}
 
-	return gomme.NewParser[byte](expected, parse, false, IndexOf(byt), nil)
+	p := gomme.NewParser[byte](expected, parse, false, IndexOf(byt), nil)
+	return literalParser(p, string([]byte{byt}))
 }
 
 // Satisfy parses a single character, and ensures that it satisfies the given predicate.
@@ -87,7 +89,59 @@ func Satisfy(expected string, predicate func(rune) bool) gomme.Parser[rune] {
 		return strings.IndexFunc(state.CurrentString(), predicate)
 	}
 
-	return gomme.NewParser[rune](expected, parse, false, recoverer, nil)
+	p := gomme.NewParser[rune](expected, parse, false, recoverer, nil)
+	return gomme.WithAnalysis(p, predicateAnalysis(1, predicate))
+}
+
+// CharRange parses a single character between `from` and `to` (inclusive).
+// This parser is a good candidate for SaveSpot and has an optimized recoverer.
+// This function panics during the construction phase if `from` is greater
+// than `to`.
+func CharRange(from, to rune) gomme.Parser[rune] {
+	if from > to {
+		panic(fmt.Sprintf("CharRange needs `from` <= `to`, but got: %q > %q", from, to))
+	}
+	return Satisfy(fmt.Sprintf("character from %q to %q", from, to), func(r rune) bool {
+		return from <= r && r <= to
+	})
+}
+
+// RuneIn parses a single character of the Unicode range table
+// (e.g. unicode.Lu for uppercase letters or unicode.Greek).
+// So grammars can refer to Unicode categories, scripts and properties
+// directly.
+// The tables of the unicode package are named in error messages.
+// This parser is a good candidate for SaveSpot and has an optimized recoverer.
+// This function panics during the construction phase if `table` is nil.
+func RuneIn(table *unicode.RangeTable) gomme.Parser[rune] {
+	if table == nil {
+		panic("RuneIn needs a range table")
+	}
+	return Satisfy(rangeTableName(table), func(r rune) bool {
+		return unicode.Is(table, r)
+	})
+}
+
+// rangeTableName returns the name of a table of the unicode package
+// (e.g. "Unicode category Lu") or a generic description.
+func rangeTableName(table *unicode.RangeTable) string {
+	for _, kind := range []struct {
+		name   string
+		tables map[string]*unicode.RangeTable
+	}{
+		{"category", unicode.Categories}, {"script", unicode.Scripts}, {"property", unicode.Properties},
+	} {
+		found := "" // the smallest name because of aliases like STerm
+		for name, t := range kind.tables {
+			if t == table && (found == "" || name < found) {
+				found = name
+			}
+		}
+		if found != "" {
+			return "Unicode " + kind.name + " " + found
+		}
+	}
+	return "character of Unicode range table"
 }
 
 // String parses a token from the input, and returns the part of the input that
@@ -107,7 +161,8 @@ func String(token string) gomme.Parser[string] {
 		return newState, token
 	}
 
-	return gomme.NewParser[string](expected, parse, false, IndexOf(token), nil)
+	p := gomme.NewParser[string](expected, parse, false, IndexOf(token), nil)
+	return literalParser(p, token)
 }
 
 // Bytes parses a token from the input, and returns the part of the input that
@@ -126,7 +181,8 @@ func Bytes(token []byte) gomme.Parser[[]byte] {
 		return newState, token
 	}
 
-	return gomme.NewParser[[]byte](expected, parse, false, IndexOf(token), nil)
+	p := gomme.NewParser[[]byte](expected, parse, false, IndexOf(token), nil)
+	return literalParser(p, string(token))
 }
 
 // UntilString parses until it finds a token in the input, and returns
@@ -225,8 +281,9 @@ func SatisfyMN(expected string, atLeast, atMost int, predicate func(rune) bool)
 		return current, output
 	}
 
-	return gomme.NewParser[string](
+	p := gomme.NewParser[string](
 		expected, parse, false, satisfyMNRecoverer(atLeast, predicate), nil)
+	return gomme.WithAnalysis(p, predicateAnalysis(atLeast, predicate))
 }
 
 func satisfyMNRecoverer(atLeast int, predicate func(rune2 rune) bool) gomme.Recoverer {
@@ -246,6 +303,115 @@ func satisfyMNRecoverer(atLeast int, predicate func(rune2 rune) bool) gomme.Reco
 	}
 }
 
+// literalParser marks a parser that matches exactly `token` as literal and
+// gives it the matching static analysis.
+func literalParser[Output any](p gomme.Parser[Output], token string) gomme.Parser[Output] {
+	return gomme.WithLiteral(gomme.WithAnalysis(p, tokenAnalysis(token)), token)
+}
+
+// tokenAnalysis is the static analysis of a parser matching exactly `token`.
+func tokenAnalysis(token string) gomme.Analysis {
+	if token == "" {
+		return gomme.Analysis{Nullable: true}
+	}
+	a := gomme.Analysis{MinLen: len(token)}
+	a.First.Add(token[0])
+	return a
+}
+
+// predicateAnalysis is the static analysis of a parser matching at least
+// `atLeast` runes that satisfy the predicate.
+// Only ASCII runes are tested; all UTF-8 start bytes are added to be safe.
+func predicateAnalysis(atLeast int, predicate func(rune) bool) gomme.Analysis {
+	a := gomme.Analysis{Nullable: atLeast == 0, MinLen: atLeast}
+	for r := rune(0); r < utf8.RuneSelf; r++ {
+		if predicate(r) {
+			a.First.Add(byte(r))
+		}
+	}
+	a.First.AddRange(0xC2, 0xF4)
+	return a
+}
+
+// Identifier parses an identifier that starts with a rune matching `startPred`
+// and continues with runes matching `contPred`.
+// If `startPred` is nil, Unicode letters and '_' are allowed at the start.
+// If `contPred` is nil, IsAlphanumeric is used for the rest of the identifier.
+// Identifiers that are equal to one of the `reserved` words are rejected.
+//
+// This parser is a good candidate for SaveSpot and has an optimized recoverer.
+func Identifier(startPred, contPred func(rune) bool, reserved ...string) gomme.Parser[string] {
+	expected := "identifier"
+
+	if startPred == nil {
+		startPred = func(r rune) bool {
+			return unicode.IsLetter(r) || r == '_'
+		}
+	}
+	if contPred == nil {
+		contPred = IsAlphanumeric
+	}
+
+	// identLen returns the length in bytes of the identifier at the start of input.
+	identLen := func(input string) int {
+		r, size := utf8.DecodeRuneInString(input)
+		if r == utf8.RuneError || !startPred(r) {
+			return 0
+		}
+		n := size
+		for n < len(input) {
+			r, size = utf8.DecodeRuneInString(input[n:])
+			if r == utf8.RuneError || !contPred(r) {
+				break
+			}
+			n += size
+		}
+		return n
+	}
+
+	parse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		input := state.CurrentString()
+		n := identLen(input)
+		if n == 0 {
+			r, size := utf8.DecodeRuneInString(input)
+			var errState gomme.State
+			switch {
+			case size == 0:
+				errState = state.NewError(expected + " (at EOF)")
+			case r == utf8.RuneError:
+				errState = state.NewError(expected + " (got UTF-8 error)")
+			default:
+				errState = state.NewError(fmt.Sprintf("%s (got %q)", expected, r))
+			}
+			return errState, "", errState.CurrentError()
+		}
+
+		ident := input[:n]
+		if slices.Contains(reserved, ident) {
+			errState := state.NewError(fmt.Sprintf("%s (got reserved word %q)", expected, ident))
+			return errState, "", errState.CurrentError()
+		}
+		return state.MoveBy(n), ident, nil
+	}
+
+	recoverer := func(state gomme.State) int {
+		input := state.CurrentString()
+		prevCont := false
+		for i, r := range input {
+			if !prevCont && startPred(r) {
+				n := identLen(input[i:])
+				if !slices.Contains(reserved, input[i:i+n]) {
+					return i
+				}
+			}
+			prevCont = contPred(r)
+		}
+		return -1
+	}
+
+	return gomme.NewParser[string](expected, parse, recoverer)
+}
+
 // AlphaMN parses at least `atLeast` and at most `atMost` Unicode letters.
 func AlphaMN(atLeast, atMost int) gomme.Parser[string] {
 	return SatisfyMN("letter", atLeast, atMost, unicode.IsLetter)
@@ -307,18 +473,113 @@ func HexDigit1() gomme.Parser[string] {
 	return SatisfyMN("hexadecimal digit", 1, math.MaxInt, IsHexDigit)
 }
 
-// Whitespace0 parses zero or more Unicode whitespace characters.
+// WhitespaceOption configures what Whitespace0 and Whitespace1 consider
+// whitespace, because that differs per format.
+// The default is UnicodeWhitespace.
+type WhitespaceOption func(*whitespaceConfig)
+
+type whitespaceConfig struct {
+	predicate func(rune) bool
+	ascii     *[utf8.RuneSelf]bool // set for the fast path if only ASCII characters are whitespace
+}
+
+// UnicodeWhitespace considers all Unicode whitespace (see unicode.IsSpace)
+// as whitespace.
+func UnicodeWhitespace() WhitespaceOption {
+	return func(cfg *whitespaceConfig) {
+		cfg.predicate, cfg.ascii = unicode.IsSpace, nil
+	}
+}
+
+// ASCIIWhitespace considers only ASCII whitespace (' ', '\t', '\n', '\v',
+// '\f' and '\r') as whitespace.
+// This is faster than UnicodeWhitespace.
+func ASCIIWhitespace() WhitespaceOption {
+	return WhitespaceChars(" \t\n\v\f\r")
+}
+
+// WhitespaceChars considers exactly the characters in `chars` as
+// whitespace (e.g. " \t" for formats with significant line breaks).
+// Sets of ASCII characters are as fast as ASCIIWhitespace.
+// This function panics during the construction phase if `chars` is empty.
+func WhitespaceChars(chars string) WhitespaceOption {
+	if chars == "" {
+		panic("WhitespaceChars needs at least one character")
+	}
+	var set *[utf8.RuneSelf]bool
+	predicate := func(r rune) bool {
+		return strings.ContainsRune(chars, r)
+	}
+	if isASCII(chars) {
+		set = &[utf8.RuneSelf]bool{}
+		for i := 0; i < len(chars); i++ {
+			set[chars[i]] = true
+		}
+		predicate = func(r rune) bool {
+			return r < utf8.RuneSelf && set[r]
+		}
+	}
+	return func(cfg *whitespaceConfig) {
+		cfg.predicate, cfg.ascii = predicate, set
+	}
+}
+
+// Whitespace0 parses zero or more whitespace characters.
+// By default, all Unicode whitespace is accepted (see WhitespaceOption).
 // In the cases where the input is empty, or no matching character is found, the parser
 // returns the input as is.
-func Whitespace0() gomme.Parser[string] {
-	return SatisfyMN("whitespace", 0, math.MaxInt, unicode.IsSpace)
+func Whitespace0(opts ...WhitespaceOption) gomme.Parser[string] {
+	return whitespace(0, opts)
 }
 
-// Whitespace1 parses one or more Unicode whitespace characters.
+// Whitespace1 parses one or more whitespace characters.
+// By default, all Unicode whitespace is accepted (see WhitespaceOption).
 // In the cases where the input doesn't hold enough data, or a terminating character
 // is found before any matching ones were, the parser returns an error result.
-func Whitespace1() gomme.Parser[string] {
-	return SatisfyMN("whitespace", 1, math.MaxInt, unicode.IsSpace)
+func Whitespace1(opts ...WhitespaceOption) gomme.Parser[string] {
+	return whitespace(1, opts)
+}
+
+func whitespace(atLeast int, opts []WhitespaceOption) gomme.Parser[string] {
+	cfg := whitespaceConfig{predicate: unicode.IsSpace}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.ascii == nil {
+		return SatisfyMN("whitespace", atLeast, math.MaxInt, cfg.predicate)
+	}
+
+	expected := "whitespace"
+	set := cfg.ascii
+	parse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		input := state.CurrentString()
+		n := 0
+		for n < len(input) && input[n] < utf8.RuneSelf && set[input[n]] {
+			n++
+		}
+		if n < atLeast {
+			var errState gomme.State
+			if n == len(input) {
+				errState = state.NewError(fmt.Sprintf("%s (need %d, found %d at EOF)", expected, atLeast, n))
+			} else {
+				r, _ := utf8.DecodeRuneInString(input[n:])
+				errState = state.NewError(fmt.Sprintf("%s (need %d, found %d, got %q)", expected, atLeast, n, r))
+			}
+			return errState, "", errState.CurrentError()
+		}
+		return state.MoveBy(n), input[:n], nil
+	}
+	p := gomme.NewParser[string](expected, parse, satisfyMNRecoverer(atLeast, cfg.predicate))
+	return gomme.WithAnalysis(p, predicateAnalysis(atLeast, cfg.predicate))
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
 }
 
 // OneOfRunes parses a single character from the given set of characters.
@@ -358,7 +619,96 @@ func OneOf(collection ...string) gomme.Parser[string] {
 		return state.NewError(expected), ""
 	}
 
-	return gomme.NewParser[string](expected, parse, false, IndexOfAny(collection...), nil)
+	analyses := make([]gomme.Analysis, len(collection))
+	for i, token := range collection {
+		analyses[i] = tokenAnalysis(token)
+	}
+	p := gomme.NewParser[string](expected, parse, false, IndexOfAny(collection...), nil)
+	return gomme.WithAnalysis(p, gomme.ChoiceAnalysis(analyses...))
+}
+
+// Suggest applies the keyword parsers (e.g. String) in order like
+// FirstSuccessful and improves the error message with the closest of
+// their literals (see gomme.LiteralOf):
+// "... did you mean `while`?"
+// The word at the start of the input is compared to all literals
+// by edit distance. Nothing is suggested if no literal is close enough.
+// Alternatives that aren't literal parsers are applied but never
+// suggested.
+// So wrapping keyword parsers with Suggest is the option to get suggestions.
+// This function panics during the construction phase if no alternatives
+// are given.
+func Suggest(alternatives ...gomme.Parser[string]) gomme.Parser[string] {
+	if len(alternatives) == 0 {
+		panic("Suggest(missing parsers)")
+	}
+	candidates := make([]string, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		if lit, ok := gomme.LiteralOf(alternative); ok {
+			candidates = append(candidates, lit)
+		}
+	}
+	parse := alternatives[0]
+	if len(alternatives) > 1 {
+		parse = FirstSuccessful(alternatives...)
+	}
+
+	sugParse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		newState, output, err := parse.It(state)
+		if err == nil {
+			return newState, output, nil
+		}
+
+		input := state.CurrentString()
+		end := strings.IndexFunc(input, func(r rune) bool { return !IsAlphanumeric(r) })
+		if end < 0 {
+			end = len(input)
+		}
+		if best := closestCandidate(input[:end], candidates); best != "" {
+			err = err.WithText(fmt.Sprintf("%s; did you mean `%s`?", err.Text(), best))
+		}
+		return state.Preserve(newState.SwapError(err)), output, err
+	}
+	return gomme.NewParser[string](parse.Expected(), sugParse, parse.Recover)
+}
+
+// closestCandidate returns the candidate with the smallest edit distance
+// to word. Only candidates with a distance of at most a third of their
+// length (at least 1) are considered.
+func closestCandidate(word string, candidates []string) string {
+	if word == "" {
+		return ""
+	}
+	best, bestDist := "", math.MaxInt
+	for _, candidate := range candidates {
+		limit := max(1, utf8.RuneCountInString(candidate)/3)
+		if dist := editDistance(word, candidate); dist <= limit && dist < bestDist {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance of a and b in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
 }
 
 // LF parses a line feed `\n` character.
@@ -2,6 +2,7 @@ package pcb
 
 import (
 	"github.com/oleiade/gomme"
+	"strings"
 	"testing"
 	"unicode"
 	"unicode/utf8"
@@ -85,6 +86,108 @@ func BenchmarkChar(b *testing.B) {
 	}
 }
 
+func TestIdentifier(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        gomme.Parser[string]
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:          "parsing identifier should succeed",
+			parser:        Identifier(nil, nil, "if", "else"),
+			input:         "_abc1 = 3",
+			wantErr:       false,
+			wantOutput:    "_abc1",
+			wantRemaining: " = 3",
+		},
+		{
+			name:          "parsing Unicode identifier should succeed",
+			parser:        Identifier(nil, nil, "if", "else"),
+			input:         "größe1+x",
+			wantErr:       false,
+			wantOutput:    "größe1",
+			wantRemaining: "+x",
+		},
+		{
+			name:          "parsing identifier starting with a reserved word should succeed",
+			parser:        Identifier(nil, nil, "if", "else"),
+			input:         "iffy;",
+			wantErr:       false,
+			wantOutput:    "iffy",
+			wantRemaining: ";",
+		},
+		{
+			name:          "parsing reserved word should fail",
+			parser:        Identifier(nil, nil, "if", "else"),
+			input:         "if x",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "if x",
+		},
+		{
+			name:          "parsing digit at start should fail",
+			parser:        Identifier(nil, nil, "if", "else"),
+			input:         "1abc",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "1abc",
+		},
+		{
+			name:          "parsing with custom predicates should succeed",
+			parser:        Identifier(func(r rune) bool { return r == '$' }, func(r rune) bool { return unicode.IsLetter(r) || r == '-' }),
+			input:         "$x-y z",
+			wantErr:       false,
+			wantOutput:    "$x-y",
+			wantRemaining: " z",
+		},
+		{
+			name:          "parsing empty input should fail",
+			parser:        Identifier(nil, nil, "if", "else"),
+			input:         "",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkIdentifier(b *testing.B) {
+	parser := Identifier(nil, nil, "if", "else")
+	input := gomme.NewFromString(1, nil, -1, "_abc1 = 3")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
 func TestAlpha0(t *testing.T) {
 	t.Parallel()
 
@@ -1405,6 +1508,72 @@ func BenchmarkCRLF(b *testing.B) {
 	}
 }
 
+func TestSuggest(t *testing.T) {
+	t.Parallel()
+
+	keywords := []gomme.Parser[string]{String("if"), String("else"), String("while"), String("return")}
+	testCases := []struct {
+		name           string
+		input          string
+		wantErr        bool
+		wantOutput     string
+		wantSuggestion string
+	}{
+		{
+			name:       "matching keyword should succeed",
+			input:      "while x",
+			wantErr:    false,
+			wantOutput: "while",
+		},
+		{
+			name:           "misspelled keyword should suggest the closest one",
+			input:          "whlie x",
+			wantErr:        true,
+			wantSuggestion: "did you mean `while`?",
+		},
+		{
+			name:           "keyword with typo should suggest it",
+			input:          "retrn x",
+			wantErr:        true,
+			wantSuggestion: "did you mean `return`?",
+		},
+		{
+			name:           "unrelated word should not suggest anything",
+			input:          "banana",
+			wantErr:        true,
+			wantSuggestion: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := Suggest(keywords...)
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+			if !tc.wantErr {
+				return
+			}
+
+			msg := newState.Errors().Error()
+			if tc.wantSuggestion == "" && strings.Contains(msg, "did you mean") {
+				t.Errorf("got error %q, want no suggestion", msg)
+			}
+			if tc.wantSuggestion != "" && !strings.Contains(msg, tc.wantSuggestion) {
+				t.Errorf("got error %q, want suggestion %q", msg, tc.wantSuggestion)
+			}
+		})
+	}
+}
+
 func TestOneOf(t *testing.T) {
 	t.Parallel()
 
@@ -1901,3 +2070,164 @@ func BenchmarkSatisfyMN(b *testing.B) {
 		_, _ = p.It(input)
 	}
 }
+
+func TestWhitespaceOptions(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        gomme.Parser[string]
+		input         string
+		wantErr       string
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:          "Unicode whitespace should be accepted by default",
+			parser:        Whitespace0(),
+			input:         "\u2003\u00a0 x",
+			wantOutput:    "\u2003\u00a0 ",
+			wantRemaining: "x",
+		}, {
+			name:          "ASCII whitespace should stop at Unicode whitespace",
+			parser:        Whitespace0(ASCIIWhitespace()),
+			input:         " \t\u3000x",
+			wantOutput:    " \t",
+			wantRemaining: "\u3000x",
+		}, {
+			name:          "custom ASCII set should stop at line break",
+			parser:        Whitespace1(WhitespaceChars(" \t")),
+			input:         " \t\nx",
+			wantOutput:    " \t",
+			wantRemaining: "\nx",
+		}, {
+			name:          "custom Unicode set should be accepted",
+			parser:        Whitespace1(WhitespaceChars("\u3000 ")),
+			input:         "\u3000 \tx",
+			wantOutput:    "\u3000 ",
+			wantRemaining: "\tx",
+		}, {
+			name:          "missing ASCII whitespace should fail",
+			parser:        Whitespace1(ASCIIWhitespace()),
+			input:         "\u00a0x",
+			wantErr:       `expected whitespace (need 1, found 0, got '\u00a0')`,
+			wantRemaining: "\u00a0x",
+		}, {
+			name:          "ASCII whitespace at EOF should fail",
+			parser:        Whitespace1(ASCIIWhitespace()),
+			input:         "",
+			wantErr:       "expected whitespace (need 1, found 0 at EOF)",
+			wantRemaining: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), tc.parser)
+			if newState.HasError() != (tc.wantErr != "") {
+				t.Errorf("got error %v, want error %q", newState.Errors(), tc.wantErr)
+			}
+			if tc.wantErr != "" {
+				if msg := newState.Errors().Error(); !strings.Contains(msg, tc.wantErr) {
+					t.Errorf("got error %q, want error %q", msg, tc.wantErr)
+				}
+			}
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkWhitespace0ASCII(b *testing.B) {
+	b.ReportAllocs()
+	parser := Whitespace0(ASCIIWhitespace())
+	input := gomme.NewFromString(" \t\n\r", false)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = parser.It(input)
+	}
+}
+
+func TestCharRangeAndRuneIn(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        gomme.Parser[rune]
+		input         string
+		wantErr       string
+		wantOutput    rune
+		wantRemaining string
+	}{
+		{
+			name:          "character in range should succeed",
+			parser:        CharRange('a', 'z'),
+			input:         "mx",
+			wantOutput:    'm',
+			wantRemaining: "x",
+		}, {
+			name:          "character out of range should fail",
+			parser:        CharRange('a', 'z'),
+			input:         "M",
+			wantErr:       `expected character from 'a' to 'z' (got 'M')`,
+			wantRemaining: "M",
+		}, {
+			name:          "uppercase letter should succeed",
+			parser:        RuneIn(unicode.Lu),
+			input:         "Ärger",
+			wantOutput:    'Ä',
+			wantRemaining: "rger",
+		}, {
+			name:          "decimal digit of other scripts should succeed",
+			parser:        RuneIn(unicode.Nd),
+			input:         "٣",
+			wantOutput:    '٣',
+			wantRemaining: "",
+		}, {
+			name:          "lowercase letter should fail for Lu",
+			parser:        RuneIn(unicode.Lu),
+			input:         "a",
+			wantErr:       `expected Unicode category Lu (got 'a')`,
+			wantRemaining: "a",
+		}, {
+			name:          "script should be named",
+			parser:        RuneIn(unicode.Greek),
+			input:         "",
+			wantErr:       "expected Unicode script Greek (at EOF)",
+			wantRemaining: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), tc.parser)
+			if newState.HasError() != (tc.wantErr != "") {
+				t.Errorf("got error %v, want error %q", newState.Errors(), tc.wantErr)
+			}
+			if tc.wantErr != "" {
+				if msg := newState.Errors().Error(); !strings.Contains(msg, tc.wantErr) {
+					t.Errorf("got error %q, want error %q", msg, tc.wantErr)
+				}
+			}
+			if tc.wantErr == "" && gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
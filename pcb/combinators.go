@@ -1,7 +1,9 @@
 package pcb
 
 import (
+	"fmt"
 	"github.com/oleiade/gomme"
+	"strings"
 )
 
 // Optional applies an optional child parser. Will return a zero value
@@ -15,7 +17,63 @@ func Optional[Output any](parse gomme.Parser[Output]) gomme.Parser[Output] {
 		}
 		return newState, output, err
 	}
-	return gomme.NewParser[Output]("Optional", optParse, Forbidden("Optional"))
+	analysis := gomme.Analyze(parse)
+	analysis.Nullable = true
+	analysis.MinLen = 0
+	p := gomme.NewParser[Output]("Optional", optParse, Forbidden("Optional"))
+	return gomme.WithAnalysis(p, analysis)
+}
+
+// Option is the output of the OptionalOK parser.
+// OK is true iff the value has been parsed.
+type Option[T any] struct {
+	Value T
+	OK    bool
+}
+
+// OptionalOK applies an optional child parser just like Optional.
+// But its output tells whether the value has been parsed or not.
+// So a parsed zero value can be distinguished from a missing one.
+func OptionalOK[Output any](parse gomme.Parser[Output]) gomme.Parser[Option[Output]] {
+	optParse := func(state gomme.State) (gomme.State, Option[Output], *gomme.ParserError) {
+		newState, output, err := parse.It(state)
+		if newState.Failed() && !state.SaveSpotMoved(newState) {
+			return state.Succeed(newState), Option[Output]{}, nil
+		}
+		if err != nil {
+			return newState, Option[Output]{}, err
+		}
+		return newState, Option[Output]{Value: output, OK: true}, nil
+	}
+	return gomme.NewParser[Option[Output]]("OptionalOK", optParse, Forbidden("OptionalOK"))
+}
+
+// OrDefault applies an optional child parser. Will return the default value
+// `def` without consuming any input if not successful.
+// OrDefault will ignore any parsing error except if a SaveSpot is active.
+// It is a clearer alternative to using Optional together with Map.
+func OrDefault[Output any](parse gomme.Parser[Output], def Output) gomme.Parser[Output] {
+	defParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		newState, output, err := parse.It(state)
+		if newState.Failed() && !state.SaveSpotMoved(newState) {
+			return state.Succeed(newState), def, nil
+		}
+		return newState, output, err
+	}
+	return gomme.NewParser[Output]("OrDefault", defParse, Forbidden("OrDefault"))
+}
+
+// SaveSpotScope applies a sub-parser and limits the effect of all SaveSpot
+// parsers inside of it to the sub-parser.
+// So a SaveSpot inside (e.g. of a string literal) doesn't prevent backtracking
+// of an enclosing alternative (e.g. a statement).
+// Within the scope the SaveSpots work as usual.
+func SaveSpotScope[Output any](parse gomme.Parser[Output]) gomme.Parser[Output] {
+	scopeParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		newState, output, err := parse.It(state)
+		return newState.ScopeSaveSpot(state), output, err
+	}
+	return gomme.NewParser[Output]("SaveSpotScope", scopeParse, parse.Recover)
 }
 
 // Peek tries to apply the provided parser without consuming any input.
@@ -89,6 +147,137 @@ func Recognize[Output any](parse gomme.Parser[Output]) gomme.Parser[[]byte] {
 		}, nil, nil, nil, nil)
 }
 
+// Checksummed applies the body parser and then the checksum parser.
+// The raw bytes consumed by the body parser are validated against the
+// parsed checksum with `verify`. This is useful for frames of binary protocols.
+// The output of the body parser is returned.
+//
+// If `verify` returns an error, the parser fails with the error message
+// positioned at the start of the checksum.
+func Checksummed[Output, Sum any](
+	body gomme.Parser[Output], checksum gomme.Parser[Sum], verify func(body []byte, sum Sum) error,
+) gomme.Parser[Output] {
+	expected := body.Expected() + " with checksum " + checksum.Expected()
+
+	checkParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		bodyState, output, err := body.It(state)
+		if err != nil {
+			return state.Preserve(bodyState), gomme.ZeroOf[Output](), err
+		}
+		sumState, sum, sErr := checksum.It(bodyState)
+		if sErr != nil {
+			return state.Preserve(sumState), gomme.ZeroOf[Output](), sErr
+		}
+		if vErr := verify(state.BytesTo(bodyState), sum); vErr != nil {
+			errState := bodyState.NewFailure(vErr.Error())
+			return state.Preserve(errState), gomme.ZeroOf[Output](), errState.CurrentError()
+		}
+		return sumState, output, nil
+	}
+	return gomme.NewParser[Output](expected, checkParse, body.Recover)
+}
+
+// ParseWith applies the outer parser and parses its output with the inner
+// parser. This is useful for embedded languages in strings
+// (e.g. SQL inside of a configuration string).
+// The inner parser has to consume the whole output of the outer parser.
+//
+// Errors of the inner parser are mapped back to the original input.
+// If the output of the outer parser can be found unchanged in the consumed
+// input, the position is exact. Otherwise (e.g. because of escape sequences)
+// the error is reported at the start of the outer parser.
+// The inner parser doesn't recover from errors. It shares the configuration,
+// user values and step budget of the state (see gomme.State.Embed).
+func ParseWith[Output any](outer gomme.Parser[string], inner gomme.Parser[Output]) gomme.Parser[Output] {
+	expected := inner.Expected() + " in " + outer.Expected()
+
+	withParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		outerState, text, err := outer.It(state)
+		if err != nil {
+			return state.Preserve(outerState), gomme.ZeroOf[Output](), err
+		}
+
+		base := strings.Index(state.StringTo(outerState), text)
+		fromStart := func(pos int) int { // position in the embedded input -> offset from `state`
+			if base < 0 {
+				return 0
+			}
+			return base + pos
+		}
+		innerState, output, iErr := inner.It(state.Embed(gomme.NewFromString(text, false)))
+		if iErr == nil && !innerState.AtEnd() {
+			innerState = innerState.NewError("end of embedded input")
+			iErr = innerState.CurrentError()
+		}
+		if iErr != nil {
+			errState := state.MoveBy(fromStart(iErr.Pos())).NewFailure(iErr.Text())
+			return state.Unembed(innerState, fromStart).Preserve(errState), gomme.ZeroOf[Output](), errState.CurrentError()
+		}
+		consumed := state.ByteCount(outerState)
+		return outerState.Unembed(innerState, func(pos int) int { return fromStart(pos) - consumed }), output, nil
+	}
+	return gomme.NewParser[Output](expected, withParse, outer.Recover)
+}
+
+// Include applies the path parser and parses the file with the parsed name
+// with the content parser. Afterwards parsing continues in the including
+// file after the path.
+// The state has to be created by a gomme.Session (see State.Include for
+// resolving the name).
+// The content parser has to consume the whole file.
+//
+// Errors inside the included file are kept with the name of that file.
+// This includes semantic errors of a successful content parser.
+// Additionally the Include parser fails after the path if the content
+// parser fails.
+// User values set by the content parser stay set after the Include parser.
+// The content parser doesn't recover from errors.
+func Include[Output any](path gomme.Parser[string], content gomme.Parser[Output]) gomme.Parser[Output] {
+	expected := content.Expected() + " included by " + path.Expected()
+
+	inclParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		pathState, name, err := path.It(state)
+		if err != nil {
+			return state.Preserve(pathState), gomme.ZeroOf[Output](), err
+		}
+
+		fileState, oErr := pathState.Include(name)
+		if oErr != nil {
+			errState := pathState.NewFailure(oErr.Error())
+			return state.Preserve(errState), gomme.ZeroOf[Output](), errState.CurrentError()
+		}
+		innerState, output, iErr := content.It(fileState)
+		if iErr == nil && !innerState.AtEnd() {
+			innerState = innerState.NewError("end of file")
+			iErr = innerState.CurrentError()
+		}
+		if iErr != nil {
+			errState := pathState.NewFailure(fmt.Sprintf("invalid included file %q", innerState.File()))
+			return state.Unembed(innerState, nil).SaveError(iErr).Preserve(errState), gomme.ZeroOf[Output](), errState.CurrentError()
+		}
+		return pathState.Unembed(innerState, nil), output, nil
+	}
+	return gomme.NewParser[Output](expected, inclParse, path.Recover)
+}
+
+// Skip applies a parser only for consuming input and discards its output.
+// This is useful for separators, padding and the like.
+// A parser without output is returned as is, so wrapping it twice
+// (e.g. for the Skip method of Seq) costs nothing.
+func Skip[Output any](parse gomme.Parser[Output]) gomme.Parser[struct{}] {
+	if skipper, ok := any(parse).(gomme.Parser[struct{}]); ok {
+		return skipper
+	}
+	skipParse := func(state gomme.State) (gomme.State, struct{}, *gomme.ParserError) {
+		newState, _, err := parse.It(state)
+		if err != nil {
+			return state.Preserve(newState), struct{}{}, err
+		}
+		return newState, struct{}{}, nil
+	}
+	return gomme.NewParser[struct{}](parse.Expected(), skipParse, parse.Recover)
+}
+
 // Assign returns the provided value if the parser succeeds, otherwise
 // it returns an error result.
 func Assign[Output1, Output2 any](value Output1, parse gomme.Parser[Output2]) gomme.Parser[Output1] {
@@ -102,6 +291,154 @@ func Assign[Output1, Output2 any](value Output1, parse gomme.Parser[Output2]) go
 	)
 }
 
+// Switch applies a parser and uses its output to choose the parser
+// that is applied next. The output of the chosen parser is returned.
+// This is needed if the layout of the following input depends on
+// the data itself (e.g. a type byte in binary formats or versioned grammars).
+//
+// NOTE:
+//   - `choose` is called during the runtime phase.
+//     So it should return parsers that have been constructed beforehand
+//     instead of constructing new ones for every call.
+//   - If `choose` returns nil, the parser fails.
+func Switch[Output1, Output2 any](
+	parse gomme.Parser[Output1], choose func(Output1) gomme.Parser[Output2],
+) gomme.Parser[Output2] {
+	var zero Output2
+
+	expected := parse.Expected() + " and matching input"
+	switchParse := func(state gomme.State) (gomme.State, Output2, *gomme.ParserError) {
+		newState, output1, err := parse.It(state)
+		if err != nil {
+			return state.Preserve(newState), zero, err
+		}
+
+		next := choose(output1)
+		if next == nil {
+			errState := newState.NewError(fmt.Sprintf("%s (no parser for %v)", expected, output1))
+			return state.Preserve(errState), zero, errState.CurrentError()
+		}
+
+		finalState, output2, err := next.It(newState)
+		if err != nil {
+			return state.Preserve(finalState), zero, err
+		}
+		return finalState, output2, nil
+	}
+	return gomme.NewParser[Output2](expected, switchParse, BasicRecovererFunc(switchParse))
+}
+
+// Verify applies a parser and checks its output with the predicate.
+// If the predicate rejects the output, the parser fails with the message
+// positioned at the start of the parsed value.
+// This is better than returning an error from a Map function because the
+// error is reported at the right position and the parser really fails.
+func Verify[Output any](parse gomme.Parser[Output], predicate func(Output) bool, message string) gomme.Parser[Output] {
+	var zero Output
+
+	verifyParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		newState, output, err := parse.It(state)
+		if err != nil {
+			return state.Preserve(newState), zero, err
+		}
+
+		if !predicate(output) {
+			errState := state.NewFailure(message)
+			return errState, zero, errState.CurrentError()
+		}
+		return newState, output, nil
+	}
+	return gomme.NewParser[Output](parse.Expected(), verifyParse, parse.Recover)
+}
+
+// Assert checks the condition on the current state without consuming any
+// input. This allows context-sensitive validation inside of a grammar
+// (e.g. "inside of a loop").
+// If the condition isn't met, a semantic error with the message is
+// reported at the current position. Like all semantic errors, it doesn't
+// stop the parser.
+func Assert(cond func(gomme.State) bool, message string) gomme.Parser[struct{}] {
+	assertParse := func(state gomme.State) (gomme.State, struct{}, *gomme.ParserError) {
+		if !cond(state) {
+			return state.NewSemanticError(message), struct{}{}, nil
+		}
+		return state, struct{}{}, nil
+	}
+	return gomme.NewParser[struct{}]("assertion", assertParse, Forbidden("Assert"))
+}
+
+// MapErr applies a parser and transforms its error with `fn` if it fails.
+// This allows to rewrite or enrich the error message (e.g. add hints) of
+// a sub-parser without reimplementing it.
+// The new error can be created with `err.WithText(...)`.
+// If `fn` returns nil, the original error is kept.
+func MapErr[Output any](parse gomme.Parser[Output], fn func(*gomme.ParserError) *gomme.ParserError) gomme.Parser[Output] {
+	mapErrParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		newState, output, err := parse.It(state)
+		if err == nil {
+			return newState, output, nil
+		}
+
+		if newErr := fn(err); newErr != nil {
+			err = newErr
+		}
+		return state.Preserve(newState.SwapError(err)), output, err
+	}
+	return gomme.NewParser[Output](parse.Expected(), mapErrParse, parse.Recover)
+}
+
+// Rule names a parser. The name is used as expectation of the parser
+// (e.g. in the "one of: ..." message of Dispatch) and in debug output.
+//
+// If the parser fails without matching anything, the error is replaced by
+// `expected <name>` (see gomme.Parser.WithExpected). So users get
+// "expected expression" instead of the expectation of the first leaf parser.
+// Errors after partial matches are kept because they are more precise.
+func Rule[Output any](name string, parse gomme.Parser[Output]) gomme.Parser[Output] {
+	return traced(name, parse).WithExpected(name)
+}
+
+// traced logs the start and end of the parser in debug output.
+func traced[Output any](name string, parse gomme.Parser[Output]) gomme.Parser[Output] {
+	tracedParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		gomme.Debugf("Rule %s - start: pos=%d", name, state.CurrentPos())
+		newState, output, err := parse.It(state)
+		if err != nil {
+			gomme.Debugf("Rule %s - failure: pos=%d, error=%q", name, err.Pos(), err.Text())
+			return newState, output, err
+		}
+		gomme.Debugf("Rule %s - success: pos=%d", name, newState.CurrentPos())
+		return newState, output, nil
+	}
+	p := gomme.NewParser[Output](parse.Expected(), tracedParse, parse.Recover)
+	p = gomme.WithAnalysis(p, gomme.Analyze(parse))
+	return gomme.WithOptimizer(p, func() gomme.Parser[Output] {
+		return traced(name, gomme.Optimize(parse))
+	})
+}
+
+// WithinBudget applies a parser but aborts it after `maxSteps` parser
+// invocations (including backtracked ones) inside of it.
+// So a pathological region of the input results in a localized error
+// at the start of the parser instead of a stalled parse.
+// Steps inside count for enclosing budgets, too.
+func WithinBudget[Output any](parse gomme.Parser[Output], maxSteps int) gomme.Parser[Output] {
+	if maxSteps <= 0 {
+		panic("WithinBudget needs a positive `maxSteps` argument")
+	}
+
+	budgetParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		newState, output, err := parse.It(state.WithinStepBudget(maxSteps))
+		if newState.StepBudgetExceeded() {
+			errState := state.NewFailure(fmt.Sprintf(
+				"%s needs more than %d parser steps", parse.Expected(), maxSteps))
+			return errState, gomme.ZeroOf[Output](), errState.CurrentError()
+		}
+		return newState.LeaveStepBudget(state), output, err
+	}
+	return gomme.NewParser[Output](parse.Expected(), budgetParse, parse.Recover)
+}
+
 // Delimited parses and discards the result from the prefix parser, then
 // parses the result of the main parser, and finally parses and discards
 // the result of the suffix parser.
@@ -114,8 +451,84 @@ func Delimited[OP, O, OS any](prefix gomme.Parser[OP], parse gomme.Parser[O], su
 		}, nil, nil)
 }
 
+// Between parses and discards the result from the open parser, then
+// parses the result of the body parser, and finally parses and discards
+// the result of the close parser just like Delimited.
+// But if the body or the close parser fail while the error is handled
+// (parsing modes `handle` and `escape`), Between recovers by moving
+// forward to the matching close delimiter (nested open and close delimiters
+// are balanced). The error is reported and parsing continues after the
+// close delimiter with the zero value as output.
+// This is the bread-and-butter recovery for blocks and parenthesized expressions.
+//
+// NOTE:
+//   - Without error recovery Between fails with the error of the body or
+//     the close parser.
+//   - If no matching close delimiter can be found, Between fails with the
+//     original error.
+func Between[OO, O, OC any](open gomme.Parser[OO], body gomme.Parser[O], close gomme.Parser[OC]) gomme.Parser[O] {
+	var zero O
+
+	betweenParse := func(state gomme.State) (gomme.State, O, *gomme.ParserError) {
+		openState, _, err := open.It(state)
+		if err != nil {
+			return state.Preserve(openState), zero, err
+		}
+
+		bodyState, output, err := body.It(openState)
+		if err != nil {
+			if handlingError(state) {
+				if skipState, ok := skipToClose(openState, open, close); ok {
+					return skipState.SaveError(err), zero, nil
+				}
+			}
+			return state.Preserve(bodyState), zero, err
+		}
+
+		closeState, _, err := close.It(bodyState)
+		if err != nil {
+			if handlingError(state) {
+				if skipState, ok := skipToClose(bodyState, open, close); ok {
+					return skipState.SaveError(err), zero, nil
+				}
+			}
+			return state.Preserve(closeState), zero, err
+		}
+		return closeState, output, nil
+	}
+	return gomme.NewParser[O]("Between", betweenParse, open.Recover)
+}
+
+// handlingError reports whether the parser is called to handle an error
+// of the error recovery.
+func handlingError(state gomme.State) bool {
+	mode := state.ParsingMode()
+	return mode == gomme.ParsingModeHandle || mode == gomme.ParsingModeEscape
+}
+
+// skipToClose moves forward until it finds the close delimiter that matches
+// the already parsed open delimiter.
+// It returns the state after the close delimiter and true if found.
+func skipToClose[OO, OC any](state gomme.State, open gomme.Parser[OO], close gomme.Parser[OC]) (gomme.State, bool) {
+	depth := 0
+	for cur := state; !cur.AtEnd(); cur = cur.Delete(1) {
+		if closeState, _, err := close.It(cur); err == nil {
+			if depth == 0 {
+				return closeState, true
+			}
+			depth--
+			continue
+		}
+		if _, _, err := open.It(cur); err == nil {
+			depth++
+		}
+	}
+	return state, false
+}
+
 // Prefixed parses and discards a result from the prefix parser. It
 // then parses a result from the main parser and returns its result.
+// Several prefixes can be given to Preceded.
 func Prefixed[OP, O any](prefix gomme.Parser[OP], parse gomme.Parser[O]) gomme.Parser[O] {
 	return MapN[OP, O, interface{}, interface{}, interface{}](
 		"Prefixed",
@@ -128,6 +541,7 @@ func Prefixed[OP, O any](prefix gomme.Parser[OP], parse gomme.Parser[O]) gomme.P
 // Suffixed parses a result from the main parser, it then
 // parses the result from the suffix parser and discards it; only
 // returning the result of the main parser.
+// Several suffixes can be given to Terminated.
 func Suffixed[O, OS any](parse gomme.Parser[O], suffix gomme.Parser[OS]) gomme.Parser[O] {
 	return MapN[O, OS, interface{}, interface{}, interface{}](
 		"Suffixed",
@@ -137,6 +551,92 @@ func Suffixed[O, OS any](parse gomme.Parser[O], suffix gomme.Parser[OS]) gomme.P
 		}, nil, nil, nil)
 }
 
+// Pair is the output of the SeparatedPair parser.
+type Pair[T1, T2 any] struct {
+	First  T1
+	Second T2
+}
+
+// SeparatedPair parses a result from the first parser, then parses and
+// discards the result of the separator parser and finally parses a result
+// from the second parser. Both results are returned as a Pair.
+func SeparatedPair[O1, OS, O2 any](
+	parse1 gomme.Parser[O1], separator gomme.Parser[OS], parse2 gomme.Parser[O2],
+) gomme.Parser[Pair[O1, O2]] {
+	return MapN[O1, OS, O2, interface{}, interface{}](
+		"SeparatedPair",
+		parse1, separator, parse2, nil, nil, 3, nil, nil,
+		func(output1 O1, _ OS, output2 O2) (Pair[O1, O2], error) {
+			return Pair[O1, O2]{First: output1, Second: output2}, nil
+		}, nil, nil)
+}
+
+// Preceded parses and discards the results of all prefix parsers in order.
+// It then parses a result from the main parser and returns its result.
+// The prefix parsers are usually created with Skip:
+//
+//	Preceded(Identifier(), Skip(String("let")), Skip(Whitespace1()))
+//
+// NOTE:
+//   - The prefixes are the last arguments but they are parsed first
+//     because Go allows variadic parameters only at the end.
+//     Prefixed is the version with a single prefix in front.
+func Preceded[O any](parse gomme.Parser[O], prefixes ...gomme.Parser[struct{}]) gomme.Parser[O] {
+	var zero O
+
+	precParse := func(state gomme.State) (gomme.State, O, *gomme.ParserError) {
+		remaining := state
+		for _, prefix := range prefixes {
+			newState, _, err := prefix.It(remaining)
+			if err != nil {
+				return state.Preserve(newState), zero, err
+			}
+			remaining = newState
+		}
+
+		newState, output, err := parse.It(remaining)
+		if err != nil {
+			return state.Preserve(newState), zero, err
+		}
+		return newState, output, nil
+	}
+
+	recoverer := parse.Recover
+	if len(prefixes) > 0 {
+		recoverer = prefixes[0].Recover
+	}
+	return gomme.NewParser[O]("Preceded", precParse, recoverer)
+}
+
+// Terminated parses a result from the main parser, it then parses
+// the results from all suffix parsers in order and discards them;
+// only returning the result of the main parser.
+// The suffix parsers are usually created with Skip:
+//
+//	Terminated(Assignment(), Skip(Whitespace0()), Skip(Char(';')))
+//
+// Suffixed is the version with a single suffix of any type.
+func Terminated[O any](parse gomme.Parser[O], suffixes ...gomme.Parser[struct{}]) gomme.Parser[O] {
+	var zero O
+
+	termParse := func(state gomme.State) (gomme.State, O, *gomme.ParserError) {
+		remaining, output, err := parse.It(state)
+		if err != nil {
+			return state.Preserve(remaining), zero, err
+		}
+
+		for _, suffix := range suffixes {
+			newState, _, sErr := suffix.It(remaining)
+			if sErr != nil {
+				return state.Preserve(newState), zero, sErr
+			}
+			remaining = newState
+		}
+		return remaining, output, nil
+	}
+	return gomme.NewParser[O]("Terminated", termParse, parse.Recover)
+}
+
 // Map applies a function to the successful result of 1 parser.
 // Arbitrary complex data structures can be built with Map and Map2 alone.
 // The other MapX parsers are provided for convenience.
@@ -187,3 +687,81 @@ func Map5[PO1, PO2, PO3, PO4, PO5 any, MO any](
 ) gomme.Parser[MO] {
 	return MapN("Map5", parse1, parse2, parse3, parse4, parse5, 5, nil, nil, nil, nil, fn)
 }
+
+// Map2Pure is like Map2 but `fn` can't fail.
+// So simple combinations of values don't need error handling.
+func Map2Pure[PO1, PO2 any, MO any](parse1 gomme.Parser[PO1], parse2 gomme.Parser[PO2], fn func(PO1, PO2) MO,
+) gomme.Parser[MO] {
+	return mapNPure[PO1, PO2, interface{}, interface{}, interface{}](
+		"Map2Pure",
+		parse1, parse2, nil, nil, nil, 2, pureFuncs[PO1, PO2, interface{}, interface{}, interface{}, MO]{fn2: fn})
+}
+
+// Map3Pure is like Map3 but `fn` can't fail.
+func Map3Pure[PO1, PO2, PO3 any, MO any](parse1 gomme.Parser[PO1], parse2 gomme.Parser[PO2], parse3 gomme.Parser[PO3],
+	fn func(PO1, PO2, PO3) MO,
+) gomme.Parser[MO] {
+	return mapNPure[PO1, PO2, PO3, interface{}, interface{}](
+		"Map3Pure",
+		parse1, parse2, parse3, nil, nil, 3, pureFuncs[PO1, PO2, PO3, interface{}, interface{}, MO]{fn3: fn})
+}
+
+// Map4Pure is like Map4 but `fn` can't fail.
+func Map4Pure[PO1, PO2, PO3, PO4 any, MO any](parse1 gomme.Parser[PO1], parse2 gomme.Parser[PO2], parse3 gomme.Parser[PO3], parse4 gomme.Parser[PO4],
+	fn func(PO1, PO2, PO3, PO4) MO,
+) gomme.Parser[MO] {
+	return mapNPure[PO1, PO2, PO3, PO4, interface{}](
+		"Map4Pure",
+		parse1, parse2, parse3, parse4, nil, 4, pureFuncs[PO1, PO2, PO3, PO4, interface{}, MO]{fn4: fn})
+}
+
+// Map5Pure is like Map5 but `fn` can't fail.
+func Map5Pure[PO1, PO2, PO3, PO4, PO5 any, MO any](
+	parse1 gomme.Parser[PO1], parse2 gomme.Parser[PO2], parse3 gomme.Parser[PO3], parse4 gomme.Parser[PO4], parse5 gomme.Parser[PO5],
+	fn func(PO1, PO2, PO3, PO4, PO5) MO,
+) gomme.Parser[MO] {
+	return mapNPure("Map5Pure", parse1, parse2, parse3, parse4, parse5, 5, pureFuncs[PO1, PO2, PO3, PO4, PO5, MO]{fn5: fn})
+}
+
+// Lift applies the pure function `f` to the result of the parser.
+// Together with Lift2 to Lift5 and Apply it allows to define grammars in
+// the applicative style known from parsec-like libraries.
+func Lift[A, R any](f func(A) R, pa gomme.Parser[A]) gomme.Parser[R] {
+	return Map(pa, func(a A) (R, error) {
+		return f(a), nil
+	})
+}
+
+// Lift2 applies the pure function `f` to the results of 2 parsers.
+func Lift2[A, B, R any](f func(A, B) R, pa gomme.Parser[A], pb gomme.Parser[B]) gomme.Parser[R] {
+	return Map2Pure(pa, pb, f)
+}
+
+// Lift3 applies the pure function `f` to the results of 3 parsers.
+func Lift3[A, B, C, R any](f func(A, B, C) R, pa gomme.Parser[A], pb gomme.Parser[B], pc gomme.Parser[C],
+) gomme.Parser[R] {
+	return Map3Pure(pa, pb, pc, f)
+}
+
+// Lift4 applies the pure function `f` to the results of 4 parsers.
+func Lift4[A, B, C, D, R any](f func(A, B, C, D) R,
+	pa gomme.Parser[A], pb gomme.Parser[B], pc gomme.Parser[C], pd gomme.Parser[D],
+) gomme.Parser[R] {
+	return Map4Pure(pa, pb, pc, pd, f)
+}
+
+// Lift5 applies the pure function `f` to the results of 5 parsers.
+func Lift5[A, B, C, D, E, R any](f func(A, B, C, D, E) R,
+	pa gomme.Parser[A], pb gomme.Parser[B], pc gomme.Parser[C], pd gomme.Parser[D], pe gomme.Parser[E],
+) gomme.Parser[R] {
+	return Map5Pure(pa, pb, pc, pd, pe, f)
+}
+
+// Apply applies the function parsed by `pf` to the result of `pa`
+// (`<*>` in Haskell).
+// Curried functions can be applied to any number of parsers this way.
+func Apply[A, R any](pf gomme.Parser[func(A) R], pa gomme.Parser[A]) gomme.Parser[R] {
+	return Map2Pure(pf, pa, func(f func(A) R, a A) R {
+		return f(a)
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"github.com/oleiade/gomme"
 	"strconv"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestOptional(t *testing.T) {
@@ -85,6 +87,239 @@ func BenchmarkOptional(b *testing.B) {
 	}
 }
 
+func TestOptionalOK(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[Option[string]]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    Option[string]
+		wantRemaining string
+	}{
+		{
+			name:  "matching parser should succeed",
+			input: "123;",
+			args: args{
+				parser: OptionalOK(Digit1()),
+			},
+			wantErr:       false,
+			wantOutput:    Option[string]{Value: "123", OK: true},
+			wantRemaining: ";",
+		},
+		{
+			name:  "no match should succeed",
+			input: "abc;",
+			args: args{
+				parser: OptionalOK(Digit1()),
+			},
+			wantErr:       false,
+			wantOutput:    Option[string]{},
+			wantRemaining: "abc;",
+		},
+		{
+			name:  "empty input should succeed",
+			input: "",
+			args: args{
+				parser: OptionalOK(Digit1()),
+			},
+			wantErr:       false,
+			wantOutput:    Option[string]{},
+			wantRemaining: "",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %v, want output %v", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkOptionalOK(b *testing.B) {
+	parser := OptionalOK(Digit1())
+	input := gomme.NewFromString(1, nil, -1, "123;")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestOrDefault(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "matching parser should succeed",
+			input: "123;",
+			args: args{
+				parser: OrDefault(Digit1(), "0"),
+			},
+			wantErr:       false,
+			wantOutput:    "123",
+			wantRemaining: ";",
+		},
+		{
+			name:  "no match should return default",
+			input: "abc;",
+			args: args{
+				parser: OrDefault(Digit1(), "0"),
+			},
+			wantErr:       false,
+			wantOutput:    "0",
+			wantRemaining: "abc;",
+		},
+		{
+			name:  "empty input should return default",
+			input: "",
+			args: args{
+				parser: OrDefault(Digit1(), "0"),
+			},
+			wantErr:       false,
+			wantOutput:    "0",
+			wantRemaining: "",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkOrDefault(b *testing.B) {
+	parser := OrDefault(Digit1(), "0")
+	input := gomme.NewFromString(1, nil, -1, "123;")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestSaveSpotScope(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "matching parser should succeed",
+			input: "a1;",
+			args: args{
+				parser: FirstSuccessful(
+					SaveSpotScope(Prefixed(gomme.SaveSpot(String("a")), Digit1())),
+					String("ab"),
+				),
+			},
+			wantErr:       false,
+			wantOutput:    "1",
+			wantRemaining: ";",
+		},
+		{
+			name:  "failing scoped parser should allow backtracking",
+			input: "ab;",
+			args: args{
+				parser: FirstSuccessful(
+					SaveSpotScope(Prefixed(gomme.SaveSpot(String("a")), Digit1())),
+					String("ab"),
+				),
+			},
+			wantErr:       false,
+			wantOutput:    "ab",
+			wantRemaining: ";",
+		},
+		{
+			name:  "no matching parser should fail",
+			input: "ac;",
+			args: args{
+				parser: FirstSuccessful(
+					SaveSpotScope(Prefixed(gomme.SaveSpot(String("a")), Digit1())),
+					String("ab"),
+				),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "ac;",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
 func TestPeek(t *testing.T) {
 	t.Parallel()
 
@@ -101,23 +336,1054 @@ func TestPeek(t *testing.T) {
 	}{
 		{
 			name:  "matching parser should succeed",
-			input: "abcd;",
+			input: "abcd;",
+			args: args{
+				parser: Peek(Alpha1()),
+			},
+			wantErr:       false,
+			wantOutput:    "abcd",
+			wantRemaining: "abcd;",
+		},
+		{
+			name:  "non matching parser should fail",
+			input: "123;",
+			args: args{
+				parser: Peek(Alpha1()),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "123;",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkPeek(b *testing.B) {
+	parser := Peek(Alpha1())
+	input := gomme.NewFromString(1, nil, -1, "abcd;")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestRecognize(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[[]byte]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "matching parser should succeed",
+			input: "123abc",
+			args: args{
+				parser: Recognize(Map2(Digit1(), Alpha1(), pairMapFunc)),
+			},
+			wantErr:       false,
+			wantOutput:    "123abc",
+			wantRemaining: "",
+		},
+		{
+			name:  "no prefix match should fail",
+			input: "abc",
+			args: args{
+				parser: Recognize(Map2(Digit1(), Alpha1(), pairMapFunc)),
+			},
+			wantErr:       true,
+			wantOutput:    "abc",
+			wantRemaining: "",
+		},
+		{
+			name:  "no postfix match should fail",
+			input: "123",
+			args: args{
+				parser: Recognize(Map2(Digit1(), Alpha1(), pairMapFunc)),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
+		},
+		{
+			name:  "empty input should fail",
+			input: "",
+			args: args{
+				parser: Recognize(Map2(Digit1(), Alpha1(), pairMapFunc)),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if string(gotResult) != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", string(gotResult), tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkRecognize(b *testing.B) {
+	parser := Recognize(Map2(Digit1(), Alpha1(), pairMapFunc))
+	input := gomme.NewFromString(1, nil, -1, "123abc")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestChecksummed(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "matching checksum should succeed",
+			input: "abc#3;",
+			args: args{
+				parser: Checksummed(Alpha1(), Prefixed(Char('#'), Int64(false, 10)), lengthChecksum),
+			},
+			wantErr:       false,
+			wantOutput:    "abc",
+			wantRemaining: ";",
+		},
+		{
+			name:  "wrong checksum should fail",
+			input: "abc#4;",
+			args: args{
+				parser: Checksummed(Alpha1(), Prefixed(Char('#'), Int64(false, 10)), lengthChecksum),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "abc#4;",
+		},
+		{
+			name:  "missing checksum should fail",
+			input: "abc;",
+			args: args{
+				parser: Checksummed(Alpha1(), Prefixed(Char('#'), Int64(false, 10)), lengthChecksum),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "abc;",
+		},
+		{
+			name:  "no body should fail",
+			input: "#0",
+			args: args{
+				parser: Checksummed(Alpha1(), Prefixed(Char('#'), Int64(false, 10)), lengthChecksum),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "#0",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkChecksummed(b *testing.B) {
+	parser := Checksummed(Alpha1(), Prefixed(Char('#'), Int64(false, 10)), lengthChecksum)
+	input := gomme.NewFromString(1, nil, -1, "abcdef#6")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func lengthChecksum(body []byte, sum int64) error {
+	if int64(len(body)) != sum {
+		return errors.New("checksum " + strconv.FormatInt(sum, 10) + " doesn't match body")
+	}
+	return nil
+}
+
+func TestParseWith(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "matching inner parser should succeed",
+			input: "\"123\" x",
+			args: args{
+				parser: ParseWith(GoString(), Digit1()),
+			},
+			wantErr:       false,
+			wantOutput:    "123",
+			wantRemaining: " x",
+		},
+		{
+			name:  "escaped output should succeed",
+			input: "\"\\x31\" x",
+			args: args{
+				parser: ParseWith(GoString(), Digit1()),
+			},
+			wantErr:       false,
+			wantOutput:    "1",
+			wantRemaining: " x",
+		},
+		{
+			name:  "inner parser not consuming everything should fail",
+			input: "\"12a\"",
+			args: args{
+				parser: ParseWith(GoString(), Digit1()),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "\"12a\"",
+		},
+		{
+			name:  "failing inner parser should fail",
+			input: "\"abc\"",
+			args: args{
+				parser: ParseWith(GoString(), Digit1()),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "\"abc\"",
+		},
+		{
+			name:  "failing outer parser should fail",
+			input: "123",
+			args: args{
+				parser: ParseWith(GoString(), Digit1()),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "123",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkParseWith(b *testing.B) {
+	parser := ParseWith(GoString(), Digit1())
+	input := gomme.NewFromString(1, nil, -1, "\"123\" x")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestParseWithErrorPosition(t *testing.T) {
+	t.Parallel()
+
+	newState, _ := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, `x = "12a"`),
+		Prefixed(String("x = "), ParseWith(GoString(), Digit1())))
+	if !newState.HasError() {
+		t.Fatalf("got no error, want error")
+	}
+	if msg := newState.Errors().Error(); !strings.Contains(msg, "[1:8]") {
+		t.Errorf("got error %q, want error at [1:8]", msg)
+	}
+}
+
+func TestParseWithSemanticError(t *testing.T) {
+	t.Parallel()
+
+	digits := Digit1()
+	evil := gomme.NewParser[string](digits.Expected(), func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		newState, output, err := digits.It(state)
+		if err == nil && output == "666" {
+			newState = state.NewSemanticError("evil number").MoveBy(len(output))
+		}
+		return newState, output, err
+	}, digits.Recover)
+
+	output, err := gomme.Parse(Prefixed(String("x = "), ParseWith(GoString(), evil)), `x = "666"`)
+	if err == nil || !strings.Contains(err.Error(), "evil number [1:6]") {
+		t.Errorf("got (%q, %v), want semantic error at [1:6]", output, err)
+	}
+}
+
+func TestInclude(t *testing.T) {
+	t.Parallel()
+
+	session := gomme.NewSession(fstest.MapFS{
+		"main.cfg":     {Data: []byte(`"sub/ok.cfg" x`)},
+		"bad.cfg":      {Data: []byte(`"sub/bad.cfg" x`)},
+		"missing.cfg":  {Data: []byte(`"nope.cfg" x`)},
+		"evil.cfg":     {Data: []byte(`"sub/evil.cfg" x`)},
+		"sub/ok.cfg":   {Data: []byte("123")},
+		"sub/bad.cfg":  {Data: []byte("12a")},
+		"sub/evil.cfg": {Data: []byte("666")},
+	})
+	digits := Digit1()
+	content := gomme.NewParser[string](digits.Expected(), func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		newState, output, err := digits.It(state)
+		if err == nil && output == "666" {
+			newState = state.NewSemanticError("evil number").MoveBy(len(output))
+		}
+		return newState, output, err
+	}, digits.Recover)
+	testCases := []struct {
+		name          string
+		file          string
+		wantErr       string
+		wantOutput    string
+		wantRemaining string
+	}{
+		{name: "valid included file should succeed", file: "main.cfg", wantOutput: "123", wantRemaining: " x"},
+		{name: "invalid included file should name it", file: "bad.cfg", wantErr: "[sub/bad.cfg:1:3]"},
+		{name: "missing included file should fail", file: "missing.cfg", wantErr: "nope.cfg"},
+		{name: "semantic error in included file should be reported", file: "evil.cfg", wantErr: "evil number [sub/evil.cfg:1:1]"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			state, err := session.Open(tc.file, false)
+			if err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			newState, gotResult := gomme.RunOnState(state, Include(GoString(), content))
+			if tc.wantErr != "" {
+				if msg := newState.Err(); msg == nil || !strings.Contains(msg.Error(), tc.wantErr) {
+					t.Errorf("got error %v, want error containing %q", msg, tc.wantErr)
+				}
+				return
+			}
+			if newState.HasError() {
+				t.Fatalf("got unexpected error: %v", newState.Errors())
+			}
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+			if remaining := newState.CurrentString(); remaining != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remaining, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestSkip(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[struct{}]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    struct{}
+		wantRemaining string
+	}{
+		{
+			name:  "matching parser should succeed",
+			input: " \t abc",
+			args: args{
+				parser: Skip(Whitespace1()),
+			},
+			wantErr:       false,
+			wantOutput:    struct{}{},
+			wantRemaining: "abc",
+		},
+		{
+			name:  "no match should fail",
+			input: "abc",
+			args: args{
+				parser: Skip(Whitespace1()),
+			},
+			wantErr:       true,
+			wantOutput:    struct{}{},
+			wantRemaining: "abc",
+		},
+		{
+			name:  "empty input should fail",
+			input: "",
+			args: args{
+				parser: Skip(Whitespace1()),
+			},
+			wantErr:       true,
+			wantOutput:    struct{}{},
+			wantRemaining: "",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %v, want output %v", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkSkip(b *testing.B) {
+	parser := Skip(Whitespace1())
+	input := gomme.NewFromString(1, nil, -1, " \t abc")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestAssign(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[int]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    int
+		wantRemaining string
+	}{
+		{
+			name:  "matching parser should succeed",
+			input: "abcd",
+			args: args{
+				parser: Assign(1234, Alpha1()),
+			},
+			wantErr:       false,
+			wantOutput:    1234,
+			wantRemaining: "",
+		},
+		{
+			name:  "non matching parser should fail",
+			input: "123abcd;",
+			args: args{
+				parser: Assign(1234, Alpha1()),
+			},
+			wantErr:       true,
+			wantOutput:    1234,
+			wantRemaining: "123abcd;",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %d, want output %d", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkAssign(b *testing.B) {
+	parser := Assign(1234, Alpha1())
+	input := gomme.NewFromString(1, nil, -1, "abcd")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "matching integer should succeed",
+			input: "i123;",
+			args: args{
+				parser: Switch(OneOf("i", "s"), switchFunc),
+			},
+			wantErr:       false,
+			wantOutput:    "123",
+			wantRemaining: ";",
+		},
+		{
+			name:  "matching letters should succeed",
+			input: "sabc;",
+			args: args{
+				parser: Switch(OneOf("i", "s"), switchFunc),
+			},
+			wantErr:       false,
+			wantOutput:    "abc",
+			wantRemaining: ";",
+		},
+		{
+			name:  "non matching payload should fail",
+			input: "iabc;",
+			args: args{
+				parser: Switch(OneOf("i", "s"), switchFunc),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "iabc;",
+		},
+		{
+			name:  "no choice parser should fail",
+			input: "x123;",
+			args: args{
+				parser: Switch(OneOf("i", "s"), switchFunc),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "x123;",
+		},
+		{
+			name:  "no parser returned should fail",
+			input: "d123;",
+			args: args{
+				parser: Switch(OneOf("i", "s", "d"), switchFunc),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "d123;",
+		},
+		{
+			name:  "empty input should fail",
+			input: "",
+			args: args{
+				parser: Switch(OneOf("i", "s"), switchFunc),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkSwitch(b *testing.B) {
+	parser := Switch(OneOf("i", "s"), switchFunc)
+	input := gomme.NewFromString(1, nil, -1, "i123;")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+var switchDigits, switchLetters = Digit1(), Alpha1()
+
+func switchFunc(kind string) gomme.Parser[string] {
+	switch kind {
+	case "i":
+		return switchDigits
+	case "s":
+		return switchLetters
+	}
+	return nil
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "valid value should succeed",
+			input: "123;",
+			args: args{
+				parser: Verify(Digit1(), func(s string) bool { return len(s) <= 3 }, "at most 3 digits allowed"),
+			},
+			wantErr:       false,
+			wantOutput:    "123",
+			wantRemaining: ";",
+		},
+		{
+			name:  "invalid value should fail",
+			input: "1234;",
+			args: args{
+				parser: Verify(Digit1(), func(s string) bool { return len(s) <= 3 }, "at most 3 digits allowed"),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "1234;",
+		},
+		{
+			name:  "non matching parser should fail",
+			input: "abc;",
+			args: args{
+				parser: Verify(Digit1(), func(s string) bool { return len(s) <= 3 }, "at most 3 digits allowed"),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "abc;",
+		},
+		{
+			name:  "empty input should fail",
+			input: "",
+			args: args{
+				parser: Verify(Digit1(), func(s string) bool { return len(s) <= 3 }, "at most 3 digits allowed"),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	parser := Verify(Digit1(), func(s string) bool { return len(s) <= 3 }, "at most 3 digits allowed")
+	input := gomme.NewFromString(1, nil, -1, "123;")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestMapErr(t *testing.T) {
+	t.Parallel()
+
+	parser := MapErr(Digit1(), func(err *gomme.ParserError) *gomme.ParserError {
+		return err.WithText("expected a port number")
+	})
+
+	_, err := gomme.RunOnString("80", parser)
+	if err != nil {
+		t.Errorf("got unexpected error: %v", err)
+	}
+
+	_, err = gomme.RunOnString("http", parser)
+	if err == nil {
+		t.Fatalf("got no error for invalid input")
+	}
+	if !strings.HasPrefix(err.Error(), "expected a port number [1:1]") {
+		t.Errorf("got error %q, want it to start with %q", err.Error(), "expected a port number [1:1]")
+	}
+}
+
+func TestAssert(t *testing.T) {
+	t.Parallel()
+
+	noLeadingZero := Assert(func(state gomme.State) bool {
+		return !strings.HasPrefix(state.CurrentString(), "0")
+	}, "number with leading zero")
+	parser := Map2Pure(noLeadingZero, Digit1(), func(_ struct{}, digits string) string {
+		return digits
+	})
+
+	output, err := gomme.RunOnString("123", parser)
+	if err != nil || output != "123" {
+		t.Errorf("got (%q, %v), want (%q, nil)", output, err, "123")
+	}
+
+	newState, output := gomme.RunOnState(gomme.NewFromString("0123", false), parser)
+	if output != "0123" {
+		t.Errorf("got output %q, want %q", output, "0123")
+	}
+	err = newState.Errors()
+	if err == nil || !strings.HasPrefix(err.Error(), "number with leading zero [1:1]") {
+		t.Errorf("got error %v, want semantic error at the start", err)
+	}
+}
+
+func TestRule(t *testing.T) {
+	t.Parallel()
+
+	number := Rule("number", Digit1())
+	pair := Rule("pair", Sequence(Digit1(), String(","), Digit1()))
+
+	if got := Dispatch(number, Rule("word", Alpha1())).Expected(); got != "one of: number, word" {
+		t.Errorf("got expectation %q, want %q", got, "one of: number, word")
+	}
+
+	_, err := gomme.RunOnString("x", number)
+	if err == nil || !strings.HasPrefix(err.Error(), "expected number [1:1]") {
+		t.Errorf("got error %v, want it to start with %q", err, "expected number [1:1]")
+	}
+
+	_, err = gomme.RunOnString("1,x", pair)
+	if err == nil || !strings.HasPrefix(err.Error(), "expected digit") {
+		t.Errorf("got error %v, want the error of the partial match", err)
+	}
+}
+
+func TestWithinBudget(t *testing.T) {
+	t.Parallel()
+
+	parser := WithinBudget(Many0(Char('a')), 10)
+
+	output, err := gomme.RunOnString("aaa", parser)
+	if err != nil || len(output) != 3 {
+		t.Errorf("got (%q, %v), want 3 runes and no error", output, err)
+	}
+
+	_, err = gomme.RunOnString(strings.Repeat("a", 100), parser)
+	if err == nil || !strings.Contains(err.Error(), "needs more than 10 parser steps [1:1]") {
+		t.Errorf("got error %v, want budget error at the start", err)
+	}
+}
+
+func TestDelimited(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "matching parser should succeed",
+			input: "+1\r\n",
+			args: args{
+				parser: Delimited(Char('+'), Digit1(), CRLF()),
+			},
+			wantErr:       false,
+			wantOutput:    "1",
+			wantRemaining: "",
+		},
+		{
+			name:  "no prefix match should fail",
+			input: "1\r\n",
+			args: args{
+				parser: Delimited(Char('+'), Digit1(), CRLF()),
+			},
+			wantErr:       true,
+			wantOutput:    "1",
+			wantRemaining: "",
+		},
+		{
+			name:  "no parser match should fail",
+			input: "+\r\n",
+			args: args{
+				parser: Delimited(Char('+'), Digit1(), CRLF()),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
+		},
+		{
+			name:  "no suffix match should fail",
+			input: "+1",
+			args: args{
+				parser: Delimited(Char('+'), Digit1(), CRLF()),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
+		},
+		{
+			name:  "empty input should fail",
+			input: "",
+			args: args{
+				parser: Delimited(Char('+'), Digit1(), CRLF()),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkDelimited(b *testing.B) {
+	parser := Delimited(Char('+'), Digit1(), CRLF())
+	input := gomme.NewFromString(1, nil, -1, "+1\r\n")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		recover       bool
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "matching parser should succeed",
+			input: "(123)abc",
 			args: args{
-				parser: Peek(Alpha1()),
+				parser: Between(Char('('), Digit1(), Char(')')),
 			},
 			wantErr:       false,
-			wantOutput:    "abcd",
-			wantRemaining: "abcd;",
+			wantOutput:    "123",
+			wantRemaining: "abc",
 		},
 		{
-			name:  "non matching parser should fail",
-			input: "123;",
+			name:    "invalid body should recover at close",
+			input:   "(1a3)abc",
+			recover: true,
 			args: args{
-				parser: Peek(Alpha1()),
+				parser: Between(Char('('), Digit1(), Char(')')),
 			},
 			wantErr:       true,
 			wantOutput:    "",
-			wantRemaining: "123;",
+			wantRemaining: "abc",
+		},
+		{
+			name:    "invalid body should recover at balanced close",
+			input:   "(a(1)b)abc",
+			recover: true,
+			args: args{
+				parser: Between(Char('('), Digit1(), Char(')')),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "abc",
+		},
+		{
+			name:  "invalid body without recovery should fail",
+			input: "(1a3)abc",
+			args: args{
+				parser: Between(Char('('), Digit1(), Char(')')),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "(1a3)abc",
+		},
+		{
+			name:  "missing close should fail",
+			input: "(123abc",
+			args: args{
+				parser: Between(Char('('), Digit1(), Char(')')),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "(123abc",
+		},
+		{
+			name:  "no open should fail",
+			input: "123)abc",
+			args: args{
+				parser: Between(Char('('), Digit1(), Char(')')),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "123)abc",
+		},
+		{
+			name:  "empty input should fail",
+			input: "",
+			args: args{
+				parser: Between(Char('('), Digit1(), Char(')')),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
 		},
 	}
 	for _, tc := range testCases {
@@ -126,7 +1392,7 @@ func TestPeek(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, tc.recover), tc.args.parser)
 			if newState.HasError() != tc.wantErr {
 				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
 			}
@@ -143,9 +1409,9 @@ func TestPeek(t *testing.T) {
 	}
 }
 
-func BenchmarkPeek(b *testing.B) {
-	parser := Peek(Alpha1())
-	input := gomme.NewFromString(1, nil, -1, "abcd;")
+func BenchmarkBetween(b *testing.B) {
+	parser := Between(Char('('), Digit1(), Char(')'))
+	input := gomme.NewFromString(1, nil, -1, "(123)abc")
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -153,11 +1419,11 @@ func BenchmarkPeek(b *testing.B) {
 	}
 }
 
-func TestRecognize(t *testing.T) {
+func TestPrefixed(t *testing.T) {
 	t.Parallel()
 
 	type args struct {
-		parser gomme.Parser[[]byte]
+		parser gomme.Parser[string]
 	}
 	testCases := []struct {
 		name          string
@@ -169,29 +1435,39 @@ func TestRecognize(t *testing.T) {
 	}{
 		{
 			name:  "matching parser should succeed",
-			input: "123abc",
+			input: "+123",
 			args: args{
-				parser: Recognize(Map2(Digit1(), Alpha1(), pairMapFunc)),
+				parser: Prefixed(Char('+'), Digit1()),
 			},
 			wantErr:       false,
-			wantOutput:    "123abc",
+			wantOutput:    "123",
 			wantRemaining: "",
 		},
+		{
+			name:  "several skipped prefixes should succeed",
+			input: "let 123;",
+			args: args{
+				parser: Prefixed(Sequence(Skip(String("let")), Skip(Whitespace1())), Digit1()),
+			},
+			wantErr:       false,
+			wantOutput:    "123",
+			wantRemaining: ";",
+		},
 		{
 			name:  "no prefix match should fail",
-			input: "abc",
+			input: "+123",
 			args: args{
-				parser: Recognize(Map2(Digit1(), Alpha1(), pairMapFunc)),
+				parser: Prefixed(Char('-'), Digit1()),
 			},
 			wantErr:       true,
-			wantOutput:    "abc",
-			wantRemaining: "",
+			wantOutput:    "",
+			wantRemaining: "123",
 		},
 		{
-			name:  "no postfix match should fail",
-			input: "123",
+			name:  "no parser match should fail",
+			input: "+",
 			args: args{
-				parser: Recognize(Map2(Digit1(), Alpha1(), pairMapFunc)),
+				parser: Prefixed(Char('+'), Digit1()),
 			},
 			wantErr:       true,
 			wantOutput:    "",
@@ -201,7 +1477,7 @@ func TestRecognize(t *testing.T) {
 			name:  "empty input should fail",
 			input: "",
 			args: args{
-				parser: Recognize(Map2(Digit1(), Alpha1(), pairMapFunc)),
+				parser: Prefixed(Char('+'), Digit1()),
 			},
 			wantErr:       true,
 			wantOutput:    "",
@@ -219,8 +1495,8 @@ func TestRecognize(t *testing.T) {
 				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
 			}
 
-			if string(gotResult) != tc.wantOutput {
-				t.Errorf("got output %q, want output %q", string(gotResult), tc.wantOutput)
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
 			}
 
 			remainingString := newState.CurrentString()
@@ -231,9 +1507,9 @@ func TestRecognize(t *testing.T) {
 	}
 }
 
-func BenchmarkRecognize(b *testing.B) {
-	parser := Recognize(Map2(Digit1(), Alpha1(), pairMapFunc))
-	input := gomme.NewFromString(1, nil, -1, "123abc")
+func BenchmarkPrefixed(b *testing.B) {
+	parser := Prefixed(Char('+'), Digit1())
+	input := gomme.NewFromString(1, nil, -1, "+123")
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -241,39 +1517,59 @@ func BenchmarkRecognize(b *testing.B) {
 	}
 }
 
-func TestAssign(t *testing.T) {
+func TestSuffixed(t *testing.T) {
 	t.Parallel()
 
 	type args struct {
-		parser gomme.Parser[int]
+		parser gomme.Parser[string]
 	}
 	testCases := []struct {
 		name          string
 		args          args
 		input         string
 		wantErr       bool
-		wantOutput    int
+		wantOutput    string
 		wantRemaining string
 	}{
 		{
 			name:  "matching parser should succeed",
-			input: "abcd",
+			input: "1+23",
 			args: args{
-				parser: Assign(1234, Alpha1()),
+				parser: Suffixed(Digit1(), Char('+')),
 			},
 			wantErr:       false,
-			wantOutput:    1234,
+			wantOutput:    "1",
+			wantRemaining: "23",
+		},
+		{
+			name:  "no suffix match should fail",
+			input: "1-23",
+			args: args{
+				parser: Suffixed(Digit1(), Char('+')),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "-23",
+		},
+		{
+			name:  "no parser match should fail",
+			input: "+",
+			args: args{
+				parser: Suffixed(Digit1(), Char('+')),
+			},
+			wantErr:       true,
+			wantOutput:    "",
 			wantRemaining: "",
 		},
 		{
-			name:  "non matching parser should fail",
-			input: "123abcd;",
+			name:  "empty input should fail",
+			input: "",
 			args: args{
-				parser: Assign(1234, Alpha1()),
+				parser: Suffixed(Digit1(), Char('+')),
 			},
 			wantErr:       true,
-			wantOutput:    1234,
-			wantRemaining: "123abcd;",
+			wantOutput:    "",
+			wantRemaining: "",
 		},
 	}
 	for _, tc := range testCases {
@@ -288,7 +1584,7 @@ func TestAssign(t *testing.T) {
 			}
 
 			if gotResult != tc.wantOutput {
-				t.Errorf("got output %d, want output %d", gotResult, tc.wantOutput)
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
 			}
 
 			remainingString := newState.CurrentString()
@@ -299,9 +1595,9 @@ func TestAssign(t *testing.T) {
 	}
 }
 
-func BenchmarkAssign(b *testing.B) {
-	parser := Assign(1234, Alpha1())
-	input := gomme.NewFromString(1, nil, -1, "abcd")
+func BenchmarkTerminated(b *testing.B) {
+	parser := Suffixed(Digit1(), Char('+'))
+	input := gomme.NewFromString(1, nil, -1, "123+")
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -309,68 +1605,58 @@ func BenchmarkAssign(b *testing.B) {
 	}
 }
 
-func TestDelimited(t *testing.T) {
+func TestSeparatedPair(t *testing.T) {
 	t.Parallel()
 
 	type args struct {
-		parser gomme.Parser[string]
+		parser gomme.Parser[Pair[string, string]]
 	}
 	testCases := []struct {
 		name          string
 		args          args
 		input         string
 		wantErr       bool
-		wantOutput    string
+		wantOutput    Pair[string, string]
 		wantRemaining string
 	}{
 		{
-			name:  "matching parser should succeed",
-			input: "+1\r\n",
+			name:  "matching parsers should succeed",
+			input: "abc=123;",
 			args: args{
-				parser: Delimited(Char('+'), Digit1(), CRLF()),
+				parser: SeparatedPair(Alpha1(), Char('='), Digit1()),
 			},
 			wantErr:       false,
-			wantOutput:    "1",
-			wantRemaining: "",
+			wantOutput:    Pair[string, string]{First: "abc", Second: "123"},
+			wantRemaining: ";",
 		},
 		{
-			name:  "no prefix match should fail",
-			input: "1\r\n",
-			args: args{
-				parser: Delimited(Char('+'), Digit1(), CRLF()),
-			},
-			wantErr:       true,
-			wantOutput:    "1",
-			wantRemaining: "",
-		},
-		{
-			name:  "no parser match should fail",
-			input: "+\r\n",
+			name:  "missing separator should fail",
+			input: "abc123;",
 			args: args{
-				parser: Delimited(Char('+'), Digit1(), CRLF()),
+				parser: SeparatedPair(Alpha1(), Char('='), Digit1()),
 			},
 			wantErr:       true,
-			wantOutput:    "",
-			wantRemaining: "",
+			wantOutput:    Pair[string, string]{},
+			wantRemaining: "abc123;",
 		},
 		{
-			name:  "no suffix match should fail",
-			input: "+1",
+			name:  "missing second should fail",
+			input: "abc=;",
 			args: args{
-				parser: Delimited(Char('+'), Digit1(), CRLF()),
+				parser: SeparatedPair(Alpha1(), Char('='), Digit1()),
 			},
 			wantErr:       true,
-			wantOutput:    "",
-			wantRemaining: "",
+			wantOutput:    Pair[string, string]{},
+			wantRemaining: "abc=;",
 		},
 		{
 			name:  "empty input should fail",
 			input: "",
 			args: args{
-				parser: Delimited(Char('+'), Digit1(), CRLF()),
+				parser: SeparatedPair(Alpha1(), Char('='), Digit1()),
 			},
 			wantErr:       true,
-			wantOutput:    "",
+			wantOutput:    Pair[string, string]{},
 			wantRemaining: "",
 		},
 	}
@@ -386,7 +1672,7 @@ func TestDelimited(t *testing.T) {
 			}
 
 			if gotResult != tc.wantOutput {
-				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+				t.Errorf("got output %v, want output %v", gotResult, tc.wantOutput)
 			}
 
 			remainingString := newState.CurrentString()
@@ -397,9 +1683,9 @@ func TestDelimited(t *testing.T) {
 	}
 }
 
-func BenchmarkDelimited(b *testing.B) {
-	parser := Delimited(Char('+'), Digit1(), CRLF())
-	input := gomme.NewFromString(1, nil, -1, "+1\r\n")
+func BenchmarkSeparatedPair(b *testing.B) {
+	parser := SeparatedPair(Alpha1(), Char('='), Digit1())
+	input := gomme.NewFromString(1, nil, -1, "abc=123;")
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -407,7 +1693,7 @@ func BenchmarkDelimited(b *testing.B) {
 	}
 }
 
-func TestPrefixed(t *testing.T) {
+func TestPreceded(t *testing.T) {
 	t.Parallel()
 
 	type args struct {
@@ -422,40 +1708,40 @@ func TestPrefixed(t *testing.T) {
 		wantRemaining string
 	}{
 		{
-			name:  "matching parser should succeed",
-			input: "+123",
+			name:  "matching parsers should succeed",
+			input: "let 123;",
 			args: args{
-				parser: Prefixed(Char('+'), Digit1()),
+				parser: Preceded(Digit1(), Skip(String("let")), Skip(Whitespace1())),
 			},
 			wantErr:       false,
 			wantOutput:    "123",
-			wantRemaining: "",
+			wantRemaining: ";",
 		},
 		{
-			name:  "no prefix match should fail",
-			input: "+123",
+			name:  "missing prefix should fail",
+			input: "123;",
 			args: args{
-				parser: Prefixed(Char('-'), Digit1()),
+				parser: Preceded(Digit1(), Skip(String("let")), Skip(Whitespace1())),
 			},
 			wantErr:       true,
 			wantOutput:    "",
-			wantRemaining: "123",
+			wantRemaining: "123;",
 		},
 		{
-			name:  "no parser match should fail",
-			input: "+",
+			name:  "missing main should fail",
+			input: "let abc;",
 			args: args{
-				parser: Prefixed(Char('+'), Digit1()),
+				parser: Preceded(Digit1(), Skip(String("let")), Skip(Whitespace1())),
 			},
 			wantErr:       true,
 			wantOutput:    "",
-			wantRemaining: "",
+			wantRemaining: "let abc;",
 		},
 		{
 			name:  "empty input should fail",
 			input: "",
 			args: args{
-				parser: Prefixed(Char('+'), Digit1()),
+				parser: Preceded(Digit1(), Skip(String("let")), Skip(Whitespace1())),
 			},
 			wantErr:       true,
 			wantOutput:    "",
@@ -485,9 +1771,9 @@ func TestPrefixed(t *testing.T) {
 	}
 }
 
-func BenchmarkPrefixed(b *testing.B) {
-	parser := Prefixed(Char('+'), Digit1())
-	input := gomme.NewFromString(1, nil, -1, "+123")
+func BenchmarkPrecededMany(b *testing.B) {
+	parser := Preceded(Digit1(), Skip(String("let")), Skip(Whitespace1()))
+	input := gomme.NewFromString(1, nil, -1, "let 123;")
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -495,7 +1781,7 @@ func BenchmarkPrefixed(b *testing.B) {
 	}
 }
 
-func TestSuffixed(t *testing.T) {
+func TestTerminated(t *testing.T) {
 	t.Parallel()
 
 	type args struct {
@@ -510,40 +1796,40 @@ func TestSuffixed(t *testing.T) {
 		wantRemaining string
 	}{
 		{
-			name:  "matching parser should succeed",
-			input: "1+23",
+			name:  "matching parsers should succeed",
+			input: "123 ;abc",
 			args: args{
-				parser: Suffixed(Digit1(), Char('+')),
+				parser: Terminated(Digit1(), Skip(Whitespace0()), Skip(Char(';'))),
 			},
 			wantErr:       false,
-			wantOutput:    "1",
-			wantRemaining: "23",
+			wantOutput:    "123",
+			wantRemaining: "abc",
 		},
 		{
-			name:  "no suffix match should fail",
-			input: "1-23",
+			name:  "missing suffix should fail",
+			input: "123 abc",
 			args: args{
-				parser: Suffixed(Digit1(), Char('+')),
+				parser: Terminated(Digit1(), Skip(Whitespace0()), Skip(Char(';'))),
 			},
 			wantErr:       true,
 			wantOutput:    "",
-			wantRemaining: "-23",
+			wantRemaining: "123 abc",
 		},
 		{
-			name:  "no parser match should fail",
-			input: "+",
+			name:  "missing main should fail",
+			input: "abc;",
 			args: args{
-				parser: Suffixed(Digit1(), Char('+')),
+				parser: Terminated(Digit1(), Skip(Whitespace0()), Skip(Char(';'))),
 			},
 			wantErr:       true,
 			wantOutput:    "",
-			wantRemaining: "",
+			wantRemaining: "abc;",
 		},
 		{
 			name:  "empty input should fail",
 			input: "",
 			args: args{
-				parser: Suffixed(Digit1(), Char('+')),
+				parser: Terminated(Digit1(), Skip(Whitespace0()), Skip(Char(';'))),
 			},
 			wantErr:       true,
 			wantOutput:    "",
@@ -573,9 +1859,9 @@ func TestSuffixed(t *testing.T) {
 	}
 }
 
-func BenchmarkTerminated(b *testing.B) {
-	parser := Suffixed(Digit1(), Char('+'))
-	input := gomme.NewFromString(1, nil, -1, "123+")
+func BenchmarkTerminatedMany(b *testing.B) {
+	parser := Terminated(Digit1(), Skip(Whitespace0()), Skip(Char(';')))
+	input := gomme.NewFromString(1, nil, -1, "123 ;abc")
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -779,6 +2065,101 @@ func TestMap2(t *testing.T) {
 	}
 }
 
+func TestMapPure(t *testing.T) {
+	t.Parallel()
+
+	concat := func(xs ...string) string { return strings.Join(xs, "|") }
+	testCases := []struct {
+		name       string
+		parser     gomme.Parser[string]
+		input      string
+		wantErr    bool
+		wantOutput string
+	}{
+		{
+			name: "Map2Pure should combine",
+			parser: Map2Pure(Digit1(), Alpha1(), func(a, b string) string {
+				return concat(a, b)
+			}),
+			input:      "1abc",
+			wantOutput: "1|abc",
+		}, {
+			name: "Map3Pure should combine",
+			parser: Map3Pure(Digit1(), Alpha1(), Digit1(), func(a, b, c string) string {
+				return concat(a, b, c)
+			}),
+			input:      "1abc2",
+			wantOutput: "1|abc|2",
+		}, {
+			name: "Map4Pure should combine",
+			parser: Map4Pure(Digit1(), Alpha1(), Digit1(), Alpha1(), func(a, b, c, d string) string {
+				return concat(a, b, c, d)
+			}),
+			input:      "1abc2d",
+			wantOutput: "1|abc|2|d",
+		}, {
+			name: "Map5Pure should combine",
+			parser: Map5Pure(Digit1(), Alpha1(), Digit1(), Alpha1(), Digit1(), func(a, b, c, d, e string) string {
+				return concat(a, b, c, d, e)
+			}),
+			input:      "1abc2d3",
+			wantOutput: "1|abc|2|d|3",
+		}, {
+			name: "failing parser should fail",
+			parser: Map2Pure(Digit1(), Alpha1(), func(a, b string) string {
+				return concat(a, b)
+			}),
+			input:   "1+",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			output, err := gomme.RunOnString(tc.input, tc.parser)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", err, tc.wantErr)
+			}
+			if output != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", output, tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestLift(t *testing.T) {
+	t.Parallel()
+
+	atoi := func(s string) int {
+		i, _ := strconv.Atoi(s)
+		return i
+	}
+	number := Lift(atoi, Digit1())
+	sum := Lift3(func(a int, _ string, b int) int { return a + b }, number, String("+"), number)
+
+	output, err := gomme.RunOnString("12+30", sum)
+	if err != nil || output != 42 {
+		t.Errorf("got (%d, %v), want (42, nil)", output, err)
+	}
+
+	add := func(a int, _ string) func(int) int {
+		return func(b int) int { return a + b }
+	}
+	applied := Apply(Lift2(add, number, String("+")), number)
+	output, err = gomme.RunOnString("1+2", applied)
+	if err != nil || output != 3 {
+		t.Errorf("got (%d, %v), want (3, nil)", output, err)
+	}
+
+	if _, err = gomme.RunOnString("1-2", applied); err == nil {
+		t.Errorf("got no error for invalid input")
+	}
+}
+
 func BenchmarkMap2(b *testing.B) {
 	type TestStruct struct {
 		Foo int
@@ -0,0 +1,95 @@
+package pcb
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/oleiade/gomme"
+)
+
+// HexBytes parses `n` bytes encoded as 2*`n` hex digits and returns the
+// decoded bytes. If `n` is 0, as many pairs of hex digits as possible are
+// parsed (at least one). A final single hex digit isn't consumed.
+// Errors point to the first invalid character.
+func HexBytes(n int) gomme.Parser[[]byte] {
+	if n < 0 {
+		panic("HexBytes is unable to handle negative `n`")
+	}
+	expected := fmt.Sprintf("%d hex encoded bytes", n)
+	if n == 0 {
+		expected = "hex encoded bytes"
+	}
+
+	parse := func(state gomme.State) (gomme.State, []byte, *gomme.ParserError) {
+		input := state.CurrentString()
+		max := n * 2
+		if n == 0 {
+			max = len(input)
+		}
+
+		i := 0
+		for i < max && i < len(input) && hexValue(input[i]) >= 0 {
+			i++
+		}
+		switch {
+		case n > 0 && i < max:
+			return hexGroupError[[]byte](state, i, fmt.Sprintf("%s (got %d hex digits)", expected, i))
+		case n == 0 && i < 2:
+			return hexGroupError[[]byte](state, i, expected)
+		}
+		i &^= 1 // whole bytes only
+
+		output := make([]byte, i/2)
+		decodeHexGroup(input, 0, i, output)
+		return state.MoveBy(i), output, nil
+	}
+
+	return gomme.NewParser[[]byte](expected, parse, hexGroupRecoverer(2))
+}
+
+// Base64String parses the longest run of characters of the alphabet of
+// `enc` (including its padding) and decodes it using `enc`.
+// Errors point to the first invalid character (e.g. a character of the
+// wrong alphabet or misplaced padding).
+func Base64String(enc *base64.Encoding) gomme.Parser[[]byte] {
+	expected := "base64 encoded data"
+	alphabet := base64Alphabet(enc)
+
+	parse := func(state gomme.State) (gomme.State, []byte, *gomme.ParserError) {
+		input := state.CurrentString()
+		i := 0
+		for i < len(input) && alphabet[input[i]] {
+			i++
+		}
+		if i == 0 {
+			return hexGroupError[[]byte](state, i, expected)
+		}
+
+		output, err := enc.DecodeString(input[:i])
+		if err != nil {
+			var corrupt base64.CorruptInputError
+			if errors.As(err, &corrupt) {
+				return hexGroupError[[]byte](state, int(corrupt), expected+" (invalid character or padding)")
+			}
+			return hexGroupError[[]byte](state, 0, fmt.Sprintf("%s (%v)", expected, err))
+		}
+		return state.MoveBy(i), output, nil
+	}
+
+	return gomme.NewParser[[]byte](expected, parse, BasicRecovererFunc(parse))
+}
+
+// base64Alphabet returns the characters of the alphabet of `enc` and
+// its padding character.
+// The encoding doesn't expose them. But the first character of the
+// encoding of a single byte is the character for its upper 6 bits.
+func base64Alphabet(enc *base64.Encoding) *[256]bool {
+	alphabet := &[256]bool{}
+	for v := 0; v < 64; v++ {
+		alphabet[enc.EncodeToString([]byte{byte(v << 2)})[0]] = true
+	}
+	if padded := enc.EncodeToString([]byte{0}); len(padded) == 4 {
+		alphabet[padded[3]] = true
+	}
+	return alphabet
+}
@@ -0,0 +1,234 @@
+package pcb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"github.com/oleiade/gomme"
+	"testing"
+)
+
+func TestHexBytes(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[[]byte]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    []byte
+		wantRemaining string
+	}{
+		{
+			name:  "parsing exact number of bytes should succeed",
+			input: "0aFf10x",
+			args: args{
+				parser: HexBytes(3),
+			},
+			wantErr:       false,
+			wantOutput:    []byte{0x0a, 0xff, 0x10},
+			wantRemaining: "x",
+		},
+		{
+			name:  "parsing only the requested bytes should succeed",
+			input: "0a0b0c",
+			args: args{
+				parser: HexBytes(1),
+			},
+			wantErr:       false,
+			wantOutput:    []byte{0x0a},
+			wantRemaining: "0b0c",
+		},
+		{
+			name:  "parsing as many bytes as possible should succeed",
+			input: "0a0b0 x",
+			args: args{
+				parser: HexBytes(0),
+			},
+			wantErr:       false,
+			wantOutput:    []byte{0x0a, 0x0b},
+			wantRemaining: "0 x",
+		},
+		{
+			name:  "parsing too few hex digits should fail",
+			input: "0a0g",
+			args: args{
+				parser: HexBytes(2),
+			},
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: "0a0g",
+		},
+		{
+			name:  "parsing a single hex digit should fail",
+			input: "5x",
+			args: args{
+				parser: HexBytes(0),
+			},
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: "5x",
+		},
+		{
+			name:  "parsing no hex digits should fail",
+			input: "xyz",
+			args: args{
+				parser: HexBytes(0),
+			},
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: "xyz",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if !bytes.Equal(gotResult, tc.wantOutput) {
+				t.Errorf("got output %x, want output %x", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkHexBytes(b *testing.B) {
+	parser := HexBytes(16)
+	input := gomme.NewFromString(1, nil, -1, "000102030405060708090a0b0c0d0e0f")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestBase64String(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[[]byte]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    []byte
+		wantRemaining string
+	}{
+		{
+			name:  "parsing standard encoding should succeed",
+			input: "aGVsbG8=, x",
+			args: args{
+				parser: Base64String(base64.StdEncoding),
+			},
+			wantErr:       false,
+			wantOutput:    []byte("hello"),
+			wantRemaining: ", x",
+		},
+		{
+			name:  "parsing URL encoding should succeed",
+			input: "-_-_",
+			args: args{
+				parser: Base64String(base64.URLEncoding),
+			},
+			wantErr:       false,
+			wantOutput:    []byte{0xfb, 0xff, 0xbf},
+			wantRemaining: "",
+		},
+		{
+			name:  "parsing raw encoding should succeed",
+			input: "aGVsbG8 x",
+			args: args{
+				parser: Base64String(base64.RawStdEncoding),
+			},
+			wantErr:       false,
+			wantOutput:    []byte("hello"),
+			wantRemaining: " x",
+		},
+		{
+			name:  "parsing wrong alphabet should fail",
+			input: "ab-d",
+			args: args{
+				parser: Base64String(base64.StdEncoding),
+			},
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: "ab-d",
+		},
+		{
+			name:  "parsing should stop at other alphabet",
+			input: "aGk=+x",
+			args: args{
+				parser: Base64String(base64.URLEncoding),
+			},
+			wantErr:       false,
+			wantOutput:    []byte("hi"),
+			wantRemaining: "+x",
+		},
+		{
+			name:  "parsing missing padding should fail",
+			input: "aGVsbG8",
+			args: args{
+				parser: Base64String(base64.StdEncoding),
+			},
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: "aGVsbG8",
+		},
+		{
+			name:  "parsing no base64 data should fail",
+			input: " x",
+			args: args{
+				parser: Base64String(base64.StdEncoding),
+			},
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: " x",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if !bytes.Equal(gotResult, tc.wantOutput) {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkBase64String(b *testing.B) {
+	parser := Base64String(base64.StdEncoding)
+	input := gomme.NewFromString(1, nil, -1, "aGVsbG8gd29ybGQ=")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
@@ -3,6 +3,8 @@ package pcb
 import (
 	"fmt"
 	"github.com/oleiade/gomme"
+	"slices"
+	"strings"
 )
 
 // FirstSuccessful tests a list of parsers in order, one by one,
@@ -10,10 +12,15 @@ import (
 // All parsers have to be of the same type.
 //
 // If no parser succeeds, this combinator produces an error Result.
+//
+// The parsers can be a slice computed at runtime (e.g. alternatives provided
+// by plugins): `FirstSuccessful(alternatives...)`.
+// The slice is copied. So later changes to it don't affect the parser.
 func FirstSuccessful[Output any](parsers ...gomme.Parser[Output]) gomme.Parser[Output] {
 	if len(parsers) == 0 {
 		panic("FirstSuccessful(missing parsers)")
 	}
+	parsers = slices.Clone(parsers)
 
 	//
 	// Construct mySaveSpotRecoverer from the sub-parsers
@@ -25,22 +32,39 @@ func FirstSuccessful[Output any](parsers ...gomme.Parser[Output]) gomme.Parser[O
 	mySaveSpotRecoverer := gomme.NewCombiningRecoverer(true, subRecoverers...)
 
 	fsd := &firstSuccessfulData[Output]{
-		id:                gomme.NewBranchParserID(),
+		id:                gomme.NewParserID(),
 		parsers:           parsers,
 		saveSpotRecoverer: mySaveSpotRecoverer,
 	}
 
-	return gomme.NewParser[Output](
+	analyses := make([]gomme.Analysis, len(parsers))
+	for i, parser := range parsers {
+		analyses[i] = gomme.Analyze(parser)
+	}
+
+	p := gomme.NewParser[Output](
 		"FirstSuccessful",
 		fsd.any,
 		true,
 		gomme.DefaultRecovererFunc(fsd.any), // you really shouldn't use this parser as a Recoverer
 		mySaveSpotRecoverer.Recover,
 	)
+	p = gomme.WithAnalysis(p, gomme.ChoiceAnalysis(analyses...))
+	return gomme.WithOptimizer(p, func() gomme.Parser[Output] {
+		return FirstSuccessful(optimizeAll(parsers)...)
+	})
+}
+
+func optimizeAll[Output any](parsers []gomme.Parser[Output]) []gomme.Parser[Output] {
+	optimized := make([]gomme.Parser[Output], len(parsers))
+	for i, parser := range parsers {
+		optimized[i] = gomme.Optimize(parser)
+	}
+	return optimized
 }
 
 type firstSuccessfulData[Output any] struct {
-	id                uint64
+	id                *gomme.ParserID
 	parsers           []gomme.Parser[Output]
 	saveSpotRecoverer gomme.CombiningRecoverer
 }
@@ -71,7 +95,7 @@ func (fsd *firstSuccessfulData[Output]) happy(state gomme.State) (gomme.State, O
 	var zero Output
 
 	// use cache to know result immediately
-	result, ok := state.CachedParserResult(fsd.id)
+	result, ok := state.CachedParserResult(fsd.id.In(state))
 	if ok {
 		if result.Failed {
 			return state.ErrorAgain(result.Error), zero
@@ -86,17 +110,18 @@ func (fsd *firstSuccessfulData[Output]) happy(state gomme.State) (gomme.State, O
 		newState, output := parse.It(state)
 		if !newState.Failed() {
 			if state.SaveSpotMoved(newState) {
-				state.CacheParserResult(fsd.id, i, i, 0, newState, output)
+				state.CacheParserResult(fsd.id.In(state), i, i, 0, newState, output)
 			} else {
-				state.CacheParserResult(fsd.id, i, -1, -1, newState, output)
+				state.CacheParserResult(fsd.id.In(state), i, -1, -1, newState, output)
 			}
 			return newState, output
 		}
 
 		if state.SaveSpotMoved(newState) { // don't look further than this
-			state.CacheParserResult(fsd.id, i, i, 0, newState, output)
-			return gomme.IWitnessed(state, fsd.id, i, newState), zero
+			state.CacheParserResult(fsd.id.In(state), i, i, 0, newState, output)
+			return gomme.IWitnessed(state, fsd.id.In(state), i, newState), zero
 		}
+		state = state.Backtrack(newState)
 
 		// may the best error win:
 		if i == 0 {
@@ -106,14 +131,14 @@ func (fsd *firstSuccessfulData[Output]) happy(state gomme.State) (gomme.State, O
 			idx = i
 		}
 	}
-	state.CacheParserResult(fsd.id, idx, idx, 0, bestState, zero)
-	return gomme.IWitnessed(state, fsd.id, idx, bestState), zero
+	state.CacheParserResult(fsd.id.In(state), idx, idx, 0, bestState, zero)
+	return gomme.IWitnessed(state, fsd.id.In(state), idx, bestState), zero
 }
 
 func (fsd *firstSuccessfulData[Output]) error(state gomme.State) (gomme.State, Output) {
 	var zero Output
 	// use cache to know right parser immediately (Idx, HasSaveSpot)
-	result, ok := state.CachedParserResult(fsd.id)
+	result, ok := state.CachedParserResult(fsd.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `FirstSuccessful(error)` parser",
@@ -136,14 +161,14 @@ func (fsd *firstSuccessfulData[Output]) error(state gomme.State) (gomme.State, O
 func (fsd *firstSuccessfulData[Output]) handle(state gomme.State) (gomme.State, Output) {
 	var zero Output
 	// use cache to know right parser immediately (Idx, Failed)
-	result, ok := state.CachedParserResult(fsd.id)
+	result, ok := state.CachedParserResult(fsd.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `FirstSuccessful(handle)` parser",
 		), zero
 	}
 	if result.Failed {
-		newState, output := gomme.HandleWitness(state, fsd.id, result.Idx, fsd.parsers...)
+		newState, output := gomme.HandleWitness(state, fsd.id.In(state), result.Idx, fsd.parsers...)
 		// the parser failed; so it MUST be the one with the error we are looking for
 		if newState.ParsingMode() != gomme.ParsingModeHappy && newState.ParsingMode() != gomme.ParsingModeEscape {
 			return state.NewSemanticError(fmt.Sprintf(
@@ -159,14 +184,14 @@ func (fsd *firstSuccessfulData[Output]) handle(state gomme.State) (gomme.State,
 func (fsd *firstSuccessfulData[Output]) rewind(state gomme.State) (gomme.State, Output) {
 	var zero Output
 	// use cache to know right parser immediately (Idx, Failed)
-	result, ok := state.CachedParserResult(fsd.id)
+	result, ok := state.CachedParserResult(fsd.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `FirstSuccessful(rewind)` parser",
 		), zero
 	}
 	if result.Failed {
-		newState, output := gomme.HandleWitness(state, fsd.id, result.Idx, fsd.parsers...)
+		newState, output := gomme.HandleWitness(state, fsd.id.In(state), result.Idx, fsd.parsers...)
 		// the parser failed; so it MUST be the one with the error we are looking for
 		if newState.ParsingMode() != gomme.ParsingModeHappy && newState.ParsingMode() != gomme.ParsingModeEscape {
 			return state.NewSemanticError(fmt.Sprintf(
@@ -202,3 +227,151 @@ func (fsd *firstSuccessfulData[Output]) escape(state gomme.State) (gomme.State,
 	}
 	return newState, output
 }
+
+// Dispatch is like FirstSuccessful but it peeks at the next byte and only
+// tries the alternatives that can start with it (according to gomme.Analyze).
+// So large keyword led alternations are O(1) instead of O(n).
+//
+// The order of the alternatives is kept for the viable ones.
+// Alternatives that might not consume any input are viable for every byte
+// and at the end of the input.
+// Lazy and custom parsers without analysis are always tried, too.
+func Dispatch[Output any](parsers ...gomme.Parser[Output]) gomme.Parser[Output] {
+	var zero Output
+	if len(parsers) == 0 {
+		panic("Dispatch(missing parsers)")
+	}
+
+	expectations := make([]string, len(parsers))
+	analyses := make([]gomme.Analysis, len(parsers))
+	table := [256][]gomme.Parser[Output]{}
+	var atEOF []gomme.Parser[Output]
+	for i, parse := range parsers {
+		expectations[i] = parse.Expected()
+		analyses[i] = gomme.Analyze(parse)
+		for b := 0; b < 256; b++ {
+			if analyses[i].Nullable || analyses[i].First.Has(byte(b)) {
+				table[b] = append(table[b], parse)
+			}
+		}
+		if analyses[i].Nullable {
+			atEOF = append(atEOF, parse)
+		}
+	}
+	expected := "one of: " + strings.Join(expectations, ", ")
+
+	dispatchParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		candidates := atEOF
+		if b, ok := state.PeekByte(); ok {
+			candidates = table[b]
+		}
+		if len(candidates) == 0 {
+			errState := state.NewError(expected)
+			return errState, zero, errState.CurrentError()
+		}
+
+		bestState, bestErr := state, (*gomme.ParserError)(nil)
+		for i, parse := range candidates {
+			newState, output, err := parse.It(state)
+			if err == nil {
+				return newState, output, nil
+			}
+			if state.SaveSpotMoved(newState) { // don't look further than this
+				return state.Preserve(newState), zero, err
+			}
+			state = state.Backtrack(newState)
+			if i == 0 || newState.CurrentPos() > bestState.CurrentPos() {
+				bestState, bestErr = newState, err
+			}
+		}
+		return state.Preserve(bestState), zero, bestErr
+	}
+
+	p := gomme.NewParser[Output](expected, dispatchParse, BasicRecovererFunc(dispatchParse))
+	p = gomme.WithAnalysis(p, gomme.ChoiceAnalysis(analyses...))
+	return gomme.WithOptimizer(p, func() gomme.Parser[Output] {
+		return Dispatch(optimizeAll(parsers)...)
+	})
+}
+
+// Choice2 is the output of the Either parser.
+// Idx is the index of the successful sub-parser and
+// only the value with the same index (V1 for 0, V2 for 1) is valid.
+type Choice2[T1, T2 any] struct {
+	Idx int
+	V1  T1
+	V2  T2
+}
+
+// Choice3 is the output of the Either3 parser.
+// Idx is the index of the successful sub-parser and
+// only the value with the same index (V1 for 0, V2 for 1, ...) is valid.
+type Choice3[T1, T2, T3 any] struct {
+	Idx int
+	V1  T1
+	V2  T2
+	V3  T3
+}
+
+// Choice4 is the output of the Either4 parser.
+// Idx is the index of the successful sub-parser and
+// only the value with the same index (V1 for 0, V2 for 1, ...) is valid.
+type Choice4[T1, T2, T3, T4 any] struct {
+	Idx int
+	V1  T1
+	V2  T2
+	V3  T3
+	V4  T4
+}
+
+// Either is like FirstSuccessful for 2 parsers with different output types.
+// The output tells which parser succeeded.
+func Either[T1, T2 any](parse1 gomme.Parser[T1], parse2 gomme.Parser[T2]) gomme.Parser[Choice2[T1, T2]] {
+	return FirstSuccessful(
+		Map(parse1, func(v T1) (Choice2[T1, T2], error) {
+			return Choice2[T1, T2]{Idx: 0, V1: v}, nil
+		}),
+		Map(parse2, func(v T2) (Choice2[T1, T2], error) {
+			return Choice2[T1, T2]{Idx: 1, V2: v}, nil
+		}),
+	)
+}
+
+// Either3 is like FirstSuccessful for 3 parsers with different output types.
+// The output tells which parser succeeded.
+func Either3[T1, T2, T3 any](
+	parse1 gomme.Parser[T1], parse2 gomme.Parser[T2], parse3 gomme.Parser[T3],
+) gomme.Parser[Choice3[T1, T2, T3]] {
+	return FirstSuccessful(
+		Map(parse1, func(v T1) (Choice3[T1, T2, T3], error) {
+			return Choice3[T1, T2, T3]{Idx: 0, V1: v}, nil
+		}),
+		Map(parse2, func(v T2) (Choice3[T1, T2, T3], error) {
+			return Choice3[T1, T2, T3]{Idx: 1, V2: v}, nil
+		}),
+		Map(parse3, func(v T3) (Choice3[T1, T2, T3], error) {
+			return Choice3[T1, T2, T3]{Idx: 2, V3: v}, nil
+		}),
+	)
+}
+
+// Either4 is like FirstSuccessful for 4 parsers with different output types.
+// The output tells which parser succeeded.
+func Either4[T1, T2, T3, T4 any](
+	parse1 gomme.Parser[T1], parse2 gomme.Parser[T2], parse3 gomme.Parser[T3], parse4 gomme.Parser[T4],
+) gomme.Parser[Choice4[T1, T2, T3, T4]] {
+	return FirstSuccessful(
+		Map(parse1, func(v T1) (Choice4[T1, T2, T3, T4], error) {
+			return Choice4[T1, T2, T3, T4]{Idx: 0, V1: v}, nil
+		}),
+		Map(parse2, func(v T2) (Choice4[T1, T2, T3, T4], error) {
+			return Choice4[T1, T2, T3, T4]{Idx: 1, V2: v}, nil
+		}),
+		Map(parse3, func(v T3) (Choice4[T1, T2, T3, T4], error) {
+			return Choice4[T1, T2, T3, T4]{Idx: 2, V3: v}, nil
+		}),
+		Map(parse4, func(v T4) (Choice4[T1, T2, T3, T4], error) {
+			return Choice4[T1, T2, T3, T4]{Idx: 3, V4: v}, nil
+		}),
+	)
+}
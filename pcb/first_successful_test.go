@@ -83,6 +83,28 @@ func TestFirstSuccessful(t *testing.T) {
 	}
 }
 
+func TestFirstSuccessfulFromSlice(t *testing.T) {
+	t.Parallel()
+
+	keywords := []string{"let", "var", "const"} // e.g. provided by plugins
+	alternatives := make([]gomme.Parser[string], 0, len(keywords))
+	for _, kw := range keywords {
+		alternatives = append(alternatives, String(kw))
+	}
+	parser := FirstSuccessful(alternatives...)
+	alternatives[0] = String("fn") // must not change the parser
+
+	for _, input := range []string{"let", "var", "const"} {
+		output, err := gomme.RunOnString(input, parser)
+		if err != nil || output != input {
+			t.Errorf("got (%q, %v), want (%q, nil)", output, err, input)
+		}
+	}
+	if _, err := gomme.RunOnString("fn", parser); err == nil {
+		t.Errorf("got no error for input %q", "fn")
+	}
+}
+
 func BenchmarkFirstSuccessful(b *testing.B) {
 	p := FirstSuccessful(Char('b'), Char('a'))
 	input := gomme.NewFromString(1, nil, -1, "abc")
@@ -92,3 +114,199 @@ func BenchmarkFirstSuccessful(b *testing.B) {
 		_, _ = p.It(input)
 	}
 }
+
+func TestDispatch(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "keyword should be dispatched by first byte",
+			input: "while x",
+			args: args{
+				parser: Dispatch(String("if"), String("else"), String("for"), String("while")),
+			},
+			wantErr:       false,
+			wantOutput:    "while",
+			wantRemaining: " x",
+		},
+		{
+			name:  "alternatives with same first byte keep their order",
+			input: "foreach",
+			args: args{
+				parser: Dispatch(String("for"), String("foreach")),
+			},
+			wantErr:       false,
+			wantOutput:    "for",
+			wantRemaining: "each",
+		},
+		{
+			name:  "alternative accepting empty input is always viable",
+			input: "123",
+			args: args{
+				parser: Dispatch(String("x"), Alpha0()),
+			},
+			wantErr:       false,
+			wantOutput:    "",
+			wantRemaining: "123",
+		},
+		{
+			name:  "no viable alternative should fail",
+			input: "do",
+			args: args{
+				parser: Dispatch(String("if"), String("else"), String("for"), String("while")),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "do",
+		},
+		{
+			name:  "viable alternative not matching should fail",
+			input: "ix",
+			args: args{
+				parser: Dispatch(String("if"), String("else"), String("for"), String("while")),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "ix",
+		},
+		{
+			name:  "empty input should fail",
+			input: "",
+			args: args{
+				parser: Dispatch(String("if"), String("else"), String("for"), String("while")),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkDispatch(b *testing.B) {
+	parser := Dispatch(String("if"), String("else"), String("for"), String("switch"), String("case"), String("while"))
+	input := gomme.NewFromString(1, nil, -1, "while")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestEither(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[Choice2[int64, string]]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    Choice2[int64, string]
+		wantRemaining string
+	}{
+		{
+			name:  "head matching parser should succeed",
+			input: "123;",
+			args: args{
+				parser: Either(Int64(false, 10), Alpha1()),
+			},
+			wantErr:       false,
+			wantOutput:    Choice2[int64, string]{Idx: 0, V1: 123},
+			wantRemaining: ";",
+		},
+		{
+			name:  "tail matching parser should succeed",
+			input: "abc;",
+			args: args{
+				parser: Either(Int64(false, 10), Alpha1()),
+			},
+			wantErr:       false,
+			wantOutput:    Choice2[int64, string]{Idx: 1, V2: "abc"},
+			wantRemaining: ";",
+		},
+		{
+			name:  "no matching parser should fail",
+			input: "$%;",
+			args: args{
+				parser: Either(Int64(false, 10), Alpha1()),
+			},
+			wantErr:       true,
+			wantOutput:    Choice2[int64, string]{},
+			wantRemaining: "$%;",
+		},
+		{
+			name:  "empty input should fail",
+			input: "",
+			args: args{
+				parser: Either(Int64(false, 10), Alpha1()),
+			},
+			wantErr:       true,
+			wantOutput:    Choice2[int64, string]{},
+			wantRemaining: "",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %v, want output %v", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkEither(b *testing.B) {
+	parser := Either(Int64(false, 10), Alpha1())
+	input := gomme.NewFromString(1, nil, -1, "abc;")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
@@ -0,0 +1,126 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"strings"
+)
+
+// FormField is a decoded key value pair of a query string or form.
+type FormField struct {
+	Key      string
+	Value    string
+	KeyPos   int // offset of the key in the input
+	ValuePos int // offset of the value in the input (-1 if there is no '=')
+}
+
+// Form is the ordered list of fields of a query string or form.
+// Repeated keys are kept.
+type Form []FormField
+
+// Get returns the value of the first field with the key or "".
+func (form Form) Get(key string) string {
+	for _, f := range form {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// All returns the values of all fields with the key in order.
+func (form Form) All(key string) []string {
+	var values []string
+	for _, f := range form {
+		if f.Key == key {
+			values = append(values, f.Value)
+		}
+	}
+	return values
+}
+
+// FormURLEncoded parses an application/x-www-form-urlencoded string or the
+// query part of a URL (e.g. "q=go+parser&tag=a&tag=b%26c").
+// Keys and values are percent-decoded and '+' is decoded as space.
+// Empty pairs (e.g. "a=1&&b=2") are skipped and a key without '=' has an
+// empty value.
+// Malformed escapes (e.g. "%G1") are reported as semantic errors at their
+// exact position and kept literally.
+// It stops at the end of the input, at a '#' or at white space.
+// So it can be used for the query of a request line, too.
+func FormURLEncoded() gomme.Parser[Form] {
+	expected := "form data"
+
+	formParse := func(state gomme.State) (gomme.State, Form, *gomme.ParserError) {
+		input := state.CurrentString()
+		end := strings.IndexAny(input, "# \t\r\n")
+		if end < 0 {
+			end = len(input)
+		}
+		start := state.CurrentPos()
+		var form Form
+		done := 0 // bytes of the input that the state has been moved by
+		report := func(pos int) {
+			state = state.MoveBy(pos - done).NewSemanticError("expected two hex digits after '%'")
+			done = pos
+		}
+
+		for pair := 0; pair < end; {
+			pairEnd := strings.IndexByte(input[pair:end], '&')
+			if pairEnd < 0 {
+				pairEnd = end
+			} else {
+				pairEnd += pair
+			}
+			if pairEnd > pair {
+				f := FormField{KeyPos: start + pair, ValuePos: -1}
+				keyEnd := pairEnd
+				eq := strings.IndexByte(input[pair:pairEnd], '=')
+				if eq >= 0 {
+					keyEnd = pair + eq
+				}
+				f.Key = formUnescape(input, pair, keyEnd, report)
+				if eq >= 0 {
+					f.ValuePos = start + keyEnd + 1
+					f.Value = formUnescape(input, keyEnd+1, pairEnd, report)
+				}
+				form = append(form, f)
+			}
+			pair = pairEnd + 1
+		}
+		return state.MoveBy(end - done), form, nil
+	}
+	return gomme.NewParser[Form](expected, formParse, Forbidden("FormURLEncoded"))
+}
+
+// formUnescape decodes input[from:to] and calls `report` with the
+// position of every malformed escape.
+func formUnescape(input string, from, to int, report func(pos int)) string {
+	s := input[from:to]
+	if strings.IndexByte(s, '%') < 0 && strings.IndexByte(s, '+') < 0 {
+		return s
+	}
+	sb := strings.Builder{}
+	sb.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '+':
+			sb.WriteByte(' ')
+		case c == '%':
+			hi, lo := -1, -1
+			if i+2 < len(s) {
+				hi, lo = hexValue(s[i+1]), hexValue(s[i+2])
+			}
+			if hi < 0 || lo < 0 {
+				report(from + i)
+				sb.WriteByte(c)
+				continue
+			}
+			sb.WriteByte(byte(hi<<4 | lo))
+			i += 2
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
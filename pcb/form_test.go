@@ -0,0 +1,86 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormURLEncoded(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErrs      []string
+		wantOutput    Form
+		wantRemaining string
+	}{
+		{
+			name:  "parsing repeated and encoded keys should succeed",
+			input: "q=go+parser&tag=a&&tag=b%26c&flag#top",
+			wantOutput: Form{
+				{Key: "q", Value: "go parser", KeyPos: 0, ValuePos: 2},
+				{Key: "tag", Value: "a", KeyPos: 12, ValuePos: 16},
+				{Key: "tag", Value: "b&c", KeyPos: 19, ValuePos: 23},
+				{Key: "flag", KeyPos: 29, ValuePos: -1},
+			},
+			wantRemaining: "#top",
+		}, {
+			name:          "parsing should stop at white space",
+			input:         "a=%C3%A4 HTTP/1.1",
+			wantOutput:    Form{{Key: "a", Value: "ä", KeyPos: 0, ValuePos: 2}},
+			wantRemaining: " HTTP/1.1",
+		}, {
+			name:     "malformed escapes should be reported",
+			input:    "k%G=1&v=%4",
+			wantErrs: []string{"expected two hex digits after '%' [1:2]", "expected two hex digits after '%' [1:9]"},
+			wantOutput: Form{
+				{Key: "k%G", Value: "1", KeyPos: 0, ValuePos: 4},
+				{Key: "v", Value: "%4", KeyPos: 6, ValuePos: 8},
+			},
+		}, {
+			name: "empty input should succeed",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), FormURLEncoded())
+			if newState.HasError() != (len(tc.wantErrs) > 0) {
+				t.Errorf("got error %v, want errors %q", newState.Errors(), tc.wantErrs)
+			}
+			if len(tc.wantErrs) > 0 {
+				msg := newState.Errors().Error()
+				for _, wantErr := range tc.wantErrs {
+					if !strings.Contains(msg, wantErr) {
+						t.Errorf("got error %q, want error %q", msg, wantErr)
+					}
+				}
+			}
+			if !reflect.DeepEqual(gotResult, tc.wantOutput) {
+				t.Errorf("got output %+v, want output %+v", gotResult, tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestFormGet(t *testing.T) {
+	t.Parallel()
+
+	form := Form{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "a", Value: "3"}}
+	if got := form.Get("a"); got != "1" {
+		t.Errorf("got %q, want %q", got, "1")
+	}
+	if got := form.All("a"); !reflect.DeepEqual(got, []string{"1", "3"}) {
+		t.Errorf("got %q, want %q", got, []string{"1", "3"})
+	}
+}
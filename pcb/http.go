@@ -0,0 +1,326 @@
+package pcb
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/oleiade/gomme"
+	"strconv"
+)
+
+// HTTPRequestLine is the output of the RequestLine parser.
+type HTTPRequestLine struct {
+	Method  string
+	Target  string
+	Version string
+}
+
+// HTTPStatusLine is the output of the StatusLine parser.
+type HTTPStatusLine struct {
+	Version string
+	Code    int
+	Reason  string
+}
+
+// HTTPHeaderField is the output of the HeaderField parser.
+// The value is returned without leading and trailing whitespace.
+type HTTPHeaderField struct {
+	Name  string
+	Value string
+}
+
+// HTTPChunkedBody is the output of the ChunkedBody parser.
+// Chunk extensions are ignored.
+type HTTPChunkedBody struct {
+	Data     []byte
+	Trailers []HTTPHeaderField
+}
+
+// The following parsers implement HTTP/1.x messages as defined in RFC 9112.
+// They work directly on the bytes of the input, so they are efficient
+// for binary input (see `gomme.NewFromBytes`), too.
+// All lines have to be terminated by CRLF.
+
+// RequestLine parses the request line of an HTTP/1.x request
+// including the terminating CRLF:
+//
+//	request-line = method SP request-target SP HTTP-version CRLF
+func RequestLine() gomme.Parser[HTTPRequestLine] {
+	expected := "HTTP request line"
+
+	parse := func(state gomme.State) (gomme.State, HTTPRequestLine, *gomme.ParserError) {
+		input := state.CurrentBytes()
+		rl := HTTPRequestLine{}
+
+		i := scanHTTPToken(input)
+		if i == 0 {
+			return httpError[HTTPRequestLine](state, i, "HTTP method", input)
+		}
+		rl.Method = string(input[:i])
+		if i >= len(input) || input[i] != ' ' {
+			return httpError[HTTPRequestLine](state, i, "' ' after HTTP method", input)
+		}
+
+		start := i + 1
+		i = start
+		for i < len(input) && input[i] > ' ' && input[i] < 0x7f {
+			i++
+		}
+		if i == start {
+			return httpError[HTTPRequestLine](state, i, "HTTP request target", input)
+		}
+		rl.Target = string(input[start:i])
+		if i >= len(input) || input[i] != ' ' {
+			return httpError[HTTPRequestLine](state, i, "' ' after HTTP request target", input)
+		}
+
+		n, ok := scanHTTPVersion(input[i+1:])
+		if !ok {
+			return httpError[HTTPRequestLine](state, i+1+n, "HTTP version", input)
+		}
+		rl.Version = string(input[i+1 : i+1+n])
+		i += 1 + n
+
+		if !bytes.HasPrefix(input[i:], []byte("\r\n")) {
+			return httpError[HTTPRequestLine](state, i, "CRLF after HTTP version", input)
+		}
+		return state.MoveBy(i + 2), rl, nil
+	}
+
+	return gomme.NewParser[HTTPRequestLine](expected, parse, BasicRecovererFunc(parse))
+}
+
+// StatusLine parses the status line of an HTTP/1.x response
+// including the terminating CRLF:
+//
+//	status-line = HTTP-version SP status-code SP [ reason-phrase ] CRLF
+func StatusLine() gomme.Parser[HTTPStatusLine] {
+	expected := "HTTP status line"
+
+	parse := func(state gomme.State) (gomme.State, HTTPStatusLine, *gomme.ParserError) {
+		input := state.CurrentBytes()
+		sl := HTTPStatusLine{}
+
+		n, ok := scanHTTPVersion(input)
+		if !ok {
+			return httpError[HTTPStatusLine](state, n, "HTTP version", input)
+		}
+		sl.Version = string(input[:n])
+		if n >= len(input) || input[n] != ' ' {
+			return httpError[HTTPStatusLine](state, n, "' ' after HTTP version", input)
+		}
+
+		i := n + 1
+		for j := 0; j < 3; j++ {
+			if i+j >= len(input) || !IsDigit(rune(input[i+j])) {
+				return httpError[HTTPStatusLine](state, i+j, "3 digit HTTP status code", input)
+			}
+		}
+		sl.Code, _ = strconv.Atoi(string(input[i : i+3])) // digits have been checked already
+		i += 3
+		if i >= len(input) || input[i] != ' ' {
+			return httpError[HTTPStatusLine](state, i, "' ' after HTTP status code", input)
+		}
+
+		end := bytes.Index(input[i+1:], []byte("\r\n"))
+		if end < 0 {
+			return httpError[HTTPStatusLine](state, len(input), "CRLF after HTTP reason phrase", input)
+		}
+		end += i + 1
+		if j := scanHTTPFieldValue(input[i+1 : end]); i+1+j < end {
+			return httpError[HTTPStatusLine](state, i+1+j, "HTTP reason phrase", input)
+		}
+		sl.Reason = string(input[i+1 : end])
+		return state.MoveBy(end + 2), sl, nil
+	}
+
+	return gomme.NewParser[HTTPStatusLine](expected, parse, IndexOf("HTTP/"))
+}
+
+// HeaderField parses a single HTTP header field including the
+// terminating CRLF:
+//
+//	field-line = field-name ":" OWS field-value OWS CRLF
+//
+// Obsolete line folding isn't supported.
+func HeaderField() gomme.Parser[HTTPHeaderField] {
+	expected := "HTTP header field"
+
+	parse := func(state gomme.State) (gomme.State, HTTPHeaderField, *gomme.ParserError) {
+		n, field, err := parseHTTPHeaderField(state.CurrentBytes())
+		if err != "" {
+			return httpError[HTTPHeaderField](state, n, err, state.CurrentBytes())
+		}
+		return state.MoveBy(n), field, nil
+	}
+
+	return gomme.NewParser[HTTPHeaderField](expected, parse, BasicRecovererFunc(parse))
+}
+
+// HeaderFields parses all HTTP header fields of a message including
+// the empty line that terminates them.
+func HeaderFields() gomme.Parser[[]HTTPHeaderField] {
+	expected := "HTTP header fields"
+
+	parse := func(state gomme.State) (gomme.State, []HTTPHeaderField, *gomme.ParserError) {
+		input := state.CurrentBytes()
+		fields, n, i, err := parseHTTPHeaderFields(input)
+		if err != "" {
+			return httpError[[]HTTPHeaderField](state, i, err, input)
+		}
+		return state.MoveBy(n), fields, nil
+	}
+
+	return gomme.NewParser[[]HTTPHeaderField](expected, parse, BasicRecovererFunc(parse))
+}
+
+// ChunkedBody parses and decodes a message body with the chunked
+// transfer coding including the trailer section:
+//
+//	chunked-body = *chunk last-chunk trailer-section CRLF
+func ChunkedBody() gomme.Parser[HTTPChunkedBody] {
+	expected := "HTTP chunked body"
+
+	parse := func(state gomme.State) (gomme.State, HTTPChunkedBody, *gomme.ParserError) {
+		input := state.CurrentBytes()
+		body := HTTPChunkedBody{Data: []byte{}}
+		i := 0
+		for {
+			start := i
+			for i < len(input) && IsHexDigit(rune(input[i])) {
+				i++
+			}
+			if i == start {
+				return httpError[HTTPChunkedBody](state, i, "HTTP chunk size", input)
+			}
+			size, err := strconv.ParseUint(string(input[start:i]), 16, 31)
+			if err != nil {
+				return httpError[HTTPChunkedBody](state, start, "HTTP chunk size (too large)", input)
+			}
+
+			end := bytes.Index(input[i:], []byte("\r\n")) // skip chunk extensions
+			if end < 0 {
+				return httpError[HTTPChunkedBody](state, len(input), "CRLF after HTTP chunk size", input)
+			}
+			i += end + 2
+			if size == 0 {
+				break
+			}
+
+			if uint64(len(input)-i) < size+2 {
+				return httpError[HTTPChunkedBody](state, len(input), fmt.Sprintf("HTTP chunk data of %d bytes", size), input)
+			}
+			body.Data = append(body.Data, input[i:i+int(size)]...)
+			i += int(size)
+			if !bytes.HasPrefix(input[i:], []byte("\r\n")) {
+				return httpError[HTTPChunkedBody](state, i, "CRLF after HTTP chunk data", input)
+			}
+			i += 2
+		}
+
+		trailers, n, j, err := parseHTTPHeaderFields(input[i:])
+		if err != "" {
+			return httpError[HTTPChunkedBody](state, i+j, err, input)
+		}
+		body.Trailers = trailers
+		return state.MoveBy(i + n), body, nil
+	}
+
+	return gomme.NewParser[HTTPChunkedBody](expected, parse, BasicRecovererFunc(parse))
+}
+
+// parseHTTPHeaderFields parses header fields until the empty line.
+// It returns the fields, the number of bytes consumed and
+// in case of an error: the position of the error and the message.
+func parseHTTPHeaderFields(input []byte) ([]HTTPHeaderField, int, int, string) {
+	fields := make([]HTTPHeaderField, 0, 16)
+	i := 0
+	for !bytes.HasPrefix(input[i:], []byte("\r\n")) {
+		n, field, err := parseHTTPHeaderField(input[i:])
+		if err != "" {
+			return nil, 0, i + n, err
+		}
+		fields = append(fields, field)
+		i += n
+	}
+	return fields, i + 2, 0, ""
+}
+
+// parseHTTPHeaderField parses a single header field.
+// It returns the number of bytes consumed (or the position of the error),
+// the field and the error message.
+func parseHTTPHeaderField(input []byte) (int, HTTPHeaderField, string) {
+	i := scanHTTPToken(input)
+	if i == 0 {
+		return 0, HTTPHeaderField{}, "HTTP header field name"
+	}
+	name := string(input[:i])
+	if i >= len(input) || input[i] != ':' {
+		return i, HTTPHeaderField{}, "':' after HTTP header field name"
+	}
+	i++
+
+	end := bytes.Index(input[i:], []byte("\r\n"))
+	if end < 0 {
+		return len(input), HTTPHeaderField{}, "CRLF after HTTP header field value"
+	}
+	end += i
+	if j := scanHTTPFieldValue(input[i:end]); i+j < end {
+		return i + j, HTTPHeaderField{}, "HTTP header field value"
+	}
+	return end + 2, HTTPHeaderField{Name: name, Value: string(bytes.Trim(input[i:end], " \t"))}, ""
+}
+
+// scanHTTPToken returns the length of the token at the start of input.
+func scanHTTPToken(input []byte) int {
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		if !isURIAlpha(c) && !isURIDigit(c) && bytes.IndexByte([]byte("!#$%&'*+-.^_`|~"), c) < 0 {
+			break
+		}
+		i++
+	}
+	return i
+}
+
+// scanHTTPVersion checks for "HTTP/" DIGIT "." DIGIT at the start of input.
+// It returns the length of the version or the position of the error.
+func scanHTTPVersion(input []byte) (int, bool) {
+	const prefix = "HTTP/"
+	for i := 0; i < len(prefix); i++ {
+		if i >= len(input) || input[i] != prefix[i] {
+			return i, false
+		}
+	}
+	for i, want := range []byte{'0', '.', '0'} {
+		j := len(prefix) + i
+		if j >= len(input) {
+			return j, false
+		}
+		if (want == '.' && input[j] != '.') || (want == '0' && !IsDigit(rune(input[j]))) {
+			return j, false
+		}
+	}
+	return len(prefix) + 3, true
+}
+
+// scanHTTPFieldValue returns the index of the first byte that isn't
+// allowed in a field value (VCHAR, SP, HTAB and obs-text).
+func scanHTTPFieldValue(input []byte) int {
+	for i, c := range input {
+		if c != '\t' && (c < ' ' || c == 0x7f) {
+			return i
+		}
+	}
+	return len(input)
+}
+
+func httpError[Output any](state gomme.State, i int, expected string, input []byte) (gomme.State, Output, *gomme.ParserError) {
+	if i < len(input) {
+		expected += fmt.Sprintf(" (got %q)", input[i])
+	} else {
+		expected += " (at EOF)"
+	}
+	errState := state.MoveBy(i).NewError(expected)
+	return state.Preserve(errState), gomme.ZeroOf[Output](), errState.CurrentError()
+}
@@ -0,0 +1,440 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"reflect"
+	"testing"
+)
+
+func TestRequestLine(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[HTTPRequestLine]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    HTTPRequestLine
+		wantRemaining string
+	}{
+		{
+			name:  "parsing request line should succeed",
+			input: "GET /index.html?q=1 HTTP/1.1\r\nHost: x\r\n",
+			args: args{
+				parser: RequestLine(),
+			},
+			wantErr:       false,
+			wantOutput:    HTTPRequestLine{Method: "GET", Target: "/index.html?q=1", Version: "HTTP/1.1"},
+			wantRemaining: "Host: x\r\n",
+		},
+		{
+			name:  "parsing missing target should fail",
+			input: "GET  HTTP/1.1\r\n",
+			args: args{
+				parser: RequestLine(),
+			},
+			wantErr:       true,
+			wantOutput:    HTTPRequestLine{},
+			wantRemaining: "GET  HTTP/1.1\r\n",
+		},
+		{
+			name:  "parsing invalid version should fail",
+			input: "GET / HTTP/x.1\r\n",
+			args: args{
+				parser: RequestLine(),
+			},
+			wantErr:       true,
+			wantOutput:    HTTPRequestLine{},
+			wantRemaining: "GET / HTTP/x.1\r\n",
+		},
+		{
+			name:  "parsing missing CRLF should fail",
+			input: "GET / HTTP/1.1\n",
+			args: args{
+				parser: RequestLine(),
+			},
+			wantErr:       true,
+			wantOutput:    HTTPRequestLine{},
+			wantRemaining: "GET / HTTP/1.1\n",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %+v, want output %+v", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkRequestLine(b *testing.B) {
+	parser := RequestLine()
+	input := gomme.NewFromString(1, nil, -1, "GET /index.html HTTP/1.1\r\n")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestStatusLine(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[HTTPStatusLine]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    HTTPStatusLine
+		wantRemaining string
+	}{
+		{
+			name:  "parsing status line should succeed",
+			input: "HTTP/1.1 404 Not Found\r\nrest",
+			args: args{
+				parser: StatusLine(),
+			},
+			wantErr:       false,
+			wantOutput:    HTTPStatusLine{Version: "HTTP/1.1", Code: 404, Reason: "Not Found"},
+			wantRemaining: "rest",
+		},
+		{
+			name:  "parsing empty reason should succeed",
+			input: "HTTP/1.0 200 \r\n",
+			args: args{
+				parser: StatusLine(),
+			},
+			wantErr:       false,
+			wantOutput:    HTTPStatusLine{Version: "HTTP/1.0", Code: 200},
+			wantRemaining: "",
+		},
+		{
+			name:  "parsing short status code should fail",
+			input: "HTTP/1.1 20 OK\r\n",
+			args: args{
+				parser: StatusLine(),
+			},
+			wantErr:       true,
+			wantOutput:    HTTPStatusLine{},
+			wantRemaining: "HTTP/1.1 20 OK\r\n",
+		},
+		{
+			name:  "parsing control character in reason should fail",
+			input: "HTTP/1.1 200 O\x01K\r\n",
+			args: args{
+				parser: StatusLine(),
+			},
+			wantErr:       true,
+			wantOutput:    HTTPStatusLine{},
+			wantRemaining: "HTTP/1.1 200 O\x01K\r\n",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %+v, want output %+v", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkStatusLine(b *testing.B) {
+	parser := StatusLine()
+	input := gomme.NewFromString(1, nil, -1, "HTTP/1.1 200 OK\r\n")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestHeaderField(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[HTTPHeaderField]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    HTTPHeaderField
+		wantRemaining string
+	}{
+		{
+			name:  "parsing header field should succeed",
+			input: "Content-Type:  text/plain \r\nrest",
+			args: args{
+				parser: HeaderField(),
+			},
+			wantErr:       false,
+			wantOutput:    HTTPHeaderField{Name: "Content-Type", Value: "text/plain"},
+			wantRemaining: "rest",
+		},
+		{
+			name:  "parsing missing colon should fail",
+			input: "Content-Type text/plain\r\n",
+			args: args{
+				parser: HeaderField(),
+			},
+			wantErr:       true,
+			wantOutput:    HTTPHeaderField{},
+			wantRemaining: "Content-Type text/plain\r\n",
+		},
+		{
+			name:  "parsing space before colon should fail",
+			input: "Host : x\r\n",
+			args: args{
+				parser: HeaderField(),
+			},
+			wantErr:       true,
+			wantOutput:    HTTPHeaderField{},
+			wantRemaining: "Host : x\r\n",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %+v, want output %+v", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkHeaderField(b *testing.B) {
+	parser := HeaderField()
+	input := gomme.NewFromString(1, nil, -1, "Content-Type: text/plain\r\n")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestHeaderFields(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[[]HTTPHeaderField]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    []HTTPHeaderField
+		wantRemaining string
+	}{
+		{
+			name:  "parsing header fields should succeed",
+			input: "Host: x\r\nAccept: */*\r\n\r\nbody",
+			args: args{
+				parser: HeaderFields(),
+			},
+			wantErr:       false,
+			wantOutput:    []HTTPHeaderField{{Name: "Host", Value: "x"}, {Name: "Accept", Value: "*/*"}},
+			wantRemaining: "body",
+		},
+		{
+			name:  "parsing no header fields should succeed",
+			input: "\r\nbody",
+			args: args{
+				parser: HeaderFields(),
+			},
+			wantErr:       false,
+			wantOutput:    []HTTPHeaderField{},
+			wantRemaining: "body",
+		},
+		{
+			name:  "parsing missing empty line should fail",
+			input: "Host: x\r\n",
+			args: args{
+				parser: HeaderFields(),
+			},
+			wantErr:       true,
+			wantOutput:    []HTTPHeaderField(nil),
+			wantRemaining: "Host: x\r\n",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if !reflect.DeepEqual(gotResult, tc.wantOutput) {
+				t.Errorf("got output %+v, want output %+v", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkHeaderFields(b *testing.B) {
+	parser := HeaderFields()
+	input := gomme.NewFromString(1, nil, -1, "Host: x\r\nAccept: */*\r\n\r\n")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestChunkedBody(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[HTTPChunkedBody]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    HTTPChunkedBody
+		wantRemaining string
+	}{
+		{
+			name:  "parsing chunked body should succeed",
+			input: "4\r\nWiki\r\n5;ext=1\r\npedia\r\n0\r\n\r\nrest",
+			args: args{
+				parser: ChunkedBody(),
+			},
+			wantErr:       false,
+			wantOutput:    HTTPChunkedBody{Data: []byte("Wikipedia"), Trailers: []HTTPHeaderField{}},
+			wantRemaining: "rest",
+		},
+		{
+			name:  "parsing trailers should succeed",
+			input: "0\r\nExpires: never\r\n\r\n",
+			args: args{
+				parser: ChunkedBody(),
+			},
+			wantErr:       false,
+			wantOutput:    HTTPChunkedBody{Data: []byte{}, Trailers: []HTTPHeaderField{{Name: "Expires", Value: "never"}}},
+			wantRemaining: "",
+		},
+		{
+			name:  "parsing too short chunk should fail",
+			input: "a\r\nabc\r\n0\r\n\r\n",
+			args: args{
+				parser: ChunkedBody(),
+			},
+			wantErr:       true,
+			wantOutput:    HTTPChunkedBody{},
+			wantRemaining: "a\r\nabc\r\n0\r\n\r\n",
+		},
+		{
+			name:  "parsing missing chunk size should fail",
+			input: "x\r\n",
+			args: args{
+				parser: ChunkedBody(),
+			},
+			wantErr:       true,
+			wantOutput:    HTTPChunkedBody{},
+			wantRemaining: "x\r\n",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if !reflect.DeepEqual(gotResult, tc.wantOutput) {
+				t.Errorf("got output %+v, want output %+v", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkChunkedBody(b *testing.B) {
+	parser := ChunkedBody()
+	input := gomme.NewFromString(1, nil, -1, "4\r\nWiki\r\n5\r\npedia\r\n0\r\n\r\n")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestHTTPBinaryInput(t *testing.T) {
+	t.Parallel()
+
+	input := gomme.NewFromBytes(1, nil, -1, []byte("GET / HTTP/1.1\r\n"))
+	newState, gotResult := gomme.RunOnState(input, RequestLine())
+	if newState.HasError() {
+		t.Errorf("got error %v, want no error", newState.Errors())
+	}
+	if want := (HTTPRequestLine{Method: "GET", Target: "/", Version: "HTTP/1.1"}); gotResult != want {
+		t.Errorf("got output %+v, want output %+v", gotResult, want)
+	}
+}
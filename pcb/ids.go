@@ -0,0 +1,130 @@
+package pcb
+
+import (
+	"fmt"
+	"github.com/oleiade/gomme"
+)
+
+// UUID parses a UUID in its canonical textual form
+// (e.g. "123e4567-e89b-12d3-a456-426614174000") as defined in RFC 9562.
+// Upper and lower case hex digits are accepted.
+// Errors point to the malformed group.
+//
+// This parser is a good candidate for SaveSpot and has an optimized recoverer.
+func UUID() gomme.Parser[[16]byte] {
+	expected := "UUID"
+	groups := []int{8, 4, 4, 4, 12} // number of hex digits per group
+
+	parse := func(state gomme.State) (gomme.State, [16]byte, *gomme.ParserError) {
+		input := state.CurrentString()
+		uuid := [16]byte{}
+		i, j := 0, 0 // position in input and uuid
+		for g, digits := range groups {
+			if g > 0 {
+				if i >= len(input) || input[i] != '-' {
+					return hexGroupError[[16]byte](state, i, fmt.Sprintf("'-' before %s group %d", expected, g+1))
+				}
+				i++
+			}
+			if !decodeHexGroup(input, i, digits, uuid[j:]) {
+				return hexGroupError[[16]byte](state, i,
+					fmt.Sprintf("%s group %d (%d hex digits)", expected, g+1, digits))
+			}
+			i += digits
+			j += digits / 2
+		}
+		return state.MoveBy(i), uuid, nil
+	}
+
+	return gomme.NewParser[[16]byte](expected, parse, hexGroupRecoverer(groups[0]))
+}
+
+// MACAddress parses an EUI-48 MAC address of 6 groups of 2 hex digits
+// that are separated by ':' or '-' (e.g. "01:23:45:67:89:ab").
+// All separators have to be the same.
+// Errors point to the malformed group.
+//
+// This parser is a good candidate for SaveSpot and has an optimized recoverer.
+func MACAddress() gomme.Parser[[6]byte] {
+	expected := "MAC address"
+
+	parse := func(state gomme.State) (gomme.State, [6]byte, *gomme.ParserError) {
+		input := state.CurrentString()
+		mac := [6]byte{}
+		sep := byte(0)
+		i := 0
+		for g := range mac {
+			if g > 0 {
+				if i >= len(input) || (input[i] != ':' && input[i] != '-') || (sep != 0 && input[i] != sep) {
+					msg := fmt.Sprintf("':' or '-' before %s group %d", expected, g+1)
+					if sep != 0 {
+						msg = fmt.Sprintf("%q before %s group %d", sep, expected, g+1)
+					}
+					return hexGroupError[[6]byte](state, i, msg)
+				}
+				sep = input[i]
+				i++
+			}
+			if !decodeHexGroup(input, i, 2, mac[g:]) {
+				return hexGroupError[[6]byte](state, i, fmt.Sprintf("%s group %d (2 hex digits)", expected, g+1))
+			}
+			i += 2
+		}
+		return state.MoveBy(i), mac, nil
+	}
+
+	return gomme.NewParser[[6]byte](expected, parse, hexGroupRecoverer(2))
+}
+
+// decodeHexGroup decodes `digits` hex digits of input starting at `start`
+// into dst. It returns false if there aren't enough hex digits.
+func decodeHexGroup(input string, start, digits int, dst []byte) bool {
+	if start+digits > len(input) {
+		return false
+	}
+	for k := 0; k < digits; k += 2 {
+		hi, lo := hexValue(input[start+k]), hexValue(input[start+k+1])
+		if hi < 0 || lo < 0 {
+			return false
+		}
+		dst[k/2] = byte(hi<<4 | lo)
+	}
+	return true
+}
+
+func hexValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
+
+func hexGroupError[Output any](state gomme.State, i int, expected string) (gomme.State, Output, *gomme.ParserError) {
+	errState := state.MoveBy(i).NewError(expected)
+	return state.Preserve(errState), gomme.ZeroOf[Output](), errState.CurrentError()
+}
+
+// hexGroupRecoverer finds the next position with at least `digits`
+// hex digits in a row.
+func hexGroupRecoverer(digits int) gomme.Recoverer {
+	return func(state gomme.State) int {
+		input := state.CurrentString()
+		count := 0
+		for i := 0; i < len(input); i++ {
+			if hexValue(input[i]) < 0 {
+				count = 0
+				continue
+			}
+			count++
+			if count == digits {
+				return i + 1 - digits
+			}
+		}
+		return -1
+	}
+}
@@ -0,0 +1,205 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"strings"
+	"testing"
+)
+
+func TestUUID(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[[16]byte]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    [16]byte
+		wantRemaining string
+	}{
+		{
+			name:  "parsing lower case UUID should succeed",
+			input: "123e4567-e89b-12d3-a456-426614174000 x",
+			args: args{
+				parser: UUID(),
+			},
+			wantErr:       false,
+			wantOutput:    [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00},
+			wantRemaining: " x",
+		},
+		{
+			name:  "parsing upper case UUID should succeed",
+			input: "FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF",
+			args: args{
+				parser: UUID(),
+			},
+			wantErr:       false,
+			wantOutput:    [16]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			wantRemaining: "",
+		},
+		{
+			name:  "parsing malformed group should fail",
+			input: "123e4567-e89b-12g3-a456-426614174000",
+			args: args{
+				parser: UUID(),
+			},
+			wantErr:       true,
+			wantOutput:    [16]byte{},
+			wantRemaining: "123e4567-e89b-12g3-a456-426614174000",
+		},
+		{
+			name:  "parsing missing dash should fail",
+			input: "123e4567e89b12d3a456426614174000",
+			args: args{
+				parser: UUID(),
+			},
+			wantErr:       true,
+			wantOutput:    [16]byte{},
+			wantRemaining: "123e4567e89b12d3a456426614174000",
+		},
+		{
+			name:  "parsing short UUID should fail",
+			input: "123e4567-e89b-12d3-a456-4266",
+			args: args{
+				parser: UUID(),
+			},
+			wantErr:       true,
+			wantOutput:    [16]byte{},
+			wantRemaining: "123e4567-e89b-12d3-a456-4266",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %x, want output %x", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkUUID(b *testing.B) {
+	parser := UUID()
+	input := gomme.NewFromString(1, nil, -1, "123e4567-e89b-12d3-a456-426614174000")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestMACAddress(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[[6]byte]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    [6]byte
+		wantRemaining string
+	}{
+		{
+			name:  "parsing colon separated address should succeed",
+			input: "01:23:45:67:89:ab x",
+			args: args{
+				parser: MACAddress(),
+			},
+			wantErr:       false,
+			wantOutput:    [6]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab},
+			wantRemaining: " x",
+		},
+		{
+			name:  "parsing dash separated address should succeed",
+			input: "01-23-45-67-89-AB",
+			args: args{
+				parser: MACAddress(),
+			},
+			wantErr:       false,
+			wantOutput:    [6]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab},
+			wantRemaining: "",
+		},
+		{
+			name:  "parsing mixed separators should fail",
+			input: "01:23-45:67:89:ab",
+			args: args{
+				parser: MACAddress(),
+			},
+			wantErr:       true,
+			wantOutput:    [6]byte{},
+			wantRemaining: "01:23-45:67:89:ab",
+		},
+		{
+			name:  "parsing malformed group should fail",
+			input: "01:23:4:67:89:ab",
+			args: args{
+				parser: MACAddress(),
+			},
+			wantErr:       true,
+			wantOutput:    [6]byte{},
+			wantRemaining: "01:23:4:67:89:ab",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %x, want output %x", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkMACAddress(b *testing.B) {
+	parser := MACAddress()
+	input := gomme.NewFromString(1, nil, -1, "01:23:45:67:89:ab")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestUUIDErrorPosition(t *testing.T) {
+	t.Parallel()
+
+	newState, _ := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, "123e4567-e89b-12g3-a456-426614174000"), UUID())
+	if !newState.HasError() {
+		t.Fatalf("got no error, want error")
+	}
+	if msg := newState.Errors().Error(); !strings.Contains(msg, "[1:15]") || !strings.Contains(msg, "group 3") {
+		t.Errorf("got error %q, want error at group 3 [1:15]", msg)
+	}
+}
@@ -0,0 +1,175 @@
+package pcb
+
+import (
+	"cmp"
+	"github.com/oleiade/gomme"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// keywordSetKey is the key of the keywords added to a set in the state.
+type keywordSetKey string
+
+// DynamicKeyword parses the longest keyword of the keyword set `name`.
+// The set consists of the `initial` keywords and the ones added to the
+// state with AddKeywords or WithKeywords.
+// So languages with user defined operators or contextual keywords
+// (SQL dialects, Haskell-like languages) can be parsed.
+// A keyword ending with a letter, digit or '_' only matches if the
+// input doesn't continue with such a character (e.g. "select" doesn't
+// match "selected").
+// `name` is used as expectation in error messages.
+func DynamicKeyword(name string, initial ...string) gomme.Parser[string] {
+	initial = sortKeywords(slices.Clone(initial))
+
+	parse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		input := state.CurrentString()
+		keyword := longestKeyword(input, initial)
+		if kw := keywordsOf(state, name).longest(input); len(kw) > len(keyword) {
+			keyword = kw
+		}
+		if keyword == "" {
+			errState := state.NewError(name)
+			return errState, "", errState.CurrentError()
+		}
+		return state.MoveBy(len(keyword)), keyword, nil
+	}
+	return gomme.NewParser[string](name, parse, BasicRecovererFunc(parse))
+}
+
+// AddKeywords runs the parser and adds the keywords returned by
+// `keywords` for its output to the keyword set `name` (see
+// DynamicKeyword). This is typically used for declarations
+// (e.g. `infixl 6 <+>`).
+// The keywords are stored in the state. So they are dropped again if
+// a parser backtracks.
+func AddKeywords[Output any](name string, parse gomme.Parser[Output], keywords func(Output) []string) gomme.Parser[Output] {
+	addParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		newState, output, err := parse.It(state)
+		if err != nil {
+			return newState, output, err
+		}
+		return WithKeywords(newState, name, keywords(output)...), output, nil
+	}
+	return gomme.NewParser[Output](parse.Expected(), addParse, parse.Recover)
+}
+
+// WithKeywords returns the state with the keywords added to the keyword
+// set `name` (see DynamicKeyword).
+// So a parse can be started with keywords of a dialect.
+func WithKeywords(state gomme.State, name string, keywords ...string) gomme.State {
+	if len(keywords) == 0 {
+		return state
+	}
+	return state.WithUserValue(keywordSetKey(name), keywordsOf(state, name).add(keywords))
+}
+
+// keywordsOf returns the keywords added to the set `name` in the state.
+func keywordsOf(state gomme.State, name string) keywordView {
+	value, _ := state.UserValue(keywordSetKey(name))
+	view, _ := value.(keywordView)
+	return view
+}
+
+// keywordSet is an append-only list of keywords shared by the states of
+// a parse. A state only sees the first keywords of it (see keywordView).
+// So adding keywords doesn't copy the set and backtracking drops the
+// added keywords without touching the set.
+type keywordSet struct {
+	keywords []string
+	index    map[string]int // position of every keyword in keywords
+	byFirst  map[byte][]int // positions of the keywords by their first byte
+}
+
+func (ks *keywordSet) append(keyword string) {
+	ks.index[keyword] = len(ks.keywords)
+	ks.byFirst[keyword[0]] = append(ks.byFirst[keyword[0]], len(ks.keywords))
+	ks.keywords = append(ks.keywords, keyword)
+}
+
+// keywordView is the part of a keywordSet seen by a state.
+type keywordView struct {
+	set *keywordSet
+	n   int // number of keywords seen
+}
+
+// add returns the view with the keywords added.
+// The set is only copied if another state has added keywords to it
+// already (after backtracking).
+func (kv keywordView) add(keywords []string) keywordView {
+	set := kv.set
+	if set == nil || kv.n < len(set.keywords) {
+		set = &keywordSet{index: make(map[string]int), byFirst: make(map[byte][]int)}
+		if kv.set != nil {
+			for _, kw := range kv.set.keywords[:kv.n] {
+				set.append(kw)
+			}
+		}
+	}
+	for _, kw := range keywords {
+		if _, ok := set.index[kw]; !ok && kw != "" {
+			set.append(kw)
+		}
+	}
+	return keywordView{set: set, n: len(set.keywords)}
+}
+
+// longest returns the longest keyword of the view at the start of the
+// input or "".
+func (kv keywordView) longest(input string) string {
+	if kv.set == nil || input == "" {
+		return ""
+	}
+	keyword := ""
+	for _, i := range kv.set.byFirst[input[0]] {
+		if i >= kv.n { // positions are ascending
+			break
+		}
+		if kw := kv.set.keywords[i]; len(kw) > len(keyword) && isKeywordAt(input, kw) {
+			keyword = kw
+		}
+	}
+	return keyword
+}
+
+// sortKeywords sorts the keywords by descending length and removes
+// empty and duplicate ones. So the first match is the longest one.
+func sortKeywords(keywords []string) []string {
+	slices.SortFunc(keywords, func(a, b string) int {
+		if c := cmp.Compare(len(b), len(a)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	keywords = slices.Compact(keywords)
+	if n := len(keywords); n > 0 && keywords[n-1] == "" {
+		keywords = keywords[:n-1]
+	}
+	return keywords
+}
+
+// longestKeyword returns the longest keyword (of the sorted ones) at the
+// start of the input or "".
+func longestKeyword(input string, keywords []string) string {
+	for _, kw := range keywords {
+		if isKeywordAt(input, kw) {
+			return kw
+		}
+	}
+	return ""
+}
+
+// isKeywordAt returns true if the input starts with the keyword and
+// doesn't continue the last word of it.
+func isKeywordAt(input, keyword string) bool {
+	if !strings.HasPrefix(input, keyword) {
+		return false
+	}
+	if len(input) == len(keyword) {
+		return true
+	}
+	last, _ := utf8.DecodeLastRuneInString(keyword)
+	next, _ := utf8.DecodeRuneInString(input[len(keyword):])
+	return !IsAlphanumeric(last) || !IsAlphanumeric(next)
+}
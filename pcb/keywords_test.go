@@ -0,0 +1,126 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"strings"
+	"testing"
+)
+
+func TestDynamicKeyword(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		added         []string
+		input         string
+		wantErr       string
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:          "parsing initial keyword should succeed",
+			input:         "select *",
+			wantOutput:    "select",
+			wantRemaining: " *",
+		}, {
+			name:          "longest keyword should win",
+			input:         "<= 1",
+			wantOutput:    "<=",
+			wantRemaining: " 1",
+		}, {
+			name:          "prefix of a word should fail",
+			input:         "selected",
+			wantErr:       "expected operator [1:1]",
+			wantRemaining: "selected",
+		}, {
+			name:          "parsing added keyword should succeed",
+			added:         []string{"<+>", "qualify"},
+			input:         "qualify x",
+			wantOutput:    "qualify",
+			wantRemaining: " x",
+		}, {
+			name:          "added keyword longer than initial one should win",
+			added:         []string{"<+>"},
+			input:         "<+> 1",
+			wantOutput:    "<+>",
+			wantRemaining: " 1",
+		}, {
+			name:          "keyword of a different state should fail",
+			input:         "qualify x",
+			wantErr:       "expected operator [1:1]",
+			wantRemaining: "qualify x",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := WithKeywords(gomme.NewFromString(tc.input, false), "operator", tc.added...)
+			newState, gotResult := gomme.RunOnState(state, DynamicKeyword("operator", "select", "<", "<="))
+			if newState.HasError() != (tc.wantErr != "") {
+				t.Errorf("got error %v, want error %q", newState.Errors(), tc.wantErr)
+			}
+			if tc.wantErr != "" {
+				if msg := newState.Errors().Error(); !strings.Contains(msg, tc.wantErr) {
+					t.Errorf("got error %q, want error %q", msg, tc.wantErr)
+				}
+			}
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestAddKeywords(t *testing.T) {
+	t.Parallel()
+
+	declaration := AddKeywords("operator", Delimited(String("infix "), Alpha1(), Char(';')), func(name string) []string {
+		return []string{name}
+	})
+	parser := Sequence(declaration, DynamicKeyword("operator"))
+
+	newState, output := gomme.RunOnState(gomme.NewFromString("infix dot;dot", false), parser)
+	if newState.HasError() {
+		t.Fatalf("got unexpected error: %v", newState.Errors())
+	}
+	if len(output) != 2 || output[1] != "dot" {
+		t.Errorf("got output %q, want [dot dot]", output)
+	}
+	if !newState.AtEnd() {
+		t.Errorf("got remaining %q, want all input consumed", newState.CurrentString())
+	}
+}
+
+func TestAddKeywordsBacktracking(t *testing.T) {
+	t.Parallel()
+
+	declaration := AddKeywords("operator", Delimited(String("infix "), Alpha1(), Char(';')), func(name string) []string {
+		return []string{name}
+	})
+	declOrWord := FirstSuccessful(declaration, Alpha1()) // its result is cached and reused after backtracking
+	parser := FirstSuccessful(
+		Sequence(declOrWord, String("!")),
+		Sequence(declOrWord, DynamicKeyword("operator")),
+	)
+
+	newState, output := gomme.RunOnState(gomme.NewFromString("infix dot;dot", false), parser)
+	if newState.HasError() {
+		t.Fatalf("got unexpected error: %v", newState.Errors())
+	}
+	if len(output) != 2 || output[1] != "dot" {
+		t.Errorf("got output %q, want [dot dot]", output)
+	}
+
+	// the keyword isn't known without the declaration
+	newState, _ = gomme.RunOnState(gomme.NewFromString("dot dot", false), parser)
+	if !newState.HasError() {
+		t.Errorf("got no error for an undeclared operator")
+	}
+}
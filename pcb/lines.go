@@ -0,0 +1,154 @@
+package pcb
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/oleiade/gomme"
+)
+
+// Continuation defines how a physical line is continued by the next one.
+type Continuation uint8
+
+const (
+	// BackslashNewline continues a line ending with `\` (Makefile, shell).
+	// The backslash and the line break are removed.
+	BackslashNewline Continuation = iota
+	// IndentedLine continues a line if the next line starts with a space
+	// or tab (YAML-ish formats, folded mail headers).
+	// Only the line break is removed.
+	IndentedLine
+)
+
+// LineMap maps positions in a logical line back to positions in the
+// physical input.
+type LineMap struct {
+	segments []lineSegment
+}
+
+// lineSegment is a part of a logical line that is contiguous in the
+// physical input.
+type lineSegment struct {
+	logical  int // start in the logical line
+	physical int // start in the physical input
+}
+
+// Physical returns the position in the physical input (relative to the
+// start of the logical line) of the position `pos` in the logical line.
+func (lm LineMap) Physical(pos int) int {
+	i := sort.Search(len(lm.segments), func(i int) bool {
+		return lm.segments[i].logical > pos
+	}) - 1
+	if i < 0 {
+		return pos
+	}
+	seg := lm.segments[i]
+	return seg.physical + pos - seg.logical
+}
+
+// JoinLogicalLine joins the physical lines at the start of the input
+// into one logical line.
+// It returns the logical line without any line break, the number of bytes
+// consumed from the input (including the final line break) and the mapping
+// of positions back to the input.
+func JoinLogicalLine(input string, cont Continuation) (line string, consumed int, lm LineMap) {
+	sb := strings.Builder{}
+	segStart := 0
+	for {
+		lm.segments = append(lm.segments, lineSegment{logical: sb.Len(), physical: segStart})
+		nl := strings.IndexByte(input[segStart:], '\n')
+		if nl < 0 {
+			sb.WriteString(input[segStart:])
+			return sb.String(), len(input), lm
+		}
+		end := segStart + nl // index of the line break
+		lineEnd := end
+		if lineEnd > segStart && input[lineEnd-1] == '\r' {
+			lineEnd--
+		}
+
+		switch {
+		case cont == BackslashNewline && lineEnd > segStart && input[lineEnd-1] == '\\':
+			sb.WriteString(input[segStart : lineEnd-1])
+		case cont == IndentedLine && end+1 < len(input) && (input[end+1] == ' ' || input[end+1] == '\t'):
+			sb.WriteString(input[segStart:lineEnd])
+		default:
+			sb.WriteString(input[segStart:lineEnd])
+			return sb.String(), end + 1, lm
+		}
+		segStart = end + 1
+	}
+}
+
+// LogicalLine joins the physical lines at the current position into one
+// logical line (see JoinLogicalLine) and parses it with the provided parser.
+// The parser has to consume the whole logical line.
+// The final line break is consumed, too.
+//
+// The parser runs with the configuration, user values and budget of the
+// state (see gomme.State.Embed).
+// Its errors (including semantic errors) are mapped back to the exact
+// position in the physical lines. So line and column of errors are correct.
+// The parser doesn't recover from errors.
+func LogicalLine[Output any](cont Continuation, parse gomme.Parser[Output]) gomme.Parser[Output] {
+	expected := "logical line with " + parse.Expected()
+
+	lineParse := func(state gomme.State) (gomme.State, Output, *gomme.ParserError) {
+		line, n, lm := JoinLogicalLine(state.CurrentString(), cont)
+
+		innerState, output, err := parse.It(state.Embed(gomme.NewFromString(line, false)))
+		if err == nil && !innerState.AtEnd() {
+			innerState = innerState.NewError("end of line")
+			err = innerState.CurrentError()
+		}
+		state = state.Unembed(innerState, lm.Physical)
+		if err != nil {
+			errState := state.MoveBy(lm.Physical(err.Pos())).NewFailure(err.Text())
+			return state.Preserve(errState), gomme.ZeroOf[Output](), errState.CurrentError()
+		}
+		return state.MoveBy(n), output, nil
+	}
+	return gomme.NewParser[Output](expected, lineParse, Forbidden("LogicalLine"))
+}
+
+// Lines parses the input line by line with the provided parser until the
+// end of the input.
+// The parser gets a single line without the line break and has to consume
+// all of it. So a broken line can never consume the next one.
+// The parser runs with the configuration, user values and budget of the
+// state (see gomme.State.Embed).
+// A bad line is reported as one semantic error at the exact position in
+// the input and is skipped. Then parsing continues with the next line.
+// Semantic errors reported by the parser are kept at their exact position,
+// too. But they don't make a line bad.
+// The output contains the outputs of all good lines in order.
+func Lines[Output any](parse gomme.Parser[Output]) gomme.Parser[[]Output] {
+	expected := "lines of " + parse.Expected()
+
+	linesParse := func(state gomme.State) (gomme.State, []Output, *gomme.ParserError) {
+		var outputs []Output
+		for !state.AtEnd() {
+			input := state.CurrentString()
+			line, n := input, len(input)
+			if nl := strings.IndexByte(input, '\n'); nl >= 0 {
+				line, n = input[:nl], nl+1
+			}
+			line = strings.TrimSuffix(line, "\r")
+
+			innerState, output, err := parse.It(state.Embed(gomme.NewFromString(line, false)))
+			if err == nil && !innerState.AtEnd() {
+				innerState = innerState.NewError("end of line")
+				err = innerState.CurrentError()
+			}
+			state = state.Unembed(innerState, func(pos int) int { return pos })
+			if err != nil {
+				state = state.MoveBy(err.Pos()).NewSemanticError(err.Text()).MoveBy(n - err.Pos())
+				continue
+			}
+			outputs = append(outputs, output)
+			state = state.MoveBy(n)
+		}
+		return state, outputs, nil
+	}
+	return gomme.NewParser[[]Output](expected, linesParse, Forbidden("Lines"))
+}
@@ -0,0 +1,131 @@
+package pcb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oleiade/gomme"
+)
+
+func TestJoinLogicalLine(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		input        string
+		cont         Continuation
+		wantLine     string
+		wantConsumed int
+		logicalPos   int
+		wantPhysical int
+	}{
+		{name: "single line", input: "abc\ndef", cont: BackslashNewline, wantLine: "abc", wantConsumed: 4, logicalPos: 2, wantPhysical: 2},
+		{name: "last line", input: "abc", cont: BackslashNewline, wantLine: "abc", wantConsumed: 3, logicalPos: 1, wantPhysical: 1},
+		{name: "backslash", input: "ab\\\ncd\\\r\nef\ngh", cont: BackslashNewline, wantLine: "abcdef", wantConsumed: 12, logicalPos: 4, wantPhysical: 9},
+		{name: "indented", input: "ab\n cd\n\tef\ngh", cont: IndentedLine, wantLine: "ab cd\tef", wantConsumed: 11, logicalPos: 6, wantPhysical: 8},
+		{name: "indentation without continuation", input: "ab\n cd", cont: BackslashNewline, wantLine: "ab", wantConsumed: 3, logicalPos: 0, wantPhysical: 0},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			line, consumed, lm := JoinLogicalLine(tc.input, tc.cont)
+			if line != tc.wantLine || consumed != tc.wantConsumed {
+				t.Errorf("got (%q, %d), want (%q, %d)", line, consumed, tc.wantLine, tc.wantConsumed)
+			}
+			if got := lm.Physical(tc.logicalPos); got != tc.wantPhysical {
+				t.Errorf("got physical position %d, want %d", got, tc.wantPhysical)
+			}
+		})
+	}
+}
+
+func TestLogicalLine(t *testing.T) {
+	t.Parallel()
+
+	parser := LogicalLine(BackslashNewline, Digit1())
+
+	newState, output := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, "12\\\n34\nrest"), parser)
+	if newState.HasError() {
+		t.Fatalf("got unexpected error: %v", newState.Errors())
+	}
+	if output != "1234" || newState.CurrentString() != "rest" {
+		t.Errorf("got (%q, %q), want (%q, %q)", output, newState.CurrentString(), "1234", "rest")
+	}
+
+	newState, _ = gomme.RunOnState(gomme.NewFromString(-1, nil, -1, "12\\\n3x\n"), parser)
+	if !newState.HasError() {
+		t.Fatalf("got no error, want error")
+	}
+	if msg := newState.Errors().Error(); !strings.Contains(msg, "[2:2]") {
+		t.Errorf("got error %q, want error at [2:2]", msg)
+	}
+}
+
+func TestLogicalLineSemanticError(t *testing.T) {
+	t.Parallel()
+
+	newState, output := gomme.RunOnState(gomme.NewFromString("12\\\n30\nrest", false), LogicalLine(BackslashNewline, noZeros()))
+	if output != "1230" || newState.CurrentString() != "rest" {
+		t.Errorf("got (%q, %q), want (%q, %q)", output, newState.CurrentString(), "1230", "rest")
+	}
+	if !newState.HasError() {
+		t.Fatalf("got no error, want semantic error")
+	}
+	if msg := newState.Errors().Error(); !strings.Contains(msg, "zero [2:2]") {
+		t.Errorf("got error %q, want error at [2:2]", msg)
+	}
+}
+
+// noZeros parses digits and reports the first 0 as semantic error.
+func noZeros() gomme.Parser[string] {
+	digits := Digit1()
+	return gomme.NewParser[string](digits.Expected(), func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		newState, output, err := digits.It(state)
+		if i := strings.IndexByte(output, '0'); err == nil && i >= 0 {
+			newState = state.MoveBy(i).NewSemanticError("zero").MoveBy(len(output) - i)
+		}
+		return newState, output, err
+	}, digits.Recover)
+}
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+
+	parser := Lines(Digit1())
+
+	newState, output := gomme.RunOnState(gomme.NewFromString("1\nx2\r\n3\n4y", false), parser)
+	if want := []string{"1", "3"}; strings.Join(output, ",") != strings.Join(want, ",") {
+		t.Errorf("got output %q, want output %q", output, want)
+	}
+	if !newState.AtEnd() {
+		t.Errorf("got remaining %q, want no remaining input", newState.CurrentString())
+	}
+	msg := newState.Errors().Error()
+	for _, pos := range []string{"[2:1]", "[4:2]"} {
+		if !strings.Contains(msg, pos) {
+			t.Errorf("got error %q, want error at %s", msg, pos)
+		}
+	}
+	if strings.Contains(msg, "[3:") {
+		t.Errorf("got error %q, want no error in line 3", msg)
+	}
+}
+
+func TestLinesSemanticError(t *testing.T) {
+	t.Parallel()
+
+	newState, output := gomme.RunOnState(gomme.NewFromString("1\n20\n3", false), Lines(noZeros()))
+	if want := []string{"1", "20", "3"}; strings.Join(output, ",") != strings.Join(want, ",") {
+		t.Errorf("got output %q, want output %q", output, want)
+	}
+	if !newState.HasError() {
+		t.Fatalf("got no error, want semantic error")
+	}
+	if msg := newState.Errors().Error(); !strings.Contains(msg, "zero [2:2]") {
+		t.Errorf("got error %q, want error at [2:2]", msg)
+	}
+}
@@ -0,0 +1,122 @@
+package pcb
+
+import (
+	"fmt"
+	"github.com/oleiade/gomme"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// GoString parses a Go string literal and returns its decoded value.
+// Both interpreted string literals ("...") with all escape sequences of the
+// Go language specification (including hex, octal and Unicode escapes)
+// and raw string literals (`...`) are supported.
+// The escape sequences are a superset of those in C.
+// Errors are reported at the exact position of the invalid escape sequence.
+//
+// This parser is a good candidate for SaveSpot and has an optimized recoverer.
+func GoString() gomme.Parser[string] {
+	expected := "string literal"
+
+	parse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		input := state.CurrentString()
+		if input == "" {
+			errState := state.NewError(expected + " (at EOF)")
+			return errState, "", errState.CurrentError()
+		}
+
+		switch input[0] {
+		case '"':
+			return interpretedString(state, input)
+		case '`':
+			return rawString(state, input)
+		}
+		r, _ := utf8.DecodeRuneInString(input)
+		errState := state.NewError(fmt.Sprintf("%s (got %q)", expected, r))
+		return errState, "", errState.CurrentError()
+	}
+
+	return gomme.NewParser[string](expected, parse, IndexOfAny('"', '`'))
+}
+
+func interpretedString(state gomme.State, input string) (gomme.State, string, *gomme.ParserError) {
+	sb := strings.Builder{}
+	i := 1 // skip the opening quote
+	for {
+		if i >= len(input) {
+			errState := state.MoveBy(i).NewError(`closing '"' (at EOF)`)
+			return state.Preserve(errState), "", errState.CurrentError()
+		}
+		switch input[i] {
+		case '"':
+			return state.MoveBy(i + 1), sb.String(), nil
+		case '\n':
+			errState := state.MoveBy(i).NewError(`closing '"' (got newline)`)
+			return state.Preserve(errState), "", errState.CurrentError()
+		}
+
+		r, multibyte, tail, err := strconv.UnquoteChar(input[i:], '"')
+		if err != nil {
+			errState := state.MoveBy(i).NewError("valid escape sequence")
+			return state.Preserve(errState), "", errState.CurrentError()
+		}
+		if r < utf8.RuneSelf || !multibyte {
+			sb.WriteByte(byte(r))
+		} else {
+			sb.WriteRune(r)
+		}
+		i = len(input) - len(tail)
+	}
+}
+
+func rawString(state gomme.State, input string) (gomme.State, string, *gomme.ParserError) {
+	end := strings.IndexByte(input[1:], '`')
+	if end < 0 {
+		errState := state.MoveBy(len(input)).NewError("closing '`' (at EOF)")
+		return state.Preserve(errState), "", errState.CurrentError()
+	}
+	// carriage returns are discarded from the raw string value (Go spec)
+	return state.MoveBy(end + 2), strings.ReplaceAll(input[1:end+1], "\r", ""), nil
+}
+
+// GoRune parses a Go rune literal ('x', '\n', '\u00e4', ...) and returns
+// its decoded value.
+// All escape sequences of the Go language specification are supported.
+// Errors are reported at the exact position of the invalid escape sequence.
+//
+// This parser is a good candidate for SaveSpot and has an optimized recoverer.
+func GoRune() gomme.Parser[rune] {
+	expected := "rune literal"
+
+	parse := func(state gomme.State) (gomme.State, rune, *gomme.ParserError) {
+		input := state.CurrentString()
+		if input == "" {
+			errState := state.NewError(expected + " (at EOF)")
+			return errState, utf8.RuneError, errState.CurrentError()
+		}
+		if input[0] != '\'' {
+			r, _ := utf8.DecodeRuneInString(input)
+			errState := state.NewError(fmt.Sprintf("%s (got %q)", expected, r))
+			return errState, utf8.RuneError, errState.CurrentError()
+		}
+		if len(input) < 2 || input[1] == '\'' || input[1] == '\n' {
+			errState := state.MoveBy(1).NewError("character")
+			return state.Preserve(errState), utf8.RuneError, errState.CurrentError()
+		}
+
+		r, _, tail, err := strconv.UnquoteChar(input[1:], '\'')
+		if err != nil {
+			errState := state.MoveBy(1).NewError("valid escape sequence")
+			return state.Preserve(errState), utf8.RuneError, errState.CurrentError()
+		}
+		i := len(input) - len(tail)
+		if tail == "" || tail[0] != '\'' {
+			errState := state.MoveBy(i).NewError(`closing "'"`)
+			return state.Preserve(errState), utf8.RuneError, errState.CurrentError()
+		}
+		return state.MoveBy(i + 1), r, nil
+	}
+
+	return gomme.NewParser[rune](expected, parse, IndexOf('\''))
+}
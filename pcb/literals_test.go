@@ -0,0 +1,227 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGoString(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        gomme.Parser[string]
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:          "parsing interpreted string should succeed",
+			parser:        GoString(),
+			input:         `"abc" def`,
+			wantErr:       false,
+			wantOutput:    "abc",
+			wantRemaining: " def",
+		},
+		{
+			name:          "parsing escapes should succeed",
+			parser:        GoString(),
+			input:         `"a\tb\x41\u00e4\101\"" def`,
+			wantErr:       false,
+			wantOutput:    "a\tbAäA\"",
+			wantRemaining: " def",
+		},
+		{
+			name:          "parsing raw string should succeed",
+			parser:        GoString(),
+			input:         "`a\\b\r\nc` def",
+			wantErr:       false,
+			wantOutput:    "a\\b\nc",
+			wantRemaining: " def",
+		},
+		{
+			name:          "parsing invalid escape should fail",
+			parser:        GoString(),
+			input:         `"a\qb" def`,
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: `"a\qb" def`,
+		},
+		{
+			name:          "parsing unterminated string should fail",
+			parser:        GoString(),
+			input:         `"abc`,
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: `"abc`,
+		},
+		{
+			name:          "parsing newline in string should fail",
+			parser:        GoString(),
+			input:         "\"ab\nc\"",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "\"ab\nc\"",
+		},
+		{
+			name:          "parsing no string should fail",
+			parser:        GoString(),
+			input:         "abc",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "abc",
+		},
+		{
+			name:          "parsing empty input should fail",
+			parser:        GoString(),
+			input:         "",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkGoString(b *testing.B) {
+	parser := GoString()
+	input := gomme.NewFromString(1, nil, -1, `"a\tb\x41\u00e4" def`)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestGoRune(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        gomme.Parser[rune]
+		input         string
+		wantErr       bool
+		wantOutput    rune
+		wantRemaining string
+	}{
+		{
+			name:          "parsing simple rune should succeed",
+			parser:        GoRune(),
+			input:         "'a' b",
+			wantErr:       false,
+			wantOutput:    'a',
+			wantRemaining: " b",
+		},
+		{
+			name:          "parsing escaped rune should succeed",
+			parser:        GoRune(),
+			input:         `'\n' b`,
+			wantErr:       false,
+			wantOutput:    '\n',
+			wantRemaining: " b",
+		},
+		{
+			name:          "parsing Unicode escape should succeed",
+			parser:        GoRune(),
+			input:         `'\u00e4' b`,
+			wantErr:       false,
+			wantOutput:    'ä',
+			wantRemaining: " b",
+		},
+		{
+			name:          "parsing quote escape should succeed",
+			parser:        GoRune(),
+			input:         `'\'' b`,
+			wantErr:       false,
+			wantOutput:    '\'',
+			wantRemaining: " b",
+		},
+		{
+			name:          "parsing invalid escape should fail",
+			parser:        GoRune(),
+			input:         `'\q' b`,
+			wantErr:       true,
+			wantOutput:    utf8.RuneError,
+			wantRemaining: `'\q' b`,
+		},
+		{
+			name:          "parsing two characters should fail",
+			parser:        GoRune(),
+			input:         "'ab' c",
+			wantErr:       true,
+			wantOutput:    utf8.RuneError,
+			wantRemaining: "'ab' c",
+		},
+		{
+			name:          "parsing empty rune should fail",
+			parser:        GoRune(),
+			input:         "'' c",
+			wantErr:       true,
+			wantOutput:    utf8.RuneError,
+			wantRemaining: "'' c",
+		},
+		{
+			name:          "parsing empty input should fail",
+			parser:        GoRune(),
+			input:         "",
+			wantErr:       true,
+			wantOutput:    utf8.RuneError,
+			wantRemaining: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkGoRune(b *testing.B) {
+	parser := GoRune()
+	input := gomme.NewFromString(1, nil, -1, `'\u00e4' b`)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
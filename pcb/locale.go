@@ -0,0 +1,162 @@
+package pcb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NumberOption configures the format of numbers for Float and Int64.
+// So numbers formatted for other locales (e.g. "1.234.567,89") can be
+// parsed without pre-processing the input.
+type NumberOption func(*numberFormat)
+
+type numberFormat struct {
+	decimalSep byte  // separator of the fraction
+	groupSep   byte  // separator of digit groups (0: no grouping)
+	grouping   []int // sizes of the digit groups from the right; the last one repeats
+}
+
+// WithDecimalComma uses ',' as decimal separator (e.g. "3,14").
+func WithDecimalComma() NumberOption {
+	return WithDecimalSeparator(',')
+}
+
+// WithDecimalSeparator uses `sep` as decimal separator instead of '.'.
+func WithDecimalSeparator(sep byte) NumberOption {
+	return func(nf *numberFormat) {
+		nf.decimalSep = sep
+	}
+}
+
+// WithThousandsSeparator allows `sep` between groups of digits of the
+// integer part (e.g. '.' for "1.234.567" or an apostrophe for "1'234'567").
+// Numbers without any separator are still allowed.
+// But if a number contains separators, all groups have to match the
+// grouping (see WithGrouping).
+func WithThousandsSeparator(sep byte) NumberOption {
+	return func(nf *numberFormat) {
+		nf.groupSep = sep
+	}
+}
+
+// WithGrouping sets the sizes of the digit groups from the right.
+// The last size is used for all remaining groups.
+// The leftmost group may be shorter.
+// The default is 3 and Indian numbers (e.g. "12,34,567") use 3, 2.
+// This function panics during the construction phase if no sizes are
+// given or a size isn't positive.
+func WithGrouping(sizes ...int) NumberOption {
+	if len(sizes) == 0 {
+		panic("WithGrouping needs at least one group size")
+	}
+	for _, size := range sizes {
+		if size <= 0 {
+			panic(fmt.Sprintf("WithGrouping needs positive group sizes, but got: %d", size))
+		}
+	}
+	return func(nf *numberFormat) {
+		nf.grouping = sizes
+	}
+}
+
+func newNumberFormat(opts []NumberOption) *numberFormat {
+	nf := &numberFormat{decimalSep: '.', grouping: []int{3}}
+	for _, opt := range opts {
+		opt(nf)
+	}
+	if nf.groupSep != 0 && nf.groupSep == nf.decimalSep {
+		panic(fmt.Sprintf("the thousands and the decimal separator are both: %q", nf.groupSep))
+	}
+	return nf
+}
+
+// scan returns the length of the number at the start of the input and
+// the number in Go syntax for the strconv package.
+// The length is 0 if there is no valid number.
+// An exponent is only allowed together with a fraction.
+func (nf *numberFormat) scan(input string, signAllowed, fraction, exponent bool) (int, string) {
+	n := 0
+	if signAllowed && n < len(input) && (input[n] == '+' || input[n] == '-') {
+		n++
+	}
+	intStart := n
+	var groups []int // lengths of the digit groups if separated
+	digits, group := 0, 0
+ForLoop:
+	for ; n < len(input); n++ {
+		switch c := input[n]; {
+		case isDigit(c):
+			digits++
+			group++
+		case c == nf.groupSep && group > 0 && n+1 < len(input) && isDigit(input[n+1]):
+			groups = append(groups, group)
+			group = 0
+		default:
+			break ForLoop // don't break switch but for
+		}
+	}
+	if groups != nil && !nf.validGroups(append(groups, group)) {
+		return 0, ""
+	}
+	intEnd := n
+
+	fractionStart := -1
+	if fraction && n < len(input) && input[n] == nf.decimalSep {
+		fractionStart = n
+		n++
+		for n < len(input) && isDigit(input[n]) {
+			n++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return 0, ""
+	}
+	if fraction && exponent && n < len(input) && (input[n] == 'e' || input[n] == 'E') { // only consume a complete exponent
+		e := n + 1
+		if e < len(input) && (input[e] == '+' || input[e] == '-') {
+			e++
+		}
+		expStart := e
+		for e < len(input) && isDigit(input[e]) {
+			e++
+		}
+		if e > expStart {
+			n = e
+		}
+	}
+
+	if groups == nil && (fractionStart < 0 || nf.decimalSep == '.') {
+		return n, input[:n] // nothing to convert
+	}
+	sb := strings.Builder{}
+	sb.Grow(n)
+	sb.WriteString(input[:intStart])
+	for i := intStart; i < intEnd; i++ {
+		if input[i] != nf.groupSep {
+			sb.WriteByte(input[i])
+		}
+	}
+	if fractionStart >= 0 {
+		sb.WriteByte('.')
+		sb.WriteString(input[fractionStart+1 : n])
+	} else {
+		sb.WriteString(input[intEnd:n])
+	}
+	return n, sb.String()
+}
+
+// validGroups checks the lengths of the digit groups against the grouping.
+func (nf *numberFormat) validGroups(groups []int) bool {
+	for i := len(groups) - 1; i >= 0; i-- {
+		size := nf.grouping[min(len(groups)-1-i, len(nf.grouping)-1)]
+		if groups[i] > size || (i > 0 && groups[i] != size) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
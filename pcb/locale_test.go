@@ -0,0 +1,100 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"testing"
+)
+
+func TestLocaleFloat(t *testing.T) {
+	t.Parallel()
+
+	german := Float(WithDecimalComma(), WithThousandsSeparator('.'))
+	indian := Float(WithThousandsSeparator(','), WithGrouping(3, 2))
+	testCases := []struct {
+		name          string
+		parser        gomme.Parser[float64]
+		input         string
+		wantErr       bool
+		wantOutput    float64
+		wantRemaining string
+	}{
+		{
+			name:          "parsing grouped number with decimal comma should succeed",
+			parser:        german,
+			input:         "1.234.567,89;",
+			wantOutput:    1234567.89,
+			wantRemaining: ";",
+		}, {
+			name:       "parsing ungrouped number should succeed",
+			parser:     german,
+			input:      "-1234,5",
+			wantOutput: -1234.5,
+		}, {
+			name:          "separator at the end should be left over",
+			parser:        german,
+			input:         "1.234.",
+			wantOutput:    1234,
+			wantRemaining: ".",
+		}, {
+			name:          "parsing invalid grouping should fail",
+			parser:        german,
+			input:         "12.34,5",
+			wantErr:       true,
+			wantRemaining: "12.34,5",
+		}, {
+			name:       "parsing indian grouping should succeed",
+			parser:     indian,
+			input:      "12,34,567.5",
+			wantOutput: 1234567.5,
+		}, {
+			name:          "parsing western grouping with indian grouping should fail",
+			parser:        indian,
+			input:         "1,234,567",
+			wantErr:       true,
+			wantRemaining: "1,234,567",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), tc.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+			if !tc.wantErr && gotResult != tc.wantOutput {
+				t.Errorf("got output %g, want output %g", gotResult, tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestLocaleInt64(t *testing.T) {
+	t.Parallel()
+
+	parser := Int64(true, 10, WithThousandsSeparator(' '))
+	newState, output := gomme.RunOnState(gomme.NewFromString("-1 234 567,89", false), parser)
+	if newState.HasError() || output != -1234567 {
+		t.Errorf("got (%d, %v), want (%d, nil)", output, newState.Errors(), -1234567)
+	}
+	if remaining := newState.CurrentString(); remaining != ",89" {
+		t.Errorf("got remaining %q, want remaining %q", remaining, ",89")
+	}
+}
+
+func TestNumberOptionsPanic(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("got no panic for equal separators")
+		}
+	}()
+	Float(WithDecimalComma(), WithThousandsSeparator(','))
+}
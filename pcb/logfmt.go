@@ -0,0 +1,155 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"strconv"
+	"strings"
+)
+
+// KeyValue is a key value pair of a logfmt line.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// DuplicateKeys defines how Logfmt handles keys that occur more than once.
+type DuplicateKeys uint8
+
+const (
+	KeepAllKeys     DuplicateKeys = iota // keep all pairs
+	KeepFirstKey                         // keep only the first pair with the key
+	KeepLastKey                          // keep only the last pair with the key (at the position of the first)
+	ReportDuplicate                      // keep the first pair and report the others as semantic errors
+)
+
+// LogfmtPair parses a single logfmt pair: key=value.
+// The value is either bare (e.g. `level=info`) or quoted with Go escapes
+// (e.g. `msg="hello \"world\""`). A key without '=' has an empty value.
+// The pair has to end at a space, tab, line break or the end of the input.
+func LogfmtPair() gomme.Parser[KeyValue] {
+	expected := "logfmt pair"
+
+	pairParse := func(state gomme.State) (gomme.State, KeyValue, *gomme.ParserError) {
+		kv, n, errMsg, errPos := scanLogfmtPair(state.CurrentString())
+		if errMsg != "" {
+			errState := state.MoveBy(errPos).NewError(errMsg)
+			return state.Preserve(errState), KeyValue{}, errState.CurrentError()
+		}
+		return state.MoveBy(n), kv, nil
+	}
+	return gomme.NewParser[KeyValue](expected, pairParse, BasicRecovererFunc(pairParse))
+}
+
+// Logfmt parses a logfmt line (e.g. `level=info msg="started" port=80`)
+// into the ordered slice of its pairs.
+// It stops at the end of the line without consuming the line break.
+// So it can be used with Lines.
+// Garbage (e.g. `=x` or an unterminated quote) is reported as semantic
+// error and skipped up to the next space. Then parsing continues.
+// Keys that occur more than once are handled according to `duplicates`.
+func Logfmt(duplicates DuplicateKeys) gomme.Parser[[]KeyValue] {
+	expected := "logfmt line"
+
+	logfmtParse := func(state gomme.State) (gomme.State, []KeyValue, *gomme.ParserError) {
+		var pairs []KeyValue
+		seen := make(map[string]int) // key -> index in pairs
+		for {
+			input := state.CurrentString()
+			state = state.MoveBy(len(input) - len(strings.TrimLeft(input, " \t")))
+			input = state.CurrentString()
+			if input == "" || input[0] == '\n' || input[0] == '\r' {
+				return state, pairs, nil
+			}
+
+			kv, n, errMsg, errPos := scanLogfmtPair(input)
+			if errMsg != "" { // skip the garbage
+				garbage := strings.IndexAny(input[errPos:], " \t\r\n")
+				if garbage < 0 {
+					garbage = len(input) - errPos
+				}
+				state = state.MoveBy(errPos).NewSemanticError("expected " + errMsg).MoveBy(garbage)
+				continue
+			}
+
+			i, dup := seen[kv.Key]
+			switch {
+			case !dup || duplicates == KeepAllKeys:
+				seen[kv.Key] = len(pairs)
+				pairs = append(pairs, kv)
+			case duplicates == KeepLastKey:
+				pairs[i] = kv
+			case duplicates == ReportDuplicate:
+				state = state.NewSemanticError("duplicate logfmt key " + strconv.Quote(kv.Key))
+			}
+			state = state.MoveBy(n)
+		}
+	}
+	return gomme.NewParser[[]KeyValue](expected, logfmtParse, Forbidden("Logfmt"))
+}
+
+// scanLogfmtPair scans a logfmt pair at the start of the input.
+// It returns the pair and its length or the expectation and the position
+// of an error.
+func scanLogfmtPair(input string) (kv KeyValue, n int, errMsg string, errPos int) {
+	for n < len(input) && isLogfmtByte(input[n]) {
+		n++
+	}
+	if n == 0 {
+		return KeyValue{}, 0, "logfmt key", 0
+	}
+	kv.Key = input[:n]
+
+	if n < len(input) && input[n] == '=' {
+		n++
+		if n < len(input) && input[n] == '"' {
+			end := quotedEnd(input[n:])
+			if end < 0 { // the error is at the end of the line
+				lineEnd := strings.IndexAny(input[n:], "\r\n")
+				if lineEnd < 0 {
+					lineEnd = len(input) - n
+				}
+				return KeyValue{}, 0, "closing quote", n + lineEnd
+			}
+			value, err := strconv.Unquote(input[n : n+end])
+			if err != nil {
+				return KeyValue{}, 0, "valid quoted logfmt value", n
+			}
+			kv.Value = value
+			n += end
+		} else {
+			start := n
+			for n < len(input) && isLogfmtByte(input[n]) {
+				n++
+			}
+			kv.Value = input[start:n]
+		}
+	}
+
+	if n < len(input) && strings.IndexByte(" \t\r\n", input[n]) < 0 {
+		return KeyValue{}, 0, "space after logfmt pair", n
+	}
+	return kv, n, "", 0
+}
+
+// isLogfmtByte reports whether the byte can be part of a key or a bare
+// value.
+func isLogfmtByte(c byte) bool {
+	return c > ' ' && c != '=' && c != '"' && c != 0x7f
+}
+
+// quotedEnd returns the length of the double quoted string at the start
+// of the input (including the quotes) or -1 if it isn't terminated on
+// the same line.
+func quotedEnd(input string) int {
+	for i := 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,95 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLogfmt(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		duplicates    DuplicateKeys
+		input         string
+		wantErrs      []string
+		wantOutput    []KeyValue
+		wantRemaining string
+	}{
+		{
+			name:  "parsing bare and quoted values should succeed",
+			input: `level=info msg="hello \"world\"" debug` + "\nnext",
+			wantOutput: []KeyValue{
+				{Key: "level", Value: "info"}, {Key: "msg", Value: `hello "world"`}, {Key: "debug"},
+			},
+			wantRemaining: "\nnext",
+		}, {
+			name:       "all duplicate keys should be kept",
+			duplicates: KeepAllKeys,
+			input:      "a=1 b=2 a=3",
+			wantOutput: []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "a", Value: "3"}},
+		}, {
+			name:       "first duplicate key should be kept",
+			duplicates: KeepFirstKey,
+			input:      "a=1 b=2 a=3",
+			wantOutput: []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}},
+		}, {
+			name:       "last duplicate key should be kept",
+			duplicates: KeepLastKey,
+			input:      "a=1 b=2 a=3",
+			wantOutput: []KeyValue{{Key: "a", Value: "3"}, {Key: "b", Value: "2"}},
+		}, {
+			name:       "duplicate key should be reported",
+			duplicates: ReportDuplicate,
+			input:      "a=1 b=2 a=3",
+			wantErrs:   []string{`duplicate logfmt key "a" [1:9]`},
+			wantOutput: []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}},
+		}, {
+			name:       "garbage should be skipped",
+			input:      `=x a=1 b=2"c d="open`,
+			wantErrs:   []string{"expected logfmt key [1:1]", "expected space after logfmt pair [1:11]", "expected closing quote [1:21]"},
+			wantOutput: []KeyValue{{Key: "a", Value: "1"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), Logfmt(tc.duplicates))
+			if newState.HasError() != (len(tc.wantErrs) > 0) {
+				t.Errorf("got error %v, want errors %q", newState.Errors(), tc.wantErrs)
+			}
+			if len(tc.wantErrs) > 0 {
+				msg := newState.Errors().Error()
+				for _, wantErr := range tc.wantErrs {
+					if !strings.Contains(msg, wantErr) {
+						t.Errorf("got error %q, want error %q", msg, wantErr)
+					}
+				}
+			}
+			if !reflect.DeepEqual(gotResult, tc.wantOutput) {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestLogfmtPair(t *testing.T) {
+	t.Parallel()
+
+	newState, output := gomme.RunOnState(gomme.NewFromString(`k="v w" rest`, false), LogfmtPair())
+	if newState.HasError() || output != (KeyValue{Key: "k", Value: "v w"}) {
+		t.Errorf("got (%+v, %v), want ({k v w}, nil)", output, newState.Errors())
+	}
+	if remaining := newState.CurrentString(); remaining != " rest" {
+		t.Errorf("got remaining %q, want remaining %q", remaining, " rest")
+	}
+}
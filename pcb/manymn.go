@@ -44,14 +44,19 @@ func SeparatedMN[Output any, S gomme.Separator](
 	}
 
 	md := &separatedData[Output, S]{
-		id:                  gomme.NewBranchParserID(),
+		id:                  gomme.NewParserID(),
 		parse:               parse,
 		separator:           separator,
 		atLeast:             atLeast,
 		atMost:              atMost,
 		parseSeparatorAtEnd: parseSeparatorAtEnd,
+		nullable: atMost > 1 && acceptsEmpty(parse) &&
+			(separator.Expected() == noSeparator.Expected() || acceptsEmpty(separator)),
 	}
 	parseSep := func(state gomme.State) (gomme.State, []Output) {
+		if md.nullable {
+			return state.NewFailure(nullableMsg(md.parse)), nil
+		}
 		outputs := make([]Output, 0, min(32, md.atMost))
 		return md.any(state, state, -1, -1, outputs)
 	}
@@ -65,12 +70,30 @@ func SeparatedMN[Output any, S gomme.Separator](
 }
 
 type separatedData[Output any, S gomme.Separator] struct {
-	id                  uint64
+	id                  *gomme.ParserID
 	parse               gomme.Parser[Output]
 	separator           gomme.Parser[S]
 	atLeast             int
 	atMost              int
 	parseSeparatorAtEnd bool
+	nullable            bool // true if the parsers would loop endlessly
+}
+
+// acceptsEmpty returns true if the static analysis of the parser says that
+// it can succeed without consuming any input (see gomme.Analyze).
+// It is used to detect endless loops of repetition parsers before parsing
+// instead of relying on the detection during parsing.
+// Parsers without analysis (e.g. lazy parsers) are left to the detection
+// during parsing, so no user code is run here.
+func acceptsEmpty[Output any](parse gomme.Parser[Output]) bool {
+	analysis := gomme.Analyze(parse)
+	return analysis.Nullable && analysis != gomme.UnknownAnalysis()
+}
+
+func nullableMsg[Output any](parse gomme.Parser[Output]) string {
+	return fmt.Sprintf("grammar error: repeating %q would loop endlessly "+
+		"because it accepts empty input (use a parser that consumes at least 1 byte)",
+		parse.Expected())
 }
 
 func (sd *separatedData[Output, S]) any(
@@ -117,17 +140,17 @@ func (sd *separatedData[Output, S]) happy(
 		newState, output := sd.parse.It(remaining)
 		if newState.Failed() {
 			if remaining.SaveSpotMoved(newState) { // fail because of SaveSpot
-				state.CacheParserResult(sd.id, 0, saveSpotIdx, saveSpotStart, newState, outputs)
-				state = gomme.IWitnessed(state, sd.id, 0, newState)
+				state.CacheParserResult(sd.id.In(state), 0, saveSpotIdx, saveSpotStart, newState, outputs)
+				state = gomme.IWitnessed(state, sd.id.In(state), 0, newState)
 				return sd.error(state, outputs)
 			}
 			if count >= sd.atLeast { // success!
-				state.CacheParserResult(sd.id, 0, saveSpotIdx, saveSpotStart, retState, outputs)
-				return retState, outputs
+				state.CacheParserResult(sd.id.In(state), 0, saveSpotIdx, saveSpotStart, retState, outputs)
+				return retState.Backtrack(newState), outputs
 			}
 			// fail:
-			state.CacheParserResult(sd.id, 0, saveSpotIdx, saveSpotStart, newState, outputs)
-			state = gomme.IWitnessed(state, sd.id, 0, newState)
+			state.CacheParserResult(sd.id.In(state), 0, saveSpotIdx, saveSpotStart, newState, outputs)
+			state = gomme.IWitnessed(state, sd.id.In(state), 0, newState)
 			if saveSpotStart < 0 { // we can't do anything here
 				return state, nil
 			}
@@ -146,17 +169,17 @@ func (sd *separatedData[Output, S]) happy(
 			sepState, _ = sd.separator.It(newState)
 			if sepState.Failed() {
 				if newState.SaveSpotMoved(sepState) { // fail because of SaveSpot
-					state.CacheParserResult(sd.id, 1, saveSpotIdx, saveSpotStart, sepState, outputs)
-					state = gomme.IWitnessed(state, sd.id, 1, sepState)
+					state.CacheParserResult(sd.id.In(state), 1, saveSpotIdx, saveSpotStart, sepState, outputs)
+					state = gomme.IWitnessed(state, sd.id.In(state), 1, sepState)
 					return sd.error(state, outputs)
 				}
 				if count >= sd.atLeast { // success!
-					state.CacheParserResult(sd.id, 1, saveSpotIdx, saveSpotStart, newState, outputs)
-					return retState, outputs
+					state.CacheParserResult(sd.id.In(state), 1, saveSpotIdx, saveSpotStart, newState, outputs)
+					return retState.Backtrack(sepState), outputs
 				}
 				// fail:
-				state.CacheParserResult(sd.id, 1, saveSpotIdx, saveSpotStart, sepState, outputs)
-				state = gomme.IWitnessed(state, sd.id, 1, sepState)
+				state.CacheParserResult(sd.id.In(state), 1, saveSpotIdx, saveSpotStart, sepState, outputs)
+				state = gomme.IWitnessed(state, sd.id.In(state), 1, sepState)
 				if saveSpotStart < 0 { // we can't do anything here
 					return state, nil
 				}
@@ -177,13 +200,13 @@ func (sd *separatedData[Output, S]) happy(
 			return state.NewError(fmt.Sprintf(
 				"many %s (empty element incl. separator => endless loop)", sd.parse.Expected())), nil
 		}
-		remaining = sepState
+		remaining = sepState.AutoClearCaches(true)
 	}
 }
 
 func (sd *separatedData[Output, S]) error(state gomme.State, outputs []Output) (gomme.State, []Output) {
 	// use cache to know result immediately (HasSaveSpot, SaveSpotIdx, SaveSpotStart)
-	result, ok := state.CachedParserResult(sd.id)
+	result, ok := state.CachedParserResult(sd.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `SeparatedMN(error)` parser",
@@ -213,7 +236,7 @@ func (sd *separatedData[Output, S]) error(state gomme.State, outputs []Output) (
 
 func (sd *separatedData[Output, S]) handle(state gomme.State, outputs []Output) (gomme.State, []Output) {
 	// use cache to know result immediately (Failed, Idx, ErrorStart)
-	result, ok := state.CachedParserResult(sd.id)
+	result, ok := state.CachedParserResult(sd.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `SeparatedMN(handle)` parser",
@@ -223,7 +246,7 @@ func (sd *separatedData[Output, S]) handle(state gomme.State, outputs []Output)
 	if result.Failed { // we should be able to switch to mode=happy (or escape)
 		outputs = result.Output.([]Output)
 		newState, output := gomme.HandleWitness(
-			state.MoveBy(result.ErrorStart), sd.id, result.Idx, sd.parse, gomme.ParserToZeroOutput[Output, S](sd.separator),
+			state.MoveBy(result.ErrorStart), sd.id.In(state), result.Idx, sd.parse, gomme.ParserToZeroOutput[Output, S](sd.separator),
 		)
 		outputs = append(outputs, output)
 		return sd.any(
@@ -239,7 +262,7 @@ func (sd *separatedData[Output, S]) handle(state gomme.State, outputs []Output)
 
 func (sd *separatedData[Output, S]) rewind(state gomme.State, outputs []Output) (gomme.State, []Output) {
 	// use cache to know result immediately (Failed, Idx, ErrorStart)
-	result, ok := state.CachedParserResult(sd.id)
+	result, ok := state.CachedParserResult(sd.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `SeparatedMN(rewind)` parser",
@@ -249,7 +272,7 @@ func (sd *separatedData[Output, S]) rewind(state gomme.State, outputs []Output)
 	if result.Failed { // we should be able to switch to mode=happy (or escape)
 		outputs = result.Output.([]Output)
 		newState, output := gomme.HandleWitness(
-			state.MoveBy(result.ErrorStart), sd.id, result.Idx, sd.parse, gomme.ParserToZeroOutput[Output, S](sd.separator),
+			state.MoveBy(result.ErrorStart), sd.id.In(state), result.Idx, sd.parse, gomme.ParserToZeroOutput[Output, S](sd.separator),
 		)
 		outputs = append(outputs, output)
 		return sd.any(
@@ -263,7 +286,7 @@ func (sd *separatedData[Output, S]) rewind(state gomme.State, outputs []Output)
 
 func (sd *separatedData[Output, S]) escape(state, remaining gomme.State, outputs []Output) (gomme.State, []Output) {
 	// use cache to know result immediately (Failed, Idx, ErrorStart)
-	result, ok := state.CachedParserResult(sd.id)
+	result, ok := state.CachedParserResult(sd.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `SeparatedMN(escape)` parser",
@@ -18,6 +18,37 @@ func MapN[PO1, PO2, PO3, PO4, PO5 any, MO any](
 	n int,
 	fn1 func(PO1) (MO, error), fn2 func(PO1, PO2) (MO, error), fn3 func(PO1, PO2, PO3) (MO, error),
 	fn4 func(PO1, PO2, PO3, PO4) (MO, error), fn5 func(PO1, PO2, PO3, PO4, PO5) (MO, error),
+) gomme.Parser[MO] {
+	return newMapN(expected, p1, p2, p3, p4, p5, n, fn1, fn2, fn3, fn4, fn5, pureFuncs[PO1, PO2, PO3, PO4, PO5, MO]{})
+}
+
+// pureFuncs are the functions of the MapXPure parsers that can't fail.
+// At most one of them is set. It is used instead of the `fn`X function
+// with the same number, so no error has to be checked.
+type pureFuncs[PO1, PO2, PO3, PO4, PO5 any, MO any] struct {
+	fn2 func(PO1, PO2) MO
+	fn3 func(PO1, PO2, PO3) MO
+	fn4 func(PO1, PO2, PO3, PO4) MO
+	fn5 func(PO1, PO2, PO3, PO4, PO5) MO
+}
+
+// mapNPure is MapN for a function `pure.fn`n that can't fail.
+func mapNPure[PO1, PO2, PO3, PO4, PO5 any, MO any](
+	expected string,
+	p1 gomme.Parser[PO1], p2 gomme.Parser[PO2], p3 gomme.Parser[PO3], p4 gomme.Parser[PO4], p5 gomme.Parser[PO5],
+	n int,
+	pure pureFuncs[PO1, PO2, PO3, PO4, PO5, MO],
+) gomme.Parser[MO] {
+	return newMapN(expected, p1, p2, p3, p4, p5, n, nil, nil, nil, nil, nil, pure)
+}
+
+func newMapN[PO1, PO2, PO3, PO4, PO5 any, MO any](
+	expected string,
+	p1 gomme.Parser[PO1], p2 gomme.Parser[PO2], p3 gomme.Parser[PO3], p4 gomme.Parser[PO4], p5 gomme.Parser[PO5],
+	n int,
+	fn1 func(PO1) (MO, error), fn2 func(PO1, PO2) (MO, error), fn3 func(PO1, PO2, PO3) (MO, error),
+	fn4 func(PO1, PO2, PO3, PO4) (MO, error), fn5 func(PO1, PO2, PO3, PO4, PO5) (MO, error),
+	pure pureFuncs[PO1, PO2, PO3, PO4, PO5, MO],
 ) gomme.Parser[MO] {
 	var zero1 PO1
 	var zero2 PO2
@@ -67,11 +98,12 @@ func MapN[PO1, PO2, PO3, PO4, PO5 any, MO any](
 	mySaveSpotRecoverer := gomme.NewCombiningRecoverer(true, subRecoverers...)
 
 	md := &mapData[PO1, PO2, PO3, PO4, PO5, MO]{
-		id:       gomme.NewBranchParserID(),
+		id:       gomme.NewParserID(),
 		expected: expected,
 		p1:       p1, p2: p2, p3: p3, p4: p4, p5: p5,
 		n:   n,
 		fn1: fn1, fn2: fn2, fn3: fn3, fn4: fn4, fn5: fn5,
+		pure:              pure,
 		saveSpotRecoverer: mySaveSpotRecoverer,
 		subRecoverers:     subRecoverers,
 	}
@@ -85,17 +117,37 @@ func MapN[PO1, PO2, PO3, PO4, PO5 any, MO any](
 		)
 	}
 
-	return gomme.NewParser[MO](
+	analyses := []gomme.Analysis{gomme.Analyze(p1)}
+	if n > 1 {
+		analyses = append(analyses, gomme.Analyze(p2))
+		if n > 2 {
+			analyses = append(analyses, gomme.Analyze(p3))
+			if n > 3 {
+				analyses = append(analyses, gomme.Analyze(p4))
+				if n > 4 {
+					analyses = append(analyses, gomme.Analyze(p5))
+				}
+			}
+		}
+	}
+
+	p := gomme.NewParser[MO](
 		expected,
 		mapParse,
 		true,
 		BasicRecovererFunc(mapParse),
 		mySaveSpotRecoverer.Recover,
 	)
+	p = gomme.WithAnalysis(p, gomme.SequenceAnalysis(analyses...))
+	return gomme.WithOptimizer(p, func() gomme.Parser[MO] {
+		return newMapN(expected,
+			gomme.Optimize(p1), gomme.Optimize(p2), gomme.Optimize(p3), gomme.Optimize(p4), gomme.Optimize(p5),
+			n, fn1, fn2, fn3, fn4, fn5, pure)
+	})
 }
 
 type mapData[PO1, PO2, PO3, PO4, PO5 any, MO any] struct {
-	id                uint64
+	id                *gomme.ParserID
 	expected          string
 	p1                gomme.Parser[PO1]
 	p2                gomme.Parser[PO2]
@@ -108,6 +160,7 @@ type mapData[PO1, PO2, PO3, PO4, PO5 any, MO any] struct {
 	fn3               func(PO1, PO2, PO3) (MO, error)
 	fn4               func(PO1, PO2, PO3, PO4) (MO, error)
 	fn5               func(PO1, PO2, PO3, PO4, PO5) (MO, error)
+	pure              pureFuncs[PO1, PO2, PO3, PO4, PO5, MO]
 	saveSpotRecoverer gomme.CombiningRecoverer
 	subRecoverers     []gomme.Recoverer
 }
@@ -158,12 +211,12 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) happy(
 
 	if startIdx <= 0 { // caching only works if parsing from the start
 		// use cache to know result immediately (Failed, Error, Consumed, Output)
-		result, ok := state.CachedParserResult(md.id)
+		result, ok := state.CachedParserResult(md.id.In(state))
 		if ok {
 			if result.Failed {
 				return state.ErrorAgain(result.Error), zeroMO
 			}
-			return state.MoveBy(result.Consumed), result.Output.(MO)
+			return state.SucceedAgain(result), result.Output.(MO)
 		}
 	}
 
@@ -173,8 +226,8 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) happy(
 	if startIdx <= 0 {
 		newState1, out1 = md.p1.It(remaining)
 		if newState1.Failed() {
-			state.CacheParserResult(md.id, 0, saveSpotIdx, saveSpotStart, newState1, outputs)
-			return gomme.IWitnessed(remaining, md.id, 0, newState1), zeroMO
+			state.CacheParserResult(md.id.In(state), 0, saveSpotIdx, saveSpotStart, newState1, outputs)
+			return gomme.IWitnessed(remaining, md.id.In(state), 0, newState1), zeroMO
 		}
 		if state.SaveSpotMoved(newState1) {
 			saveSpotIdx = 0
@@ -191,8 +244,8 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) happy(
 			}
 			newState2, out2 = md.p2.It(newState1)
 			if newState2.Failed() {
-				state.CacheParserResult(md.id, 1, saveSpotIdx, saveSpotStart, newState2, outputs)
-				state = gomme.IWitnessed(newState1, md.id, 0, newState2)
+				state.CacheParserResult(md.id.In(state), 1, saveSpotIdx, saveSpotStart, newState2, outputs)
+				state = gomme.IWitnessed(newState1, md.id.In(state), 0, newState2)
 				if saveSpotStart < 0 { // we can't do anything here
 					return state, zeroMO
 				}
@@ -213,8 +266,8 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) happy(
 				}
 				newState3, out3 = md.p3.It(newState2)
 				if newState3.Failed() {
-					state.CacheParserResult(md.id, 2, saveSpotIdx, saveSpotStart, newState3, outputs)
-					state = gomme.IWitnessed(newState2, md.id, 0, newState3)
+					state.CacheParserResult(md.id.In(state), 2, saveSpotIdx, saveSpotStart, newState3, outputs)
+					state = gomme.IWitnessed(newState2, md.id.In(state), 0, newState3)
 					if saveSpotStart < 0 { // we can't do anything here
 						return state, zeroMO
 					}
@@ -235,8 +288,8 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) happy(
 					}
 					newState4, out4 = md.p4.It(newState3)
 					if newState4.Failed() {
-						state.CacheParserResult(md.id, 3, saveSpotIdx, saveSpotStart, newState4, outputs)
-						state = gomme.IWitnessed(newState3, md.id, 0, newState4)
+						state.CacheParserResult(md.id.In(state), 3, saveSpotIdx, saveSpotStart, newState4, outputs)
+						state = gomme.IWitnessed(newState3, md.id.In(state), 0, newState4)
 						if saveSpotStart < 0 { // we can't do anything here
 							return state, zeroMO
 						}
@@ -256,8 +309,8 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) happy(
 					}
 					newState5, out5 = md.p5.It(newState4)
 					if newState5.Failed() {
-						state.CacheParserResult(md.id, 4, saveSpotIdx, saveSpotStart, newState5, outputs)
-						state = gomme.IWitnessed(newState4, md.id, 0, newState5)
+						state.CacheParserResult(md.id.In(state), 4, saveSpotIdx, saveSpotStart, newState5, outputs)
+						state = gomme.IWitnessed(newState4, md.id.In(state), 0, newState5)
 						if saveSpotStart < 0 { // we can't do anything here
 							return state, zeroMO
 						}
@@ -268,44 +321,64 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) happy(
 						saveSpotStart = state.ByteCount(newState4)
 					}
 
+					if md.pure.fn5 != nil {
+						mapped := md.pure.fn5(out1, out2, out3, out4, out5)
+						state.CacheParserResult(md.id.In(state), 4, saveSpotIdx, saveSpotStart, newState5, mapped)
+						return newState5, mapped
+					}
 					mapped, err := md.fn5(out1, out2, out3, out4, out5)
 					if err != nil {
-						state.CacheParserResult(md.id, 4, saveSpotIdx, saveSpotStart, newState5, zeroMO)
+						state.CacheParserResult(md.id.In(state), 4, saveSpotIdx, saveSpotStart, newState5, zeroMO)
 						return newState5.NewSemanticError(err.Error()), zeroMO
 					}
-					state.CacheParserResult(md.id, 4, saveSpotIdx, saveSpotStart, newState5, mapped)
+					state.CacheParserResult(md.id.In(state), 4, saveSpotIdx, saveSpotStart, newState5, mapped)
 					return newState5, mapped
 				}
+				if md.pure.fn4 != nil {
+					mapped := md.pure.fn4(out1, out2, out3, out4)
+					state.CacheParserResult(md.id.In(state), 3, saveSpotIdx, saveSpotStart, newState4, mapped)
+					return newState4, mapped
+				}
 				mapped, err := md.fn4(out1, out2, out3, out4)
 				if err != nil {
-					state.CacheParserResult(md.id, 3, saveSpotIdx, saveSpotStart, newState4, zeroMO)
+					state.CacheParserResult(md.id.In(state), 3, saveSpotIdx, saveSpotStart, newState4, zeroMO)
 					return newState4.NewSemanticError(err.Error()), zeroMO
 				}
-				state.CacheParserResult(md.id, 3, saveSpotIdx, saveSpotStart, newState4, mapped)
+				state.CacheParserResult(md.id.In(state), 3, saveSpotIdx, saveSpotStart, newState4, mapped)
 				return newState4, mapped
 			}
+			if md.pure.fn3 != nil {
+				mapped := md.pure.fn3(out1, out2, out3)
+				state.CacheParserResult(md.id.In(state), 2, saveSpotIdx, saveSpotStart, newState3, mapped)
+				return newState3, mapped
+			}
 			mapped, err := md.fn3(out1, out2, out3)
 			if err != nil {
-				state.CacheParserResult(md.id, 2, saveSpotIdx, saveSpotStart, newState3, zeroMO)
+				state.CacheParserResult(md.id.In(state), 2, saveSpotIdx, saveSpotStart, newState3, zeroMO)
 				return newState3.NewSemanticError(err.Error()), zeroMO
 			}
-			state.CacheParserResult(md.id, 2, saveSpotIdx, saveSpotStart, newState3, mapped)
+			state.CacheParserResult(md.id.In(state), 2, saveSpotIdx, saveSpotStart, newState3, mapped)
 			return newState3, mapped
 		}
+		if md.pure.fn2 != nil {
+			mapped := md.pure.fn2(out1, out2)
+			state.CacheParserResult(md.id.In(state), 1, saveSpotIdx, saveSpotStart, newState2, mapped)
+			return newState2, mapped
+		}
 		mapped, err := md.fn2(out1, out2)
 		if err != nil {
-			state.CacheParserResult(md.id, 1, saveSpotIdx, saveSpotStart, newState2, zeroMO)
+			state.CacheParserResult(md.id.In(state), 1, saveSpotIdx, saveSpotStart, newState2, zeroMO)
 			return newState2.NewSemanticError(err.Error()), zeroMO
 		}
-		state.CacheParserResult(md.id, 1, saveSpotIdx, saveSpotStart, newState2, mapped)
+		state.CacheParserResult(md.id.In(state), 1, saveSpotIdx, saveSpotStart, newState2, mapped)
 		return newState2, mapped
 	}
 	mapped, err := md.fn1(out1)
 	if err != nil {
-		state.CacheParserResult(md.id, 0, saveSpotIdx, saveSpotStart, newState1, zeroMO)
+		state.CacheParserResult(md.id.In(state), 0, saveSpotIdx, saveSpotStart, newState1, zeroMO)
 		return newState1.NewSemanticError(err.Error()), zeroMO
 	}
-	state.CacheParserResult(md.id, 0, saveSpotIdx, saveSpotStart, newState1, mapped)
+	state.CacheParserResult(md.id.In(state), 0, saveSpotIdx, saveSpotStart, newState1, mapped)
 	return newState1, mapped
 }
 
@@ -317,7 +390,7 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) error(
 	var zeroMO MO
 
 	// use cache to know result immediately (HasSaveSpot, SaveSpotIdx, SaveSpotStart)
-	result, ok := state.CachedParserResult(md.id)
+	result, ok := state.CachedParserResult(md.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `MapN(error)` parser",
@@ -366,7 +439,7 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) handle(
 	var zeroMO MO
 
 	// use cache to know result immediately (Failed, Idx, ErrorStart)
-	result, ok := state.CachedParserResult(md.id)
+	result, ok := state.CachedParserResult(md.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `MapN(handle)` parser",
@@ -377,22 +450,22 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) handle(
 		var newState gomme.State
 		switch result.Idx {
 		case 0:
-			newState, out1 = gomme.HandleWitness(state, md.id, 0, md.p1)
+			newState, out1 = gomme.HandleWitness(state, md.id.In(state), 0, md.p1)
 		case 1:
 			newState, out2 = gomme.HandleWitness(
-				state.MoveBy(result.ErrorStart), md.id, 0, md.p2,
+				state.MoveBy(result.ErrorStart), md.id.In(state), 0, md.p2,
 			)
 		case 2:
 			newState, out3 = gomme.HandleWitness(
-				state.MoveBy(result.ErrorStart), md.id, 0, md.p3,
+				state.MoveBy(result.ErrorStart), md.id.In(state), 0, md.p3,
 			)
 		case 3:
 			newState, out4 = gomme.HandleWitness(
-				state.MoveBy(result.ErrorStart), md.id, 0, md.p4,
+				state.MoveBy(result.ErrorStart), md.id.In(state), 0, md.p4,
 			)
 		default:
 			newState, out5 = gomme.HandleWitness(
-				state.MoveBy(result.ErrorStart), md.id, 0, md.p5,
+				state.MoveBy(result.ErrorStart), md.id.In(state), 0, md.p5,
 			)
 		}
 		return md.any(
@@ -414,7 +487,7 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) rewind(
 
 	gomme.Debugf("MapN.rewind - startIdx=%d", startIdx)
 	// use cache to know result immediately (Failed, Idx, ErrorStart)
-	result, ok := state.CachedParserResult(md.id)
+	result, ok := state.CachedParserResult(md.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `MapN(rewind)` parser",
@@ -426,22 +499,22 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) rewind(
 		var newState gomme.State
 		switch result.Idx {
 		case 0:
-			newState, out1 = gomme.HandleWitness(state, md.id, 0, md.p1)
+			newState, out1 = gomme.HandleWitness(state, md.id.In(state), 0, md.p1)
 		case 1:
 			newState, out2 = gomme.HandleWitness(
-				state.MoveBy(result.ErrorStart), md.id, 0, md.p2,
+				state.MoveBy(result.ErrorStart), md.id.In(state), 0, md.p2,
 			)
 		case 2:
 			newState, out3 = gomme.HandleWitness(
-				state.MoveBy(result.ErrorStart), md.id, 0, md.p3,
+				state.MoveBy(result.ErrorStart), md.id.In(state), 0, md.p3,
 			)
 		case 3:
 			newState, out4 = gomme.HandleWitness(
-				state.MoveBy(result.ErrorStart), md.id, 0, md.p4,
+				state.MoveBy(result.ErrorStart), md.id.In(state), 0, md.p4,
 			)
 		default:
 			newState, out5 = gomme.HandleWitness(
-				state.MoveBy(result.ErrorStart), md.id, 0, md.p5,
+				state.MoveBy(result.ErrorStart), md.id.In(state), 0, md.p5,
 			)
 		}
 
@@ -506,7 +579,7 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) escape(
 		newState, out5 = md.p5.It(remaining)
 	}
 	if newState.ParsingMode() == gomme.ParsingModeHappy {
-		result, ok := state.CachedParserResult(md.id)
+		result, ok := state.CachedParserResult(md.id.In(state))
 		if !ok {
 			result.SaveSpotIdx = -1
 			result.SaveSpotStart = -1
@@ -529,16 +602,24 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) mapn(
 	var zero, mo MO
 	var err error
 
-	switch md.n {
-	case 1:
+	switch {
+	case md.n == 1:
 		mo, err = md.fn1(out1)
-	case 2:
+	case md.pure.fn2 != nil:
+		mo = md.pure.fn2(out1, out2)
+	case md.n == 2:
 		mo, err = md.fn2(out1, out2)
-	case 3:
+	case md.pure.fn3 != nil:
+		mo = md.pure.fn3(out1, out2, out3)
+	case md.n == 3:
 		mo, err = md.fn3(out1, out2, out3)
-	case 4:
+	case md.pure.fn4 != nil:
+		mo = md.pure.fn4(out1, out2, out3, out4)
+	case md.n == 4:
 		mo, err = md.fn4(out1, out2, out3, out4)
-	case 5:
+	case md.pure.fn5 != nil:
+		mo = md.pure.fn5(out1, out2, out3, out4, out5)
+	case md.n == 5:
 		mo, err = md.fn5(out1, out2, out3, out4, out5)
 	}
 	if err != nil {
@@ -0,0 +1,115 @@
+package pcb
+
+import (
+	"fmt"
+	"github.com/oleiade/gomme"
+	"strconv"
+	"strings"
+)
+
+// Amount is the output of the Money parser.
+// The value is kept in minor units (e.g. cents) so no precision is lost.
+type Amount struct {
+	Currency string // ISO 4217 code (empty if the input has no currency)
+	Minor    int64  // value in minor units (value * 10^scale)
+}
+
+// currencySymbols maps the common currency symbols to their ISO codes.
+var currencySymbols = map[string]string{
+	"$": "USD",
+	"€": "EUR",
+	"£": "GBP",
+	"¥": "JPY",
+	"₹": "INR",
+}
+
+// Money parses an amount of money with an optional currency.
+// The currency is an ISO 4217 code (e.g. "CHF") or one of the symbols
+// $, €, £, ¥ and ₹. It can be in front of the amount or after it
+// (optionally separated by spaces): "-$12.50", "EUR 3", "1.234,56 €".
+// The amount is converted to minor units with `scale` decimal places
+// (e.g. 2 for cents). More decimal places and amounts that don't fit into
+// an int64 are reported as semantic errors.
+// The options configure the format of the amount (see NumberOption).
+// This function panics during the construction phase if `scale` is
+// negative or larger than 18.
+func Money(scale int, opts ...NumberOption) gomme.Parser[Amount] {
+	if scale < 0 || scale > 18 {
+		panic(fmt.Sprintf("Money needs a scale between 0 and 18, but got: %d", scale))
+	}
+	expected := "amount of money"
+	nf := newNumberFormat(opts)
+
+	moneyParse := func(state gomme.State) (gomme.State, Amount, *gomme.ParserError) {
+		input := state.CurrentString()
+		n := 0
+		sign := ""
+		if len(input) > 1 && (input[0] == '-' || input[0] == '+') {
+			if _, m := currencyAt(input[1:]); m > 0 { // sign in front of the currency
+				sign, n = input[:1], 1
+			}
+		}
+		currency, m := currencyAt(input[n:])
+		if m > 0 {
+			n += m
+			n += len(input[n:]) - len(strings.TrimLeft(input[n:], " "))
+		}
+
+		m, literal := nf.scan(input[n:], sign == "", true, false)
+		if m == 0 {
+			errState := state.NewError(expected)
+			return errState, Amount{}, errState.CurrentError()
+		}
+		n += m
+		if currency == "" {
+			spaces := len(input[n:]) - len(strings.TrimLeft(input[n:], " "))
+			if c, m := currencyAt(input[n+spaces:]); m > 0 {
+				currency = c
+				n += spaces + m
+			}
+		}
+
+		newState := state.MoveBy(n)
+		minor, err := minorUnits(sign+literal, scale)
+		if err != nil {
+			return newState.NewSemanticError(err.Error()), Amount{}, nil
+		}
+		return newState, Amount{Currency: currency, Minor: minor}, nil
+	}
+	return gomme.NewParser[Amount](expected, moneyParse, IndexOfAny('0', '1', '2', '3', '4', '5', '6', '7', '8', '9'))
+}
+
+// currencyAt returns the ISO code of the currency at the start of the
+// input and the number of bytes it uses (0 if there is no currency).
+func currencyAt(input string) (string, int) {
+	for symbol, code := range currencySymbols {
+		if strings.HasPrefix(input, symbol) {
+			return code, len(symbol)
+		}
+	}
+	if len(input) < 3 {
+		return "", 0
+	}
+	for i := 0; i < 3; i++ {
+		if input[i] < 'A' || input[i] > 'Z' {
+			return "", 0
+		}
+	}
+	if len(input) > 3 && (input[3] >= 'A' && input[3] <= 'Z' || input[3] >= 'a' && input[3] <= 'z') {
+		return "", 0
+	}
+	return input[:3], 3
+}
+
+// minorUnits converts a decimal number in Go syntax to minor units.
+func minorUnits(literal string, scale int) (int64, error) {
+	intPart, fracPart, _ := strings.Cut(literal, ".")
+	if len(fracPart) > scale {
+		return 0, fmt.Errorf("amount %s has more than %d decimal places", literal, scale)
+	}
+	minor, err := strconv.ParseInt(intPart+fracPart+strings.Repeat("0", scale-len(fracPart)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("amount %s is too large", literal)
+	}
+	return minor, nil
+}
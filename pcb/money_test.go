@@ -0,0 +1,83 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"testing"
+)
+
+func TestMoney(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        gomme.Parser[Amount]
+		input         string
+		wantErr       bool
+		wantOutput    Amount
+		wantRemaining string
+	}{
+		{
+			name:       "parsing symbol in front should succeed",
+			parser:     Money(2),
+			input:      "$12.5",
+			wantOutput: Amount{Currency: "USD", Minor: 1250},
+		}, {
+			name:       "parsing sign in front of symbol should succeed",
+			parser:     Money(2),
+			input:      "-$0.07",
+			wantOutput: Amount{Currency: "USD", Minor: -7},
+		}, {
+			name:          "parsing ISO code in front should succeed",
+			parser:        Money(2),
+			input:         "CHF -3;",
+			wantOutput:    Amount{Currency: "CHF", Minor: -300},
+			wantRemaining: ";",
+		}, {
+			name:       "parsing european format with symbol after amount should succeed",
+			parser:     Money(2, WithDecimalComma(), WithThousandsSeparator('.')),
+			input:      "1.234,56 €",
+			wantOutput: Amount{Currency: "EUR", Minor: 123456},
+		}, {
+			name:       "parsing amount without currency should succeed",
+			parser:     Money(0),
+			input:      "500",
+			wantOutput: Amount{Minor: 500},
+		}, {
+			name:          "word after amount should be left over",
+			parser:        Money(2),
+			input:         "5 EURO",
+			wantOutput:    Amount{Minor: 500},
+			wantRemaining: " EURO",
+		}, {
+			name:    "too many decimal places should fail",
+			parser:  Money(2),
+			input:   "1.234",
+			wantErr: true,
+		}, {
+			name:          "missing amount should fail",
+			parser:        Money(2),
+			input:         "USD x",
+			wantErr:       true,
+			wantRemaining: "USD x",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), tc.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %+v, want output %+v", gotResult, tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package pcb
 
 import (
+	"fmt"
 	"github.com/oleiade/gomme"
 	"math"
 )
@@ -17,12 +18,51 @@ func Count[Output any](parse gomme.Parser[Output], count int) gomme.Parser[[]Out
 	return ManyMN(parse, count, count)
 }
 
+// CountBy parses the number of elements with the `count` parser first and
+// then runs the provided parser exactly that many times.
+// This is useful for length prefixed repetitions in binary formats.
+//
+// If the count is negative or too large for an int or the provided parser
+// cannot be successfully applied `count` times, the operation fails.
+func CountBy[Output any, N gomme.Integral](count gomme.Parser[N], parse gomme.Parser[Output]) gomme.Parser[[]Output] {
+	expected := fmt.Sprintf("%s times %s", count.Expected(), parse.Expected())
+
+	countParse := func(state gomme.State) (gomme.State, []Output, *gomme.ParserError) {
+		remaining, n, err := count.It(state)
+		if err != nil {
+			return state.Preserve(remaining), nil, err
+		}
+		if n < 0 {
+			errState := state.NewFailure(fmt.Sprintf("count has to be positive but is: %d", n))
+			return errState, nil, errState.CurrentError()
+		}
+		if uint64(n) > math.MaxInt {
+			errState := state.NewFailure(fmt.Sprintf("count is too large: %d", n))
+			return errState, nil, errState.CurrentError()
+		}
+
+		outputs := make([]Output, 0, min(int(n), 32))
+		for i := 0; i < int(n); i++ {
+			newState, output, pErr := parse.It(remaining)
+			if pErr != nil {
+				return state.Preserve(newState), nil, pErr
+			}
+			outputs = append(outputs, output)
+			remaining = newState
+		}
+		return remaining, outputs, nil
+	}
+	return gomme.NewParser[[]Output](expected, countParse, BasicRecovererFunc(countParse))
+}
+
 // Many0 applies a parser repeatedly until it fails, and returns a slice of all
 // the results as the Result's Output.
 //
 // Note that Many0 will succeed even if the parser fails to match at all. It will
 // however fail if the provided parser accepts empty inputs (such as `Digit0`, or
 // `Alpha0`) in order to prevent infinite loops.
+// The caches are cleared after items according to the CacheClearing policy
+// of the state (see gomme.State.AutoClearCaches).
 func Many0[Output any](parse gomme.Parser[Output]) gomme.Parser[[]Output] {
 	return ManyMN(parse, 0, math.MaxInt)
 }
@@ -37,6 +77,138 @@ func Many1[Output any](parse gomme.Parser[Output]) gomme.Parser[[]Output] {
 	return ManyMN(parse, 1, math.MaxInt)
 }
 
+// Many0Each applies a parser repeatedly until it fails, and calls `fn` for
+// every parsed item instead of collecting them in a slice.
+// So huge streams of records can be processed in constant memory.
+// The output is the number of items parsed.
+//
+// If `fn` returns an error, the parser fails with the error message
+// positioned at the start of the item.
+// Just like Many0 it fails if the provided parser accepts empty inputs.
+func Many0Each[Output any](parse gomme.Parser[Output], fn func(Output) error) gomme.Parser[int] {
+	expected := "many " + parse.Expected()
+
+	nullable := acceptsEmpty(parse)
+	eachParse := func(state gomme.State) (gomme.State, int, *gomme.ParserError) {
+		if nullable {
+			errState := state.NewFailure(nullableMsg(parse))
+			return errState, 0, errState.CurrentError()
+		}
+		count := 0
+		remaining := state
+		for {
+			newState, output, err := parse.It(remaining)
+			if err != nil {
+				if remaining.SaveSpotMoved(newState) { // fail because of SaveSpot
+					return remaining.Preserve(newState), count, err
+				}
+				return remaining.Succeed(newState), count, nil
+			}
+
+			// Checking for infinite loops, if nothing was consumed,
+			// the provided parser would make us go around in circles.
+			if !newState.Moved(remaining) {
+				errState := remaining.NewError(expected + " (empty element => endless loop)")
+				return state.Preserve(errState), count, errState.CurrentError()
+			}
+
+			if fnErr := fn(output); fnErr != nil {
+				errState := remaining.NewFailure(fnErr.Error())
+				return state.Preserve(errState), count, errState.CurrentError()
+			}
+			count++
+			remaining = newState.AutoClearCaches(true)
+		}
+	}
+	return gomme.NewParser[int](expected, eachParse, Forbidden("Many0Each"))
+}
+
+// SkipMany0 applies a parser repeatedly until it fails, and discards all
+// of its output. So no slice of results is allocated.
+//
+// Note that SkipMany0 will succeed even if the parser fails to match at all.
+// Just like Many0 it fails if the provided parser accepts empty inputs.
+func SkipMany0[Output any](parse gomme.Parser[Output]) gomme.Parser[struct{}] {
+	expected := "many " + parse.Expected()
+
+	nullable := acceptsEmpty(parse)
+	skipParse := func(state gomme.State) (gomme.State, struct{}, *gomme.ParserError) {
+		if nullable {
+			errState := state.NewFailure(nullableMsg(parse))
+			return errState, struct{}{}, errState.CurrentError()
+		}
+		remaining := state
+		for {
+			newState, _, err := parse.It(remaining)
+			if err != nil {
+				if remaining.SaveSpotMoved(newState) { // fail because of SaveSpot
+					return remaining.Preserve(newState), struct{}{}, err
+				}
+				return remaining.Succeed(newState), struct{}{}, nil
+			}
+
+			// Checking for infinite loops, if nothing was consumed,
+			// the provided parser would make us go around in circles.
+			if !newState.Moved(remaining) {
+				errState := remaining.NewError(expected + " (empty element => endless loop)")
+				return state.Preserve(errState), struct{}{}, errState.CurrentError()
+			}
+			remaining = newState
+		}
+	}
+	return gomme.NewParser[struct{}](expected, skipParse, Forbidden("SkipMany0"))
+}
+
+// TopLevel applies the item parser repeatedly until the end of the input
+// and returns all successfully parsed items.
+// It is meant for grammars of the form `file = many declarations`.
+//
+// Every failed item is reported as error of the state but doesn't let
+// TopLevel fail. Instead parsing jumps to the next sync point found by the
+// `sync` recoverer (e.g. IndexOf("func ")) starting at the error position.
+// So the sync point should be the start of the next item.
+// If no sync point can be found, the rest of the input is skipped.
+//
+// TopLevel itself never fails, except if the item parser accepts empty input.
+func TopLevel[Output any](parse gomme.Parser[Output], sync gomme.Recoverer) gomme.Parser[[]Output] {
+	expected := "top level " + parse.Expected()
+
+	topParse := func(state gomme.State) (gomme.State, []Output, *gomme.ParserError) {
+		outputs := make([]Output, 0, 16)
+		remaining := state
+		for !remaining.AtEnd() {
+			newState, output, err := parse.It(remaining)
+			if err == nil && !newState.Moved(remaining) {
+				errState := remaining.NewError(expected + " (empty element => endless loop)")
+				return errState, outputs, errState.CurrentError()
+			}
+			if err == nil {
+				outputs = append(outputs, output)
+				remaining = newState.AutoClearCaches(true)
+				continue
+			}
+
+			remaining = remaining.SaveError(err)
+			next := remaining.MoveBy(err.Pos() - remaining.CurrentPos())
+			for !next.AtEnd() {
+				waste := sync(next)
+				if waste < 0 { // no sync point anymore
+					next = next.MoveBy(next.BytesRemaining())
+					break
+				}
+				next = next.MoveBy(waste)
+				if next.Moved(remaining) {
+					break
+				}
+				next = next.Delete(1) // the item failed right at the sync point
+			}
+			remaining = next
+		}
+		return remaining, outputs, nil
+	}
+	return gomme.NewParser[[]Output](expected, topParse, Forbidden("TopLevel"))
+}
+
 // ManyMN applies a parser repeatedly until it fails, and returns a slice of all
 // the results as the Result's Output.
 //
@@ -1,7 +1,9 @@
 package pcb
 
 import (
+	"errors"
 	"github.com/oleiade/gomme"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -95,6 +97,102 @@ func BenchmarkCount(b *testing.B) {
 	}
 }
 
+func TestCountBy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        gomme.Parser[[]string]
+		input         string
+		wantErr       bool
+		wantOutput    []string
+		wantRemaining string
+	}{
+		{
+			name:          "parsing exact count should succeed",
+			parser:        CountBy(UInt8(false, 10), String("ab")),
+			input:         "2abab",
+			wantErr:       false,
+			wantOutput:    []string{"ab", "ab"},
+			wantRemaining: "",
+		},
+		{
+			name:          "parsing more than count should succeed",
+			parser:        CountBy(UInt8(false, 10), String("ab")),
+			input:         "1abab",
+			wantErr:       false,
+			wantOutput:    []string{"ab"},
+			wantRemaining: "ab",
+		},
+		{
+			name:          "parsing zero count should succeed",
+			parser:        CountBy(UInt8(false, 10), String("ab")),
+			input:         "0abab",
+			wantErr:       false,
+			wantOutput:    []string{},
+			wantRemaining: "abab",
+		},
+		{
+			name:          "parsing less than count should fail",
+			parser:        CountBy(UInt8(false, 10), String("ab")),
+			input:         "3abab",
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: "3abab",
+		},
+		{
+			name:          "negative count should fail",
+			parser:        CountBy(Int8(true, 10), String("ab")),
+			input:         "-1abab",
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: "-1abab",
+		},
+		{
+			name: "count too large for int should fail",
+			parser: CountBy(Map(String("x"), func(string) (uint64, error) {
+				return math.MaxUint64, nil
+			}), String("ab")),
+			input:         "xabab",
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: "xabab",
+		},
+		{
+			name:          "parsing empty input should fail",
+			parser:        CountBy(UInt8(false, 10), String("ab")),
+			input:         "",
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			assert.Equal(t,
+				tc.wantOutput,
+				gotResult,
+				"got output %v, want output %v", gotResult, tc.wantOutput,
+			)
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
 func TestMany0(t *testing.T) {
 	t.Parallel()
 
@@ -174,11 +272,48 @@ func TestMany0DetectsInfiniteLoops(t *testing.T) {
 
 	newState, output := parser.It(state)
 
-	assert.Error(t, newState.Errors())
+	assert.Error(t, newState.Err())
 	assert.Empty(t, output)
 	assert.Equal(t, state.CurrentString(), newState.CurrentString())
 }
 
+func TestRepeatingNullableParserIsReported(t *testing.T) {
+	t.Parallel()
+
+	for name, parser := range map[string]gomme.Parser[int]{
+		"Many0":      Map(Many0(Digit0()), func(ds []string) (int, error) { return len(ds), nil }),
+		"Separated0": Map(Separated0(Digit0(), Optional(Char(',')), false), func(ds []string) (int, error) { return len(ds), nil }),
+		"SkipMany0":  Assign(0, SkipMany0(Alpha0())),
+		"Many0Each":  Many0Each(Alpha0(), func(string) error { return nil }),
+	} {
+		newState, _ := gomme.RunOnState(gomme.NewFromString(1, nil, -1, "123"), parser)
+		assert.ErrorContains(t, newState.Err(), "grammar error", name)
+	}
+
+	// the separator consumes input, so there is no endless loop
+	parser := Separated0(Digit0(), Char(','), false)
+	newState, output := gomme.RunOnState(gomme.NewFromString(1, nil, -1, "1,,2"), parser)
+	assert.NoError(t, newState.Err())
+	assert.Equal(t, []string{"1", "", "2"}, output)
+}
+
+func TestRepeatingParserIsNotProbed(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	digits := Digit1()
+	counted := gomme.NewParser[string]("counted digits", func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		calls++
+		return digits.It(state)
+	}, digits.Recover)
+
+	parser := Many0(counted)
+	newState, output := gomme.RunOnState(gomme.NewFromString("12", false), parser)
+	assert.NoError(t, newState.Err())
+	assert.Equal(t, []string{"12"}, output)
+	assert.Equal(t, 2, calls, "the nullable check must not run the parser")
+}
+
 func BenchmarkMany0(b *testing.B) {
 	parser := Many0(Char('#'))
 	state := gomme.NewFromString(1, nil, -1, "###")
@@ -189,6 +324,74 @@ func BenchmarkMany0(b *testing.B) {
 	}
 }
 
+func TestMany0Each(t *testing.T) {
+	t.Parallel()
+
+	var got []rune
+	parser := Many0Each(Char('#'), func(r rune) error {
+		got = append(got, r)
+		return nil
+	})
+	state := gomme.NewFromString(1, nil, -1, "###abc")
+
+	newState, count := gomme.RunOnState(state, parser)
+
+	assert.NoError(t, newState.Err())
+	assert.Equal(t, 3, count)
+	assert.Equal(t, []rune{'#', '#', '#'}, got)
+	assert.Equal(t, "abc", newState.CurrentString())
+}
+
+func TestMany0EachCallbackError(t *testing.T) {
+	t.Parallel()
+
+	parser := Many0Each(Digit1(), func(digits string) error {
+		if len(digits) > 2 {
+			return errors.New("too many digits")
+		}
+		return nil
+	})
+	state := gomme.NewFromString(1, nil, -1, "123")
+
+	newState, _ := gomme.RunOnState(state, parser)
+
+	assert.ErrorContains(t, newState.Err(), "too many digits")
+}
+
+func TestSkipMany0(t *testing.T) {
+	t.Parallel()
+
+	parser := SkipMany0(Char('#'))
+
+	newState, _ := gomme.RunOnState(gomme.NewFromString(1, nil, -1, "###abc"), parser)
+	assert.NoError(t, newState.Err())
+	assert.Equal(t, "abc", newState.CurrentString())
+
+	newState, _ = gomme.RunOnState(gomme.NewFromString(1, nil, -1, "abc"), parser)
+	assert.NoError(t, newState.Err())
+	assert.Equal(t, "abc", newState.CurrentString())
+}
+
+func TestTopLevel(t *testing.T) {
+	t.Parallel()
+
+	parser := TopLevel(Prefixed(String("let "), Digit1()), IndexOf("let "))
+
+	newState, output := gomme.RunOnState(gomme.NewFromString(1, nil, -1, "let 1let 23"), parser)
+	assert.NoError(t, newState.Err())
+	assert.Equal(t, []string{"1", "23"}, output)
+
+	newState, output = gomme.RunOnState(gomme.NewFromString(1, nil, -1, "let 1let xlet 23"), parser)
+	assert.Equal(t, 1, newState.Errors().Len())
+	assert.Equal(t, 9, newState.Errors().All()[0].Pos())
+	assert.Equal(t, []string{"1", "23"}, output)
+
+	newState, output = gomme.RunOnState(gomme.NewFromString(1, nil, -1, "?let 1let x"), parser)
+	assert.Equal(t, 2, newState.Errors().Len())
+	assert.Equal(t, []string{"1"}, output)
+	assert.True(t, newState.AtEnd())
+}
+
 func TestMany1(t *testing.T) {
 	t.Parallel()
 
@@ -288,7 +491,7 @@ func TestMany1DetectsInfiniteLoops(t *testing.T) {
 
 	newState, output := parser.It(state)
 
-	assert.Error(t, newState.Errors())
+	assert.Error(t, newState.Err())
 	assert.Empty(t, output)
 	assert.Equal(t, state.CurrentString(), newState.CurrentString())
 }
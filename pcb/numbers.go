@@ -3,13 +3,13 @@ package pcb
 import (
 	"fmt"
 	"github.com/oleiade/gomme"
+	"math/big"
+	"math/bits"
 	"strconv"
 	"strings"
 	"unicode"
 )
 
-// import "math"
-
 // Integer parses any kind of integer number.
 // `signAllowed` can be false to parse only unsigned integers.
 // `radix` can be 0 to honor prefixes "0x", "0X", "0b", "0B", "0o", "0O" and "0"
@@ -138,7 +138,16 @@ func digitsToRunes(digits string) []rune {
 }
 
 // Int64 parses an integer from the input using `strconv.ParseInt`.
-func Int64(signAllowed bool, base int) gomme.Parser[int64] {
+// Options allow thousands separators (see NumberOption).
+// This function panics during the construction phase if options are
+// given for a base other than 10.
+func Int64(signAllowed bool, base int, opts ...NumberOption) gomme.Parser[int64] {
+	if len(opts) > 0 {
+		if base != 10 {
+			panic(fmt.Sprintf("Int64 supports number options only for base 10, but base is: %d", base))
+		}
+		return localeInt64(signAllowed, newNumberFormat(opts))
+	}
 	underscoreAllowed := false
 	if base == 0 {
 		underscoreAllowed = true
@@ -148,6 +157,26 @@ func Int64(signAllowed bool, base int) gomme.Parser[int64] {
 	})
 }
 
+func localeInt64(signAllowed bool, nf *numberFormat) gomme.Parser[int64] {
+	expected := "decimal integer"
+
+	intParse := func(state gomme.State) (gomme.State, int64, *gomme.ParserError) {
+		n, literal := nf.scan(state.CurrentString(), signAllowed, false, false)
+		if n == 0 {
+			errState := state.NewError(expected)
+			return errState, 0, errState.CurrentError()
+		}
+
+		newState := state.MoveBy(n)
+		i, err := strconv.ParseInt(literal, 10, 64)
+		if err != nil {
+			return newState.NewSemanticError(err.Error()), i, nil
+		}
+		return newState, i, nil
+	}
+	return gomme.NewParser[int64](expected, intParse, IndexOfAny('0', '1', '2', '3', '4', '5', '6', '7', '8', '9'))
+}
+
 // Int8 parses an integer from the input using `strconv.ParseInt`.
 func Int8(signAllowed bool, base int) gomme.Parser[int8] {
 	underscoreAllowed := false
@@ -175,58 +204,192 @@ func UInt8(signAllowed bool, base int) gomme.Parser[uint8] {
 	})
 }
 
-// Float parses a sequence of numerical characters into a float64.
-// The '.' character is used as the optional decimal delimiter. Any
-// number without a decimal part will still be parsed as a float64.
-//
-// N.B: it is not the parser's role to make sure the floating point
-// number you're attempting to parse fits into a 64 bits float.
-
-// func Float[I bytes]() Parser[I, float64] {
-// 	digitsParser := TakeWhileOneOf[I]([]rune("0123456789")...)
-// 	minusParser := Char[I]('-')
-// 	dotParser := Char[I]('.')
-
-// 	return func(input I) Result[float64, I] {
-// 		var negative bool
-
-// 		minusresult := minusParser(input)
-// 		if !result.Failed() {
-// 			negative = true
-// 		}
-
-// 		result = digitsParser(result.Remaining)
-// 		// result = Expect(digitsParser, "digits")(result.Remaining)
-// 		// if result.Failed() {
-// 		// 	return result
-// 		// }
-
-// 		parsed, ok := result.Output.(string)
-// 		if !ok {
-// 			err := fmt.Errorf("failed parsing floating point value; " +
-// 				"reason: converting Float() parser result's output to string failed",
-// 			)
-// 			return Preserve(NewFatalError(input, err, "float"), input)
-// 		}
-// 		if resultTest := dotParser(result.Remaining); resultTest.Err == nil {
-// 			if resultTest = digitsParser(resultTest.Remaining); resultTest.Err == nil {
-// 				parsed = parsed + "." + resultTest.Output.(string)
-// 				result = resultTest
-// 			}
-// 		}
-
-// 		floatingPointValue, err := strconv.ParseFloat(parsed, 64)
-// 		if err != nil {
-// 			err = fmt.Errorf("failed to parse '%v' as float; reason: %w", parsed, err)
-// 			return Preserve(NewFatalError(input, err), input)
-// 		}
-
-// 		if negative {
-// 			floatingPointValue = -floatingPointValue
-// 		}
-
-// 		result.Output = floatingPointValue
-
-// 		return result
-// 	}
-// }
+// BigInt parses an integer of arbitrary size from the input using
+// `big.Int.SetString`.
+// So it can't overflow like Int64.
+// With base 0 the prefixes of Integer and '_' as digit separator are
+// allowed.
+func BigInt(signAllowed bool, base int) gomme.Parser[*big.Int] {
+	underscoreAllowed := base == 0
+	return Map(Integer(signAllowed, base, underscoreAllowed), func(integer string) (*big.Int, error) {
+		return parseBigInt(integer, base)
+	})
+}
+
+// BigRat parses a rational number of arbitrary precision from the input.
+// It is an integer (see BigInt) that is optionally followed by '/' and an
+// unsigned denominator (e.g. "-3/4" or "0x10/3").
+// With base 0 the numerator and the denominator can use different prefixes.
+func BigRat(signAllowed bool, base int) gomme.Parser[*big.Rat] {
+	underscoreAllowed := base == 0
+	return Map2(
+		Integer(signAllowed, base, underscoreAllowed),
+		OptionalOK(Prefixed(Char('/'), Integer(false, base, underscoreAllowed))),
+		func(numerator string, denominator Option[string]) (*big.Rat, error) {
+			num, err := parseBigInt(numerator, base)
+			if err != nil {
+				return nil, err
+			}
+			if !denominator.OK {
+				return new(big.Rat).SetInt(num), nil
+			}
+			denom, err := parseBigInt(denominator.Value, base)
+			if err != nil {
+				return nil, err
+			}
+			if denom.Sign() == 0 {
+				return nil, fmt.Errorf("denominator of rational number %s/%s is zero",
+					numerator, denominator.Value)
+			}
+			return new(big.Rat).SetFrac(num, denom), nil
+		},
+	)
+}
+
+func parseBigInt(integer string, base int) (*big.Int, error) {
+	i, ok := new(big.Int).SetString(integer, base)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q of base %d", integer, base)
+	}
+	return i, nil
+}
+
+// IntBase parses an unsigned integer with a custom digit alphabet.
+// The base is the number of digits and the first digit has the value 0
+// (e.g. "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz" for
+// base58 IDs).
+// A number that doesn't fit into an uint64 is reported as semantic error
+// at the first digit that is too much.
+// This function panics during the construction phase if the alphabet
+// has less than 2 digits, non-ASCII or duplicate digits.
+func IntBase(digits string) gomme.Parser[uint64] {
+	if len(digits) < 2 {
+		panic(fmt.Sprintf("IntBase needs at least 2 digits, but got: %q", digits))
+	}
+	var values [256]int16
+	for i := range values {
+		values[i] = -1
+	}
+	for i := 0; i < len(digits); i++ {
+		d := digits[i]
+		if d >= 0x80 {
+			panic(fmt.Sprintf("IntBase needs ASCII digits, but got: %q", digits))
+		}
+		if values[d] >= 0 {
+			panic(fmt.Sprintf("IntBase got the digit %q twice in: %q", d, digits))
+		}
+		values[d] = int16(i)
+	}
+	base := uint64(len(digits))
+	expected := fmt.Sprintf("base %d number", base)
+
+	intParse := func(state gomme.State) (gomme.State, uint64, *gomme.ParserError) {
+		input := state.CurrentString()
+		var result uint64
+		overflow := -1 // position of the first digit that is too much
+		n := 0
+		for ; n < len(input) && values[input[n]] >= 0; n++ {
+			if overflow >= 0 {
+				continue
+			}
+			hi, lo := bits.Mul64(result, base)
+			sum, carry := bits.Add64(lo, uint64(values[input[n]]), 0)
+			if hi != 0 || carry != 0 {
+				overflow = n
+				continue
+			}
+			result = sum
+		}
+		if n == 0 {
+			errState := state.NewError(expected)
+			return errState, 0, errState.CurrentError()
+		}
+		if overflow >= 0 {
+			return state.MoveBy(overflow).NewSemanticError(
+				fmt.Sprintf("%s %s is too large for 64 bits", expected, input[:n]),
+			).MoveBy(n - overflow), 0, nil
+		}
+		return state.MoveBy(n), result, nil
+	}
+	return gomme.NewParser[uint64](expected, intParse, func(state gomme.State) int {
+		return strings.IndexAny(state.CurrentString(), digits)
+	})
+}
+
+// Float parses a floating point number from the input.
+// The literal is recognized in a single pass over the input bytes and
+// then converted by `strconv.ParseFloat`. So no intermediate slices are
+// created.
+// An optional sign is followed by digits with an optional decimal point
+// and an optional exponent (e.g. "-1.5e-3", ".5" or "7.").
+// There has to be at least one digit before the exponent.
+// A number that is too large for a float64 is reported as semantic error.
+// Options allow other decimal and thousands separators (see NumberOption).
+func Float(opts ...NumberOption) gomme.Parser[float64] {
+	expected := "floating point number"
+	scan := func(input string) (int, string) {
+		n := floatSpan(input)
+		return n, input[:n]
+	}
+	if len(opts) > 0 {
+		nf := newNumberFormat(opts)
+		scan = func(input string) (int, string) {
+			return nf.scan(input, true, true, true)
+		}
+	}
+
+	floatParse := func(state gomme.State) (gomme.State, float64, *gomme.ParserError) {
+		n, literal := scan(state.CurrentString())
+		if n == 0 {
+			errState := state.NewError(expected)
+			return errState, 0, errState.CurrentError()
+		}
+
+		newState := state.MoveBy(n)
+		f, err := strconv.ParseFloat(literal, 64)
+		if err != nil {
+			return newState.NewSemanticError(err.Error()), f, nil
+		}
+		return newState, f, nil
+	}
+	return gomme.NewParser[float64](expected, floatParse, IndexOfAny('0', '1', '2', '3', '4', '5', '6', '7', '8', '9'))
+}
+
+// floatSpan returns the length of the floating point literal at the
+// start of the input or 0.
+func floatSpan(input string) int {
+	n := 0
+	if n < len(input) && (input[n] == '+' || input[n] == '-') {
+		n++
+	}
+	digits := 0
+	for n < len(input) && input[n] >= '0' && input[n] <= '9' {
+		n++
+		digits++
+	}
+	if n < len(input) && input[n] == '.' {
+		n++
+		for n < len(input) && input[n] >= '0' && input[n] <= '9' {
+			n++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return 0
+	}
+
+	if n < len(input) && (input[n] == 'e' || input[n] == 'E') { // only consume a complete exponent
+		e := n + 1
+		if e < len(input) && (input[e] == '+' || input[e] == '-') {
+			e++
+		}
+		expStart := e
+		for e < len(input) && input[e] >= '0' && input[e] <= '9' {
+			e++
+		}
+		if e > expStart {
+			n = e
+		}
+	}
+	return n
+}
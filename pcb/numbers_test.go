@@ -2,6 +2,9 @@ package pcb
 
 import (
 	"github.com/oleiade/gomme"
+	"math/big"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -270,3 +273,269 @@ func BenchmarkUInt8(b *testing.B) {
 		_, _ = parser.It(input)
 	}
 }
+
+func TestBigInt(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        gomme.Parser[*big.Int]
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:          "parsing huge integer should succeed",
+			parser:        BigInt(true, 10),
+			input:         "-123456789012345678901234567890;",
+			wantOutput:    "-123456789012345678901234567890",
+			wantRemaining: ";",
+		}, {
+			name:       "parsing prefix and separators should succeed",
+			parser:     BigInt(false, 0),
+			input:      "0x_ffff_ffff_ffff_ffff_ffff",
+			wantOutput: "1208925819614629174706175",
+		}, {
+			name:       "parsing other base should succeed",
+			parser:     BigInt(false, 36),
+			input:      "zz",
+			wantOutput: "1295",
+		}, {
+			name:          "parsing no digits should fail",
+			parser:        BigInt(true, 10),
+			input:         "-abc",
+			wantErr:       true,
+			wantRemaining: "-abc",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), tc.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+			if !tc.wantErr && gotResult.String() != tc.wantOutput {
+				t.Errorf("got output %s, want output %s", gotResult, tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestBigRat(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        gomme.Parser[*big.Rat]
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:       "parsing fraction should succeed",
+			parser:     BigRat(true, 10),
+			input:      "-6/8",
+			wantOutput: "-3/4",
+		}, {
+			name:          "parsing integer should succeed",
+			parser:        BigRat(true, 10),
+			input:         "12345678901234567890 ",
+			wantOutput:    "12345678901234567890",
+			wantRemaining: " ",
+		}, {
+			name:       "parsing different prefixes should succeed",
+			parser:     BigRat(false, 0),
+			input:      "0x10/0b11",
+			wantOutput: "16/3",
+		}, {
+			name:          "missing denominator should be left over",
+			parser:        BigRat(false, 10),
+			input:         "1/x",
+			wantOutput:    "1",
+			wantRemaining: "/x",
+		}, {
+			name:    "zero denominator should fail",
+			parser:  BigRat(false, 10),
+			input:   "1/0",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), tc.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+			if !tc.wantErr && gotResult.RatString() != tc.wantOutput {
+				t.Errorf("got output %s, want output %s", gotResult.RatString(), tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestFloat(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       bool
+		wantOutput    float64
+		wantRemaining string
+	}{
+		{
+			name:       "parsing integer should succeed",
+			input:      "123",
+			wantOutput: 123,
+		}, {
+			name:          "parsing negative decimal should succeed",
+			input:         "-1.5;",
+			wantOutput:    -1.5,
+			wantRemaining: ";",
+		}, {
+			name:       "parsing exponent should succeed",
+			input:      "+2.5E-3",
+			wantOutput: 0.0025,
+		}, {
+			name:       "parsing leading decimal point should succeed",
+			input:      ".5",
+			wantOutput: 0.5,
+		}, {
+			name:          "parsing trailing decimal point should succeed",
+			input:         "7.x",
+			wantOutput:    7,
+			wantRemaining: "x",
+		}, {
+			name:          "incomplete exponent should be left over",
+			input:         "1e+x",
+			wantOutput:    1,
+			wantRemaining: "e+x",
+		}, {
+			name:          "sign without digits should fail",
+			input:         "-.e3",
+			wantErr:       true,
+			wantRemaining: "-.e3",
+		}, {
+			name:    "too large number should fail",
+			input:   "1e999",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), Float())
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+			if !tc.wantErr && gotResult != tc.wantOutput {
+				t.Errorf("got output %g, want output %g", gotResult, tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+const benchmarkFloatInput = "-12345.6789e-12"
+
+func BenchmarkFloat(b *testing.B) {
+	parser := Float()
+	input := gomme.NewFromString(benchmarkFloatInput, false)
+
+	b.SetBytes(int64(len(benchmarkFloatInput)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = parser.It(input)
+	}
+}
+
+// BenchmarkFloatScanner is the baseline for BenchmarkFloat:
+// a hand-rolled scanner that only finds the literal and converts it.
+func BenchmarkFloatScanner(b *testing.B) {
+	b.SetBytes(int64(len(benchmarkFloatInput)))
+	for i := 0; i < b.N; i++ {
+		n := floatSpan(benchmarkFloatInput)
+		_, _ = strconv.ParseFloat(benchmarkFloatInput[:n], 64)
+	}
+}
+
+func TestIntBase(t *testing.T) {
+	t.Parallel()
+
+	const base58 = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	testCases := []struct {
+		name          string
+		parser        gomme.Parser[uint64]
+		input         string
+		wantErr       bool
+		wantOutput    uint64
+		wantRemaining string
+	}{
+		{
+			name:          "parsing base58 should succeed",
+			parser:        IntBase(base58),
+			input:         "2g0",
+			wantOutput:    97,
+			wantRemaining: "0",
+		}, {
+			name:       "parsing maximum value should succeed",
+			parser:     IntBase("01"),
+			input:      strings.Repeat("1", 64),
+			wantOutput: 1<<64 - 1,
+		}, {
+			name:    "parsing too large value should fail",
+			parser:  IntBase("01"),
+			input:   "1" + strings.Repeat("0", 64),
+			wantErr: true,
+		}, {
+			name:          "parsing invalid digit should fail",
+			parser:        IntBase(base58),
+			input:         "0",
+			wantErr:       true,
+			wantRemaining: "0",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), tc.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %d, want output %d", gotResult, tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package pcb
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/oleiade/gomme"
+)
+
+// OperatorKind is the kind of an operator of an OperatorTable.
+type OperatorKind uint8
+
+const (
+	Infix   OperatorKind = iota // between two operands, e.g. `a + b`
+	Prefix                      // before its operand, e.g. `-a`
+	Postfix                     // after its operand, e.g. `a!`
+)
+
+// Assoc is the associativity of infix operators.
+type Assoc uint8
+
+const (
+	AssocLeft  Assoc = iota // `a - b - c` is `(a - b) - c`
+	AssocRight              // `a ^ b ^ c` is `a ^ (b ^ c)`
+	AssocNone               // `a < b < c` is an error
+)
+
+// Operator is one entry (row) of an OperatorTable.
+type Operator[T any] struct {
+	Kind       OperatorKind
+	Token      string               // the operator, e.g. `+`; only used if Parser is nil
+	Parser     gomme.Parser[string] // optional parser for the operator (e.g. for keywords)
+	Precedence int                  // operators with higher precedence bind stronger
+	Assoc      Assoc                // only used for infix operators
+	// Separators turn the operator into a mixfix operator (see OperatorN).
+	Separators []gomme.Parser[string]
+	// Combine computes the value of the operator applied to its operands.
+	// `op` is the parsed operator (the first part of mixfix operators).
+	// An error lets the parser fail.
+	Combine func(op string, operands []T) (T, error)
+}
+
+// OperatorN returns a mixfix operator consisting of the interleaved parts.
+// A full expression is parsed between two parts. The kind defines whether
+// operands are parsed before the first and after the last part:
+//
+//   - Infix: `a ? b : c` (parts `?` and `:`), the operands are a, b and c.
+//   - Prefix: `if a then b else c` (parts `if`, `then` and `else`),
+//     the operands are a, b and c.
+//   - Postfix: `a[b]` (parts `[` and `]`), the operands are a and b.
+//
+// The precedence and associativity apply to the outer operands just
+// like for other operators.
+// This function panics during the construction phase if less than two
+// parts are given.
+func OperatorN[T any](
+	kind OperatorKind, precedence int, assoc Assoc, combine func(op string, operands []T) (T, error),
+	parts ...gomme.Parser[string],
+) Operator[T] {
+	if len(parts) < 2 {
+		panic("OperatorN needs at least two parts")
+	}
+	return Operator[T]{
+		Kind: kind, Parser: parts[0], Separators: parts[1:],
+		Precedence: precedence, Assoc: assoc, Combine: combine,
+	}
+}
+
+// OperatorTable is a declarative description of the operators of an
+// expression language.
+// Because it is just data, it can be constructed at runtime (e.g. for
+// user-defined operators) and applied to an operand parser with Parser.
+type OperatorTable[T any] struct {
+	Operators []Operator[T]
+	SkipSpace bool // skip whitespace before all operators and operands
+}
+
+// Add appends an operator to the table and returns the table.
+func (ot *OperatorTable[T]) Add(op Operator[T]) *OperatorTable[T] {
+	ot.Operators = append(ot.Operators, op)
+	return ot
+}
+
+// Parser returns a parser for expressions built from the operators of the
+// table and the operand parser.
+// Parentheses have to be handled by the operand parser
+// (usually with a LazyParser for the returned parser).
+// Later changes to the table don't affect the returned parser.
+//
+// The operators are matched by the longest match. So `<=` is preferred over `<`.
+// This function panics during the construction phase if an operator has
+// neither token nor parser or no Combine function.
+func (ot *OperatorTable[T]) Parser(operand gomme.Parser[T]) gomme.Parser[T] {
+	ops := make([]tableOp[T], 0, len(ot.Operators))
+	for _, op := range ot.Operators {
+		if op.Combine == nil {
+			panic(fmt.Sprintf("operator %q has no Combine function", op.Token))
+		}
+		tok := op.Parser
+		if tok == nil {
+			if op.Token == "" {
+				panic("operator without token and parser")
+			}
+			tok = String(op.Token)
+		}
+		ops = append(ops, tableOp[T]{Operator: op, token: tok})
+	}
+	ep := &exprParser[T]{ops: ops, operand: operand, skipSpace: ot.SkipSpace}
+
+	expected := "expression of " + operand.Expected()
+	return gomme.NewParser[T](expected, ep.parseAll, operand.Recover)
+}
+
+type tableOp[T any] struct {
+	Operator[T]
+	token gomme.Parser[string]
+}
+
+type exprParser[T any] struct {
+	ops       []tableOp[T]
+	operand   gomme.Parser[T]
+	skipSpace bool
+}
+
+func (ep *exprParser[T]) parseAll(state gomme.State) (gomme.State, T, *gomme.ParserError) {
+	return ep.parse(state, math.MinInt, false)
+}
+
+// parse parses an expression with operators of at least precedence
+// `minPrec` (or higher than `minPrec` if `above` is true).
+func (ep *exprParser[T]) parse(state gomme.State, minPrec int, above bool) (gomme.State, T, *gomme.ParserError) {
+	var left T
+	binds := func(prec int) bool {
+		return prec > minPrec || (prec == minPrec && !above)
+	}
+
+	// prefix operator (always allowed in front of an operand) or operand
+	if op, name, opState, ok := ep.match(state, Prefix, func(int) bool { return true }); ok {
+		midState, operands, err := ep.middle(opState, op)
+		if err != nil {
+			return state.Preserve(midState), left, err
+		}
+		xState, x, err := ep.parse(midState, op.Precedence, false)
+		if err != nil {
+			return state.Preserve(xState), left, err
+		}
+		if left, err = ep.combine(state, op, name, append(operands, x)); err != nil {
+			return state.ErrorAgain(err), left, err
+		}
+		state = xState
+	} else {
+		newState, output, err := ep.operand.It(ep.space(state))
+		if err != nil {
+			return state.Preserve(newState), left, err
+		}
+		left, state = output, newState
+	}
+
+	lastNone := -1 // precedence of the last non-associative operator
+	for {
+		if op, name, opState, ok := ep.match(state, Postfix, binds); ok {
+			midState, operands, err := ep.middle(opState, op)
+			if err != nil {
+				return state.Preserve(midState), left, err
+			}
+			result, err := ep.combine(state, op, name, append([]T{left}, operands...))
+			if err != nil {
+				return state.ErrorAgain(err), left, err
+			}
+			left, state = result, midState
+			continue
+		}
+		op, name, opState, ok := ep.match(state, Infix, binds)
+		if !ok {
+			return state, left, nil
+		}
+		if op.Assoc == AssocNone && op.Precedence == lastNone {
+			errState := ep.space(state).NewFailure(fmt.Sprintf("operator %q is not associative", name))
+			return errState, left, errState.CurrentError()
+		}
+		midState, operands, err := ep.middle(opState, op)
+		if err != nil {
+			return state.Preserve(midState), left, err
+		}
+		rightState, right, err := ep.parse(midState, op.Precedence, op.Assoc != AssocRight)
+		if err != nil {
+			return state.Preserve(rightState), left, err
+		}
+		operands = append(append([]T{left}, operands...), right)
+		result, err := ep.combine(state, op, name, operands)
+		if err != nil {
+			return state.ErrorAgain(err), left, err
+		}
+		lastNone = -1
+		if op.Assoc == AssocNone {
+			lastNone = op.Precedence
+		}
+		left, state = result, rightState
+	}
+}
+
+// middle parses the operands between the parts of a mixfix operator.
+// Nothing is parsed for other operators.
+func (ep *exprParser[T]) middle(state gomme.State, op *tableOp[T]) (gomme.State, []T, *gomme.ParserError) {
+	operands := make([]T, 0, len(op.Separators)+2)
+	for _, sep := range op.Separators {
+		newState, x, err := ep.parse(state, math.MinInt, false)
+		if err != nil {
+			return newState, nil, err
+		}
+		sepState, _, err := sep.It(ep.space(newState))
+		if err != nil {
+			return sepState, nil, err
+		}
+		operands = append(operands, x)
+		state = sepState
+	}
+	return state, operands, nil
+}
+
+// match finds the longest operator of the kind at the start of the input.
+func (ep *exprParser[T]) match(
+	state gomme.State, kind OperatorKind, binds func(int) bool,
+) (op *tableOp[T], name string, newState gomme.State, ok bool) {
+	start := ep.space(state)
+	for i := range ep.ops {
+		cand := &ep.ops[i]
+		if cand.Kind != kind || !binds(cand.Precedence) {
+			continue
+		}
+		candState, candName, err := cand.token.It(start)
+		if err != nil || (ok && candState.CurrentPos() <= newState.CurrentPos()) {
+			continue
+		}
+		op, name, newState, ok = cand, candName, candState, true
+	}
+	return op, name, newState, ok
+}
+
+func (ep *exprParser[T]) combine(state gomme.State, op *tableOp[T], name string, operands []T) (T, *gomme.ParserError) {
+	result, err := op.Combine(name, operands)
+	if err != nil {
+		return result, ep.space(state).NewFailure(err.Error()).CurrentError()
+	}
+	return result, nil
+}
+
+func (ep *exprParser[T]) space(state gomme.State) gomme.State {
+	if !ep.skipSpace {
+		return state
+	}
+	input := state.CurrentString()
+	return state.MoveBy(len(input) - len(strings.TrimLeftFunc(input, unicode.IsSpace)))
+}
@@ -0,0 +1,150 @@
+package pcb
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/oleiade/gomme"
+)
+
+func arithmeticTable() *OperatorTable[int64] {
+	binary := func(op string, operands []int64) (int64, error) {
+		a, b := operands[0], operands[1]
+		switch op {
+		case "+":
+			return a + b, nil
+		case "-":
+			return a - b, nil
+		case "*":
+			return a * b, nil
+		case "/":
+			if b == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return a / b, nil
+		case "^":
+			return int64(math.Pow(float64(a), float64(b))), nil
+		case "<":
+			if a < b {
+				return 1, nil
+			}
+			return 0, nil
+		}
+		return 0, errors.New("unknown operator " + op)
+	}
+
+	ot := &OperatorTable[int64]{SkipSpace: true}
+	ot.Add(Operator[int64]{Kind: Infix, Token: "<", Precedence: 1, Assoc: AssocNone, Combine: binary}).
+		Add(Operator[int64]{Kind: Infix, Token: "+", Precedence: 2, Combine: binary}).
+		Add(Operator[int64]{Kind: Infix, Token: "-", Precedence: 2, Combine: binary}).
+		Add(Operator[int64]{Kind: Infix, Token: "*", Precedence: 3, Combine: binary}).
+		Add(Operator[int64]{Kind: Infix, Token: "/", Precedence: 3, Combine: binary}).
+		Add(Operator[int64]{Kind: Infix, Token: "^", Precedence: 5, Assoc: AssocRight, Combine: binary}).
+		Add(Operator[int64]{Kind: Prefix, Token: "-", Precedence: 4, Combine: func(_ string, x []int64) (int64, error) {
+			return -x[0], nil
+		}}).
+		Add(Operator[int64]{Kind: Postfix, Token: "!", Precedence: 6, Combine: func(_ string, x []int64) (int64, error) {
+			result := int64(1)
+			for i := int64(2); i <= x[0]; i++ {
+				result *= i
+			}
+			return result, nil
+		}})
+	return ot
+}
+
+func TestOperatorTable(t *testing.T) {
+	t.Parallel()
+
+	parser := arithmeticTable().Parser(Int64(false, 10))
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       bool
+		wantOutput    int64
+		wantRemaining string
+	}{
+		{name: "single operand", input: "42", wantOutput: 42},
+		{name: "precedence", input: "1 + 2 * 3", wantOutput: 7},
+		{name: "left associativity", input: "10 - 4 - 3", wantOutput: 3},
+		{name: "right associativity", input: "2 ^ 3 ^ 2", wantOutput: 512},
+		{name: "prefix", input: "-2 ^ 2", wantOutput: -4},
+		{name: "postfix", input: "3! * 2", wantOutput: 12},
+		{name: "trailing input", input: "1 + 2 )", wantOutput: 3, wantRemaining: " )"},
+		{name: "non-associative operator", input: "1 < 2 < 3", wantErr: true},
+		{name: "missing operand", input: "1 +", wantErr: true},
+		{name: "failing combine", input: "1 / 0", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", newState.Errors(), tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %d, want output %d", gotResult, tc.wantOutput)
+			}
+			if remaining := newState.CurrentString(); remaining != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remaining, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestOperatorN(t *testing.T) {
+	t.Parallel()
+
+	ternary := func(_ string, operands []int64) (int64, error) {
+		if operands[0] != 0 {
+			return operands[1], nil
+		}
+		return operands[2], nil
+	}
+	ot := arithmeticTable()
+	ot.Add(OperatorN(Infix, 0, AssocRight, ternary, String("?"), String(":"))).
+		Add(OperatorN(Prefix, 0, AssocLeft, ternary, String("if"), String("then"), String("else"))).
+		Add(OperatorN(Postfix, 7, AssocLeft, func(_ string, operands []int64) (int64, error) {
+			return operands[0] % operands[1], nil // a[b] is a modulo b
+		}, String("["), String("]")))
+	parser := ot.Parser(Int64(false, 10))
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput int64
+	}{
+		{name: "ternary", input: "1 < 2 ? 10 : 20", wantOutput: 10},
+		{name: "nested ternary", input: "0 ? 1 : 0 ? 2 : 3", wantOutput: 3},
+		{name: "ternary in the middle", input: "1 ? 0 ? 4 : 5 : 6", wantOutput: 5},
+		{name: "prefix mixfix", input: "if 0 then 1 else 2 + 3", wantOutput: 5},
+		{name: "postfix mixfix", input: "2 * 17[5]", wantOutput: 4},
+		{name: "missing separator", input: "1 ? 2", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", newState.Errors(), tc.wantErr)
+			}
+			if !tc.wantErr && gotResult != tc.wantOutput {
+				t.Errorf("got output %d, want output %d", gotResult, tc.wantOutput)
+			}
+		})
+	}
+}
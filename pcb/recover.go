@@ -2,8 +2,8 @@ package pcb
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/oleiade/gomme"
-	"reflect"
 	"strings"
 )
 
@@ -25,32 +25,30 @@ func Forbidden(name string) gomme.Recoverer {
 // IndexOf searches until it finds the stop token in the input.
 // If found the Recoverer returns the number of bytes up to the stop.
 // If the token could not be found, the recoverer returns -1.
-// This function panics during the construction phase if `stop` is empty.
+// This function panics during the construction phase if `stop` is empty
+// or if its type isn't exactly byte, rune, string or []byte.
 func IndexOf[S gomme.Separator](stop S) gomme.Recoverer {
-	// This IS type safe because of the `Separator` constraint!
-	// Performance doesn't matter either because this is done during the
-	// construction phase.
-	switch v := reflect.ValueOf(stop); v.Kind() {
-	case reflect.Uint8:
-		xstop := interface{}(stop).(byte)
+	// A type switch doesn't need reflection (so it doesn't get in the way of TinyGo).
+	// But it doesn't match named types like `type Sep string` that are
+	// allowed by the `Separator` constraint because of their underlying type.
+	switch xstop := interface{}(stop).(type) {
+	case byte:
 		return func(state gomme.State) int {
 			return bytes.IndexByte(state.CurrentBytes(), xstop)
 		}
-	case reflect.Int32:
-		rstop := interface{}(stop).(rune)
+	case rune:
 		return func(state gomme.State) int {
-			return strings.IndexRune(state.CurrentString(), rstop)
+			return strings.IndexRune(state.CurrentString(), xstop)
 		}
-	case reflect.String:
-		sstop := interface{}(stop).(string)
-		if len(sstop) == 0 {
+	case string:
+		if len(xstop) == 0 {
 			panic("stop is empty")
 		}
 		return func(state gomme.State) int {
-			return strings.Index(state.CurrentString(), sstop)
+			return strings.Index(state.CurrentString(), xstop)
 		}
-	case reflect.Slice:
-		bstop := interface{}(stop).([]byte)
+	case []byte:
+		bstop := xstop
 		if len(bstop) == 0 {
 			panic("stop is empty")
 		}
@@ -58,7 +56,7 @@ func IndexOf[S gomme.Separator](stop S) gomme.Recoverer {
 			return bytes.Index(state.CurrentBytes(), bstop)
 		}
 	default:
-		return nil // can never happen because of the `Separator` constraint!
+		panic(unsupportedStop("IndexOf", stop))
 	}
 }
 
@@ -68,8 +66,8 @@ func IndexOf[S gomme.Separator](stop S) gomme.Recoverer {
 //
 // NOTE:
 //   - If any of the `stops` is empty it returns 0.
-//   - If no stops are provided then this function panics during
-//     the construction phase.
+//   - If no stops are provided or their type isn't exactly byte, rune,
+//     string or []byte then this function panics during the construction phase.
 func IndexOfAny[S gomme.Separator](stops ...S) gomme.Recoverer {
 	const (
 		modeByte = iota
@@ -84,20 +82,19 @@ func IndexOfAny[S gomme.Separator](stops ...S) gomme.Recoverer {
 		panic("no stops provided")
 	}
 
-	// This IS type safe because of the `Separator` constraint!
-	// Performance doesn't matter either because this is done during the
-	// construction phase.
-	switch v := reflect.ValueOf(stops[0]); v.Kind() {
-	case reflect.Uint8:
+	// A type switch doesn't need reflection (so it doesn't get in the way of TinyGo).
+	// Named types are rejected just like in IndexOf.
+	switch interface{}(stops[0]).(type) {
+	case byte:
 		mode = modeByte
-	case reflect.Int32:
+	case rune:
 		mode = modeRune
-	case reflect.String:
+	case string:
 		mode = modeString
-	case reflect.Slice:
+	case []byte:
 		mode = modeBytes
 	default:
-		// can never happen because of the `Separator` constraint!
+		panic(unsupportedStop("IndexOfAny", stops[0]))
 	}
 
 	indexOfOneOfByte := func(state gomme.State) int {
@@ -160,6 +157,14 @@ func IndexOfAny[S gomme.Separator](stops ...S) gomme.Recoverer {
 	}
 }
 
+// unsupportedStop returns the panic message for a stop of a named type.
+func unsupportedStop[S gomme.Separator](name string, stop S) string {
+	return fmt.Sprintf(
+		"%s needs a stop of type byte, rune, string or []byte, but got type %T; please convert it",
+		name, stop,
+	)
+}
+
 // BasicRecovererFunc recovers by trying to parse again and again and again.
 // It moves forward in the input using the Deleter one token at a time.
 func BasicRecovererFunc[Output any](parse func(gomme.State) (gomme.State, Output, *gomme.ParserError)) func(gomme.State) int {
@@ -4,12 +4,22 @@ import (
 	"fmt"
 	"github.com/oleiade/gomme"
 	"slices"
+	"strconv"
+	"strings"
 )
 
 // Sequence applies a sequence of parsers of the same type and
 // returns either a slice of results or an error if any parser fails.
+// The sequence can be of any length (unlike the MapX parsers) and takes
+// part in error recovery just like the MapX parsers.
 // Use one of the MapX parsers for differently typed parsers.
 func Sequence[Output any](parsers ...gomme.Parser[Output]) gomme.Parser[[]Output] {
+	return newSequence(parsers, nil)
+}
+
+// newSequence creates a Sequence parser that matches the literal runs
+// (indexed by their first sub-parser) at once.
+func newSequence[Output any](parsers []gomme.Parser[Output], runs []*literalRun[Output]) gomme.Parser[[]Output] {
 	// Construct mySaveSpotRecoverer from the sub-parsers
 	subRecoverers := make([]gomme.Recoverer, len(parsers))
 	for i, parser := range parsers {
@@ -18,10 +28,11 @@ func Sequence[Output any](parsers ...gomme.Parser[Output]) gomme.Parser[[]Output
 	mySaveSpotRecoverer := gomme.NewCombiningRecoverer(true, subRecoverers...)
 
 	seq := &sequenceData[Output]{
-		id:                gomme.NewBranchParserID(),
+		id:                gomme.NewParserID(),
 		parsers:           parsers,
 		saveSpotRecoverer: mySaveSpotRecoverer,
 		subRecoverers:     subRecoverers,
+		runs:              runs,
 	}
 
 	// finally the parse function
@@ -40,20 +51,120 @@ func Sequence[Output any](parsers ...gomme.Parser[Output]) gomme.Parser[[]Output
 		myRecoverer = parsers[0].MyRecoverer()
 	}
 
-	return gomme.NewParser[[]Output](
+	analyses := make([]gomme.Analysis, len(parsers))
+	for i, parser := range parsers {
+		analyses[i] = gomme.Analyze(parser)
+	}
+
+	p := gomme.NewParser[[]Output](
 		"Sequence",
 		parseSeq,
 		true,
 		myRecoverer,
 		mySaveSpotRecoverer.Recover,
 	)
+	p = gomme.WithAnalysis(p, gomme.SequenceAnalysis(analyses...))
+	return gomme.WithOptimizer(p, func() gomme.Parser[[]Output] {
+		return optimizeSequence(parsers)
+	})
+}
+
+// optimizeSequence optimizes all sub-parsers and fuses them into a single
+// literal match if all of them are literal parsers.
+// Otherwise runs of at least 2 adjacent literal parsers are matched at once.
+func optimizeSequence[Output any](parsers []gomme.Parser[Output]) gomme.Parser[[]Output] {
+	optimized := make([]gomme.Parser[Output], len(parsers))
+	literals := make([]string, len(parsers))
+	isLiteral := make([]bool, len(parsers))
+	allLiterals := len(parsers) > 0
+	for i, parser := range parsers {
+		optimized[i] = gomme.Optimize(parser)
+		literals[i], isLiteral[i] = gomme.LiteralOf(optimized[i])
+		allLiterals = allLiterals && isLiteral[i]
+	}
+	if allLiterals {
+		return fusedLiterals(optimized, literals)
+	}
+
+	var runs []*literalRun[Output]
+	for start := 0; start < len(optimized); {
+		end := start
+		for end < len(optimized) && isLiteral[end] {
+			end++
+		}
+		if end-start >= 2 {
+			if run, ok := newLiteralRun(optimized[start:end], literals[start:end]); ok {
+				if runs == nil {
+					runs = make([]*literalRun[Output], len(optimized))
+				}
+				runs[start] = run
+			}
+		}
+		start = max(end, start+1)
+	}
+	return newSequence(optimized, runs)
+}
+
+// literalRun is a run of adjacent literal parsers inside of a Sequence.
+// Literal parsers always produce the same output, so the outputs are
+// computed once.
+type literalRun[Output any] struct {
+	token   string
+	outputs []Output
+}
+
+func newLiteralRun[Output any](parsers []gomme.Parser[Output], literals []string) (*literalRun[Output], bool) {
+	outputs := make([]Output, len(parsers))
+	for i, parser := range parsers {
+		_, output, err := parser.It(gomme.NewFromString(literals[i], false))
+		if err != nil { // a literal parser must match its own literal
+			return nil, false
+		}
+		outputs[i] = output
+	}
+	return &literalRun[Output]{token: strings.Join(literals, ""), outputs: outputs}, true
+}
+
+// fusedLiterals matches all literals at once.
+// Literal parsers always produce the same output, so the outputs are
+// computed once here.
+// Errors are reported at the first literal that doesn't match and with its
+// expectation, just like the unfused Sequence would do.
+func fusedLiterals[Output any](parsers []gomme.Parser[Output], literals []string) gomme.Parser[[]Output] {
+	run, ok := newLiteralRun(parsers, literals)
+	if !ok {
+		return Sequence(parsers...)
+	}
+	token, outputs := run.token, run.outputs
+
+	fusedParse := func(state gomme.State) (gomme.State, []Output, *gomme.ParserError) {
+		input := state.CurrentString()
+		if strings.HasPrefix(input, token) {
+			return state.MoveBy(len(token)), slices.Clone(outputs), nil
+		}
+
+		offset := 0
+		for i, lit := range literals {
+			if !strings.HasPrefix(input[offset:], lit) {
+				errState := state.MoveBy(offset).NewError(parsers[i].Expected())
+				return state.Preserve(errState), nil, errState.CurrentError()
+			}
+			offset += len(lit)
+		}
+		errState := state.NewError(strconv.Quote(token)) // not reached
+		return errState, nil, errState.CurrentError()
+	}
+
+	p := gomme.NewParser[[]Output]("Sequence", fusedParse, IndexOf(token))
+	return literalParser(p, token)
 }
 
 type sequenceData[Output any] struct {
-	id                uint64
+	id                *gomme.ParserID
 	parsers           []gomme.Parser[Output]
 	saveSpotRecoverer gomme.CombiningRecoverer
 	subRecoverers     []gomme.Recoverer
+	runs              []*literalRun[Output] // literal runs by index of their first parser (nil: none)
 }
 
 func (seq *sequenceData[Output]) any(
@@ -91,22 +202,31 @@ func (seq *sequenceData[Output]) happy( // normal parsing (forward)
 ) (gomme.State, []Output) {
 	if startIdx <= 0 { // caching only works if parsing from the start
 		// use cache to know result immediately (Failed, Error, Consumed, Output)
-		result, ok := state.CachedParserResult(seq.id)
+		result, ok := state.CachedParserResult(seq.id.In(state))
 		if ok {
 			if result.Failed {
 				return state.ErrorAgain(result.Error), nil
 			}
-			return state.MoveBy(result.Consumed), result.Output.([]Output)
+			return state.SucceedAgain(result), result.Output.([]Output)
 		}
 	}
 
 	// cache miss: parse
 	for i := startIdx; i < len(seq.parsers); i++ {
+		if seq.runs != nil && seq.runs[i] != nil && strings.HasPrefix(remaining.CurrentString(), seq.runs[i].token) {
+			run := seq.runs[i]
+			for j, output := range run.outputs {
+				outputs = saveOutput(outputs, output, i+j)
+			}
+			remaining = remaining.MoveBy(len(run.token))
+			i += len(run.outputs) - 1
+			continue
+		} // a failing run is parsed literal by literal for the exact error
 		parse := seq.parsers[i]
 		newState, output := parse.It(remaining)
 		if newState.Failed() {
-			state.CacheParserResult(seq.id, i, saveSpotIdx, saveSpotStart, newState, outputs)
-			state = gomme.IWitnessed(remaining, seq.id, i, newState)
+			state.CacheParserResult(seq.id.In(state), i, saveSpotIdx, saveSpotStart, newState, outputs)
+			state = gomme.IWitnessed(remaining, seq.id.In(state), i, newState)
 			if saveSpotStart < 0 { // we can't do anything here
 				return state, nil
 			}
@@ -121,7 +241,7 @@ func (seq *sequenceData[Output]) happy( // normal parsing (forward)
 		remaining = newState
 	}
 
-	state.CacheParserResult(seq.id, len(seq.parsers)-1, saveSpotIdx, saveSpotStart, remaining, outputs)
+	state.CacheParserResult(seq.id.In(state), len(seq.parsers)-1, saveSpotIdx, saveSpotStart, remaining, outputs)
 	return remaining, outputs
 }
 
@@ -131,7 +251,7 @@ func (seq *sequenceData[Output]) error(
 	outputs []Output,
 ) (gomme.State, []Output) {
 	// use cache to know result immediately (HasSaveSpot, SaveSpotIdx, SaveSpotStart)
-	result, ok := state.CachedParserResult(seq.id)
+	result, ok := state.CachedParserResult(seq.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `Sequence(error)` parser",
@@ -161,7 +281,7 @@ func (seq *sequenceData[Output]) handle( // find error again (forward)
 	outputs []Output,
 ) (gomme.State, []Output) {
 	// use cache to know result immediately (Failed, Idx, ErrorStart)
-	result, ok := state.CachedParserResult(seq.id)
+	result, ok := state.CachedParserResult(seq.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `Sequence(handle)` parser",
@@ -171,7 +291,7 @@ func (seq *sequenceData[Output]) handle( // find error again (forward)
 	if result.Failed { // we should be able to switch to mode=happy (or escape)
 		outputs = result.Output.([]Output)
 		newState, output := gomme.HandleWitness(
-			state.MoveBy(result.ErrorStart), seq.id, result.Idx, seq.parsers...,
+			state.MoveBy(result.ErrorStart), seq.id.In(state), result.Idx, seq.parsers...,
 		)
 		outputs = saveOutput(outputs, output, result.Idx)
 		return seq.any(
@@ -192,7 +312,7 @@ func (seq *sequenceData[Output]) rewind(
 	outputs []Output,
 ) (gomme.State, []Output) {
 	// use cache to know result immediately (Failed, Idx, ErrorStart)
-	result, ok := state.CachedParserResult(seq.id)
+	result, ok := state.CachedParserResult(seq.id.In(state))
 	if !ok {
 		return state.NewSemanticError(
 			"grammar error: cache was empty in `Sequence(rewind)` parser",
@@ -202,7 +322,7 @@ func (seq *sequenceData[Output]) rewind(
 	if result.Failed { // we should be able to switch to mode=happy (or escape)
 		outputs = result.Output.([]Output)
 		newState, output := gomme.HandleWitness(
-			state.MoveBy(result.ErrorStart), seq.id, result.Idx, seq.parsers...,
+			state.MoveBy(result.ErrorStart), seq.id.In(state), result.Idx, seq.parsers...,
 		)
 		outputs = saveOutput(outputs, output, result.Idx)
 		return seq.any(
@@ -60,6 +60,16 @@ func TestSequence(t *testing.T) {
 			wantOutput:    []string{"1", "a", ""},
 			wantRemaining: "",
 		},
+		{
+			name:  "long sequence should succeed",
+			input: "1a2b3c4",
+			args: args{
+				parser: Sequence(Digit1(), Alpha1(), Digit1(), Alpha1(), Digit1(), Alpha1(), Digit1()),
+			},
+			wantErr:       false,
+			wantOutput:    []string{"1", "a", "2", "b", "3", "c", "4"},
+			wantRemaining: "",
+		},
 		{
 			name:  "empty input should fail",
 			input: "",
@@ -105,3 +115,142 @@ func BenchmarkSequence(b *testing.B) {
 		_, _ = parser.It(input)
 	}
 }
+
+func TestOptimizeSequence(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[[]string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    []string
+		wantRemaining string
+	}{
+		{
+			name:  "fused literals should succeed",
+			input: "<!-- x",
+			args: args{
+				parser: gomme.Optimize(Sequence(String("<"), String("!--"), String(" "))),
+			},
+			wantErr:       false,
+			wantOutput:    []string{"<", "!--", " "},
+			wantRemaining: "x",
+		},
+		{
+			name:  "fused literals in the middle should fail",
+			input: "<!x",
+			args: args{
+				parser: gomme.Optimize(Sequence(String("<"), String("!--"), String(" "))),
+			},
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: "<!x",
+		},
+		{
+			name:  "fused literals at end should fail",
+			input: "<!--",
+			args: args{
+				parser: gomme.Optimize(Sequence(String("<"), String("!--"), String(" "))),
+			},
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: "<!--",
+		},
+		{
+			name:  "literal run before non-literal should succeed",
+			input: "<!--12x",
+			args: args{
+				parser: gomme.Optimize(Sequence(String("<"), String("!--"), Digit1())),
+			},
+			wantErr:       false,
+			wantOutput:    []string{"<", "!--", "12"},
+			wantRemaining: "x",
+		},
+		{
+			name:  "literal run after non-literal should succeed",
+			input: "12-->x",
+			args: args{
+				parser: gomme.Optimize(Sequence(Digit1(), String("--"), String(">"))),
+			},
+			wantErr:       false,
+			wantOutput:    []string{"12", "--", ">"},
+			wantRemaining: "x",
+		},
+		{
+			name:  "failing literal run should fail",
+			input: "<!-12x",
+			args: args{
+				parser: gomme.Optimize(Sequence(String("<"), String("!--"), Digit1())),
+			},
+			wantErr:       true,
+			wantOutput:    nil,
+			wantRemaining: "<!-12x",
+		},
+		{
+			name:  "sequence with non-literal should stay unfused",
+			input: "x12y",
+			args: args{
+				parser: gomme.Optimize(Sequence(String("x"), Digit1())),
+			},
+			wantErr:       false,
+			wantOutput:    []string{"x", "12"},
+			wantRemaining: "y",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			// testify makes it easier comparing slices
+			assert.Equal(t,
+				tc.wantOutput, gotResult,
+				"got output %v, want output %v", gotResult, tc.wantOutput,
+			)
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkOptimizeSequence(b *testing.B) {
+	parser := gomme.Optimize(Sequence(String("<"), String("!--"), String(" ")))
+	input := gomme.NewFromString(1, nil, -1, "<!-- x")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestOptimizeNestedSequences(t *testing.T) {
+	t.Parallel()
+
+	parser := gomme.Optimize(Sequence(
+		Sequence(String("a"), String("b")),
+		Sequence(String("c")),
+	))
+	lit, ok := gomme.LiteralOf(parser)
+	if !ok || lit != "abc" {
+		t.Errorf("got literal %q (ok: %t), want literal %q", lit, ok, "abc")
+	}
+
+	newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, "abcd"), parser)
+	if newState.HasError() {
+		t.Errorf("got unexpected error %v", newState.Errors())
+	}
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, gotResult)
+}
@@ -0,0 +1,200 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"strings"
+)
+
+// ShellSegmentKind is the kind of a segment of a shell word.
+type ShellSegmentKind uint8
+
+const (
+	ShellLiteral  ShellSegmentKind = iota // literal text with quotes and escapes removed
+	ShellVariable                         // variable reference ($NAME or ${NAME}); the text is the name
+)
+
+// ShellSegment is a piece of a shell word.
+type ShellSegment struct {
+	Kind ShellSegmentKind
+	Text string
+}
+
+// ShellWord is a word of a command line split into literal text and
+// variable references.
+type ShellWord []ShellSegment
+
+// Expand returns the word with all variables replaced by the result of
+// `lookup` (e.g. os.Getenv).
+func (word ShellWord) Expand(lookup func(name string) string) string {
+	sb := strings.Builder{}
+	for _, seg := range word {
+		if seg.Kind == ShellVariable {
+			sb.WriteString(lookup(seg.Text))
+		} else {
+			sb.WriteString(seg.Text)
+		}
+	}
+	return sb.String()
+}
+
+// ShellWords splits a command line into words like a POSIX shell does
+// (e.g. `cp "my file" '$1 b' \$HOME $DIR/x`).
+// Words are separated by spaces and tabs.
+// Single quotes keep everything literally. Double quotes keep everything
+// literally except variables and the escapes \$, \`, \", \\ and
+// \<newline>. Outside of quotes a backslash escapes any character and
+// \<newline> continues the line.
+// Variables ($NAME or ${NAME}) are returned as segments of their own and
+// not expanded (see ShellWord.Expand). A '$' that isn't followed by a
+// name is literal.
+// There is no field splitting, globbing or command substitution.
+// It stops at the end of the line without consuming the line break.
+// So it can be used with Lines.
+func ShellWords() gomme.Parser[[]ShellWord] {
+	expected := "shell words"
+
+	wordsParse := func(state gomme.State) (gomme.State, []ShellWord, *gomme.ParserError) {
+		words, n, errMsg, errPos := scanShellWords(state.CurrentString())
+		if errMsg != "" {
+			errState := state.MoveBy(errPos).NewError(errMsg)
+			return state.Preserve(errState), nil, errState.CurrentError()
+		}
+		return state.MoveBy(n), words, nil
+	}
+	return gomme.NewParser[[]ShellWord](expected, wordsParse, Forbidden("ShellWords"))
+}
+
+// scanShellWords scans the words of the line at the start of the input.
+// It returns the words and their length or the expectation and the
+// position of an error.
+func scanShellWords(input string) (words []ShellWord, n int, errMsg string, errPos int) {
+	var word ShellWord
+	inWord := false // also true for empty quotes
+	literal := strings.Builder{}
+	flush := func() {
+		if literal.Len() > 0 {
+			word = append(word, ShellSegment{Kind: ShellLiteral, Text: literal.String()})
+			literal.Reset()
+		}
+	}
+	endWord := func() {
+		flush()
+		if inWord {
+			if word == nil {
+				word = ShellWord{{Kind: ShellLiteral}}
+			}
+			words = append(words, word)
+		}
+		word, inWord = nil, false
+	}
+
+	quote := byte(0)
+	for n < len(input) {
+		c := input[n]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				literal.WriteByte(c)
+			}
+			n++
+		case c == '\\':
+			if n+1 >= len(input) {
+				return nil, 0, "escaped character", n + 1
+			}
+			e := input[n+1]
+			switch {
+			case e == '\n': // line continuation
+				n += 2
+			case quote == 0 || strings.IndexByte("$`\"\\", e) >= 0:
+				literal.WriteByte(e)
+				inWord = true
+				n += 2
+			default: // the backslash is literal in double quotes
+				literal.WriteByte(c)
+				n++
+			}
+		case c == '$':
+			name, m, msg := shellVariable(input[n:])
+			if msg != "" {
+				return nil, 0, msg, n + m
+			}
+			if m == 0 {
+				literal.WriteByte(c)
+				m = 1
+			} else {
+				flush()
+				word = append(word, ShellSegment{Kind: ShellVariable, Text: name})
+			}
+			inWord = true
+			n += m
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				literal.WriteByte(c)
+			}
+			n++
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+			n++
+		case c == ' ' || c == '\t':
+			endWord()
+			n++
+		case c == '\n' || c == '\r':
+			endWord()
+			return words, n, "", 0
+		default:
+			literal.WriteByte(c)
+			inWord = true
+			n++
+		}
+	}
+	if quote != 0 {
+		return nil, 0, "closing quote", n
+	}
+	endWord()
+	return words, n, "", 0
+}
+
+// shellVariable returns the name of the variable reference at the start
+// of the input (starting with '$') and its length.
+// The length is 0 if the '$' isn't followed by a name.
+// For a malformed ${...} the expectation and the position of the error
+// are returned instead.
+func shellVariable(input string) (name string, n int, errMsg string) {
+	if len(input) < 2 {
+		return "", 0, ""
+	}
+	if input[1] != '{' {
+		m := shellNameLen(input[1:])
+		if m == 0 {
+			return "", 0, ""
+		}
+		return input[1 : 1+m], 1 + m, ""
+	}
+	m := shellNameLen(input[2:])
+	switch {
+	case m == 0:
+		return "", 2, "variable name"
+	case 2+m >= len(input) || input[2+m] != '}':
+		return "", 2 + m, "closing brace"
+	}
+	return input[2 : 2+m], 3 + m, ""
+}
+
+// shellNameLen returns the length of the variable name (letters, digits
+// and underscores not starting with a digit) at the start of the input.
+func shellNameLen(input string) int {
+	n := 0
+	for n < len(input) {
+		c := input[n]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || n > 0 && isDigit(c)) {
+			break
+		}
+		n++
+	}
+	return n
+}
@@ -0,0 +1,90 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShellWords(t *testing.T) {
+	t.Parallel()
+
+	lit := func(text string) ShellSegment { return ShellSegment{Kind: ShellLiteral, Text: text} }
+	variable := func(name string) ShellSegment { return ShellSegment{Kind: ShellVariable, Text: name} }
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       string
+		wantOutput    []ShellWord
+		wantRemaining string
+	}{
+		{
+			name:          "splitting quoted and escaped words should succeed",
+			input:         `cp "my file" '$1 b' \$HOME` + "\nnext",
+			wantOutput:    []ShellWord{{lit("cp")}, {lit("my file")}, {lit("$1 b")}, {lit("$HOME")}},
+			wantRemaining: "\nnext",
+		}, {
+			name:       "variables should be segments",
+			input:      `"x $A y" ${B}c $DIR/x`,
+			wantOutput: []ShellWord{{lit("x "), variable("A"), lit(" y")}, {variable("B"), lit("c")}, {variable("DIR"), lit("/x")}},
+		}, {
+			name:       "empty quotes should be a word",
+			input:      ` a ""  b `,
+			wantOutput: []ShellWord{{lit("a")}, {lit("")}, {lit("b")}},
+		}, {
+			name:       "dollar without name should be literal",
+			input:      `$ a$ "\q\$"`,
+			wantOutput: []ShellWord{{lit("$")}, {lit("a$")}, {lit(`\q$`)}},
+		}, {
+			name:       "escaped line break should continue the line",
+			input:      "a\\\nb c",
+			wantOutput: []ShellWord{{lit("ab")}, {lit("c")}},
+		}, {
+			name:          "unterminated quote should fail",
+			input:         `echo "open`,
+			wantErr:       "expected closing quote [1:11]",
+			wantRemaining: `echo "open`,
+		}, {
+			name:          "unterminated variable should fail",
+			input:         `echo ${A`,
+			wantErr:       "expected closing brace [1:9]",
+			wantRemaining: `echo ${A`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), ShellWords())
+			if newState.HasError() != (tc.wantErr != "") {
+				t.Errorf("got error %v, want error %q", newState.Errors(), tc.wantErr)
+			}
+			if tc.wantErr != "" {
+				if msg := newState.Errors().Error(); !strings.Contains(msg, tc.wantErr) {
+					t.Errorf("got error %q, want error %q", msg, tc.wantErr)
+				}
+			}
+			if !reflect.DeepEqual(gotResult, tc.wantOutput) {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestShellWordExpand(t *testing.T) {
+	t.Parallel()
+
+	word := ShellWord{{Kind: ShellVariable, Text: "HOME"}, {Kind: ShellLiteral, Text: "/bin"}}
+	got := word.Expand(func(name string) string { return "/home/" + strings.ToLower(name) })
+	if got != "/home/home/bin" {
+		t.Errorf("got %q, want %q", got, "/home/home/bin")
+	}
+}
@@ -0,0 +1,229 @@
+package pcb
+
+import (
+	"strings"
+
+	"github.com/oleiade/gomme"
+)
+
+// Segment is a part of an interpolated string.
+// It is either literal text or an embedded expression.
+type Segment[Expr any] struct {
+	Text   string // the literal text (empty for expressions)
+	Expr   Expr   // the embedded expression (zero value for text)
+	IsExpr bool   // true for embedded expressions
+}
+
+// Interpolated parses a string body with literal text and embedded
+// expressions (like `${...}`) alternating.
+// The text parser parses literal text until the next open delimiter or the
+// end of the body. It may accept empty input.
+// Every expression is enclosed by the open and close delimiters.
+// The output contains the non-empty text segments and all expressions in order.
+//
+// Parsing stops at the first position where neither text nor an open
+// delimiter can be found. That is usually the end of the string body
+// (e.g. a closing quote).
+// Interpolated fails if an expression or its close delimiter fails.
+func Interpolated[Expr, OO, OC any](
+	text gomme.Parser[string], open gomme.Parser[OO], expr gomme.Parser[Expr], close gomme.Parser[OC],
+) gomme.Parser[[]Segment[Expr]] {
+	expected := "text with embedded " + expr.Expected()
+
+	interParse := func(state gomme.State) (gomme.State, []Segment[Expr], *gomme.ParserError) {
+		var segments []Segment[Expr]
+		remaining := state
+		for {
+			textState, txt, err := text.It(remaining)
+			if err != nil && remaining.SaveSpotMoved(textState) {
+				return state.Preserve(textState), nil, err
+			}
+			if err == nil && txt != "" {
+				segments = append(segments, Segment[Expr]{Text: txt})
+				remaining = textState
+			}
+
+			openState, _, oErr := open.It(remaining)
+			if oErr != nil {
+				if remaining.SaveSpotMoved(openState) {
+					return state.Preserve(openState), nil, oErr
+				}
+				return remaining.Succeed(openState), segments, nil
+			}
+			exprState, output, eErr := expr.It(openState)
+			if eErr != nil {
+				return state.Preserve(exprState), nil, eErr
+			}
+			closeState, _, cErr := close.It(exprState)
+			if cErr != nil {
+				return state.Preserve(closeState), nil, cErr
+			}
+			segments = append(segments, Segment[Expr]{Expr: output, IsExpr: true})
+			remaining = closeState
+		}
+	}
+	return gomme.NewParser[[]Segment[Expr]](expected, interParse, Forbidden("Interpolated"))
+}
+
+// TemplateDelims are the delimiters of a `{{ ... }}`-style template language.
+// A trim marker `-` directly after the open delimiter (`{{- `) trims the
+// whitespace before the action. A trim marker directly before the close
+// delimiter (` -}}`) trims the whitespace after it.
+// Just like in Go templates the trim markers have to be separated from the
+// content by whitespace.
+type TemplateDelims struct {
+	Open         string // opens an action, e.g. `{{`
+	Close        string // closes an action, e.g. `}}`
+	CommentOpen  string // opens a comment inside of an action, e.g. `/*`
+	CommentClose string // closes a comment inside of an action, e.g. `*/`
+}
+
+// GoTemplateDelims returns the delimiters of Go templates.
+func GoTemplateDelims() TemplateDelims {
+	return TemplateDelims{Open: "{{", Close: "}}", CommentOpen: "/*", CommentClose: "*/"}
+}
+
+// TemplateAction is the output of the Action parser.
+type TemplateAction[Output any] struct {
+	Body      Output
+	TrimLeft  bool // the action started with a trim marker
+	TrimRight bool // the action ended with a trim marker
+}
+
+// RawText parses the literal text of a template until the next open
+// delimiter or the end of the input.
+// If the next action starts with a trim marker, the trailing whitespace
+// is removed from the output.
+// RawText never fails but may return empty text.
+func RawText(delims TemplateDelims) gomme.Parser[string] {
+	rawParse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		input := state.CurrentString()
+		i := strings.Index(input, delims.Open)
+		if i < 0 {
+			i = len(input)
+		}
+		text := input[:i]
+		if _, trim := delims.openTrim(input[i:]); trim {
+			text = strings.TrimRight(text, templateSpace)
+		}
+		return state.MoveBy(i), text, nil
+	}
+	return gomme.NewParser[string]("raw text", rawParse, Forbidden("RawText"))
+}
+
+// Action parses an action block with the body parser.
+// Whitespace around the body and trim markers are handled by Action.
+// If the action ends with a trim marker, the whitespace after it is
+// consumed, too.
+func Action[Output any](delims TemplateDelims, body gomme.Parser[Output]) gomme.Parser[TemplateAction[Output]] {
+	expected := delims.Open + " " + body.Expected() + " " + delims.Close
+
+	actParse := func(state gomme.State) (gomme.State, TemplateAction[Output], *gomme.ParserError) {
+		action := TemplateAction[Output]{}
+		input := state.CurrentString()
+		if !strings.HasPrefix(input, delims.Open) {
+			errState := state.NewError(expected)
+			return errState, action, errState.CurrentError()
+		}
+		n, trim := delims.openTrim(input)
+		action.TrimLeft = trim
+
+		bodyState, output, err := body.It(state.MoveBy(n + spaceLen(input[n:])))
+		if err != nil {
+			return state.Preserve(bodyState), action, err
+		}
+		n, trim, ok := delims.closeTrim(bodyState.CurrentString())
+		if !ok {
+			errState := bodyState.MoveBy(spaceLen(bodyState.CurrentString())).NewError(delims.Close)
+			return state.Preserve(errState), action, errState.CurrentError()
+		}
+		action.Body = output
+		action.TrimRight = trim
+		return bodyState.MoveBy(n), action, nil
+	}
+	return gomme.NewParser[TemplateAction[Output]](expected, actParse, TemplateRecoverer(delims))
+}
+
+// Comment parses a comment block like `{{/* comment */}}` and returns the
+// text of the comment.
+// Trim markers are handled just like by Action.
+func Comment(delims TemplateDelims) gomme.Parser[string] {
+	expected := delims.Open + delims.CommentOpen + " comment " + delims.CommentClose + delims.Close
+
+	commentParse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		input := state.CurrentString()
+		if !strings.HasPrefix(input, delims.Open) {
+			errState := state.NewError(expected)
+			return errState, "", errState.CurrentError()
+		}
+		n, _ := delims.openTrim(input)
+		n += spaceLen(input[n:])
+		if !strings.HasPrefix(input[n:], delims.CommentOpen) {
+			errState := state.NewError(expected)
+			return errState, "", errState.CurrentError()
+		}
+		n += len(delims.CommentOpen)
+		end := strings.Index(input[n:], delims.CommentClose)
+		if end < 0 {
+			errState := state.MoveBy(len(input)).NewError(delims.CommentClose)
+			return state.Preserve(errState), "", errState.CurrentError()
+		}
+		text := input[n : n+end]
+		afterState := state.MoveBy(n + end + len(delims.CommentClose))
+		m, _, ok := delims.closeTrim(afterState.CurrentString())
+		if !ok {
+			errState := afterState.MoveBy(spaceLen(afterState.CurrentString())).NewError(delims.Close)
+			return state.Preserve(errState), "", errState.CurrentError()
+		}
+		return afterState.MoveBy(m), text, nil
+	}
+	return gomme.NewParser[string](expected, commentParse, TemplateRecoverer(delims))
+}
+
+// TemplateRecoverer resynchronizes at the next open delimiter after the
+// current position. So a broken action is skipped as a whole.
+func TemplateRecoverer(delims TemplateDelims) gomme.Recoverer {
+	return func(state gomme.State) int {
+		input := state.CurrentString()
+		if input == "" {
+			return -1
+		}
+		i := strings.Index(input[1:], delims.Open)
+		if i < 0 {
+			return -1
+		}
+		return i + 1
+	}
+}
+
+const templateSpace = " \t\r\n"
+
+func spaceLen(input string) int {
+	return len(input) - len(strings.TrimLeft(input, templateSpace))
+}
+
+// openTrim returns the length of the open delimiter including a trim marker.
+func (delims TemplateDelims) openTrim(input string) (n int, trim bool) {
+	n = len(delims.Open)
+	if !strings.HasPrefix(input, delims.Open) {
+		return 0, false
+	}
+	if len(input) > n+1 && input[n] == '-' && strings.IndexByte(templateSpace, input[n+1]) >= 0 {
+		return n + 1, true
+	}
+	return n, false
+}
+
+// closeTrim returns the length of the whitespace and close delimiter at the
+// start of the input including a trim marker and the whitespace after it.
+func (delims TemplateDelims) closeTrim(input string) (n int, trim, ok bool) {
+	n = spaceLen(input)
+	if n > 0 && strings.HasPrefix(input[n:], "-"+delims.Close) {
+		n += 1 + len(delims.Close)
+		return n + spaceLen(input[n:]), true, true
+	}
+	if strings.HasPrefix(input[n:], delims.Close) {
+		return n + len(delims.Close), false, true
+	}
+	return 0, false, false
+}
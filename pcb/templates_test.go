@@ -0,0 +1,118 @@
+package pcb
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/oleiade/gomme"
+)
+
+func TestInterpolated(t *testing.T) {
+	t.Parallel()
+
+	text := SatisfyMN("text", 0, math.MaxInt, func(r rune) bool { return r != '$' && r != '"' })
+	parser := Interpolated(text, String("${"), Alpha1(), Char('}'))
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       bool
+		wantOutput    []Segment[string]
+		wantRemaining string
+	}{
+		{
+			name:  "text and expressions should succeed",
+			input: `a ${b}c"`,
+			wantOutput: []Segment[string]{
+				{Text: "a "}, {Expr: "b", IsExpr: true}, {Text: "c"},
+			},
+			wantRemaining: `"`,
+		},
+		{
+			name:  "adjacent expressions should succeed",
+			input: "${x}${y}",
+			wantOutput: []Segment[string]{
+				{Expr: "x", IsExpr: true}, {Expr: "y", IsExpr: true},
+			},
+			wantRemaining: "",
+		},
+		{
+			name:          "empty input should succeed",
+			input:         "",
+			wantOutput:    nil,
+			wantRemaining: "",
+		},
+		{
+			name:    "invalid expression should fail",
+			input:   "a${1}",
+			wantErr: true,
+		},
+		{
+			name:    "missing close delimiter should fail",
+			input:   "a${b",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", newState.Errors(), tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(gotResult, tc.wantOutput) {
+				t.Errorf("got output %#v, want output %#v", gotResult, tc.wantOutput)
+			}
+			if remaining := newState.CurrentString(); remaining != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remaining, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	t.Parallel()
+
+	delims := GoTemplateDelims()
+	action := Action(delims, Alpha1())
+
+	newState, text := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, "Hello  {{- name}}"), RawText(delims))
+	if newState.HasError() || text != "Hello" || newState.CurrentString() != "{{- name}}" {
+		t.Errorf("got (%q, %q, %v), want raw text with trimmed space", text, newState.CurrentString(), newState.Errors())
+	}
+
+	newState, act := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, "{{- name -}}  !"), action)
+	want := TemplateAction[string]{Body: "name", TrimLeft: true, TrimRight: true}
+	if newState.HasError() || act != want || newState.CurrentString() != "!" {
+		t.Errorf("got (%+v, %q, %v), want (%+v, %q)", act, newState.CurrentString(), newState.Errors(), want, "!")
+	}
+
+	newState, act = gomme.RunOnState(gomme.NewFromString(-1, nil, -1, "{{name}}x"), action)
+	want = TemplateAction[string]{Body: "name"}
+	if newState.HasError() || act != want || newState.CurrentString() != "x" {
+		t.Errorf("got (%+v, %q, %v), want (%+v, %q)", act, newState.CurrentString(), newState.Errors(), want, "x")
+	}
+
+	newState, _ = gomme.RunOnState(gomme.NewFromString(-1, nil, -1, "{{name"), action)
+	if !newState.HasError() {
+		t.Errorf("got no error for a missing close delimiter")
+	}
+
+	newState, text = gomme.RunOnState(gomme.NewFromString(-1, nil, -1, "{{/* a comment */}}x"), Comment(delims))
+	if newState.HasError() || text != " a comment " || newState.CurrentString() != "x" {
+		t.Errorf("got (%q, %q, %v), want comment %q", text, newState.CurrentString(), newState.Errors(), " a comment ")
+	}
+
+	recoverer := TemplateRecoverer(delims)
+	if waste := recoverer(gomme.NewFromString(-1, nil, -1, "{{ 1 }} text {{ x }}")); waste != 13 {
+		t.Errorf("got waste %d, want 13", waste)
+	}
+}
@@ -0,0 +1,135 @@
+package pcb
+
+import (
+	"fmt"
+	"github.com/oleiade/gomme"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeMatch is the output of the Timestamp parser.
+type TimeMatch struct {
+	Time   time.Time
+	Layout string // the layout that matched
+}
+
+// locations caches the successful results of time.LoadLocation because
+// it reads the zone database every time.
+// Unknown zones aren't cached, so arbitrary input can't grow the cache.
+var locations sync.Map // zone name -> *time.Location
+
+// Timestamp parses a timestamp in one of the layouts of the time package
+// (e.g. time.RFC3339 or "Jan _2 15:04:05").
+// The layouts are tried in order and the first one that matches wins.
+// The timestamp may end at a space or right before one of ",;)]}",
+// so timestamps inside of lists and records are found, too.
+// It may be followed by a space and a named time zone of the
+// zone database (e.g. "2024-03-01 12:00:00 Europe/Berlin").
+// Only names containing a '/' and "UTC" are considered zone names.
+// Without zone the time is in `loc` (UTC if `loc` is nil) unless the
+// layout contains a zone itself.
+// The zone database has to be available (see time.LoadLocation and
+// the time/tzdata package).
+// This function panics during the construction phase if no layouts are
+// given.
+func Timestamp(loc *time.Location, layouts ...string) gomme.Parser[TimeMatch] {
+	if len(layouts) == 0 {
+		panic("Timestamp needs at least one layout")
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	expected := "timestamp"
+	fieldCounts := make([]int, len(layouts))
+	for i, layout := range layouts {
+		fieldCounts[i] = len(strings.Fields(layout))
+	}
+
+	parse := func(state gomme.State) (gomme.State, TimeMatch, *gomme.ParserError) {
+		input := state.CurrentString()
+		for i, layout := range layouts {
+			lastStart, end := fieldsEnd(input, fieldCounts[i])
+			if end == 0 {
+				continue
+			}
+			// The longest candidate wins, so separators inside of the
+			// last field of the layout still work.
+			for n := end; n > lastStart; n-- {
+				if n < end && !isTimestampEnd(input[n]) {
+					continue
+				}
+				zoneLoc, zoneLen := zoneAt(input[n:])
+				if zoneLen == 0 {
+					zoneLoc = loc
+				}
+				t, err := time.ParseInLocation(layout, input[:n], zoneLoc)
+				if err != nil {
+					continue
+				}
+				return state.MoveBy(n + zoneLen), TimeMatch{Time: t, Layout: layout}, nil
+			}
+		}
+		errState := state.NewError(fmt.Sprintf("%s (%s)", expected, strings.Join(layouts, " | ")))
+		return errState, TimeMatch{}, errState.CurrentError()
+	}
+	return gomme.NewParser[TimeMatch](expected, parse, BasicRecovererFunc(parse))
+}
+
+// fieldsEnd returns the start of the last and the end of the first
+// `count` space separated fields of the input or 0, 0 if the input has
+// less fields.
+// Spaces between the fields are skipped like strings.Fields does.
+func fieldsEnd(input string, count int) (int, int) {
+	n, start := 0, 0
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			n += len(input[n:]) - len(strings.TrimLeft(input[n:], " \t"))
+		}
+		start = n
+		for n < len(input) && input[n] != ' ' && input[n] != '\t' && input[n] != '\n' && input[n] != '\r' {
+			n++
+		}
+		if n == start {
+			return 0, 0
+		}
+	}
+	return start, n
+}
+
+// isTimestampEnd reports whether a timestamp can end right before the byte
+// without a space.
+func isTimestampEnd(c byte) bool {
+	return strings.IndexByte(",;)]}", c) >= 0
+}
+
+// zoneAt returns the location of the zone name after a single space at
+// the start of the input and the number of bytes used by both.
+func zoneAt(input string) (*time.Location, int) {
+	if len(input) < 2 || input[0] != ' ' {
+		return nil, 0
+	}
+	n := 1
+	for n < len(input) && isZoneChar(input[n]) {
+		n++
+	}
+	name := input[1:n]
+	if name != "UTC" && !strings.Contains(name, "/") {
+		return nil, 0
+	}
+	if cached, ok := locations.Load(name); ok {
+		return cached.(*time.Location), n
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, 0
+	}
+	locations.Store(name, loc)
+	return loc, n
+}
+
+// isZoneChar reports whether the byte can be part of a zone name like
+// "America/Argentina/Buenos_Aires" or "Etc/GMT+5".
+func isZoneChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c) || strings.IndexByte("/_-+", c) >= 0
+}
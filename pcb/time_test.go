@@ -0,0 +1,105 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"testing"
+	"time"
+	_ "time/tzdata" // the tests shouldn't depend on the zone database of the system
+)
+
+func TestTimestamp(t *testing.T) {
+	t.Parallel()
+
+	const isoSpace = "2006-01-02 15:04:05"
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	parser := Timestamp(nil, time.RFC3339, isoSpace, time.Stamp)
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       bool
+		wantTime      time.Time
+		wantLayout    string
+		wantRemaining string
+	}{
+		{
+			name:          "parsing RFC3339 should succeed",
+			input:         "2024-03-01T12:00:00+02:00 msg",
+			wantTime:      time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			wantLayout:    time.RFC3339,
+			wantRemaining: " msg",
+		}, {
+			name:          "parsing named zone should succeed",
+			input:         "2024-03-01 12:00:00 Europe/Berlin: msg",
+			wantTime:      time.Date(2024, 3, 1, 12, 0, 0, 0, berlin),
+			wantLayout:    isoSpace,
+			wantRemaining: ": msg",
+		}, {
+			name:          "unknown zone should be left over",
+			input:         "2024-03-01 12:00:00 Nowhere/Land",
+			wantTime:      time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+			wantLayout:    isoSpace,
+			wantRemaining: " Nowhere/Land",
+		}, {
+			name:          "timestamp before a comma should succeed",
+			input:         "2024-03-01T12:00:00Z, next",
+			wantTime:      time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+			wantLayout:    time.RFC3339,
+			wantRemaining: ", next",
+		}, {
+			name:          "timestamp before a bracket should succeed",
+			input:         "2024-03-01 12:00:00]",
+			wantTime:      time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+			wantLayout:    isoSpace,
+			wantRemaining: "]",
+		}, {
+			name:          "parsing padded syslog stamp should succeed",
+			input:         "Mar  1 12:00:00 host",
+			wantTime:      time.Date(0, 3, 1, 12, 0, 0, 0, time.UTC),
+			wantLayout:    time.Stamp,
+			wantRemaining: " host",
+		}, {
+			name:          "parsing no timestamp should fail",
+			input:         "yesterday",
+			wantErr:       true,
+			wantRemaining: "yesterday",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(tc.input, false), parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+			if !gotResult.Time.Equal(tc.wantTime) || gotResult.Layout != tc.wantLayout {
+				t.Errorf("got output (%v, %q), want output (%v, %q)",
+					gotResult.Time, gotResult.Layout, tc.wantTime, tc.wantLayout)
+			}
+			if remainingString := newState.CurrentString(); remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestTimestampCachesKnownZonesOnly(t *testing.T) {
+	t.Parallel()
+
+	parser := Timestamp(nil, "2006-01-02 15:04:05")
+	gomme.RunOnState(gomme.NewFromString("2024-03-01 12:00:00 Nowhere/Cached", false), parser)
+	if _, ok := locations.Load("Nowhere/Cached"); ok {
+		t.Errorf("got unknown zone cached, want it not cached")
+	}
+	gomme.RunOnState(gomme.NewFromString("2024-03-01 12:00:00 Asia/Tokyo", false), parser)
+	if _, ok := locations.Load("Asia/Tokyo"); !ok {
+		t.Errorf("got known zone not cached, want it cached")
+	}
+}
@@ -0,0 +1,232 @@
+package pcb
+
+import (
+	"fmt"
+	"github.com/oleiade/gomme"
+	"strings"
+)
+
+// URIAuthority is the output of the Authority parser.
+// All parts are returned as found in the input (still percent-encoded).
+type URIAuthority struct {
+	UserInfo string
+	Host     string
+	Port     string
+}
+
+// Scheme parses the scheme of a URI as defined in RFC 3986 section 3.1:
+//
+//	scheme = ALPHA *( ALPHA / DIGIT / "+" / "-" / "." )
+//
+// The trailing ':' isn't part of the scheme and isn't consumed.
+func Scheme() gomme.Parser[string] {
+	expected := "URI scheme"
+
+	parse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		input := state.CurrentString()
+		if input == "" || !isURIAlpha(input[0]) {
+			errState := state.NewError(expected)
+			return errState, "", errState.CurrentError()
+		}
+		i := 1
+		for i < len(input) && (isURIAlpha(input[i]) || isURIDigit(input[i]) || strings.IndexByte("+-.", input[i]) >= 0) {
+			i++
+		}
+		return state.MoveBy(i), input[:i], nil
+	}
+
+	return gomme.NewParser[string](expected, parse, BasicRecovererFunc(parse))
+}
+
+// Authority parses the authority of a URI as defined in RFC 3986 section 3.2:
+//
+//	authority = [ userinfo "@" ] host [ ":" port ]
+//
+// The leading "//" isn't part of the authority and has to be parsed separately.
+// The authority ends at the first character that can't be part of it
+// (e.g. '/', '?', '#' or a space) or at the end of the input.
+// IPv6 and future IP literals in square brackets are accepted as host
+// but their content isn't validated beyond the allowed characters.
+func Authority() gomme.Parser[URIAuthority] {
+	expected := "URI authority"
+
+	parse := func(state gomme.State) (gomme.State, URIAuthority, *gomme.ParserError) {
+		input := state.CurrentString()
+		end := 0
+		for end < len(input) && isURIAuthorityChar(input[end]) {
+			end++
+		}
+		input = input[:end]
+		auth := URIAuthority{}
+
+		start := 0
+		if at := strings.LastIndexByte(input, '@'); at >= 0 {
+			if i := scanURIChars(input[:at], isURIUserInfoChar); i < at {
+				return uriError[URIAuthority](state, i, "URI user info", input[i:])
+			}
+			auth.UserInfo = input[:at]
+			start = at + 1
+		}
+
+		hostEnd := start
+		if strings.HasPrefix(input[start:], "[") {
+			closing := strings.IndexByte(input[start:], ']')
+			if closing < 0 {
+				errState := state.MoveBy(len(input)).NewError("closing ']' of IP literal")
+				return state.Preserve(errState), URIAuthority{}, errState.CurrentError()
+			}
+			hostEnd = start + closing + 1
+			if i := start + 1 + scanURIChars(input[start+1:hostEnd-1], isURIIPLiteralChar); i < hostEnd-1 {
+				return uriError[URIAuthority](state, i, "URI IP literal", input[i:])
+			}
+		} else {
+			hostEnd = start + scanURIChars(input[start:], isURIRegNameChar)
+		}
+		auth.Host = input[start:hostEnd]
+
+		if hostEnd < len(input) {
+			if input[hostEnd] != ':' {
+				return uriError[URIAuthority](state, hostEnd, "URI host", input[hostEnd:])
+			}
+			i := hostEnd + 1
+			for i < len(input) && isURIDigit(input[i]) {
+				i++
+			}
+			if i < len(input) {
+				return uriError[URIAuthority](state, i, "URI port", input[i:])
+			}
+			auth.Port = input[hostEnd+1:]
+		}
+		return state.MoveBy(end), auth, nil
+	}
+
+	return gomme.NewParser[URIAuthority](expected, parse, BasicRecovererFunc(parse))
+}
+
+// PathAbEmpty parses a path that is either empty or starts with a '/'
+// as defined in RFC 3986 section 3.3:
+//
+//	path-abempty = *( "/" segment )
+//
+// This is the kind of path that follows an authority.
+func PathAbEmpty() gomme.Parser[string] {
+	expected := "URI path"
+
+	parse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		input := state.CurrentString()
+		if input == "" || input[0] != '/' {
+			return state, "", nil
+		}
+		i := scanURIChars(input, func(c byte) bool { return c == '/' || isURIPChar(c) })
+		if i < len(input) && input[i] == '%' {
+			return uriError[string](state, i, expected, input[i:])
+		}
+		return state.MoveBy(i), input[:i], nil
+	}
+
+	return gomme.NewParser[string](expected, parse, BasicRecovererFunc(parse))
+}
+
+// Query parses the query of a URI as defined in RFC 3986 section 3.4:
+//
+//	query = *( pchar / "/" / "?" )
+//
+// The leading '?' isn't part of the query and has to be parsed separately.
+func Query() gomme.Parser[string] {
+	return uriQueryOrFragment("URI query")
+}
+
+// Fragment parses the fragment of a URI as defined in RFC 3986 section 3.5:
+//
+//	fragment = *( pchar / "/" / "?" )
+//
+// The leading '#' isn't part of the fragment and has to be parsed separately.
+func Fragment() gomme.Parser[string] {
+	return uriQueryOrFragment("URI fragment")
+}
+
+func uriQueryOrFragment(expected string) gomme.Parser[string] {
+	parse := func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		input := state.CurrentString()
+		i := scanURIChars(input, func(c byte) bool { return c == '/' || c == '?' || isURIPChar(c) })
+		if i < len(input) && input[i] == '%' {
+			return uriError[string](state, i, expected, input[i:])
+		}
+		return state.MoveBy(i), input[:i], nil
+	}
+
+	return gomme.NewParser[string](expected, parse, BasicRecovererFunc(parse))
+}
+
+// scanURIChars returns the index of the first byte of input that isn't
+// allowed or isn't part of a valid percent-encoding.
+func scanURIChars(input string, allowed func(byte) bool) int {
+	i := 0
+	for i < len(input) {
+		switch {
+		case input[i] == '%':
+			if i+2 >= len(input) || !isURIHexDigit(input[i+1]) || !isURIHexDigit(input[i+2]) {
+				return i
+			}
+			i += 3
+		case allowed(input[i]):
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func uriError[Output any](state gomme.State, i int, expected, rest string) (gomme.State, Output, *gomme.ParserError) {
+	msg := expected
+	if strings.HasPrefix(rest, "%") {
+		msg += " (invalid percent-encoding)"
+	} else {
+		msg += fmt.Sprintf(" (got %q)", rest[0])
+	}
+	errState := state.MoveBy(i).NewError(msg)
+	return state.Preserve(errState), gomme.ZeroOf[Output](), errState.CurrentError()
+}
+
+func isURIAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isURIDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isURIHexDigit(c byte) bool {
+	return isURIDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isURIUnreserved(c byte) bool {
+	return isURIAlpha(c) || isURIDigit(c) || c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isURISubDelim(c byte) bool {
+	return strings.IndexByte("!$&'()*+,;=", c) >= 0
+}
+
+func isURIRegNameChar(c byte) bool {
+	return isURIUnreserved(c) || isURISubDelim(c)
+}
+
+func isURIUserInfoChar(c byte) bool {
+	return isURIRegNameChar(c) || c == ':'
+}
+
+// isURIAuthorityChar returns true for all characters of an authority
+// including the '%' of percent-encodings.
+func isURIAuthorityChar(c byte) bool {
+	return isURIUserInfoChar(c) || c == '@' || c == '[' || c == ']' || c == '%'
+}
+
+func isURIIPLiteralChar(c byte) bool {
+	return isURIUserInfoChar(c)
+}
+
+func isURIPChar(c byte) bool {
+	return isURIUserInfoChar(c) || c == '@'
+}
@@ -0,0 +1,408 @@
+package pcb
+
+import (
+	"github.com/oleiade/gomme"
+	"testing"
+)
+
+func TestScheme(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "parsing scheme should succeed",
+			input: "svn+ssh://host",
+			args: args{
+				parser: Scheme(),
+			},
+			wantErr:       false,
+			wantOutput:    "svn+ssh",
+			wantRemaining: "://host",
+		},
+		{
+			name:  "parsing scheme with digits and dots should succeed",
+			input: "a1.b-c:x",
+			args: args{
+				parser: Scheme(),
+			},
+			wantErr:       false,
+			wantOutput:    "a1.b-c",
+			wantRemaining: ":x",
+		},
+		{
+			name:  "parsing scheme starting with digit should fail",
+			input: "1abc:",
+			args: args{
+				parser: Scheme(),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "1abc:",
+		},
+		{
+			name:  "parsing empty input should fail",
+			input: "",
+			args: args{
+				parser: Scheme(),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkScheme(b *testing.B) {
+	parser := Scheme()
+	input := gomme.NewFromString(1, nil, -1, "https://example.com")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestAuthority(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[URIAuthority]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    URIAuthority
+		wantRemaining string
+	}{
+		{
+			name:  "parsing host only should succeed",
+			input: "example.com/path",
+			args: args{
+				parser: Authority(),
+			},
+			wantErr:       false,
+			wantOutput:    URIAuthority{Host: "example.com"},
+			wantRemaining: "/path",
+		},
+		{
+			name:  "parsing authority followed by text should succeed",
+			input: "host:80 rest",
+			args: args{
+				parser: Authority(),
+			},
+			wantErr:       false,
+			wantOutput:    URIAuthority{Host: "host", Port: "80"},
+			wantRemaining: " rest",
+		},
+		{
+			name:  "parsing full authority should succeed",
+			input: "user:pw@example.com:8080?q",
+			args: args{
+				parser: Authority(),
+			},
+			wantErr:       false,
+			wantOutput:    URIAuthority{UserInfo: "user:pw", Host: "example.com", Port: "8080"},
+			wantRemaining: "?q",
+		},
+		{
+			name:  "parsing IP literal should succeed",
+			input: "[::1]:80#f",
+			args: args{
+				parser: Authority(),
+			},
+			wantErr:       false,
+			wantOutput:    URIAuthority{Host: "[::1]", Port: "80"},
+			wantRemaining: "#f",
+		},
+		{
+			name:  "parsing percent-encoded host should succeed",
+			input: "ex%41mple",
+			args: args{
+				parser: Authority(),
+			},
+			wantErr:       false,
+			wantOutput:    URIAuthority{Host: "ex%41mple"},
+			wantRemaining: "",
+		},
+		{
+			name:  "parsing empty authority should succeed",
+			input: "/path",
+			args: args{
+				parser: Authority(),
+			},
+			wantErr:       false,
+			wantOutput:    URIAuthority{},
+			wantRemaining: "/path",
+		},
+		{
+			name:  "parsing invalid port should fail",
+			input: "example.com:8x/",
+			args: args{
+				parser: Authority(),
+			},
+			wantErr:       true,
+			wantOutput:    URIAuthority{},
+			wantRemaining: "example.com:8x/",
+		},
+		{
+			name:  "parsing invalid percent-encoding should fail",
+			input: "ex%4Gmple/",
+			args: args{
+				parser: Authority(),
+			},
+			wantErr:       true,
+			wantOutput:    URIAuthority{},
+			wantRemaining: "ex%4Gmple/",
+		},
+		{
+			name:  "parsing unclosed IP literal should fail",
+			input: "[::1",
+			args: args{
+				parser: Authority(),
+			},
+			wantErr:       true,
+			wantOutput:    URIAuthority{},
+			wantRemaining: "[::1",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %+v, want output %+v", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkAuthority(b *testing.B) {
+	parser := Authority()
+	input := gomme.NewFromString(1, nil, -1, "user@example.com:8080/path")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestPathAbEmpty(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "parsing path should succeed",
+			input: "/a/b%20c/d:e@f?q",
+			args: args{
+				parser: PathAbEmpty(),
+			},
+			wantErr:       false,
+			wantOutput:    "/a/b%20c/d:e@f",
+			wantRemaining: "?q",
+		},
+		{
+			name:  "parsing empty path should succeed",
+			input: "?q",
+			args: args{
+				parser: PathAbEmpty(),
+			},
+			wantErr:       false,
+			wantOutput:    "",
+			wantRemaining: "?q",
+		},
+		{
+			name:  "parsing relative path should return empty path",
+			input: "a/b",
+			args: args{
+				parser: PathAbEmpty(),
+			},
+			wantErr:       false,
+			wantOutput:    "",
+			wantRemaining: "a/b",
+		},
+		{
+			name:  "parsing invalid percent-encoding should fail",
+			input: "/a%2",
+			args: args{
+				parser: PathAbEmpty(),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "/a%2",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkPathAbEmpty(b *testing.B) {
+	parser := PathAbEmpty()
+	input := gomme.NewFromString(1, nil, -1, "/a/b/c?q")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		parser gomme.Parser[string]
+	}
+	testCases := []struct {
+		name          string
+		args          args
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:  "parsing query should succeed",
+			input: "a=1&b=/x?y#frag",
+			args: args{
+				parser: Query(),
+			},
+			wantErr:       false,
+			wantOutput:    "a=1&b=/x?y",
+			wantRemaining: "#frag",
+		},
+		{
+			name:  "parsing empty query should succeed",
+			input: "",
+			args: args{
+				parser: Query(),
+			},
+			wantErr:       false,
+			wantOutput:    "",
+			wantRemaining: "",
+		},
+		{
+			name:  "parsing invalid percent-encoding should fail",
+			input: "a=%zz",
+			args: args{
+				parser: Query(),
+			},
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "a=%zz",
+		},
+		{
+			name:  "parsing fragment should succeed",
+			input: "top?x",
+			args: args{
+				parser: Fragment(),
+			},
+			wantErr:       false,
+			wantOutput:    "top?x",
+			wantRemaining: "",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult := gomme.RunOnState(gomme.NewFromString(-1, nil, -1, tc.input), tc.args.parser)
+			if newState.HasError() != tc.wantErr {
+				t.Errorf("got error %v, want error %v", newState.Errors(), tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func BenchmarkQuery(b *testing.B) {
+	parser := Query()
+	input := gomme.NewFromString(1, nil, -1, "a=1&b=2#frag")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = gomme.RunOnState(input, parser)
+	}
+}
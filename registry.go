@@ -0,0 +1,170 @@
+package gomme
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ============================================================================
+// Registry Of Named Rules
+//
+
+// Registry holds parsers registered by name.
+// Parsers reference each other with Ref before they are registered.
+// The references are resolved by Build.
+// So large grammars can be split across files and packages without
+// initialization order problems.
+// A Registry is safe for concurrent use.
+type Registry struct {
+	mutex      sync.Mutex
+	rules      map[string]any // the registered Parser[Output] by name
+	duplicates []string
+	refs       []registryRef
+}
+
+// registryRef is a reference to a rule that has to be resolved by Build.
+type registryRef struct {
+	name    string
+	resolve func(rule any) error
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{rules: make(map[string]any)}
+}
+
+// Register registers the parser as rule `name`.
+// Registering the same name twice is reported by Build.
+func Register[Output any](r *Registry, name string, parse Parser[Output]) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.rules[name]; ok {
+		r.duplicates = append(r.duplicates, name)
+		return
+	}
+	r.rules[name] = parse
+}
+
+// Ref returns a parser that refers to the rule `name` of the registry.
+// The rule doesn't have to be registered yet, but it has to be registered
+// with the same output type before Build is called.
+// Using the returned parser before a successful Build panics.
+func Ref[Output any](r *Registry, name string) Parser[Output] {
+	ref := &refprsr[Output]{name: name}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.refs = append(r.refs, registryRef{name: name, resolve: func(rule any) error {
+		parse, ok := rule.(Parser[Output])
+		if !ok {
+			return fmt.Errorf("rule %q is a %T but is referenced as %T", name, rule, Parser[Output](nil))
+		}
+		ref.target = parse
+		return nil
+	}})
+	return ref
+}
+
+// Build resolves all references created with Ref.
+// All problems are reported together: missing rules, rules registered
+// more than once and references with the wrong output type.
+func (r *Registry) Build() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var errs []error
+	for _, name := range r.duplicates {
+		errs = append(errs, fmt.Errorf("rule %q is registered more than once", name))
+	}
+	missing := make(map[string]bool)
+	for _, ref := range r.refs {
+		rule, ok := r.rules[ref.name]
+		if !ok {
+			missing[ref.name] = true
+			continue
+		}
+		if err := ref.resolve(rule); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errs = append(errs, fmt.Errorf("rule %q is referenced but not registered", name))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Lookup returns the registered rule `name`.
+// It fails if the rule doesn't exist or has a different output type.
+func Lookup[Output any](r *Registry, name string) (Parser[Output], error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	rule, ok := r.rules[name]
+	if !ok {
+		return nil, fmt.Errorf("rule %q is not registered", name)
+	}
+	parse, ok := rule.(Parser[Output])
+	if !ok {
+		return nil, fmt.Errorf("rule %q is a %T but is looked up as %T", name, rule, Parser[Output](nil))
+	}
+	return parse, nil
+}
+
+type refprsr[Output any] struct {
+	name   string
+	target Parser[Output]
+}
+
+func (rp *refprsr[Output]) ensureTarget() {
+	if rp.target == nil {
+		panic(fmt.Sprintf("rule %q is used before Registry.Build resolved it", rp.name))
+	}
+}
+
+func (rp *refprsr[Output]) Expected() string {
+	if rp.target == nil {
+		return rp.name
+	}
+	return rp.target.Expected()
+}
+
+func (rp *refprsr[Output]) It(state State) (State, Output, *ParserError) {
+	rp.ensureTarget()
+	return rp.target.It(state)
+}
+
+func (rp *refprsr[Output]) IsSaveSpot() bool {
+	rp.ensureTarget()
+	return rp.target.IsSaveSpot()
+}
+
+func (rp *refprsr[Output]) setSaveSpot() {
+	rp.ensureTarget()
+	rp.target.setSaveSpot()
+}
+
+func (rp *refprsr[Output]) Recover(state State) int {
+	rp.ensureTarget()
+	return rp.target.Recover(state)
+}
+
+func (rp *refprsr[Output]) SwapRecoverer(newRecoverer Recoverer) Parser[Output] {
+	rp.ensureTarget()
+	return rp.target.SwapRecoverer(newRecoverer)
+}
+
+func (rp *refprsr[Output]) WithExpected(expected string) Parser[Output] {
+	return LazyParser(func() Parser[Output] { // the rule might not be resolved yet
+		rp.ensureTarget()
+		return rp.target.WithExpected(expected)
+	})
+}
@@ -0,0 +1,68 @@
+package gomme_test
+
+import (
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	reg := gomme.NewRegistry()
+	// list <- "(" item* ")" ; item <- digits / list
+	gomme.Register(reg, "list", pcb.Map(
+		pcb.Delimited(pcb.Char('('), pcb.Many0(gomme.Ref[int](reg, "item")), pcb.Char(')')),
+		func(items []int) (int, error) {
+			sum := 0
+			for _, item := range items {
+				sum += item
+			}
+			return sum, nil
+		}))
+	gomme.Register(reg, "item", pcb.FirstSuccessful(
+		pcb.Map(pcb.Digit1(), func(digits string) (int, error) { return len(digits), nil }),
+		gomme.Ref[int](reg, "list"),
+	))
+	if err := reg.Build(); err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+
+	list, err := gomme.Lookup[int](reg, "list")
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	output, err := gomme.RunOnString("(12(345)6)", list)
+	if err != nil || output != 6 {
+		t.Errorf("got (%d, %v), want (6, nil)", output, err)
+	}
+}
+
+func TestRegistryErrors(t *testing.T) {
+	t.Parallel()
+
+	reg := gomme.NewRegistry()
+	gomme.Register(reg, "digits", pcb.Digit1())
+	gomme.Register(reg, "digits", pcb.Alpha1())
+	gomme.Ref[string](reg, "missing")
+	gomme.Ref[int](reg, "digits")
+
+	err := reg.Build()
+	if err == nil {
+		t.Fatalf("got no error, want errors")
+	}
+	for _, want := range []string{
+		`rule "digits" is registered more than once`,
+		`rule "missing" is referenced but not registered`,
+		`rule "digits" is a`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %q, want it to contain %q", err, want)
+		}
+	}
+
+	if _, err = gomme.Lookup[int](reg, "digits"); err == nil {
+		t.Errorf("got no error for a lookup with the wrong type")
+	}
+}
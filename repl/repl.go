@@ -0,0 +1,185 @@
+// Package repl helps exercising grammars interactively.
+// Rules are registered by name. Every line of input is parsed with the
+// current rule and the output value, the remaining input, the
+// diagnostics and optionally a trace of all parsers are printed.
+// The command `cmd/gomme-repl` is a thin wrapper around this package.
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/oleiade/gomme"
+)
+
+// Result is the result of parsing one input with a rule.
+type Result struct {
+	Output    any    // the output value of the rule
+	Remaining string // the input that hasn't been consumed
+	Err       error  // the diagnostics (nil if the parse was successful)
+	Trace     string // the trace of all parsers (empty if tracing is off)
+}
+
+// rule can parse input with a registered parser of any output type.
+type rule func(input string, trace bool) Result
+
+// REPL holds the registered rules and the settings of an interactive session.
+type REPL struct {
+	rules   map[string]rule
+	current string
+	trace   bool
+}
+
+// New returns a REPL without any rules.
+func New() *REPL {
+	return &REPL{rules: make(map[string]rule)}
+}
+
+// Register registers the parser built by `build` as rule `name`.
+// If tracing is turned on, the parser runs with the trace as global
+// middleware. So the trace contains all parsers of the rule.
+// The first registered rule is the current rule.
+func Register[Output any](r *REPL, name string, build func() gomme.Parser[Output]) {
+	parse := build()
+	r.rules[name] = func(input string, trace bool) Result {
+		sb := strings.Builder{}
+		state := gomme.NewFromString(input, false)
+		if trace {
+			state = gomme.NewGrammar(gomme.WithGlobalMiddleware(textTrace(&sb))).NewFromString(input, false)
+		}
+		newState, output := gomme.RunOnState(state, parse)
+		result := Result{Output: output, Remaining: newState.CurrentString(), Trace: sb.String()}
+		if err := newState.Err(); err != nil {
+			result.Output, result.Err = nil, err
+		}
+		return result
+	}
+	if r.current == "" {
+		r.current = name
+	}
+}
+
+// Rules returns the names of all registered rules in alphabetical order.
+func (r *REPL) Rules() []string {
+	names := make([]string, 0, len(r.rules))
+	for name := range r.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Use sets the current rule.
+func (r *REPL) Use(name string) error {
+	if _, ok := r.rules[name]; !ok {
+		return fmt.Errorf("rule %q is not registered", name)
+	}
+	r.current = name
+	return nil
+}
+
+// SetTrace turns tracing on or off.
+func (r *REPL) SetTrace(on bool) {
+	r.trace = on
+}
+
+// Parse parses the input with the current rule.
+func (r *REPL) Parse(input string) (Result, error) {
+	parse, ok := r.rules[r.current]
+	if !ok {
+		return Result{}, fmt.Errorf("no rule has been registered")
+	}
+	return parse(input, r.trace), nil
+}
+
+// Run reads lines from `in` and writes the results to `out` until the
+// input ends or the `:quit` command is given.
+// Lines starting with a colon are commands (see `:help`).
+func (r *REPL) Run(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	r.prompt(out)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, ":") {
+			if quit := r.command(line, out); quit {
+				return nil
+			}
+		} else if result, err := r.Parse(line); err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+		} else {
+			printResult(out, result)
+		}
+		r.prompt(out)
+	}
+	return scanner.Err()
+}
+
+func (r *REPL) prompt(out io.Writer) {
+	fmt.Fprintf(out, "%s> ", r.current)
+}
+
+// command executes a command and returns true if the REPL should stop.
+func (r *REPL) command(line string, out io.Writer) bool {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ":quit", ":q":
+		return true
+	case ":rules":
+		fmt.Fprintln(out, strings.Join(r.Rules(), "\n"))
+	case ":use":
+		if len(fields) != 2 {
+			fmt.Fprintln(out, "usage: :use <rule>")
+		} else if err := r.Use(fields[1]); err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+		}
+	case ":trace":
+		if len(fields) != 2 || (fields[1] != "on" && fields[1] != "off") {
+			fmt.Fprintln(out, "usage: :trace on|off")
+		} else {
+			r.SetTrace(fields[1] == "on")
+		}
+	default:
+		fmt.Fprint(out, `commands:
+  :rules          list all rules
+  :use <rule>     parse with the rule
+  :trace on|off   show the trace of all parsers
+  :quit           stop
+`)
+	}
+	return false
+}
+
+func printResult(out io.Writer, result Result) {
+	if result.Trace != "" {
+		fmt.Fprint(out, result.Trace)
+	}
+	if result.Err != nil {
+		fmt.Fprintf(out, "diagnostics:\n%v\n", result.Err)
+	} else {
+		fmt.Fprintf(out, "output: %#v\n", result.Output)
+	}
+	fmt.Fprintf(out, "remaining: %q\n", result.Remaining)
+}
+
+// textTrace returns a Middleware that writes an indented line for the
+// start and the result of every parser.
+func textTrace(w io.Writer) gomme.Middleware {
+	depth := 0
+	return func(expected string, state gomme.State, next func(gomme.State) (gomme.State, *gomme.ParserError),
+	) (gomme.State, *gomme.ParserError) {
+		indent := strings.Repeat("  ", depth)
+		fmt.Fprintf(w, "%s%s @%d\n", indent, expected, state.CurrentPos())
+		depth++
+		newState, err := next(state)
+		depth--
+		if err != nil {
+			fmt.Fprintf(w, "%s-> failed: %s\n", indent, err.Text())
+		} else {
+			fmt.Fprintf(w, "%s-> ok @%d\n", indent, newState.CurrentPos())
+		}
+		return newState, err
+	}
+}
@@ -0,0 +1,51 @@
+package repl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"github.com/oleiade/gomme/repl"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	r := repl.New()
+	repl.Register(r, "word", pcb.Alpha1)
+	repl.Register(r, "int", func() gomme.Parser[int64] { return pcb.Int64(true, 10) })
+
+	out := strings.Builder{}
+	in := strings.NewReader("abc12\n:use int\n-42\nx\n:use nothing\n:quit\nnever parsed\n")
+	if err := r.Run(in, &out); err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"word> ", `output: "abc"`, `remaining: "12"`,
+		"int> ", "output: -42", "diagnostics:", `rule "nothing" is not registered`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("got output %q, want it to contain %q", out.String(), want)
+		}
+	}
+	if strings.Contains(out.String(), "never") {
+		t.Errorf("got input parsed after :quit")
+	}
+}
+
+func TestTrace(t *testing.T) {
+	t.Parallel()
+
+	r := repl.New()
+	repl.Register(r, "word", pcb.Alpha1)
+	r.SetTrace(true)
+
+	result, err := r.Parse("abc")
+	if err != nil || result.Err != nil || result.Output != "abc" {
+		t.Fatalf("got (%+v, %v), want output %q", result, err, "abc")
+	}
+	if !strings.Contains(result.Trace, "-> ok @3") {
+		t.Errorf("got trace %q, want it to contain the successful parser", result.Trace)
+	}
+}
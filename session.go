@@ -0,0 +1,78 @@
+package gomme
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ============================================================================
+// Parsing Multiple Files
+//
+
+// Session opens the files of a multi-file parse from a file system.
+// States created by a Session know the name of their file. So errors name
+// the right file and files can include other files (see pcb.Include).
+type Session struct {
+	fsys fs.FS
+}
+
+// includeFrame is one file in the chain of including files.
+type includeFrame struct {
+	file   string
+	parent *includeFrame
+}
+
+// NewSession returns a Session that opens files from `fsys`.
+func NewSession(fsys fs.FS) *Session {
+	return &Session{fsys: fsys}
+}
+
+// Open reads the file `name` and returns a new parser state for its content.
+func (s *Session) Open(name string, recover bool) (State, error) {
+	data, err := fs.ReadFile(s.fsys, name)
+	if err != nil {
+		return State{}, err
+	}
+	state := NewFromString(string(data), recover)
+	state.input.file = name
+	state.session = s
+	return state, nil
+}
+
+// File returns the name of the file of the input.
+// It is empty if the state hasn't been created by a Session.
+func (st State) File() string {
+	return st.input.file
+}
+
+// Include opens the file `name` for including it at the current position.
+// A relative name is resolved relative to the directory of the current file.
+// The returned state starts at the beginning of the included file and
+// is embedded into this state (see Embed). So it has the same
+// configuration, user values and budget but its own errors and caches.
+// An error is returned if the state hasn't been created by a Session,
+// the file can't be read or it is already being included (include cycle).
+func (st State) Include(name string) (State, error) {
+	if st.session == nil {
+		return State{}, fmt.Errorf("unable to include %q without a Session", name)
+	}
+	if !strings.HasPrefix(name, "/") {
+		name = path.Join(path.Dir(st.input.file), name)
+	}
+	name = strings.TrimPrefix(path.Clean(name), "/")
+	includes := &includeFrame{file: st.input.file, parent: st.includes}
+	for frame := includes; frame != nil; frame = frame.parent {
+		if frame.file == name {
+			return State{}, fmt.Errorf("include cycle: %q includes itself", name)
+		}
+	}
+
+	newState, err := st.session.Open(name, st.recover)
+	if err != nil {
+		return State{}, err
+	}
+	newState.includes = includes
+	return st.Embed(newState), nil
+}
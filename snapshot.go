@@ -0,0 +1,168 @@
+package gomme
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ============================================================================
+// Snapshots of the State for checkpointing long parses
+//
+
+const snapshotHeader = "gomme-state\x01"
+
+// ErrInvalidSnapshot is returned by ResumeFromSnapshot for data that isn't
+// a snapshot or doesn't fit the input.
+var ErrInvalidSnapshot = errors.New("gomme: invalid state snapshot")
+
+// Snapshot returns the position, line tracking, SaveSpot mark and all
+// errors of the state in a compact binary form.
+// So a long parse can be checkpointed between two items and resumed with
+// ResumeFromSnapshot after a process restart.
+// The input itself, caches, budgets, observers and user values aren't part
+// of the snapshot.
+// Snapshots can't be taken while an error is handled or for a state of a
+// Session.
+func (st State) Snapshot() ([]byte, error) {
+	if st.errHand.err != nil || st.mode != ParsingModeHappy {
+		return nil, fmt.Errorf("unable to snapshot the state in %s mode while handling an error", st.mode)
+	}
+	if st.session != nil {
+		return nil, errors.New("unable to snapshot the state of a Session")
+	}
+
+	flags := byte(0)
+	if st.input.binary {
+		flags |= 1
+	}
+	if st.recover {
+		flags |= 2
+	}
+	buf := append([]byte(snapshotHeader), flags)
+	buf = binary.AppendUvarint(buf, uint64(st.input.pos))
+	buf = binary.AppendUvarint(buf, uint64(st.input.line))
+	buf = binary.AppendVarint(buf, int64(st.input.prevNl))
+	buf = binary.AppendVarint(buf, int64(st.saveSpot))
+	buf = appendSnapshotString(buf, st.input.file)
+	buf = binary.AppendUvarint(buf, uint64(len(st.oldErrors)))
+	for _, err := range st.oldErrors {
+		flags = 0
+		if err.expected {
+			flags |= 1
+		}
+		if err.semantic {
+			flags |= 2
+		}
+		buf = append(buf, flags)
+		buf = binary.AppendUvarint(buf, uint64(err.pos))
+		buf = binary.AppendVarint(buf, int64(err.parserID))
+		buf = appendSnapshotString(buf, err.text)
+	}
+	return buf, nil
+}
+
+// ResumeFromSnapshot returns `state` moved to the position of the snapshot
+// (see State.Snapshot) with the SaveSpot mark and errors restored.
+// `state` has to be a new state for the same input (e.g. created with
+// NewFromString). Its configuration (caches, budget, ...) is kept.
+// ErrInvalidSnapshot is returned if the snapshot is corrupt or obviously
+// doesn't fit the input.
+func ResumeFromSnapshot(snapshot []byte, state State) (State, error) {
+	sr := snapshotReader{data: snapshot}
+	if len(snapshot) < len(snapshotHeader)+1 || string(snapshot[:len(snapshotHeader)]) != snapshotHeader {
+		return state, ErrInvalidSnapshot
+	}
+	sr.data = sr.data[len(snapshotHeader):]
+	flags := sr.byte()
+	pos, line, prevNl := int(sr.uvarint()), int(sr.uvarint()), int(sr.varint())
+	saveSpot := int(sr.varint())
+	file := sr.string()
+	if sr.bad || (flags&1 != 0) != state.input.binary || pos > state.input.n || line < 1 ||
+		prevNl >= pos || saveSpot < -1 || (prevNl >= 0 && !state.input.binary && state.input.text[prevNl] != '\n') {
+		return state, ErrInvalidSnapshot
+	}
+
+	input := state.input
+	input.pos, input.line, input.prevNl, input.file = pos, line, prevNl, file
+	count := sr.uvarint()
+	var errs []ParserError
+	for i := uint64(0); i < count && !sr.bad; i++ {
+		errFlags := sr.byte()
+		err := ParserError{
+			expected: errFlags&1 != 0,
+			semantic: errFlags&2 != 0,
+			pos:      int(sr.uvarint()),
+			parserID: int32(sr.varint()),
+			input:    input,
+			excerpt:  state.binaryExcerpt,
+		}
+		err.text = sr.string()
+		if err.pos > state.input.n {
+			sr.bad = true
+		}
+		errs = append(errs, err)
+	}
+	if sr.bad || len(sr.data) > 0 {
+		return state, ErrInvalidSnapshot
+	}
+
+	state.input = input
+	state.recover = flags&2 != 0
+	state.saveSpot = saveSpot
+	state.oldErrors = errs
+	return state, nil
+}
+
+func appendSnapshotString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// snapshotReader reads the parts of a snapshot.
+// `bad` is set for any corrupt data and stays set.
+type snapshotReader struct {
+	data []byte
+	bad  bool
+}
+
+func (sr *snapshotReader) byte() byte {
+	if len(sr.data) == 0 {
+		sr.bad = true
+		return 0
+	}
+	b := sr.data[0]
+	sr.data = sr.data[1:]
+	return b
+}
+
+func (sr *snapshotReader) uvarint() uint64 {
+	v, n := binary.Uvarint(sr.data)
+	if n <= 0 || v > 1<<62 {
+		sr.bad = true
+		return 0
+	}
+	sr.data = sr.data[n:]
+	return v
+}
+
+func (sr *snapshotReader) varint() int64 {
+	v, n := binary.Varint(sr.data)
+	if n <= 0 {
+		sr.bad = true
+		return 0
+	}
+	sr.data = sr.data[n:]
+	return v
+}
+
+func (sr *snapshotReader) string() string {
+	n := sr.uvarint()
+	if n > uint64(len(sr.data)) {
+		sr.bad = true
+		return ""
+	}
+	s := string(sr.data[:n])
+	sr.data = sr.data[n:]
+	return s
+}
@@ -0,0 +1,48 @@
+package gomme_test
+
+import (
+	"errors"
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	input := "line1\nline2 bad\nline3"
+	state := gomme.NewFromString(input, true).MoveBy(12).NewSemanticError("bad word").MoveBy(5)
+	snapshot, err := state.Snapshot()
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+
+	resumed, err := gomme.ResumeFromSnapshot(snapshot, gomme.NewFromString(input, false))
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	if got, want := resumed.CurrentPos(), state.CurrentPos(); got != want {
+		t.Errorf("got position %d, want %d", got, want)
+	}
+	if got, want := resumed.Errors().Error(), state.Errors().Error(); got != want {
+		t.Errorf("got errors %q, want %q", got, want)
+	}
+	if got, want := resumed.CurrentSourceLine(), state.CurrentSourceLine(); got != want {
+		t.Errorf("got source line %q, want %q", got, want)
+	}
+
+	newState, output := gomme.RunOnState(resumed, pcb.String("line3"))
+	if output != "line3" || !newState.AtEnd() {
+		t.Errorf("got output %q and remaining %q, want to parse the rest", output, newState.CurrentString())
+	}
+
+	if _, err = gomme.ResumeFromSnapshot(snapshot, gomme.NewFromString("short", false)); !errors.Is(err, gomme.ErrInvalidSnapshot) {
+		t.Errorf("got error %v for wrong input, want %v", err, gomme.ErrInvalidSnapshot)
+	}
+	if _, err = gomme.ResumeFromSnapshot(snapshot[:len(snapshot)-1], gomme.NewFromString(input, false)); !errors.Is(err, gomme.ErrInvalidSnapshot) {
+		t.Errorf("got error %v for truncated snapshot, want %v", err, gomme.ErrInvalidSnapshot)
+	}
+	if _, err = state.NewError("digit").Snapshot(); err == nil {
+		t.Errorf("got no error for a snapshot while handling an error")
+	}
+}
@@ -2,10 +2,11 @@ package gomme
 
 import (
 	"cmp"
-	"errors"
+	"encoding/binary"
 	"fmt"
 	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 )
 
@@ -14,23 +15,6 @@ import (
 // methods.
 // ============================================================================
 
-var cachingRecovererIDs = &atomic.Uint64{}
-
-type cachedWaste struct {
-	pos   int // position in the input
-	waste int // waste of the recoverer
-}
-
-var combiningRecovererIDs = &atomic.Uint64{}
-
-type cachedWasteIdx struct {
-	pos   int // position in the input
-	waste int // waste of the recoverer
-	idx   int // index of the best sub-recoverer
-}
-
-var combiningParserIDs = &atomic.Uint64{}
-
 type ParserResult struct {
 	pos           int          // position in the input
 	Idx           int          // index of the chosen branch or parser (success or fail)
@@ -43,6 +27,8 @@ type ParserResult struct {
 	Consumed      int          // number of bytes consumed from the input during successful parsing
 	Output        interface{}  // the Output of the parser (nil if it failed)
 	Error         *ParserError // the error if the parser failed (nil if it succeeded)
+	userIn        *userValue   // user values the parser started with
+	userOut       *userValue   // user values after the parser
 }
 
 type ParserOutput struct {
@@ -54,16 +40,90 @@ var callIDs = &atomic.Uint64{} // used for endless loop prevention
 
 // State represents the current state of a parser.
 type State struct {
-	mode                   ParsingMode // one of: happy, error, handle, record, choose, play
-	input                  Input
-	saveSpot               int           // mark set by the SaveSpot parser
-	recover                bool          // recover from errors
-	errHand                errHand       // everything for handling one error
-	oldErrors              []ParserError // errors that are or have been handled
-	recovererWasteCache    map[uint64][]cachedWaste
-	recovererWasteIdxCache map[uint64][]cachedWasteIdx
-	parserCache            map[uint64][]ParserResult
-	outputCache            map[int32][]ParserOutput
+	mode             ParsingMode // one of: happy, error, handle, record, choose, play
+	input            Input
+	saveSpot         int                  // mark set by the SaveSpot parser
+	recover          bool                 // recover from errors
+	errHand          errHand              // everything for handling one error
+	oldErrors        []ParserError        // errors that are or have been handled
+	cache            CacheBackend         // for recoverer waste and parser results
+	limitCaches      map[int]CacheBackend // separate caches of limited views by their end (see Limit)
+	sharedIDs        map[*ParserID]uint64 // numbers of IDs bound to another IDAllocator (for the current run)
+	outputCache      map[int32][]ParserOutput
+	cacheClearing    CacheClearing // policy for clearing the caches automatically
+	lastClearPos     int           // input position of the last clearing of the caches
+	endianness       Endianness    // default byte order for binary numbers
+	binaryExcerpt    BinaryExcerpt // rendering of errors in binary input
+	recovery         *recoveryLog  // telemetry of recovery actions
+	recoveryObserver func(RecoveryEvent)
+	traces           []*binaryTracer // binary traces recording the recovery actions (see BinaryTrace)
+	errArena         *errorArena     // allocates errors cheaply
+	session          *Session        // for including files (nil outside of a Session)
+	includes         *includeFrame
+	budget           *stepBudget  // limits the number of parser invocations (nil: unlimited)
+	strictLL         *llLog       // strict no-backtracking mode (nil: off)
+	stats            *statsLog    // statistics of ParseWithStats (nil: off)
+	user             *userValue   // values set with WithUserValue (nil: none)
+	ids              *IDAllocator // numbers of the ParserIDs (nil: default allocator)
+	middleware       []Middleware // global middleware of the Grammar
+}
+
+// userValue is an entry of the immutable list of user values.
+type userValue struct {
+	key, value any
+	next       *userValue
+}
+
+// Endianness is the byte order of multi-byte binary numbers.
+// The zero value is BigEndian (network byte order).
+type Endianness uint8
+
+const (
+	BigEndian Endianness = iota
+	LittleEndian
+)
+
+// ByteOrder returns the matching byte order of the `encoding/binary` package.
+func (e Endianness) ByteOrder() binary.ByteOrder {
+	if e == LittleEndian {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// String returns the name of the endianness.
+func (e Endianness) String() string {
+	if e == LittleEndian {
+		return "little endian"
+	}
+	return "big endian"
+}
+
+// BinaryExcerpt configures how the input around an error is rendered
+// for binary input. The bytes are shown as a hex dump with 16 bytes per row.
+// The zero value renders 16 bytes with offset column and ASCII gutter.
+type BinaryExcerpt struct {
+	Width      int  // number of bytes shown around the error (16 if <= 0)
+	HideOffset bool // don't show the offset column
+	HideASCII  bool // don't show the ASCII gutter
+}
+
+func (be BinaryExcerpt) width() int {
+	if be.Width <= 0 {
+		return 16
+	}
+	return be.Width
+}
+
+// CacheClearing configures when the caches of a State are cleared
+// automatically. So long parses don't accumulate unbounded cache memory.
+// The zero value turns automatic clearing off.
+// The policy only applies to parses without error recovery (see
+// State.AutoClearCaches). With error recovery the caches are cleared by
+// successful SaveSpot parsers.
+type CacheClearing struct {
+	AfterEachItem bool // clear after every item of a repetition parser (Many0, Separated1, ...)
+	AfterBytes    int  // clear after at least this many bytes of progress (0 turns it off)
 }
 
 // ============================================================================
@@ -82,14 +142,33 @@ func (st State) CurrentString() string {
 	if st.input.binary && len(st.input.text) < st.input.n {
 		st.input.text = string(st.input.bytes)
 	}
-	return st.input.text[st.input.pos:]
+	return st.input.text[st.input.pos:st.input.n]
 }
 
 func (st State) CurrentBytes() []byte {
 	if !st.input.binary && len(st.input.bytes) < st.input.n {
 		st.input.bytes = []byte(st.input.text)
 	}
-	return st.input.bytes[st.input.pos:]
+	return st.input.bytes[st.input.pos:st.input.n]
+}
+
+// PeekByte returns the current byte without converting the input.
+// It returns (0, false) at the end of the input.
+func (st State) PeekByte() (byte, bool) {
+	if st.AtEnd() {
+		return 0, false
+	}
+	if st.input.binary {
+		return st.input.bytes[st.input.pos], true
+	}
+	return st.input.text[st.input.pos], true
+}
+
+// IsBinary reports whether the input is binary.
+// CurrentBytes is cheap for binary input and CurrentString for text input.
+// The other one converts the whole remaining input.
+func (st State) IsBinary() bool {
+	return st.input.binary
 }
 
 func (st State) CurrentPos() int {
@@ -103,8 +182,8 @@ func (st State) StringTo(remaining State) string {
 	if st.input.binary && len(st.input.text) < st.input.n {
 		st.input.text = string(st.input.bytes)
 	}
-	if remaining.input.pos > len(st.input.text) {
-		return st.input.text[st.input.pos:]
+	if remaining.input.pos > st.input.n {
+		return st.input.text[st.input.pos:st.input.n]
 	}
 	return st.input.text[st.input.pos:remaining.input.pos]
 }
@@ -116,8 +195,8 @@ func (st State) BytesTo(remaining State) []byte {
 	if !st.input.binary && len(st.input.bytes) < st.input.n {
 		st.input.bytes = []byte(st.input.text)
 	}
-	if remaining.input.pos > len(st.input.bytes) {
-		return st.input.bytes[st.input.pos:]
+	if remaining.input.pos > st.input.n {
+		return st.input.bytes[st.input.pos:st.input.n]
 	}
 	return st.input.bytes[st.input.pos:remaining.input.pos]
 }
@@ -154,10 +233,87 @@ func (st State) MoveBy(countBytes int) State {
 	return st
 }
 
+// Limit returns the State with the end of the input set to `count` bytes
+// after the current position. Parsers see the limit as the end of the input.
+// The end of the input is never extended.
+// Parsers might fail at the limit but succeed without it. So the limited
+// view gets its own caches (one per end of the view).
+func (st State) Limit(count int) State {
+	end := min(st.input.n, st.input.pos+max(count, 0))
+	if end < st.input.n && st.limitCaches != nil {
+		cache, ok := st.limitCaches[end]
+		if !ok {
+			cache = NewMapCache()
+			st.limitCaches[end] = cache
+		}
+		st.cache = cache
+	}
+	st.input.n = end
+	return st
+}
+
+// Unlimit returns the State with the end of the input and the caches set
+// back to the ones of the `outer` state (the one before calling Limit).
+func (st State) Unlimit(outer State) State {
+	st.input.n = outer.input.n
+	st.cache = outer.cache
+	return st
+}
+
+// MoveTo moves to the absolute position `pos` in the input.
+// Moving backwards in text input recounts the lines from the start.
+func (st State) MoveTo(pos int) State {
+	if pos >= st.input.pos {
+		return st.MoveBy(pos - st.input.pos)
+	}
+	st.input.pos = 0
+	st.input.prevNl = -1
+	st.input.line = 1
+	return st.MoveBy(pos)
+}
+
 func (st State) Moved(other State) bool {
 	return st.input.pos != other.input.pos
 }
 
+// Checkpoint is an opaque snapshot of the position in the input
+// (including line tracking) and the SaveSpot mark of a State.
+// It is created with State.Checkpoint and used with State.Restore.
+type Checkpoint struct {
+	pos      int // position in the input a.k.a. the *byte* index
+	prevNl   int // position of newline preceding 'pos'
+	line     int // line number at 'pos'
+	saveSpot int // mark set by the SaveSpot parser
+}
+
+// Pos returns the position in the input of the checkpoint.
+func (cp Checkpoint) Pos() int {
+	return cp.pos
+}
+
+// Checkpoint returns the current position in the input and the SaveSpot mark.
+// Together with State.Restore it allows custom parsers to implement
+// backtracking.
+func (st State) Checkpoint() Checkpoint {
+	return Checkpoint{
+		pos:      st.input.pos,
+		prevNl:   st.input.prevNl,
+		line:     st.input.line,
+		saveSpot: st.saveSpot,
+	}
+}
+
+// Restore returns the State with the position in the input and
+// the SaveSpot mark set back to the checkpoint.
+// Everything else (errors, caches, ...) is kept.
+func (st State) Restore(cp Checkpoint) State {
+	st.input.pos = cp.pos
+	st.input.prevNl = cp.prevNl
+	st.input.line = cp.line
+	st.saveSpot = cp.saveSpot
+	return st
+}
+
 // Delete moves forward in the input, thus simulating deletion of input.
 // For binary input it moves forward by bytes otherwise by UNICODE runes.
 func (st State) Delete(count int) State {
@@ -183,11 +339,124 @@ func (st State) Delete(count int) State {
 // Caching
 //
 
-// NewBranchParserID returns a new ID for a combining parser.
+// ParserID identifies a combining parser or a caching recoverer in the
+// caches of a State.
+// The number of the ID is taken from the IDAllocator of the state the first
+// time it is needed (see State.WithIDs). So the numbers only depend on the
+// parsers used by a parse and not on parsers constructed elsewhere
+// (e.g. by other goroutines).
+// A ParserID is safe for concurrent use.
+type ParserID struct {
+	bound atomic.Pointer[boundID] // number taken from the first IDAllocator
+}
+
+type boundID struct {
+	ids *IDAllocator
+	id  uint64
+}
+
+// NewParserID returns a new ParserID.
+// It should be called in the construction phase of the parser and
+// the number of it should be retrieved with ParserID.In in the runtime
+// phase for caching.
+func NewParserID() *ParserID {
+	return &ParserID{}
+}
+
+// In returns the number of the ID in the IDAllocator of the state.
+// An ID that took its number from another allocator first gets a number
+// that is kept only for the current run of the state. So nothing is kept
+// per parser in long-lived allocators.
+func (pid *ParserID) In(state State) uint64 {
+	ids := state.idAllocator()
+	if b := pid.bound.Load(); b != nil && b.ids == ids {
+		return b.id
+	}
+	if id, ok := state.sharedIDs[pid]; ok {
+		return id
+	}
+	id, bound := ids.idOf(pid)
+	if !bound && state.sharedIDs != nil {
+		state.sharedIDs[pid] = id
+	}
+	return id
+}
+
+// IDAllocator hands out the numbers of ParserIDs for the states using it.
+// The numbers are only keys into the caches of a State, so they have to be
+// unique within one IDAllocator but not across allocators.
+// They are handed out in the order the parsers are used first. So parsing
+// the same input with a fresh IDAllocator yields the same numbers.
+// The IDAllocator of a Grammar is dropped together with the grammar.
+// An IDAllocator is safe for concurrent use.
+type IDAllocator struct {
+	mu   sync.Mutex
+	last uint64
+}
+
+// NewIDAllocator returns an IDAllocator that starts counting at 1.
+func NewIDAllocator() *IDAllocator {
+	return &IDAllocator{}
+}
+
+// defaultIDs is used by states without an IDAllocator of their own.
+var defaultIDs = NewIDAllocator()
+
+// idOf binds the ID to this allocator if it isn't bound yet.
+// Otherwise a new number is returned that has to be remembered by the
+// caller (bound is false then).
+func (ids *IDAllocator) idOf(pid *ParserID) (id uint64, bound bool) {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+
+	if b := pid.bound.Load(); b != nil && b.ids == ids {
+		return b.id, true
+	}
+	ids.last++
+	return ids.last, pid.bound.CompareAndSwap(nil, &boundID{ids: ids, id: ids.last})
+}
+
+// newID returns a number that isn't used by any ParserID.
+func (ids *IDAllocator) newID() uint64 {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	ids.last++
+	return ids.last
+}
+
+// WithIDs returns the State with the IDAllocator used for the numbers of
+// all ParserIDs (see Grammar).
+// It should be called before parsing starts.
+func (st State) WithIDs(ids *IDAllocator) State {
+	st.ids = ids
+	st.sharedIDs = make(map[*ParserID]uint64)
+	return st
+}
+
+func (st State) idAllocator() *IDAllocator {
+	if st.ids == nil {
+		return defaultIDs
+	}
+	return st.ids
+}
+
+// unscopedIDBase is the first ID handed out by NewBranchParserID.
+// So these IDs never collide with the numbers of ParserIDs.
+const unscopedIDBase = 1 << 63
+
+var unscopedIDs = func() *atomic.Uint64 {
+	ids := &atomic.Uint64{}
+	ids.Store(unscopedIDBase)
+	return ids
+}()
+
+// NewBranchParserID returns a new, globally unique ID for a combining parser.
 // This ID should be retrieved in the construction phase of the parsers and
 // used in the runtime phase for caching.
+// Prefer NewParserID because its numbers are reproducible and scoped to
+// the IDAllocator of the state.
 func NewBranchParserID() uint64 {
-	return combiningParserIDs.Add(1)
+	return unscopedIDs.Add(1)
 }
 
 // NewCallID returns a new ID for a function call that might run into an
@@ -201,47 +470,25 @@ func NewCallID() uint64 {
 // cacheRecovererWaste remembers the `waste` at the current input position
 // for the CachingRecoverer with ID `id`.
 func (st State) cacheRecovererWaste(id uint64, waste int) {
-	cacheValue(st.recovererWasteCache, id, cachedWaste{pos: st.input.pos, waste: waste},
-		func(a, b cachedWaste) int {
-			return cmp.Compare(a.pos, b.pos)
-		}, st.maxDel)
+	st.cache.StoreWaste(id, st.input.pos, waste)
 }
 
 // cachedRecovererWaste returns the saved waste for the current
 // input position and CachingRecoverer ID `id` or (-1, false) if not found.
 func (st State) cachedRecovererWaste(id uint64) (waste int, ok bool) {
-	var wasteData cachedWaste
-
-	wasteData, ok = cachedValue(st.recovererWasteCache, id, func(wasteData cachedWaste) bool {
-		return wasteData.pos == st.input.pos
-	})
-	if !ok {
-		return -1, false
-	}
-	return wasteData.waste, true
+	return st.cache.LoadWaste(id, st.input.pos)
 }
 
 // cacheRecovererWasteIdx remembers the `waste` and index at the
 // current input position for the CombiningRecoverer with ID `crID`.
 func (st State) cacheRecovererWasteIdx(crID uint64, waste, idx int) {
-	cacheValue(st.recovererWasteIdxCache, crID, cachedWasteIdx{pos: st.input.pos, waste: waste, idx: idx},
-		func(a, b cachedWasteIdx) int {
-			return cmp.Compare(a.pos, b.pos)
-		}, st.maxDel)
+	st.cache.StoreWasteIdx(crID, st.input.pos, waste, idx)
 }
 
 // cachedRecovererWasteIdx returns the saved waste and index for the current
 // input position and CombiningRecoverer ID or (-1, -1, false) if not found.
 func (st State) cachedRecovererWasteIdx(crID uint64) (waste, idx int, ok bool) {
-	var wasteData cachedWasteIdx
-
-	wasteData, ok = cachedValue(st.recovererWasteIdxCache, crID, func(wasteData cachedWasteIdx) bool {
-		return wasteData.pos == st.input.pos
-	})
-	if !ok {
-		return -1, -1, false
-	}
-	return wasteData.waste, wasteData.idx, true
+	return st.cache.LoadWasteIdx(crID, st.input.pos)
 }
 
 func (st State) CacheParserResult(
@@ -272,17 +519,30 @@ func (st State) CacheParserResult(
 		Error:         newState.errHand.err,
 		ErrorStart:    errStart,
 		Output:        output,
+		userIn:        st.user,
+		userOut:       newState.user,
+	}
+	if !result.Failed {
+		result.Consumed = st.ByteCount(newState)
 	}
 
-	cacheValue(st.parserCache, id, result, func(a, b ParserResult) int {
-		return cmp.Compare(a.pos, b.pos)
-	}, st.maxDel)
+	st.cache.StoreResult(id, st.input.pos, result)
 }
 
+// CachedParserResult returns the result cached for the parser with ID `id`
+// at the current position.
+// In happy mode a result cached with other user values (see WithUserValue)
+// isn't found because the parser might depend on them.
+// The other modes only navigate to an error found before.
 func (st State) CachedParserResult(id uint64) (result ParserResult, ok bool) {
-	return cachedValue(st.parserCache, id, func(data ParserResult) bool {
-		return data.pos == st.input.pos
-	})
+	result, ok = st.cache.LoadResult(id, st.input.pos)
+	if !ok {
+		return ParserResult{}, false
+	}
+	if st.mode == ParsingModeHappy && result.userIn != st.user {
+		return ParserResult{}, false
+	}
+	return result, true
 }
 
 func cacheValue[T any, U cmp.Ordered](cache map[U][]T, id U, value T, f func(T, T) int, maxDel int) {
@@ -361,10 +621,68 @@ func (st State) PurgeOutput(id int32) {
 // Since we reached a new position in the input and won't go back anymore,
 // the cache contains nothing useful anymore.
 func (st State) ClearAllCaches() State {
-	clear(st.recovererWasteCache)
-	clear(st.recovererWasteIdxCache)
-	clear(st.parserCache)
+	st.cache.Clear()
+	clear(st.limitCaches)
 	// clear(st.outputCache) the output might be needed by later parsers as it isn't part of the error handling
+	st.lastClearPos = st.input.pos
+	return st
+}
+
+// WithCacheClearing returns the State with the policy for clearing
+// the caches automatically set.
+func (st State) WithCacheClearing(policy CacheClearing) State {
+	if policy.AfterBytes < 0 {
+		policy.AfterBytes = 0
+	}
+	st.cacheClearing = policy
+	return st
+}
+
+// AutoClearCaches clears all caches if the CacheClearing policy of the state
+// says so.
+// Repetition parsers call it after each successfully parsed item with
+// `item` set to true.
+// Other parsers can call it with `item` set to false to check only the
+// progress in the input.
+// The caches are only cleared at safe points. Without error recovery they
+// only save reparsing when backtracking, so clearing them is always safe.
+// With error recovery the enclosing parsers need their cached results for
+// finding an error again. So the caches are never cleared then.
+func (st State) AutoClearCaches(item bool) State {
+	if st.recover || st.mode != ParsingModeHappy || st.errHand.witnessID > 0 {
+		return st
+	}
+	if item && st.cacheClearing.AfterEachItem {
+		return st.ClearAllCaches()
+	}
+	if st.cacheClearing.AfterBytes > 0 && st.input.pos-st.lastClearPos >= st.cacheClearing.AfterBytes {
+		return st.ClearAllCaches()
+	}
+	return st
+}
+
+// Endianness returns the default byte order for binary numbers.
+func (st State) Endianness() Endianness {
+	return st.endianness
+}
+
+// WithEndianness returns the State with the default byte order for
+// binary numbers set.
+func (st State) WithEndianness(endianness Endianness) State {
+	st.endianness = endianness
+	return st
+}
+
+// BinaryExcerpt returns the configuration for rendering errors in binary input.
+func (st State) BinaryExcerpt() BinaryExcerpt {
+	return st.binaryExcerpt
+}
+
+// WithBinaryExcerpt returns the State with the rendering of errors in
+// binary input configured.
+// Errors created before keep their rendering.
+func (st State) WithBinaryExcerpt(excerpt BinaryExcerpt) State {
+	st.binaryExcerpt = excerpt
 	return st
 }
 
@@ -379,12 +697,54 @@ func (st State) ParsingMode() ParsingMode {
 	return st.mode
 }
 
+// WithUserValue returns the State with the value stored for the key
+// (like context.WithValue). So parsers can keep data of the parse
+// (e.g. user defined operators) in the state.
+// A parser that backtracks to an earlier state drops the values set
+// since then automatically. So values must not be modified after they
+// have been stored. Store a modified copy instead.
+// Keys should be of an unexported type to avoid collisions.
+func (st State) WithUserValue(key, value any) State {
+	st.user = &userValue{key: key, value: value, next: withoutUserValue(st.user, key)}
+	return st
+}
+
+// withoutUserValue returns the list without the entry of the key.
+// The entries in front of it are copied because the list is shared.
+func withoutUserValue(uv *userValue, key any) *userValue {
+	if uv == nil {
+		return nil
+	}
+	if uv.key == key {
+		return uv.next
+	}
+	next := withoutUserValue(uv.next, key)
+	if next == uv.next {
+		return uv
+	}
+	return &userValue{key: uv.key, value: uv.value, next: next}
+}
+
+// UserValue returns the value stored for the key with WithUserValue
+// or (nil, false).
+func (st State) UserValue(key any) (any, bool) {
+	for uv := st.user; uv != nil; uv = uv.next {
+		if uv.key == key {
+			return uv.value, true
+		}
+	}
+	return nil, false
+}
+
 // Succeed returns the State with SaveSpot mark and mode saved from
 // the subState.
 // The error handling is not kept so it will turn a failed result into a
 // successful one.
 // This should only be used by the pcb.Optional parser.
+// In strict LL mode giving up the consumed input of a failed subState is
+// reported (see WithStrictLL).
 func (st State) Succeed(subState State) State {
+	st.reportBacktracking(subState)
 	st.saveSpot = max(st.saveSpot, subState.saveSpot)
 	if st.mode != ParsingModeHappy || subState.mode != ParsingModeError {
 		st.mode = subState.mode
@@ -423,11 +783,13 @@ func (st State) Fail(subState State) State {
 	return st
 }
 
-// SucceedAgain sets the SaveSpot mark and input position from the result.
+// SucceedAgain sets the SaveSpot mark, input position and user values
+// from the result.
 func (st State) SucceedAgain(result ParserResult) State {
 	if result.SaveSpot >= 0 {
 		st.saveSpot = result.SaveSpot
 	}
+	st.user = result.userOut
 	return st.MoveBy(result.Consumed)
 }
 
@@ -454,9 +816,21 @@ func (st State) ErrorAgain(newErr *ParserError) State {
 // position and source line including marker are appended.
 func (st State) NewError(message string) State {
 	newErr := st.newParserError()
-	newErr.text = "expected " + message
+	newErr.text = message
+	newErr.expected = true
 
-	return st.ErrorAgain(&newErr)
+	return st.ErrorAgain(newErr)
+}
+
+// NewFailure sets an error with the message in this state at the current
+// position and lets the parser fail just like NewError.
+// But like for semantic errors `expected` is NOT prepended to the message.
+// This is useful for input that has been parsed successfully but isn't valid.
+func (st State) NewFailure(message string) State {
+	newErr := st.newParserError()
+	newErr.text = message
+
+	return st.ErrorAgain(newErr)
 }
 
 // NewSemanticError sets a semantic error with the messages in this state at the
@@ -466,23 +840,92 @@ func (st State) NewError(message string) State {
 func (st State) NewSemanticError(message string) State {
 	err := st.newParserError()
 	err.text = message
-	st.oldErrors = append(st.oldErrors, err)
+	err.semantic = true
+	st.oldErrors = append(st.oldErrors, *err)
 	return st
 }
 
-func (st State) newParserError() ParserError {
-	newErr := ParserError{pos: st.input.pos, binary: st.input.binary, parserID: -1}
-	if st.input.binary { // the rare binary case is misusing the text case data a bit...
-		newErr.line, newErr.col, newErr.srcLine = st.bytesAround(st.input.pos)
-	} else {
-		newErr.line, newErr.col, newErr.srcLine = st.textAround(st.input.pos)
+// newParserError returns a new error at the current position.
+// The source line isn't extracted here but only when the error is rendered.
+func (st State) newParserError() *ParserError {
+	newErr := st.errArena.newError()
+	*newErr = ParserError{pos: st.input.pos, input: st.input, excerpt: st.binaryExcerpt, parserID: -1}
+	return newErr
+}
+
+// errorChunkSize is the number of errors allocated at once by an errorArena.
+const errorChunkSize = 64
+
+// errorArena hands out errors from chunks that are allocated together.
+// So parsers that rely on backtracking don't allocate for every failed branch.
+// Like the caches it is shared by all states of one parse run.
+// It is reset at the end of every run (see State.endRun). So the next run
+// of a state derived from the same initial state reuses the chunks.
+// Errors are only handed out during a run (see RunOnState) because only
+// then the errors that leave the run are copied.
+type errorArena struct {
+	chunks  [][]ParserError
+	chunk   int  // index of the current chunk
+	next    int  // index of the next free error in the current chunk
+	running bool // a run is using the arena
+}
+
+// beginRun lets the arena hand out errors until the end of the run.
+func (ea *errorArena) beginRun() {
+	if ea != nil {
+		ea.running = true
+	}
+}
+
+func (ea *errorArena) newError() *ParserError {
+	if ea == nil || !ea.running {
+		return &ParserError{}
+	}
+	if ea.chunk == len(ea.chunks) {
+		ea.chunks = append(ea.chunks, make([]ParserError, errorChunkSize))
+	}
+	newErr := &ea.chunks[ea.chunk][ea.next]
+	ea.next++
+	if ea.next == errorChunkSize {
+		ea.chunk, ea.next = ea.chunk+1, 0
 	}
 	return newErr
 }
 
+// endRun detaches the state from the error arena at the end of a run and
+// resets the arena for the next run.
+// Handled errors are stored by value anyway. The current error is copied.
+// The caches are cleared because cached results point into the arena.
+// The numbers of IDs bound to other allocators are forgotten, too.
+func (st State) endRun() State {
+	clear(st.sharedIDs)
+	if st.errArena == nil {
+		return st
+	}
+	if st.errHand.err != nil {
+		err := *st.errHand.err
+		st.errHand.err = &err
+	}
+	if st.cache != nil {
+		st.cache.Clear()
+	}
+	clear(st.limitCaches)
+	st.errArena.chunk, st.errArena.next, st.errArena.running = 0, 0, false
+	return st
+}
+
 func (st State) CurrentError() *ParserError {
 	return st.errHand.err
 }
+
+// SwapError returns the State with the current error replaced by `err`.
+// Nothing is changed if the state hasn't failed.
+func (st State) SwapError(err *ParserError) State {
+	if st.errHand.err != nil && err != nil {
+		st.errHand.err = err
+	}
+	return st
+}
 func (st State) SaveError(err *ParserError) State {
 	st.oldErrors = append(st.oldErrors, *err)
 	return st
@@ -508,27 +951,82 @@ func (st State) StillHandlingError() bool {
 	return st.errHand.ignoreErrParser || st.errHand.curDel > 1
 }
 
+// ============================================================================
+// Embedded Input
+//
+
+// Embed returns the state `sub` for separate input (e.g. an included file
+// or a logical line) with the configuration, user values, step budget,
+// statistics and telemetry of this state.
+// The embedded state keeps its own errors, SaveSpot mark and caches
+// because its positions refer to other input.
+// Use Unembed to take the results of the embedded parse back.
+func (st State) Embed(sub State) State {
+	sub.cacheClearing = st.cacheClearing
+	sub.endianness = st.endianness
+	sub.binaryExcerpt = st.binaryExcerpt
+	sub.recovery = st.recovery
+	sub.recoveryObserver = st.recoveryObserver
+	sub.traces = st.traces
+	sub.errArena = st.errArena
+	sub.budget = st.budget
+	sub.strictLL = st.strictLL
+	sub.user = st.user
+	sub.ids = st.ids
+	sub.sharedIDs = st.sharedIDs
+	sub.middleware = st.middleware
+	if st.stats != nil {
+		sub.stats = &statsLog{result: st.stats.result}
+	}
+	return sub
+}
+
+// Unembed returns this state with the user values and semantic errors of
+// the embedded state `sub` (see Embed).
+// If `pos` is nil, the errors are kept with the embedded input
+// (e.g. for included files).
+// Otherwise the errors are moved into this input. `pos` maps a position in
+// the embedded input to the number of bytes after the current position
+// (negative for bytes before it).
+func (st State) Unembed(sub State, pos func(int) int) State {
+	st.user = sub.user
+	for _, err := range sub.oldErrors {
+		if pos == nil {
+			st.oldErrors = append(st.oldErrors, err)
+			continue
+		}
+		errState := st.MoveTo(st.input.pos + pos(err.pos))
+		moved := errState.newParserError()
+		moved.text, moved.expected, moved.semantic = err.text, err.expected, err.semantic
+		st.oldErrors = append(st.oldErrors, *moved)
+	}
+	return st
+}
+
 // ============================================================================
 // Produce error messages and give them back
 //
 
 // CurrentSourceLine returns the source line corresponding to the current position
-// including [line:column] at the start and a marker at the exact error position.
+// including [line:column] and the byte offset at the start and a marker at the
+// exact error position.
 // This should be used for reporting errors that are detected later.
 // The binary case is handled accordingly.
 func (st State) CurrentSourceLine() string {
 	if st.input.binary {
-		return formatBinaryLine(st.bytesAround(st.input.pos))
+		start, col, srcLine := st.bytesAround(st.input.pos, st.binaryExcerpt.width())
+		return formatBinaryLine(start, col, srcLine, st.input.file, st.binaryExcerpt)
 	} else {
-		return formatSrcLine(st.textAround(st.input.pos))
+		line, col, srcLine := st.textAround(st.input.pos)
+		return formatSrcLine(line, col, srcLine, st.input.file, st.input.pos)
 	}
 }
 
-func (st State) bytesAround(pos int) (line, col int, srcLine string) {
-	start := max(0, pos-8)
-	end := min(start+16, st.input.n)
-	if end-start < 16 { // try to fill up from the other end...
-		start = max(0, end-16)
+func (st State) bytesAround(pos, width int) (line, col int, srcLine string) {
+	start := max(0, pos-width/2)
+	end := min(start+width, st.input.n)
+	if end-start < width { // try to fill up from the other end...
+		start = max(0, end-width)
 	}
 	srcLine = string(st.input.bytes[start:end])
 	return start, pos - start, srcLine
@@ -593,25 +1091,33 @@ func (st State) tryWhere(prevNl int, pos int, nextNl int, lineNum int) (line, co
 	return 1, 0, "", false
 }
 
-// Errors returns all error messages accumulated by the state as a Go error.
-// Multiple errors have been joined (by errors.Join()).
-func (st State) Errors() error {
+// Errors returns all errors accumulated by the state.
+// It returns nil if there are no errors.
+//
+// NOTE:
+//   - Use State.Err if you need a plain Go error because a nil *ParseErrors
+//     isn't a nil error.
+func (st State) Errors() *ParseErrors {
 	pcbErrors := slices.Clone(st.oldErrors)
 	n := len(pcbErrors)
 	if st.errHand.err != nil && (n == 0 || st.errHand.err.pos != pcbErrors[n-1].pos) {
 		pcbErrors = append(pcbErrors, *st.errHand.err)
 	}
+	pcbErrors = st.withLLErrors(pcbErrors)
 
 	if len(pcbErrors) == 0 {
 		return nil
 	}
+	return &ParseErrors{errs: pcbErrors, budgetExceeded: st.maxStepsExceeded()}
+}
 
-	goErrors := make([]error, len(pcbErrors))
-	for i, pe := range pcbErrors {
-		goErrors[i] = errors.New(singleErrorMsg(pe))
+// Err returns all errors accumulated by the state as a Go error
+// (of type *ParseErrors) or nil if there are no errors.
+func (st State) Err() error {
+	if pErrs := st.Errors(); pErrs != nil {
+		return pErrs
 	}
-
-	return errors.Join(goErrors...)
+	return nil
 }
 
 // SaveSpot is true iff we crossed a saveSpot.
@@ -619,6 +1125,15 @@ func (st State) SaveSpot() bool {
 	return st.saveSpot >= st.input.pos
 }
 
+// ScopeSaveSpot returns the State with the SaveSpot mark reset to the one of
+// the `outer` state.
+// So SaveSpots crossed inside a sub-grammar don't prevent backtracking of
+// parsers outside of it.
+func (st State) ScopeSaveSpot(outer State) State {
+	st.saveSpot = outer.saveSpot
+	return st
+}
+
 // SaveSpotMoved is true iff the saveSpot is different between the 2 states.
 func (st State) SaveSpotMoved(other State) bool {
 	return st.saveSpot != other.saveSpot
@@ -0,0 +1,26 @@
+package gomme
+
+import "testing"
+
+func TestSharedIDsEndWithTheRun(t *testing.T) {
+	t.Parallel()
+
+	pid := NewParserID()
+	state1 := NewFromString("", false).WithIDs(NewIDAllocator())
+	state2 := NewFromString("", false).WithIDs(NewIDAllocator())
+	if id := pid.In(state1); id != 1 {
+		t.Errorf("got ID %d in the first allocator, want 1", id)
+	}
+
+	id := pid.In(state2)
+	if again := pid.In(state2); again != id {
+		t.Errorf("got ID %d in the same run, want %d", again, id)
+	}
+	if len(state2.sharedIDs) != 1 {
+		t.Errorf("got %d shared IDs during the run, want 1", len(state2.sharedIDs))
+	}
+	state2 = state2.endRun()
+	if len(state2.sharedIDs) != 0 {
+		t.Errorf("got %d shared IDs after the run, want 0", len(state2.sharedIDs))
+	}
+}
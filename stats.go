@@ -0,0 +1,150 @@
+package gomme
+
+import "time"
+
+// ============================================================================
+// Statistics of a parse run
+//
+
+// ParseStats are the statistics of a parse run collected by ParseWithStats.
+// So performance regressions of grammars can be measured in tests.
+type ParseStats struct {
+	BytesConsumed    int           // position after the parse
+	Invocations      int           // number of parser invocations (including backtracked ones)
+	BacktrackedBytes int           // bytes that have been consumed again after backtracking
+	Recoveries       RecoveryStats // actions of the error recovery
+	CacheHits        int           // successful lookups in the caches
+	CacheMisses      int           // failed lookups in the caches
+	PeakCacheEntries int           // maximum number of entries held by the caches at the same time
+	WallTime         time.Duration // duration of the whole parse
+}
+
+// ParseWithStats runs a parser on text input just like Parse and
+// additionally returns the statistics of the run.
+// Collecting the statistics makes parsing slower. So it is meant for tests
+// and benchmarks.
+func ParseWithStats[Output any](parse Parser[Output], input string) (Output, ParseStats, error) {
+	return ParseStateWithStats(NewFromString(input, true), parse)
+}
+
+// ParseStateWithStats runs a parser on the state just like ParseWithStats.
+// So the statistics can be collected with the configuration of the state,
+// e.g. the global middleware of a Grammar.
+func ParseStateWithStats[Output any](state State, parse Parser[Output]) (Output, ParseStats, error) {
+	stats := &statsLog{result: &ParseStats{}}
+	state.stats = stats
+	state.cache = &statsCache{CacheBackend: state.cache, log: stats, keys: make(map[statsCacheKey]struct{})}
+
+	start := time.Now()
+	newState, output := RunOnState(state, parse)
+	stats.result.WallTime = time.Since(start)
+	stats.result.BytesConsumed = newState.CurrentPos()
+	stats.result.Recoveries = newState.Recoveries()
+
+	if err := newState.Errors(); err != nil {
+		return ZeroOf[Output](), *stats.result, err
+	}
+	return output, *stats.result, nil
+}
+
+// statsLog is shared by all states of one parse run (like the caches).
+// States for embedded input (see State.Embed) have their own statsLog
+// with the same result because their positions refer to other input.
+type statsLog struct {
+	result    *ParseStats
+	highWater int // farthest position consumed since the last backtracking
+}
+
+// invoke counts a parser invocation starting at `pos`.
+func (sl *statsLog) invoke(pos int) {
+	sl.result.Invocations++
+	if pos < sl.highWater {
+		sl.result.BacktrackedBytes += sl.highWater - pos
+		sl.highWater = pos
+	}
+}
+
+// consumed records the position after a successful parser.
+func (sl *statsLog) consumed(pos int) {
+	sl.highWater = max(sl.highWater, pos)
+}
+
+type statsCacheKey struct {
+	kind CacheKind
+	id   uint64
+	pos  int
+}
+
+// cacheLen is implemented by the backends of this package.
+// They know how many entries survived the evictions.
+type cacheLen interface {
+	len() int
+}
+
+// statsCache counts the lookups of the wrapped backend and its entries.
+// Other backends don't tell about evictions, so the distinct keys stored
+// since the last clearing are counted for them instead.
+type statsCache struct {
+	CacheBackend
+	log  *statsLog
+	keys map[statsCacheKey]struct{}
+}
+
+// stored records a new entry after it has been stored in the backend.
+func (sc *statsCache) stored(kind CacheKind, id uint64, pos int) {
+	entries := 0
+	if backend, ok := sc.CacheBackend.(cacheLen); ok {
+		entries = backend.len()
+	} else {
+		sc.keys[statsCacheKey{kind: kind, id: id, pos: pos}] = struct{}{}
+		entries = len(sc.keys)
+	}
+	sc.log.result.PeakCacheEntries = max(sc.log.result.PeakCacheEntries, entries)
+}
+
+// loaded counts a lookup.
+func (sc *statsCache) loaded(ok bool) {
+	if ok {
+		sc.log.result.CacheHits++
+	} else {
+		sc.log.result.CacheMisses++
+	}
+}
+
+func (sc *statsCache) StoreWaste(id uint64, pos int, waste int) {
+	sc.CacheBackend.StoreWaste(id, pos, waste)
+	sc.stored(CacheRecovererWaste, id, pos)
+}
+
+func (sc *statsCache) LoadWaste(id uint64, pos int) (waste int, ok bool) {
+	waste, ok = sc.CacheBackend.LoadWaste(id, pos)
+	sc.loaded(ok)
+	return waste, ok
+}
+
+func (sc *statsCache) StoreWasteIdx(id uint64, pos int, waste, idx int) {
+	sc.CacheBackend.StoreWasteIdx(id, pos, waste, idx)
+	sc.stored(CacheRecovererWasteIdx, id, pos)
+}
+
+func (sc *statsCache) LoadWasteIdx(id uint64, pos int) (waste, idx int, ok bool) {
+	waste, idx, ok = sc.CacheBackend.LoadWasteIdx(id, pos)
+	sc.loaded(ok)
+	return waste, idx, ok
+}
+
+func (sc *statsCache) StoreResult(id uint64, pos int, result ParserResult) {
+	sc.CacheBackend.StoreResult(id, pos, result)
+	sc.stored(CacheParserResult, id, pos)
+}
+
+func (sc *statsCache) LoadResult(id uint64, pos int) (result ParserResult, ok bool) {
+	result, ok = sc.CacheBackend.LoadResult(id, pos)
+	sc.loaded(ok)
+	return result, ok
+}
+
+func (sc *statsCache) Clear() {
+	clear(sc.keys)
+	sc.CacheBackend.Clear()
+}
@@ -0,0 +1,36 @@
+package gomme
+
+import "testing"
+
+func TestStatsCachePeakEntries(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		backend   CacheBackend
+		wantEntry int
+	}{
+		{name: "evicting map", backend: NewMapCacheWithSlots(1), wantEntry: 1},
+		{name: "bounded", backend: NewBoundedCache(16), wantEntry: 3},
+		{name: "nop", backend: NewNopCache(), wantEntry: 0},
+		{name: "other backend", backend: struct{ CacheBackend }{NewMapCacheWithSlots(1)}, wantEntry: 3},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			stats := &statsLog{result: &ParseStats{}}
+			cache := &statsCache{CacheBackend: tc.backend, log: stats, keys: make(map[statsCacheKey]struct{})}
+			for pos := 1; pos <= 3; pos++ {
+				cache.StoreWaste(1, pos, pos)
+			}
+			cache.StoreWaste(1, 3, 0) // replaces the value
+			cache.Clear()
+			cache.StoreWaste(1, 1, 1)
+			if got := stats.result.PeakCacheEntries; got != tc.wantEntry {
+				t.Errorf("got %d peak cache entries, want %d", got, tc.wantEntry)
+			}
+		})
+	}
+}
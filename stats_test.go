@@ -0,0 +1,64 @@
+package gomme_test
+
+import (
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"testing"
+)
+
+func TestParseWithStats(t *testing.T) {
+	t.Parallel()
+
+	parser := pcb.FirstSuccessful(
+		pcb.Sequence(pcb.String("ab"), pcb.String("x")),
+		pcb.Sequence(pcb.String("ab"), pcb.String("c")),
+	)
+
+	output, stats, err := gomme.ParseWithStats(parser, "abc")
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	if len(output) != 2 || output[1] != "c" {
+		t.Errorf("got output %q, want [ab c]", output)
+	}
+	if stats.BytesConsumed != 3 {
+		t.Errorf("got %d bytes consumed, want 3", stats.BytesConsumed)
+	}
+	if stats.BacktrackedBytes != 2 {
+		t.Errorf("got %d backtracked bytes, want 2", stats.BacktrackedBytes)
+	}
+	if stats.Invocations < 7 {
+		t.Errorf("got %d invocations, want at least 7", stats.Invocations)
+	}
+	if stats.WallTime <= 0 {
+		t.Errorf("got wall time %v, want a positive duration", stats.WallTime)
+	}
+
+	_, stats, err = gomme.ParseWithStats(parser, "abz")
+	if err == nil {
+		t.Errorf("got no error for bad input")
+	}
+	if stats.Invocations < 7 || stats.CacheHits+stats.CacheMisses == 0 {
+		t.Errorf("got stats %+v, want invocations and cache lookups of the error recovery", stats)
+	}
+}
+
+func TestParseStateWithStatsKeepsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	count := 0
+	counter := func(_ string, state gomme.State, next func(gomme.State) (gomme.State, *gomme.ParserError)) (gomme.State, *gomme.ParserError) {
+		count++
+		return next(state)
+	}
+	g := gomme.NewGrammar(gomme.WithGlobalMiddleware(counter))
+	parser := pcb.Sequence(pcb.String("a"), pcb.String("b"))
+
+	_, stats, err := gomme.ParseStateWithStats(g.NewFromString("ab", false), parser)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	if count == 0 || count != stats.Invocations {
+		t.Errorf("got %d middleware calls, want one per invocation (%d)", count, stats.Invocations)
+	}
+}
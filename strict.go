@@ -0,0 +1,75 @@
+package gomme
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// ============================================================================
+// Strict No-Backtracking (LL) Mode
+//
+
+// llLog collects the attempts to backtrack in strict LL mode.
+// It is shared by all states of a parse.
+type llLog struct {
+	errs []ParserError
+}
+
+// WithStrictLL returns the State with strict LL mode turned on.
+// In this mode no parser should backtrack over input that has been consumed
+// by a failed sub-parser. Every time a parser does it anyway (see Succeed
+// and Backtrack), it is reported as a semantic grammar error at the
+// position the backtracking parser started at.
+// This helps to restructure grammars for predictable performance and to
+// pinpoint the rules that rely on backtracking.
+// It should be called before parsing starts.
+func (st State) WithStrictLL() State {
+	st.strictLL = &llLog{}
+	return st
+}
+
+// Backtrack returns this state unchanged after giving up the failed
+// state `failed` that started at this state.
+// Parsers that try something else after a failed sub-parser (like
+// alternatives or the end of repetitions) call it, so that strict LL
+// mode can report backtracking over consumed input.
+// Optional parsers use Succeed instead.
+func (st State) Backtrack(failed State) State {
+	st.reportBacktracking(failed)
+	return st
+}
+
+// reportBacktracking reports a grammar error in strict LL mode if going
+// back from the failed state `other` to this state gives up consumed input.
+// It is only called at the sites that really backtrack (and only in the
+// happy parsing mode) so every backtracking is reported at most once.
+func (st State) reportBacktracking(other State) {
+	err := other.errHand.err
+	if st.strictLL == nil || st.mode != ParsingModeHappy || err == nil || err.pos <= st.input.pos {
+		return
+	}
+	text := fmt.Sprintf("grammar error: backtracking over %d consumed bytes after: %s (strict LL mode)",
+		err.pos-st.input.pos, err.Text())
+	if !slices.ContainsFunc(st.strictLL.errs, func(e ParserError) bool {
+		return e.pos == st.input.pos && e.text == text
+	}) {
+		newErr := st.newParserError()
+		newErr.text = text
+		newErr.semantic = true
+		st.strictLL.errs = append(st.strictLL.errs, *newErr)
+	}
+}
+
+// withLLErrors adds the grammar errors of strict LL mode to the errors
+// (sorted by position).
+func (st State) withLLErrors(pcbErrors []ParserError) []ParserError {
+	if st.strictLL == nil || len(st.strictLL.errs) == 0 {
+		return pcbErrors
+	}
+	pcbErrors = append(pcbErrors, st.strictLL.errs...)
+	slices.SortStableFunc(pcbErrors, func(a, b ParserError) int {
+		return cmp.Compare(a.pos, b.pos)
+	})
+	return pcbErrors
+}
@@ -0,0 +1,71 @@
+package gomme_test
+
+import (
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"strings"
+	"testing"
+)
+
+func TestWithStrictLL(t *testing.T) {
+	t.Parallel()
+
+	a, b := pcb.String("a"), pcb.String("b")
+	ab := gomme.NewParser[string]("ab", func(state gomme.State) (gomme.State, string, *gomme.ParserError) {
+		aState, _, err := a.It(state)
+		if err != nil {
+			return state.Preserve(aState), "", err
+		}
+		bState, _, err := b.It(aState)
+		if err != nil {
+			return state.Preserve(bState), "", err
+		}
+		return bState, "ab", nil
+	}, nil)
+	parser := pcb.Optional(ab)
+
+	newState, _ := gomme.RunOnState(gomme.NewFromString("ac", false), parser)
+	if err := newState.Errors(); err != nil || newState.CurrentString() != "ac" {
+		t.Errorf("got (%q, %v), want backtracking without error", newState.CurrentString(), err)
+	}
+
+	newState, _ = gomme.RunOnState(gomme.NewFromString("ac", false).WithStrictLL(), parser)
+	err := newState.Errors()
+	if err == nil || !strings.HasPrefix(err.Error(), "grammar error: backtracking over 1 consumed bytes") {
+		t.Fatalf("got error %v, want grammar error about backtracking", err)
+	}
+	if got := err.Semantic(); len(got) != 1 || got[0].Pos() != 0 {
+		t.Errorf("got semantic errors %v, want 1 at position 0", got)
+	}
+
+	newState, output := gomme.RunOnState(gomme.NewFromString("ab", false).WithStrictLL(), parser)
+	if err := newState.Errors(); err != nil || output != "ab" {
+		t.Errorf("got (%q, %v), want (%q, nil)", output, err, "ab")
+	}
+}
+
+func TestWithStrictLLReportsOnlyBacktracking(t *testing.T) {
+	t.Parallel()
+
+	a, b := pcb.String("a"), pcb.String("b")
+	ab := pcb.Prefixed(a, b)
+
+	parser := pcb.FirstSuccessful(ab, a)
+	newState, output := gomme.RunOnState(gomme.NewFromString("ac", false).WithStrictLL(), parser)
+	err := newState.Errors()
+	if output != "a" || err == nil {
+		t.Fatalf("got (%q, %v), want (%q, grammar error)", output, err, "a")
+	}
+	if got := err.Semantic(); len(got) != 1 || got[0].Pos() != 0 {
+		t.Errorf("got semantic errors %v, want 1 at position 0", got)
+	}
+
+	newState, _ = gomme.RunOnState(gomme.NewFromString("ac", false).WithStrictLL(), pcb.Many1(ab))
+	err = newState.Errors()
+	if err == nil {
+		t.Fatal("got no error, want the error of the failed parser")
+	}
+	if got := err.Semantic(); len(got) != 0 {
+		t.Errorf("got semantic errors %v, want none because nothing backtracks", got)
+	}
+}
@@ -0,0 +1,326 @@
+package gomme
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+)
+
+// TraceEvent is one event of a trace written by JSONTrace or BinaryTrace.
+// JSONTrace only writes the events "start", "success" and "failure".
+// BinaryTrace additionally writes the decisions of the error recovery:
+//   - "mode": `Parser` ended or starts at `Pos` in the new `Mode`,
+//   - "witness": `Parser` became the witness of the error at `ErrPos`,
+//   - "delete", "jump" and "escape": the recovery skipped the input from
+//     `Pos` to `End` (see RecoveryKind).
+type TraceEvent struct {
+	Event  string `json:"event"`           // "start", "success", "failure" or a recovery decision
+	Parser string `json:"parser"`          // expectation of the parser
+	Pos    int    `json:"pos"`             // input position at the start of the parser
+	Mode   string `json:"mode"`            // parsing mode at the start of the parser
+	End    int    `json:"end"`             // input position after a successful parser
+	Error  string `json:"error,omitempty"` // error of a failed parser
+	ErrPos int    `json:"errPos"`          // position of the error of a failed parser
+}
+
+// The binary trace starts with the header. Every event consists of:
+//   - the event kind (1 byte),
+//   - the parsing mode (1 byte),
+//   - the expectation of the parser (string reference),
+//   - the start position (uvarint),
+//   - for success: end - start (varint),
+//   - for failure: error position - start (varint) and the error text
+//     (string reference),
+//   - for witness: error position - start (varint),
+//   - for delete, jump and escape: the number of skipped bytes (uvarint).
+//
+// A string reference is the index in the string table (uvarint).
+// The first reference to a new string is followed by its length (uvarint)
+// and its bytes. So every expectation and error text is written only once.
+const binaryTraceHeader = "gomme-trace\x02"
+
+const (
+	traceStart byte = iota
+	traceSuccess
+	traceFailure
+	traceMode
+	traceWitness
+	traceDelete
+	traceJump
+	traceEscape
+)
+
+var traceEventNames = [...]string{
+	traceStart: "start", traceSuccess: "success", traceFailure: "failure",
+	traceMode: "mode", traceWitness: "witness",
+	traceDelete: "delete", traceJump: "jump", traceEscape: "escape",
+}
+
+// ErrInvalidTrace is returned by TraceReplayer for input that isn't a
+// binary trace.
+var ErrInvalidTrace = errors.New("gomme: invalid binary trace")
+
+// BinaryTrace returns a Middleware that records the start and result of
+// every parser and the decisions of the error recovery in a compact
+// binary format to `w`.
+// The recovery decisions are the switches of the parsing mode, the
+// witnesses of errors and the deleted, jumped over and escaped input
+// (see TraceEvent). The recovery never inserts input, so there are no
+// insertions to record.
+// Mode switches and witnesses are seen when a parser starts or ends.
+// So the middleware should be used for all parsers (see
+// WithGlobalMiddleware).
+// The trace can be replayed step by step with TraceReplayer.
+// So the decisions of a parse of a customer input (especially during
+// error recovery) can be analyzed offline.
+// `w` should be buffered (e.g. with bufio.Writer).
+// The middleware is safe for concurrent use. But the events of concurrent
+// parses are mixed up, so every parse should get its own trace.
+// Write errors are ignored.
+func BinaryTrace(w io.Writer) Middleware {
+	bt := &binaryTracer{w: w, strs: make(map[string]uint64), buf: []byte(binaryTraceHeader)}
+	return func(expected string, state State, next func(State) (State, *ParserError)) (State, *ParserError) {
+		if !slices.Contains(state.traces, bt) {
+			state.traces = append(slices.Clip(state.traces), bt)
+		}
+		pos, mode := state.CurrentPos(), state.ParsingMode()
+		bt.start(expected, mode, pos)
+		newState, err := next(state)
+		bt.end(expected, mode, pos, newState, err)
+		return newState, err
+	}
+}
+
+// binaryTracer writes the events of a BinaryTrace.
+type binaryTracer struct {
+	mutex       sync.Mutex
+	w           io.Writer
+	strs        map[string]uint64
+	buf         []byte
+	started     bool        // the first event has been written
+	lastMode    ParsingMode // mode of the last event
+	lastWitness uint64      // ID of the last witness (0: none)
+	lastWitPos  int         // position of the last witness
+}
+
+func (bt *binaryTracer) start(expected string, mode ParsingMode, pos int) {
+	bt.mutex.Lock()
+	defer bt.mutex.Unlock()
+	if bt.started && mode != bt.lastMode { // switched by the calling parser
+		bt.event(traceMode, expected, mode, pos)
+	}
+	bt.started = true
+	bt.lastMode = mode
+	bt.event(traceStart, expected, mode, pos)
+	bt.flush()
+}
+
+func (bt *binaryTracer) end(expected string, mode ParsingMode, pos int, newState State, err *ParserError) {
+	bt.mutex.Lock()
+	defer bt.mutex.Unlock()
+	witness, witPos := newState.errHand.witnessID, newState.errHand.witnessPos
+	if witness != 0 && (witness != bt.lastWitness || witPos != bt.lastWitPos) {
+		errPos := witPos
+		if err != nil {
+			errPos = err.Pos()
+		}
+		bt.event(traceWitness, expected, newState.mode, witPos)
+		bt.buf = binary.AppendVarint(bt.buf, int64(errPos-witPos))
+	}
+	bt.lastWitness, bt.lastWitPos = witness, witPos
+	if newState.mode != bt.lastMode {
+		bt.event(traceMode, expected, newState.mode, newState.CurrentPos())
+		bt.lastMode = newState.mode
+	}
+	if err != nil {
+		bt.event(traceFailure, expected, mode, pos)
+		bt.buf = binary.AppendVarint(bt.buf, int64(err.Pos()-pos))
+		bt.putString(err.Text())
+	} else {
+		bt.event(traceSuccess, expected, mode, pos)
+		bt.buf = binary.AppendVarint(bt.buf, int64(newState.CurrentPos()-pos))
+	}
+	bt.flush()
+}
+
+// recovery records an action of the error recovery (see State.recordRecovery).
+func (bt *binaryTracer) recovery(mode ParsingMode, event RecoveryEvent) {
+	bt.mutex.Lock()
+	defer bt.mutex.Unlock()
+	kind := traceDelete
+	switch event.Kind {
+	case RecoveryJump:
+		kind = traceJump
+	case RecoveryEscape:
+		kind = traceEscape
+	}
+	bt.event(kind, "", mode, event.Pos)
+	bt.buf = binary.AppendUvarint(bt.buf, uint64(event.Bytes))
+	bt.flush()
+}
+
+// event appends the fields that all events have.
+func (bt *binaryTracer) event(kind byte, expected string, mode ParsingMode, pos int) {
+	bt.buf = append(bt.buf, kind, byte(mode))
+	bt.putString(expected)
+	bt.buf = binary.AppendUvarint(bt.buf, uint64(pos))
+}
+
+func (bt *binaryTracer) putString(s string) {
+	if id, ok := bt.strs[s]; ok {
+		bt.buf = binary.AppendUvarint(bt.buf, id)
+		return
+	}
+	id := uint64(len(bt.strs))
+	bt.strs[s] = id
+	bt.buf = binary.AppendUvarint(bt.buf, id)
+	bt.buf = binary.AppendUvarint(bt.buf, uint64(len(s)))
+	bt.buf = append(bt.buf, s...)
+}
+
+func (bt *binaryTracer) flush() {
+	_, _ = bt.w.Write(bt.buf)
+	bt.buf = bt.buf[:0]
+}
+
+// TraceReplayer replays a trace written by BinaryTrace event by event.
+// It isn't safe for concurrent use.
+type TraceReplayer struct {
+	r      *bufio.Reader
+	header bool     // the header has been read
+	strs   []string // the string table
+	depth  int      // number of running parsers
+	level  int      // nesting level of the last event
+}
+
+// NewTraceReplayer returns a TraceReplayer that reads the trace from `r`.
+func NewTraceReplayer(r io.Reader) *TraceReplayer {
+	return &TraceReplayer{r: bufio.NewReader(r)}
+}
+
+// Next returns the next event of the trace.
+// It returns io.EOF at the end of the trace, io.ErrUnexpectedEOF for a
+// truncated trace and ErrInvalidTrace for corrupt data.
+func (rp *TraceReplayer) Next() (TraceEvent, error) {
+	if !rp.header {
+		header := make([]byte, len(binaryTraceHeader))
+		if _, err := io.ReadFull(rp.r, header); err != nil {
+			return TraceEvent{}, err
+		}
+		if string(header) != binaryTraceHeader {
+			return TraceEvent{}, ErrInvalidTrace
+		}
+		rp.header = true
+	}
+
+	kind, err := rp.r.ReadByte()
+	if err != nil {
+		return TraceEvent{}, err // io.EOF between events is the regular end
+	}
+	if int(kind) >= len(traceEventNames) {
+		return TraceEvent{}, ErrInvalidTrace
+	}
+	event := TraceEvent{Event: traceEventNames[kind]}
+	mode, err := rp.r.ReadByte()
+	if err != nil {
+		return TraceEvent{}, unexpectedEOF(err)
+	}
+	event.Mode = ParsingMode(mode).String()
+	if event.Parser, err = rp.readString(); err != nil {
+		return TraceEvent{}, err
+	}
+	pos, err := binary.ReadUvarint(rp.r)
+	if err != nil {
+		return TraceEvent{}, unexpectedEOF(err)
+	}
+	event.Pos = int(pos)
+
+	switch kind {
+	case traceStart:
+		rp.level = rp.depth
+		rp.depth++
+		return event, nil
+	case traceMode:
+		rp.level = rp.depth
+		return event, nil
+	case traceWitness:
+		delta, err := binary.ReadVarint(rp.r)
+		if err != nil {
+			return TraceEvent{}, unexpectedEOF(err)
+		}
+		event.ErrPos = event.Pos + int(delta)
+		rp.level = rp.depth
+		return event, nil
+	case traceDelete, traceJump, traceEscape:
+		n, err := binary.ReadUvarint(rp.r)
+		if err != nil {
+			return TraceEvent{}, unexpectedEOF(err)
+		}
+		event.End = event.Pos + int(n)
+		rp.level = rp.depth
+		return event, nil
+	case traceSuccess:
+		delta, err := binary.ReadVarint(rp.r)
+		if err != nil {
+			return TraceEvent{}, unexpectedEOF(err)
+		}
+		event.End = event.Pos + int(delta)
+	default:
+		delta, err := binary.ReadVarint(rp.r)
+		if err != nil {
+			return TraceEvent{}, unexpectedEOF(err)
+		}
+		event.ErrPos = event.Pos + int(delta)
+		if event.Error, err = rp.readString(); err != nil {
+			return TraceEvent{}, err
+		}
+	}
+	rp.depth = max(rp.depth-1, 0)
+	rp.level = rp.depth
+	return event, nil
+}
+
+// Depth returns the nesting level of the last event.
+// It is 0 for the outermost parser. Recovery decisions are one level
+// deeper than the parser running during them. So a debugger UI can indent the
+// events or step over a parser.
+func (rp *TraceReplayer) Depth() int {
+	return rp.level
+}
+
+// readString reads a string reference.
+func (rp *TraceReplayer) readString() (string, error) {
+	id, err := binary.ReadUvarint(rp.r)
+	switch {
+	case err != nil:
+		return "", unexpectedEOF(err)
+	case id < uint64(len(rp.strs)):
+		return rp.strs[id], nil
+	case id > uint64(len(rp.strs)):
+		return "", ErrInvalidTrace
+	}
+	n, err := binary.ReadUvarint(rp.r)
+	if err != nil {
+		return "", unexpectedEOF(err)
+	}
+	if n > 1<<20 { // no expectation or error text is that long
+		return "", ErrInvalidTrace
+	}
+	s := make([]byte, n)
+	if _, err = io.ReadFull(rp.r, s); err != nil {
+		return "", unexpectedEOF(err)
+	}
+	rp.strs = append(rp.strs, string(s))
+	return string(s), nil
+}
+
+// unexpectedEOF converts io.EOF in the middle of an event.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
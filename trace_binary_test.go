@@ -0,0 +1,100 @@
+package gomme_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"io"
+	"testing"
+)
+
+func TestBinaryTrace(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	parser := gomme.Wrap(pcb.String("abc"), gomme.BinaryTrace(&buf))
+	if _, err := gomme.RunOnString("abc", parser); err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	_, _ = gomme.RunOnString("xyz", parser)
+
+	var events []gomme.TraceEvent
+	replayer := gomme.NewTraceReplayer(bytes.NewReader(buf.Bytes()))
+	for {
+		event, err := replayer.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("got unexpected error after %d events: %v", len(events), err)
+		}
+		if replayer.Depth() != 0 {
+			t.Errorf("got depth %d for event %+v, want 0", replayer.Depth(), event)
+		}
+		events = append(events, event)
+	}
+	want := []gomme.TraceEvent{
+		{Event: "start", Parser: `"abc"`, Mode: "happy"},
+		{Event: "success", Parser: `"abc"`, Mode: "happy", End: 3},
+		{Event: "start", Parser: `"abc"`, Mode: "happy"},
+		{Event: "failure", Parser: `"abc"`, Mode: "happy", Error: `expected "abc"`},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %v", len(events), len(want), events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("got event %d: %+v, want %+v", i, events[i], want[i])
+		}
+	}
+
+	truncated := gomme.NewTraceReplayer(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	var err error
+	for err == nil {
+		_, err = truncated.Next()
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got error %v for truncated trace, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err = gomme.NewTraceReplayer(bytes.NewReader([]byte("not a trace at all"))).Next(); !errors.Is(err, gomme.ErrInvalidTrace) {
+		t.Errorf("got error %v for invalid trace, want %v", err, gomme.ErrInvalidTrace)
+	}
+}
+
+func TestBinaryTraceRecovery(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	g := gomme.NewGrammar(gomme.WithGlobalMiddleware(gomme.BinaryTrace(&buf)))
+	parser := pcb.Sequence(gomme.SaveSpot(pcb.String("a")), pcb.String("b"), gomme.SaveSpot(pcb.String("c")))
+	newState, _ := gomme.RunOnState(g.NewFromString("a##bc", true), parser)
+	if !newState.HasError() {
+		t.Fatalf("got no error, want the error at '#'")
+	}
+
+	seen := make(map[string]bool)
+	replayer := gomme.NewTraceReplayer(bytes.NewReader(buf.Bytes()))
+	for {
+		event, err := replayer.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		switch event.Event {
+		case "mode":
+			seen["mode "+event.Mode] = true
+		case "witness":
+			seen["witness"] = event.Pos == 0 && event.ErrPos == 1
+		case "delete":
+			seen["delete"] = event.Pos == 1 && event.End == 3
+		}
+	}
+	for _, want := range []string{"mode error", "mode handle", "mode happy", "witness", "delete"} {
+		if !seen[want] {
+			t.Errorf("got no %q event in the trace (seen: %v)", want, seen)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+//go:build !gomme_tiny
+
+package gomme
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// The JSON trace is left out with the build tag `gomme_tiny`,
+// because encoding/json relies heavily on reflection (see TINYGO.md).
+
+// JSONTrace returns a Middleware that writes the start and result of every
+// parser as newline delimited JSON (see TraceEvent) to `w`.
+// So traces of huge parses can be analyzed with tools like jq.
+// The middleware is safe for concurrent use. Write errors are ignored.
+func JSONTrace(w io.Writer) Middleware {
+	var mutex sync.Mutex
+	enc := json.NewEncoder(w)
+	emit := func(event TraceEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		_ = enc.Encode(event)
+	}
+	return func(expected string, state State, next func(State) (State, *ParserError)) (State, *ParserError) {
+		event := TraceEvent{
+			Event: "start", Parser: expected, Pos: state.CurrentPos(), Mode: state.ParsingMode().String(),
+		}
+		emit(event)
+		newState, err := next(state)
+		if err != nil {
+			event.Event, event.Error, event.ErrPos = "failure", err.Text(), err.Pos()
+		} else {
+			event.Event, event.End = "success", newState.CurrentPos()
+		}
+		emit(event)
+		return newState, err
+	}
+}
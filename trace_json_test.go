@@ -0,0 +1,46 @@
+//go:build !gomme_tiny
+
+package gomme_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/oleiade/gomme"
+	"github.com/oleiade/gomme/pcb"
+	"strings"
+	"testing"
+)
+
+func TestJSONTrace(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	parser := gomme.Wrap(pcb.String("abc"), gomme.JSONTrace(&buf))
+	if _, err := gomme.RunOnString("abc", parser); err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	_, _ = gomme.RunOnString("xyz", parser)
+
+	var events []gomme.TraceEvent
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event gomme.TraceEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("got invalid JSON line %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	want := []gomme.TraceEvent{
+		{Event: "start", Parser: `"abc"`, Mode: "happy"},
+		{Event: "success", Parser: `"abc"`, Mode: "happy", End: 3},
+		{Event: "start", Parser: `"abc"`, Mode: "happy"},
+		{Event: "failure", Parser: `"abc"`, Mode: "happy", Error: `expected "abc"`},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %v", len(events), len(want), events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("got event %d: %+v, want %+v", i, events[i], want[i])
+		}
+	}
+}